@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/distninja/distninja/store"
+	"github.com/distninja/distninja/utils"
+)
+
+var cleanStore string
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove the build graph of a running server or a local store",
+	Long: "Remove all rules, builds, targets and files, role bindings, webhooks and audit entries are kept.\n" +
+		"The store is compacted afterwards so its file shrinks. With --store the local store is reset,\n" +
+		"it must not be in use by a server.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var result map[string]interface{}
+		var err error
+
+		if cleanStore != "" {
+			result, err = cleanLocal(cmd.Context(), utils.ExpandTilde(cleanStore))
+		} else {
+			result, err = cleanRemote()
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return printResult(cmd.Context(), result, "status", "removed.rules", "removed.builds", "removed.targets",
+			"removed.files", "compacted.bytes_before", "compacted.bytes_after")
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(cleanCmd)
+	addClientFlags(cleanCmd)
+
+	cleanCmd.Flags().StringVar(&cleanStore, "store", "", "reset and compact this local store instead of a server")
+}
+
+// cleanRemote resets the store of the server and compacts its file
+func cleanRemote() (map[string]interface{}, error) {
+	client, err := newAPIClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// Both rewrite the whole store, the server allows them as long as a load
+	client.http.Timeout = loadTimeout
+
+	var result map[string]interface{}
+	if err := client.do(http.MethodPost, "/admin/reset", nil, &result); err != nil {
+		return nil, err
+	}
+
+	var compacted map[string]interface{}
+	if err := client.do(http.MethodPost, "/admin/compact", nil, &compacted); err != nil {
+		return nil, fmt.Errorf("store was reset but not compacted, retry POST /api/v1/admin/compact: %w", err)
+	}
+
+	result["compacted"] = compacted
+
+	return result, nil
+}
+
+// cleanLocal resets the store at path and compacts its file
+func cleanLocal(ctx context.Context, path string) (map[string]interface{}, error) {
+	// Opening would create an empty store
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to find store: %w", err)
+	}
+
+	ninjaStore, err := openLocalStore(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+
+	defer func(ninjaStore *store.NinjaStore) {
+		_ = ninjaStore.Close()
+	}(ninjaStore)
+
+	stats, err := ninjaStore.Reset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	compacted, err := ninjaStore.Compact(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Same shape as the server response, so table columns resolve alike
+	return map[string]interface{}{
+		"status": "reset",
+		"removed": map[string]interface{}{
+			"rules":   stats.Rules,
+			"builds":  stats.Builds,
+			"targets": stats.Targets,
+			"files":   stats.Files,
+		},
+		"compacted": map[string]interface{}{
+			"bytes_before": compacted.BytesBefore,
+			"bytes_after":  compacted.BytesAfter,
+		},
+	}, nil
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/distninja/distninja/utils"
+)
+
+const (
+	defaultServerURL = "http://127.0.0.1:9090"
+	clientTimeout    = 30 * time.Second
+)
+
+// Output formats of the client commands
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+var (
+	serverURL    string
+	clientToken  string
+	outputFormat string
+)
+
+// addClientFlags registers the flags of commands talking to a running server and printing its answers
+func addClientFlags(cmd *cobra.Command) {
+	addServerFlags(cmd)
+	cmd.PersistentFlags().StringVarP(&outputFormat, "format", "o", formatTable, "output format (table, json, yaml)")
+
+	// Takes precedence over the hook of addServerFlags, the format is checked before any request is sent
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+
+		switch outputFormat {
+		case formatTable, formatJSON, formatYAML:
+			return nil
+		default:
+			return fmt.Errorf("unknown format %s, expected table, json or yaml", outputFormat)
+		}
+	}
+}
+
+// addServerFlags registers the flags selecting and authenticating to a running server
+func addServerFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&serverURL, "server", "", "server url (env DISTNINJA_SERVER, default "+defaultServerURL+")")
+	cmd.PersistentFlags().StringVar(&clientToken, "token", "", "api key or bearer token (env DISTNINJA_TOKEN)")
+
+	// Arguments are valid once this runs, request failures are printed once by Execute without the usage
+	cmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+	}
+}
+
+// apiClient calls the HTTP API of a running server
+type apiClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func newAPIClient() (*apiClient, error) {
+	base := serverURL
+	if base == "" {
+		base = os.Getenv("DISTNINJA_SERVER")
+	}
+	if base == "" {
+		base = defaultServerURL
+	}
+
+	token := clientToken
+	if token == "" {
+		token = os.Getenv("DISTNINJA_TOKEN")
+	}
+
+	return &apiClient{
+		baseURL: strings.TrimSuffix(base, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: clientTimeout},
+	}, nil
+}
+
+// do sends a request with a JSON body to path below /api/v1 and decodes the JSON response into out
+func (c *apiClient) do(method, path string, body, out interface{}) error {
+	if body == nil {
+		return c.send(method, path, "", nil, out)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	return c.send(method, path, "application/json", bytes.NewReader(data), out)
+}
+
+// send sends a request with a raw body to path below /api/v1 and decodes the JSON response into out
+func (c *apiClient) send(method, path, contentType string, body io.Reader, out interface{}) error {
+	resp, err := c.open(method, path, contentType, body)
+	if err != nil {
+		return err
+	}
+
+	defer func(body io.ReadCloser) {
+		_ = body.Close()
+	}(resp)
+
+	if out == nil {
+		return nil
+	}
+
+	decoder := json.NewDecoder(resp)
+	// Keeps large counts readable in tables
+	decoder.UseNumber()
+
+	return decoder.Decode(out)
+}
+
+// open sends a request to path below /api/v1 and returns the body of a successful response
+func (c *apiClient) open(method, path, contentType string, body io.Reader) (io.ReadCloser, error) {
+	req, err := http.NewRequest(method, c.baseURL+"/api/v1"+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach server: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer func(body io.ReadCloser) {
+			_ = body.Close()
+		}(resp.Body)
+
+		// The request ID finds the request in the server logs and audit log
+		suffix := ""
+		if id := resp.Header.Get(utils.RequestIDHeader); id != "" {
+			suffix = " (request id " + id + ")"
+		}
+
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("%s: %s%s", resp.Status, apiErr.Error, suffix)
+		}
+		return nil, fmt.Errorf("%s%s", resp.Status, suffix)
+	}
+
+	return resp.Body, nil
+}
+
+// dial opens a websocket to path below /api/v1
+func (c *apiClient) dial(path string) (*websocket.Conn, error) {
+	address := c.baseURL + "/api/v1" + path
+	switch {
+	case strings.HasPrefix(address, "https://"):
+		address = "wss://" + strings.TrimPrefix(address, "https://")
+	case strings.HasPrefix(address, "http://"):
+		address = "ws://" + strings.TrimPrefix(address, "http://")
+	}
+
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = clientTimeout
+
+	conn, resp, err := dialer.Dial(address, header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("failed to reach server: %s", resp.Status)
+		}
+		return nil, fmt.Errorf("failed to reach server: %w", err)
+	}
+
+	return conn, nil
+}
+
+// graphQL runs a GraphQL query and decodes its data into out
+func (c *apiClient) graphQL(query string, out interface{}) error {
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := c.do(http.MethodPost, "/graphql", map[string]string{"query": query}, &resp); err != nil {
+		return err
+	}
+
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("query failed: %s", resp.Errors[0].Message)
+	}
+
+	return json.Unmarshal(resp.Data, out)
+}
+
+// escapePath escapes a target path for use in a URL while keeping its slashes
+func escapePath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// printResult writes v to stdout in the selected format, tables list the columns of each object in v,
+// nested fields are addressed as parent.child
+func printResult(ctx context.Context, v interface{}, columns ...string) error {
+	switch outputFormat {
+	case formatJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case formatYAML:
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+
+	rows, ok := v.([]interface{})
+	if !ok {
+		rows = []interface{}{v}
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = strings.ReplaceAll(column, ".", " ")
+	}
+
+	data := [][]string{header}
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = tableCell(lookupField(row, column))
+		}
+		data = append(data, cells)
+	}
+
+	return utils.WriteTable(ctx, data)
+}
+
+// lookupField returns the value at a dotted path of nested objects, lists yield the value of each item
+func lookupField(v interface{}, path string) interface{} {
+	key, rest, nested := strings.Cut(path, ".")
+
+	switch value := v.(type) {
+	case []interface{}:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = lookupField(item, path)
+		}
+		return items
+	case map[string]interface{}:
+		if nested {
+			return lookupField(value[key], rest)
+		}
+		return value[key]
+	default:
+		return nil
+	}
+}
+
+func tableCell(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "-"
+	case string:
+		return value
+	case []interface{}:
+		cells := make([]string, len(value))
+		for i, item := range value {
+			cells[i] = tableCell(item)
+		}
+		return strings.Join(cells, ",")
+	case map[string]interface{}:
+		data, _ := json.Marshal(value)
+		return string(data)
+	default:
+		return fmt.Sprint(value)
+	}
+}
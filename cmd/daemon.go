@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/distninja/distninja/utils"
+)
+
+const (
+	// daemonEnv marks the background process started by serve --daemon
+	daemonEnv = "DISTNINJA_DAEMON"
+
+	defaultPidFile = "distninja.pid"
+	defaultLogFile = "distninja.log"
+
+	// daemonStartupWait is how long serve --daemon watches the background process for early failures
+	daemonStartupWait = time.Second
+	// daemonStopTimeout covers the graceful shutdown of the servers
+	daemonStopTimeout = 30 * time.Second
+)
+
+// startDaemon starts serve again as a detached background process and returns once it is running
+func startDaemon() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find executable: %w", err)
+	}
+
+	if pid, running := readPidFile(pidFile); running {
+		return fmt.Errorf("distninja is already running with pid %d", pid)
+	}
+
+	if logFile == "" {
+		logFile = defaultLogFile
+	}
+
+	// Output before the logger is set up, such as panics, ends up in the log file too
+	output, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, utils.PermFile)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %s: %w", logFile, err)
+	}
+
+	defer func(output *os.File) {
+		_ = output.Close()
+	}(output)
+
+	// The last --log-file wins, so the default applies to the daemon too
+	child := exec.Command(executable, append(os.Args[1:], "--log-file="+logFile)...)
+	child.Env = append(os.Environ(), daemonEnv+"=1")
+	child.Stdout = output
+	child.Stderr = output
+	if err := detach(child); err != nil {
+		return err
+	}
+
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("failed to start daemon: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- child.Wait()
+	}()
+
+	select {
+	case err := <-exited:
+		return fmt.Errorf("daemon exited during startup (%v), see %s", err, logFile)
+	case <-time.After(daemonStartupWait):
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "distninja started with pid %d, logging to %s\n", child.Process.Pid, logFile)
+
+	return nil
+}
+
+// setupProcess sends logs to the rotating log file if one is configured and writes the pid file if
+// writePid is set, the returned function undoes both
+func setupProcess(writePid bool) (func(), error) {
+	var cleanups []func()
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+
+	if logFile != "" {
+		output, err := utils.NewRotatingFile(logFile, logMaxBytes, logMaxBackups)
+		if err != nil {
+			return nil, err
+		}
+
+		logger, err := utils.NewLogger(output, logLevel, logFormat)
+		if err != nil {
+			_ = output.Close()
+			return nil, err
+		}
+
+		previous := slog.Default()
+		slog.SetDefault(logger)
+
+		reload := make(chan os.Signal, 1)
+		notifyReload(reload)
+
+		go func() {
+			for range reload {
+				if err := output.Reopen(); err != nil {
+					slog.Error("failed to reopen log file", "path", logFile, "error", err)
+					continue
+				}
+				slog.Info("reopened log file", "path", logFile)
+			}
+		}()
+
+		cleanups = append(cleanups, func() {
+			signal.Stop(reload)
+			close(reload)
+			slog.SetDefault(previous)
+			_ = output.Close()
+		})
+	}
+
+	if writePid {
+		if err := writePidFile(pidFile); err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		cleanups = append(cleanups, func() {
+			_ = os.Remove(pidFile)
+		})
+	}
+
+	return cleanup, nil
+}
+
+// writePidFile records the pid of this process, a file left behind by a process that is gone is replaced
+func writePidFile(path string) error {
+	if pid, running := readPidFile(path); running && pid != os.Getpid() {
+		return fmt.Errorf("distninja is already running with pid %d", pid)
+	}
+
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), utils.PermFile); err != nil {
+		return fmt.Errorf("failed to write pid file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// readPidFile returns the pid recorded in path and whether that process is still running
+func readPidFile(path string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+
+	return pid, processRunning(pid)
+}
+
+// stopDaemon asks the process of the pid file to shut down and waits until it is gone
+func stopDaemon() error {
+	pid, running := readPidFile(pidFile)
+	if !running {
+		_ = os.Remove(pidFile)
+		return errors.New("distninja is not running")
+	}
+
+	if err := terminateProcess(pid); err != nil {
+		return fmt.Errorf("failed to stop pid %d: %w", pid, err)
+	}
+
+	for deadline := time.Now().Add(daemonStopTimeout); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		if !processRunning(pid) {
+			_, _ = fmt.Fprintf(os.Stdout, "distninja with pid %d stopped\n", pid)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("distninja with pid %d did not stop within %s", pid, daemonStopTimeout)
+}
+
+// reloadDaemon asks the process of the pid file to reopen its log file
+func reloadDaemon() error {
+	pid, running := readPidFile(pidFile)
+	if !running {
+		return errors.New("distninja is not running")
+	}
+
+	if err := reloadProcess(pid); err != nil {
+		return fmt.Errorf("failed to reload pid %d: %w", pid, err)
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "distninja with pid %d reloaded\n", pid)
+
+	return nil
+}
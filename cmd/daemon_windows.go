@@ -0,0 +1,35 @@
+//go:build windows
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+var errDaemonUnsupported = errors.New("daemon mode is not supported on windows, run distninja as a service instead")
+
+func detach(_ *exec.Cmd) error {
+	return errDaemonUnsupported
+}
+
+// notifyReload is a no-op, windows has no reload signal
+func notifyReload(_ chan<- os.Signal) {}
+
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = process.Release()
+	return true
+}
+
+func terminateProcess(_ int) error {
+	return errDaemonUnsupported
+}
+
+func reloadProcess(_ int) error {
+	return errDaemonUnsupported
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// formatPaths prints one path per line for xargs and shell loops
+const formatPaths = "paths"
+
+var (
+	depsTransitive bool
+	depsDepth      int
+	depsFormat     string
+)
+
+var depsCmd = &cobra.Command{
+	Use:   "deps <target>...",
+	Short: "List the files targets depend on",
+	Long: "List the files targets depend on, with --transitive also the dependencies of the targets among them.\n" +
+		"Paths are printed one per line, e.g. for xargs.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDeps(cmd, args, "dependencies", "path", "file_type")
+	},
+}
+
+var rdepsCmd = &cobra.Command{
+	Use:   "rdeps <file>...",
+	Short: "List the targets depending on files",
+	Long: "List the targets depending on files, with --transitive also the targets depending on those targets.\n" +
+		"Paths are printed one per line, e.g. for xargs.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDeps(cmd, args, "reverse_dependencies", "path", "status", "build")
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	for _, cmd := range []*cobra.Command{depsCmd, rdepsCmd} {
+		rootCmd.AddCommand(cmd)
+		addServerFlags(cmd)
+
+		cmd.Flags().BoolVar(&depsTransitive, "transitive", false, "follow dependencies through other targets")
+		cmd.Flags().IntVar(&depsDepth, "depth", 0, "levels to follow, unlimited when 0, implies --transitive")
+		cmd.Flags().StringVarP(&depsFormat, "format", "o", formatPaths, "output format (paths, table, json, yaml)")
+	}
+}
+
+// runDeps queries endpoint of each path and prints the union of the results, sorted by path
+func runDeps(cmd *cobra.Command, paths []string, endpoint string, columns ...string) error {
+	switch depsFormat {
+	case formatPaths, formatTable, formatJSON, formatYAML:
+	default:
+		return fmt.Errorf("unknown format %s, expected paths, table, json or yaml", depsFormat)
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if depsTransitive {
+		query.Set("transitive", "true")
+	}
+	if depsDepth > 0 {
+		query.Set("depth", strconv.Itoa(depsDepth))
+	}
+
+	seen := make(map[string]bool)
+	var results []interface{}
+
+	for _, path := range paths {
+		var items []interface{}
+		if err := client.do(http.MethodGet, "/targets/"+escapePath(path)+"/"+endpoint+"?"+query.Encode(), nil, &items); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		for _, item := range items {
+			itemPath, _ := lookupField(item, "path").(string)
+			if !seen[itemPath] {
+				seen[itemPath] = true
+				results = append(results, item)
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, _ := lookupField(results[i], "path").(string)
+		b, _ := lookupField(results[j], "path").(string)
+		return a < b
+	})
+
+	if depsFormat != formatPaths {
+		outputFormat = depsFormat
+		return printResult(cmd.Context(), results, columns...)
+	}
+
+	for _, item := range results {
+		_, _ = fmt.Fprintln(os.Stdout, lookupField(item, "path"))
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/distninja/distninja/parser"
+	"github.com/distninja/distninja/utils"
+)
+
+// formatText prints one line per change followed by its details
+const formatText = "text"
+
+var (
+	diffFailOnChange bool
+	diffFormat       string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old ninja file> <new ninja file>",
+	Short: "Compare the rules and builds of two ninja files",
+	Long: "Compare the rules and builds of two ninja files and their includes without a server, e.g. before and\n" +
+		"after a generator re-run. Added, removed and changed entries are printed with +, - and ~, builds are\n" +
+		"named by their outputs. With --fail-on-change the command fails if anything changed.",
+	Args: cobra.ExactArgs(2),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch diffFormat {
+		case formatText, formatJSON, formatYAML:
+		default:
+			return fmt.Errorf("unknown format %s, expected text, json or yaml", diffFormat)
+		}
+
+		manifests := make([]*parser.Manifest, len(args))
+		for i, path := range args {
+			manifest, err := readDiffManifest(utils.ExpandTilde(path))
+			if err != nil {
+				return err
+			}
+			manifests[i] = manifest
+		}
+
+		changes := parser.DiffManifests(manifests[0], manifests[1])
+
+		if diffFormat != formatText {
+			outputFormat = diffFormat
+			if changes == nil {
+				changes = []parser.ManifestChange{}
+			}
+			if err := printResult(cmd.Context(), changes); err != nil {
+				return err
+			}
+		} else {
+			for _, change := range changes {
+				_, _ = fmt.Fprintln(os.Stdout, change.String())
+			}
+		}
+
+		if diffFailOnChange && len(changes) > 0 {
+			return errors.New(diffSummary(changes))
+		}
+
+		return nil
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().BoolVar(&diffFailOnChange, "fail-on-change", false, "fail if the build graph changed")
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "o", formatText, "output format (text, json, yaml)")
+}
+
+// readDiffManifest reads a ninja file, its includes are resolved in the directory of the file
+func readDiffManifest(path string) (*parser.Manifest, error) {
+	manifest, diagnostics, err := parser.ReadManifest(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+
+	// A statement that can't be parsed would show up as a removed build or rule
+	if len(diagnostics) > 0 {
+		for _, diagnostic := range diagnostics {
+			_, _ = fmt.Fprintln(os.Stderr, diagnostic.String())
+		}
+		return nil, fmt.Errorf("failed to parse %s", path)
+	}
+
+	return manifest, nil
+}
+
+// diffSummary counts the changes by kind and change
+func diffSummary(changes []parser.ManifestChange) string {
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Change]++
+	}
+
+	return fmt.Sprintf("build graph changed: %d added, %d removed, %d changed",
+		counts[parser.ChangeAdded], counts[parser.ChangeRemoved], counts[parser.ChangeChanged])
+}
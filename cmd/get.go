@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	targetDeps       bool
+	targetDependents bool
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Show targets, rules and builds of a running server",
+}
+
+var getTargetsCmd = &cobra.Command{
+	Use:   "targets",
+	Short: "List all targets",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		var targets []interface{}
+		if err := client.do(http.MethodGet, "/targets", nil, &targets); err != nil {
+			return err
+		}
+
+		return printResult(cmd.Context(), targets, "path", "status", "hash", "build")
+	},
+}
+
+var getRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "List all rules",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		var data struct {
+			Rules []interface{} `json:"rules"`
+		}
+		if err := client.graphQL("{ rules { name command description } }", &data); err != nil {
+			return err
+		}
+
+		return printResult(cmd.Context(), data.Rules, "name", "command", "description")
+	},
+}
+
+var getBuildsCmd = &cobra.Command{
+	Use:   "builds",
+	Short: "List all builds",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		var data struct {
+			Builds []interface{} `json:"builds"`
+		}
+		if err := client.graphQL("{ builds { id pool rule { name } outputs { path } } }", &data); err != nil {
+			return err
+		}
+
+		return printResult(cmd.Context(), data.Builds, "id", "rule.name", "pool", "outputs.path")
+	},
+}
+
+var getTargetCmd = &cobra.Command{
+	Use:   "target <path>",
+	Short: "Show a target, or its dependencies with --deps",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		path := "/targets/" + escapePath(args[0])
+
+		switch {
+		case targetDeps && targetDependents:
+			return fmt.Errorf("--deps and --dependents are mutually exclusive")
+		case targetDeps:
+			var files []interface{}
+			if err := client.do(http.MethodGet, path+"/dependencies", nil, &files); err != nil {
+				return err
+			}
+			return printResult(cmd.Context(), files, "path", "file_type")
+		case targetDependents:
+			var targets []interface{}
+			if err := client.do(http.MethodGet, path+"/reverse_dependencies", nil, &targets); err != nil {
+				return err
+			}
+			return printResult(cmd.Context(), targets, "path", "status", "build")
+		}
+
+		var target interface{}
+		if err := client.do(http.MethodGet, path, nil, &target); err != nil {
+			return err
+		}
+
+		return printResult(cmd.Context(), target, "path", "status", "hash", "build")
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(getCmd)
+	addClientFlags(getCmd)
+
+	getTargetCmd.Flags().BoolVar(&targetDeps, "deps", false, "list the files the target depends on")
+	getTargetCmd.Flags().BoolVar(&targetDependents, "dependents", false, "list the targets depending on the target")
+
+	getCmd.AddCommand(getTargetsCmd, getRulesCmd, getBuildsCmd, getTargetCmd)
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// Graph formats rendered by the graph command, the others are passed through from the export API
+const (
+	graphFormatDOT = "dot"
+	graphFormatSVG = "svg"
+)
+
+var (
+	graphTarget string
+	graphDepth  int
+	graphFormat string
+	graphOutput string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Render the dependency graph of a running server",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exportFormat := graphFormat
+		dotPath := ""
+
+		switch graphFormat {
+		case graphFormatSVG:
+			// Rendered locally from DOT
+			exportFormat = graphFormatDOT
+
+			var err error
+			if dotPath, err = exec.LookPath("dot"); err != nil {
+				return fmt.Errorf("svg output needs graphviz, install it or use --format dot: %w", err)
+			}
+		case graphFormatDOT, "graphml", "cyjs":
+		default:
+			return fmt.Errorf("unknown graph format %s, expected dot, svg, graphml or cyjs", graphFormat)
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		query := url.Values{"format": {exportFormat}}
+		if graphTarget != "" {
+			query.Set("root", graphTarget)
+		}
+		if graphDepth > 0 {
+			query.Set("depth", strconv.Itoa(graphDepth))
+		}
+
+		graph, err := client.open(http.MethodGet, "/graph?"+query.Encode(), "", nil)
+		if err != nil {
+			return err
+		}
+
+		defer func(graph io.ReadCloser) {
+			_ = graph.Close()
+		}(graph)
+
+		out := io.Writer(os.Stdout)
+		if graphOutput != "" && graphOutput != "-" {
+			file, err := os.Create(graphOutput)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", graphOutput, err)
+			}
+			defer func(file *os.File) {
+				_ = file.Close()
+			}(file)
+			out = file
+		}
+
+		if graphFormat != graphFormatSVG {
+			_, err = io.Copy(out, graph)
+			return err
+		}
+
+		return renderSVG(dotPath, out, graph)
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	addServerFlags(graphCmd)
+
+	graphCmd.Flags().StringVar(&graphTarget, "target", "", "only the subgraph below this target")
+	graphCmd.Flags().IntVar(&graphDepth, "depth", 0, "levels of dependencies below --target, 0 for all")
+	graphCmd.Flags().StringVar(&graphFormat, "format", graphFormatDOT, "output format (dot, svg, graphml, cyjs), svg needs graphviz")
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", "", "write to this file instead of stdout")
+}
+
+// renderSVG converts a DOT graph to SVG with the graphviz dot binary at dotPath
+func renderSVG(dotPath string, w io.Writer, dot io.Reader) error {
+	render := exec.Command(dotPath, "-Tsvg")
+	render.Stdin = dot
+	render.Stdout = w
+	render.Stderr = os.Stderr
+
+	if err := render.Run(); err != nil {
+		return fmt.Errorf("failed to render svg: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/distninja/distninja/parser"
+	"github.com/distninja/distninja/utils"
+)
+
+// importFormats convert other build descriptions into a ninja manifest
+var importFormats = map[string]func(r io.Reader) (*parser.Manifest, error){
+	"aquery": parser.ReadAquery,
+	"make":   parser.ReadMakefile,
+}
+
+var (
+	importOutput string
+	importStore  string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <format> <file>",
+	Short: "Convert another build description and load it like a ninja file",
+	Long: "Convert another build description into ninja and load it into a running server or a local store,\n" +
+		"or write the ninja file with --output. Formats:\n" +
+		"  aquery  bazel aquery --output=proto or --output=jsonproto, one rule per action mnemonic\n" +
+		"  make    a GNU Makefile using variables, explicit, pattern and static pattern rules and .PHONY\n" +
+		"Use - as the file to read standard input.",
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		read, ok := importFormats[args[0]]
+		if !ok {
+			return fmt.Errorf("unknown format %s, expected %s", args[0], strings.Join(importFormatNames(), ", "))
+		}
+
+		input := os.Stdin
+		if args[1] != "-" {
+			file, err := os.Open(utils.ExpandTilde(args[1]))
+			if err != nil {
+				return err
+			}
+
+			defer func(file *os.File) {
+				_ = file.Close()
+			}(file)
+
+			input = file
+		}
+
+		manifest, err := read(input)
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", args[1], err)
+		}
+
+		var content bytes.Buffer
+		if err := manifest.WriteNinja(&content); err != nil {
+			return err
+		}
+
+		if importOutput != "" {
+			if importOutput == "-" {
+				_, err := content.WriteTo(os.Stdout)
+				return err
+			}
+			return os.WriteFile(utils.ExpandTilde(importOutput), content.Bytes(), utils.PermFile)
+		}
+
+		var result map[string]interface{}
+		if importStore != "" {
+			result, err = loadLocal(cmd.Context(), utils.ExpandTilde(importStore), &content)
+		} else {
+			result, err = loadRemote(&content)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return printLoadResult(cmd, result)
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(importCmd)
+	addClientFlags(importCmd)
+
+	importCmd.Flags().StringVar(&importOutput, "output", "", "write the ninja file here instead of loading it, - for standard output")
+	importCmd.Flags().StringVar(&importStore, "store", "", "load into this local store instead of a server")
+	importCmd.MarkFlagsMutuallyExclusive("output", "store")
+}
+
+func importFormatNames() []string {
+	names := make([]string, 0, len(importFormats))
+	for name := range importFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/distninja/distninja/parser"
+	"github.com/distninja/distninja/utils"
+)
+
+// cmakeNinjaFile is the top level ninja file written by the CMake ninja generator
+const cmakeNinjaFile = "build.ninja"
+
+var (
+	initCMake     string
+	initBuildDir  string
+	initCMakeArgs []string
+	initReconfig  bool
+	initStore     string
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init --cmake <srcdir>",
+	Short: "Generate the ninja files of a project and load them",
+	Long: "Run the CMake ninja generator for the project in <srcdir> and load the generated build.ninja with\n" +
+		"the rules.ninja it includes. A build directory that was already configured is loaded as it is, use\n" +
+		"--reconfigure to run cmake again.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		srcDir := utils.ExpandTilde(initCMake)
+
+		buildDir := filepath.Join(srcDir, "build")
+		if initBuildDir != "" {
+			buildDir = utils.ExpandTilde(initBuildDir)
+		}
+
+		if err := configureCMake(srcDir, buildDir); err != nil {
+			return err
+		}
+
+		content := parser.NewIncludeReader(buildDir, cmakeNinjaFile)
+		defer func(content io.ReadCloser) {
+			_ = content.Close()
+		}(content)
+
+		var result map[string]interface{}
+		var err error
+
+		if initStore != "" {
+			result, err = loadLocal(cmd.Context(), utils.ExpandTilde(initStore), content)
+		} else {
+			result, err = loadRemote(content)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return printLoadResult(cmd, result)
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(initCmd)
+	addClientFlags(initCmd)
+
+	initCmd.Flags().StringVar(&initCMake, "cmake", "", "source directory of the CMake project")
+	initCmd.Flags().StringVarP(&initBuildDir, "build-dir", "B", "", "build directory, <srcdir>/build by default")
+	initCmd.Flags().StringArrayVar(&initCMakeArgs, "cmake-arg", nil, "extra argument for cmake, e.g. -DCMAKE_BUILD_TYPE=Release")
+	initCmd.Flags().BoolVar(&initReconfig, "reconfigure", false, "run cmake even if the build directory is configured")
+	initCmd.Flags().StringVar(&initStore, "store", "", "load into this local store instead of a server")
+	_ = initCmd.MarkFlagRequired("cmake")
+}
+
+// configureCMake runs the CMake ninja generator unless buildDir already holds its output
+func configureCMake(srcDir, buildDir string) error {
+	if !initReconfig {
+		if _, err := os.Stat(filepath.Join(buildDir, cmakeNinjaFile)); err == nil {
+			return nil
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(srcDir, "CMakeLists.txt")); err != nil {
+		return fmt.Errorf("%s is not a CMake project: %w", srcDir, err)
+	}
+
+	cmakePath, err := exec.LookPath("cmake")
+	if err != nil {
+		return fmt.Errorf("cmake is needed to configure %s: %w", srcDir, err)
+	}
+
+	configure := exec.Command(cmakePath, append([]string{"-S", srcDir, "-B", buildDir, "-G", "Ninja"}, initCMakeArgs...)...)
+	configure.Stdout = os.Stderr
+	configure.Stderr = os.Stderr
+
+	if err := configure.Run(); err != nil {
+		return fmt.Errorf("failed to configure %s: %w", srcDir, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/distninja/distninja/parser"
+	"github.com/distninja/distninja/store"
+	"github.com/distninja/distninja/utils"
+)
+
+// loadTimeout matches the server side limit of /api/v1/load
+const loadTimeout = 30 * time.Minute
+
+var (
+	loadDir   string
+	loadFile  string
+	loadStore string
+)
+
+var loadCmd = &cobra.Command{
+	Use:   "load",
+	Short: "Load a ninja file into a running server or a local store",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := utils.ExpandTilde(loadDir)
+
+		content := parser.NewIncludeReader(dir, loadFile)
+		defer func(content io.ReadCloser) {
+			_ = content.Close()
+		}(content)
+
+		var result map[string]interface{}
+		var err error
+
+		if loadStore != "" {
+			result, err = loadLocal(cmd.Context(), utils.ExpandTilde(loadStore), content)
+		} else {
+			result, err = loadRemote(content)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return printLoadResult(cmd, result)
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(loadCmd)
+	addClientFlags(loadCmd)
+
+	loadCmd.Flags().StringVarP(&loadDir, "directory", "C", ".", "directory to resolve the ninja file and its includes in")
+	loadCmd.Flags().StringVarP(&loadFile, "file", "f", "build.ninja", "ninja file to load")
+	loadCmd.Flags().StringVar(&loadStore, "store", "", "load into this local store instead of a server")
+}
+
+// loadRemote streams content to the server
+func loadRemote(content io.Reader) (map[string]interface{}, error) {
+	client, err := newAPIClient()
+	if err != nil {
+		return nil, err
+	}
+
+	client.http.Timeout = loadTimeout
+
+	var result map[string]interface{}
+	if err := client.send(http.MethodPost, "/load", "text/plain", content, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// loadLocal parses content into the store at path, which must not be in use by a server
+func loadLocal(ctx context.Context, path string, content io.Reader) (map[string]interface{}, error) {
+	startTime := time.Now()
+
+	ninjaStore, err := openLocalStore(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+
+	defer func(ninjaStore *store.NinjaStore) {
+		_ = ninjaStore.Close()
+	}(ninjaStore)
+
+	if err := parser.NewNinjaParser(ninjaStore).ParseAndLoadReader(ctx, content); err != nil {
+		return nil, fmt.Errorf("failed to parse and load Ninja file: %w", err)
+	}
+
+	stats, err := ninjaStore.GetBuildStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get build stats: %w", err)
+	}
+
+	return map[string]interface{}{
+		"status":     "success",
+		"message":    "Ninja file loaded successfully",
+		"stats":      stats,
+		"build_time": time.Since(startTime).String(),
+	}, nil
+}
+
+// printLoadResult prints the load response, tables show one row per statistic
+func printLoadResult(cmd *cobra.Command, result map[string]interface{}) error {
+	if outputFormat != formatTable {
+		return printResult(cmd.Context(), result)
+	}
+
+	stats, _ := result["stats"].(map[string]interface{})
+
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make([]interface{}, 0, len(names)+1)
+	for _, name := range names {
+		rows = append(rows, map[string]interface{}{"stat": name, "value": stats[name]})
+	}
+	rows = append(rows, map[string]interface{}{"stat": "build_time", "value": result["build_time"]})
+
+	return printResult(cmd.Context(), rows, "stat", "value")
+}
@@ -0,0 +1,480 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/distninja/distninja/parser"
+	"github.com/distninja/distninja/store"
+	"github.com/distninja/distninja/utils"
+)
+
+var (
+	ninjaDir       string
+	ninjaFile      string
+	ninjaJobs      int
+	ninjaKeepGoing int
+	ninjaDryRun    bool
+	ninjaTool      string
+)
+
+// ninjaTools are the ninja subtools distninja answers from the parsed build files
+var ninjaTools = []struct {
+	name        string
+	description string
+	run         func(w io.Writer, manifest *parser.Manifest, args []string) error
+}{
+	{"deps", "show implicit dependencies of targets", ninjaDeps},
+	{"graph", "output graphviz dot file for targets", ninjaGraph},
+	{"query", "show inputs/outputs for a path", ninjaQuery},
+	{"rules", "list all rules", ninjaRules},
+	{"targets", "list targets by their rule or depth in the DAG", ninjaTargets},
+}
+
+var ninjaCmd = &cobra.Command{
+	Use:   "ninja [targets]",
+	Short: "Ninja compatible command line",
+	Long: "Ninja compatible command line for wrappers and IDE integrations. The -t tools deps, graph, query,\n" +
+		"rules and targets read the build files locally like ninja does, and -n prints the commands of the\n" +
+		"targets in build order without running them. Building through distninja is not available yet, -j\n" +
+		"and -k are accepted for compatibility.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if ninjaTool == "" && !ninjaDryRun {
+			return errors.New("building is not supported yet, distninja has no execution backend; use -n for a dry run or -t list for the available tools")
+		}
+
+		if ninjaTool == "list" {
+			_, _ = fmt.Fprintln(os.Stdout, "ninja subtools:")
+			for _, tool := range ninjaTools {
+				_, _ = fmt.Fprintf(os.Stdout, "%10s  %s\n", tool.name, tool.description)
+			}
+			return nil
+		}
+
+		run := ninjaDryRunBuild
+		if ninjaTool != "" {
+			run = nil
+			for _, tool := range ninjaTools {
+				if tool.name == ninjaTool {
+					run = tool.run
+				}
+			}
+			if run == nil {
+				return fmt.Errorf("unknown tool '%s', use -t list for the available tools", ninjaTool)
+			}
+		}
+
+		manifest, diagnostics, err := parser.ReadManifest(utils.ExpandTilde(ninjaDir), ninjaFile)
+		if err != nil {
+			return fmt.Errorf("loading '%s': %w", ninjaFile, err)
+		}
+		// Statements that could not be parsed are skipped, like ninja the tools still answer for the rest
+		for _, diagnostic := range diagnostics {
+			_, _ = fmt.Fprintln(os.Stderr, diagnostic.String())
+		}
+
+		return run(os.Stdout, manifest, args)
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(ninjaCmd)
+
+	ninjaCmd.Flags().StringVarP(&ninjaDir, "directory", "C", ".", "change to this directory before doing anything else")
+	ninjaCmd.Flags().StringVarP(&ninjaFile, "file", "f", "build.ninja", "specify input build file")
+	ninjaCmd.Flags().IntVarP(&ninjaJobs, "jobs", "j", 0, "run this many jobs in parallel, accepted for compatibility")
+	ninjaCmd.Flags().IntVarP(&ninjaKeepGoing, "keep-going", "k", 1, "keep going until this many jobs fail, accepted for compatibility")
+	ninjaCmd.Flags().BoolVarP(&ninjaDryRun, "dry-run", "n", false, "dry run (print the commands in build order without running them)")
+	ninjaCmd.Flags().StringVarP(&ninjaTool, "tool", "t", "", "run a subtool (use -t list to list subtools)")
+}
+
+// ninjaDryRunBuild prints the commands of the builds the targets need in build order like ninja -n, the
+// default targets or the root targets when none are given. Ready builds are taken in file order, the
+// fifo order of the build order endpoint.
+func ninjaDryRunBuild(w io.Writer, manifest *parser.Manifest, targets []string) error {
+	producers := manifest.Producers()
+	consumers := manifest.Consumers()
+
+	if len(targets) == 0 {
+		targets = manifest.Defaults
+	}
+	if len(targets) == 0 {
+		targets = rootTargets(manifest)
+	}
+
+	needed := make(map[*parser.ParsedBuild]bool)
+
+	var visit func(path string)
+	visit = func(path string) {
+		build := producers[path]
+		if build == nil || needed[build] {
+			return
+		}
+		needed[build] = true
+
+		for _, input := range build.AllInputs() {
+			visit(input)
+		}
+	}
+
+	for _, target := range targets {
+		if producers[target] == nil && len(consumers[target]) == 0 {
+			return fmt.Errorf("unknown target '%s'", target)
+		}
+		visit(target)
+	}
+
+	// Builds are named by their index in file order
+	ids := make(map[*parser.ParsedBuild]string, len(needed))
+	builds := make(map[string]*parser.ParsedBuild, len(needed))
+	var ordered []string
+	for i, build := range manifest.Builds {
+		if needed[build] {
+			id := strconv.Itoa(i)
+			ids[build] = id
+			builds[id] = build
+			ordered = append(ordered, id)
+		}
+	}
+
+	dependents := make(map[string][]string, len(ordered))
+	waiting := make(map[string]int, len(ordered))
+	for _, id := range ordered {
+		seen := make(map[string]bool)
+		for _, input := range builds[id].AllInputs() {
+			producer, ok := ids[producers[input]]
+			if !ok || producer == id || seen[producer] {
+				continue
+			}
+			seen[producer] = true
+
+			dependents[producer] = append(dependents[producer], id)
+			waiting[id]++
+		}
+	}
+
+	policy := &store.FIFOPolicy{}
+	policy.Init(dependents)
+	for _, id := range ordered {
+		if waiting[id] == 0 {
+			policy.Push(id)
+		}
+	}
+
+	var commands []string
+	emitted := 0
+
+	for policy.Len() > 0 {
+		id := policy.Pop()
+		emitted++
+
+		command, err := manifest.Command(builds[id])
+		if err != nil {
+			return err
+		}
+		if builds[id].Rule != "phony" {
+			commands = append(commands, command)
+		}
+
+		for _, dependent := range dependents[id] {
+			waiting[dependent]--
+			if waiting[dependent] == 0 {
+				policy.Push(dependent)
+			}
+		}
+	}
+
+	if emitted != len(ordered) {
+		return errors.New("dependency cycle")
+	}
+
+	if len(commands) == 0 {
+		_, _ = fmt.Fprintln(w, "ninja: no work to do.")
+		return nil
+	}
+
+	for i, command := range commands {
+		_, _ = fmt.Fprintf(w, "[%d/%d] %s\n", i+1, len(commands), command)
+	}
+
+	return nil
+}
+
+// ninjaDeps prints the implicit dependencies of the given targets, or of every target having some, in
+// the layout of ninja -t deps. The build files don't record modification times, so none are shown.
+func ninjaDeps(w io.Writer, manifest *parser.Manifest, args []string) error {
+	producers := manifest.Producers()
+
+	targets := args
+	if len(targets) == 0 {
+		for _, build := range manifest.Builds {
+			if len(build.ImplicitDeps) > 0 {
+				targets = append(targets, build.Outputs...)
+			}
+		}
+	}
+
+	for _, target := range targets {
+		build := producers[target]
+		if build == nil || len(build.ImplicitDeps) == 0 {
+			_, _ = fmt.Fprintf(w, "%s: deps not found\n", target)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "%s: #deps %d\n", target, len(build.ImplicitDeps))
+		for _, dep := range build.ImplicitDeps {
+			_, _ = fmt.Fprintf(w, "    %s\n", dep)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
+	return nil
+}
+
+// ninjaTargets lists targets like ninja -t targets [depth N | rule [NAME] | all]
+func ninjaTargets(w io.Writer, manifest *parser.Manifest, args []string) error {
+	mode := "depth"
+	if len(args) > 0 {
+		mode = args[0]
+	}
+
+	producers := manifest.Producers()
+
+	switch mode {
+	case "depth":
+		depth := 1
+		if len(args) > 1 {
+			var err error
+			if depth, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("invalid depth %s", args[1])
+			}
+		}
+		printTargetTree(w, producers, rootTargets(manifest), depth, 0)
+	case "rule":
+		if len(args) > 1 {
+			var outputs []string
+			for _, build := range manifest.Builds {
+				if build.Rule == args[1] {
+					outputs = append(outputs, build.Outputs...)
+				}
+			}
+			for _, output := range sortedUnique(outputs) {
+				_, _ = fmt.Fprintln(w, output)
+			}
+			return nil
+		}
+
+		// Without a rule name the source files are listed
+		var sources []string
+		for _, build := range manifest.Builds {
+			for _, input := range build.AllInputs() {
+				if producers[input] == nil {
+					sources = append(sources, input)
+				}
+			}
+		}
+		for _, source := range sortedUnique(sources) {
+			_, _ = fmt.Fprintln(w, source)
+		}
+	case "all":
+		for _, build := range manifest.Builds {
+			for _, output := range build.Outputs {
+				_, _ = fmt.Fprintf(w, "%s: %s\n", output, build.Rule)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown target tool mode '%s', expected depth, rule or all", mode)
+	}
+
+	return nil
+}
+
+// rootTargets returns the outputs no build uses as an input, in file order
+func rootTargets(manifest *parser.Manifest) []string {
+	consumers := manifest.Consumers()
+
+	var roots []string
+	for _, build := range manifest.Builds {
+		for _, output := range build.Outputs {
+			if len(consumers[output]) == 0 {
+				roots = append(roots, output)
+			}
+		}
+	}
+
+	return roots
+}
+
+// printTargetTree prints paths with their rule and the inputs of their build below them, depth <= 0 is
+// unlimited
+func printTargetTree(w io.Writer, producers map[string]*parser.ParsedBuild, paths []string, depth, indent int) {
+	for _, path := range paths {
+		prefix := strings.Repeat("  ", indent)
+
+		build := producers[path]
+		if build == nil {
+			_, _ = fmt.Fprintf(w, "%s%s\n", prefix, path)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "%s%s: %s\n", prefix, path, build.Rule)
+		if depth > 1 || depth <= 0 {
+			printTargetTree(w, producers, build.AllInputs(), depth-1, indent+1)
+		}
+	}
+}
+
+// ninjaQuery prints the inputs and dependents of each path like ninja -t query
+func ninjaQuery(w io.Writer, manifest *parser.Manifest, args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected a target to query")
+	}
+
+	producers := manifest.Producers()
+	consumers := manifest.Consumers()
+
+	for _, path := range args {
+		if producers[path] == nil && len(consumers[path]) == 0 {
+			return fmt.Errorf("unknown target '%s'", path)
+		}
+
+		_, _ = fmt.Fprintf(w, "%s:\n", path)
+
+		if build := producers[path]; build != nil {
+			_, _ = fmt.Fprintf(w, "  input: %s\n", build.Rule)
+			for _, group := range []struct {
+				label string
+				paths []string
+			}{{"", build.Inputs}, {"| ", build.ImplicitDeps}, {"|| ", build.OrderDeps}} {
+				for _, input := range group.paths {
+					_, _ = fmt.Fprintf(w, "    %s%s\n", group.label, input)
+				}
+			}
+		}
+
+		_, _ = fmt.Fprintln(w, "  outputs:")
+		for _, build := range consumers[path] {
+			for _, output := range build.Outputs {
+				_, _ = fmt.Fprintf(w, "    %s\n", output)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ninjaRules lists the rule names like ninja -t rules
+func ninjaRules(w io.Writer, manifest *parser.Manifest, _ []string) error {
+	for _, name := range sortedUnique(append(manifest.RuleNames(), "phony")) {
+		_, _ = fmt.Fprintln(w, name)
+	}
+
+	return nil
+}
+
+// ninjaGraph writes the graph of the given targets, or of all root targets, in the dot layout of
+// ninja -t graph
+func ninjaGraph(w io.Writer, manifest *parser.Manifest, args []string) error {
+	producers := manifest.Producers()
+
+	targets := args
+	if len(targets) == 0 {
+		targets = rootTargets(manifest)
+	}
+
+	for _, target := range targets {
+		if producers[target] == nil && len(manifest.Consumers()[target]) == 0 {
+			return fmt.Errorf("unknown target '%s'", target)
+		}
+	}
+
+	_, _ = fmt.Fprintln(w, "digraph ninja {")
+	_, _ = fmt.Fprintln(w, `rankdir="LR"`)
+	_, _ = fmt.Fprintln(w, "node [fontsize=10, shape=box, height=0.25]")
+	_, _ = fmt.Fprintln(w, "edge [fontsize=10]")
+
+	nodes := make(map[string]bool)
+	builds := make(map[*parser.ParsedBuild]int)
+
+	var visit func(path string)
+	visit = func(path string) {
+		if nodes[path] {
+			return
+		}
+		nodes[path] = true
+		_, _ = fmt.Fprintf(w, "%s [label=%s]\n", graphQuote(path), graphQuote(path))
+
+		build := producers[path]
+		if build == nil {
+			return
+		}
+		if _, ok := builds[build]; ok {
+			return
+		}
+		builds[build] = len(builds)
+
+		inputs := build.AllInputs()
+
+		// Single input and output builds are drawn as a labelled edge
+		if len(inputs) == 1 && len(build.Outputs) == 1 {
+			_, _ = fmt.Fprintf(w, "%s -> %s [label=\" %s\"]\n", graphQuote(inputs[0]), graphQuote(path), build.Rule)
+		} else {
+			id := graphQuote(fmt.Sprintf("build %d", builds[build]))
+			_, _ = fmt.Fprintf(w, "%s [label=%s, shape=ellipse]\n", id, graphQuote(build.Rule))
+			for _, output := range build.Outputs {
+				_, _ = fmt.Fprintf(w, "%s -> %s\n", id, graphQuote(output))
+			}
+			for _, input := range build.Inputs {
+				_, _ = fmt.Fprintf(w, "%s -> %s [arrowhead=none]\n", graphQuote(input), id)
+			}
+			for _, input := range build.ImplicitDeps {
+				_, _ = fmt.Fprintf(w, "%s -> %s [arrowhead=none]\n", graphQuote(input), id)
+			}
+			for _, input := range build.OrderDeps {
+				_, _ = fmt.Fprintf(w, "%s -> %s [arrowhead=none, style=dotted]\n", graphQuote(input), id)
+			}
+		}
+
+		for _, input := range inputs {
+			visit(input)
+		}
+	}
+
+	for _, target := range targets {
+		visit(target)
+	}
+
+	_, _ = fmt.Fprintln(w, "}")
+
+	return nil
+}
+
+// graphQuote quotes s as a dot string
+func graphQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func sortedUnique(values []string) []string {
+	sort.Strings(values)
+
+	unique := values[:0]
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			unique = append(unique, value)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/distninja/distninja/store"
+	"github.com/distninja/distninja/utils"
+)
+
+var (
+	restoreStore      string
+	restoreKeyCommand string
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <snapshot>",
+	Short: "Replace a local store with a snapshot",
+	Long: "Replace the local store with a snapshot taken by POST /api/v1/admin/snapshot. The store must not be in\n" +
+		"use by a server, stop it first and start it again afterwards. The replaced store is kept as\n" +
+		"<store>.previous until the next restore. Encrypted snapshots are decrypted with the key of\n" +
+		"--snapshot-key-command or DISTNINJA_SNAPSHOT_KEY, stores encrypted at rest are restored encrypted\n" +
+		"with it.",
+	Args: cobra.ExactArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := snapshotKey(restoreKeyCommand)
+		if err != nil {
+			return err
+		}
+
+		stats, err := store.RestoreSnapshot(utils.ExpandTilde(restoreStore), utils.ExpandTilde(args[0]), key)
+		if err != nil {
+			return fmt.Errorf("failed to restore snapshot: %w", err)
+		}
+
+		return printResult(cmd.Context(), map[string]interface{}{
+			"status":   "restored",
+			"quads":    stats.Quads,
+			"previous": stats.Previous,
+		}, "status", "quads", "previous")
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().StringVarP(&restoreStore, "store", "s", "ninja.db", "store path")
+	restoreCmd.Flags().StringVar(&restoreKeyCommand, "snapshot-key-command", "", "command printing the base64 key of encrypted snapshots, e.g. a kms client (default env DISTNINJA_SNAPSHOT_KEY)")
+}
+
+// snapshotKey returns the key printed by command, split at spaces, or the key in DISTNINJA_SNAPSHOT_KEY
+// without a command, nil when neither is set
+func snapshotKey(command string) ([]byte, error) {
+	var provider store.KeyProvider = store.EnvKeyProvider{}
+
+	if fields := strings.Fields(command); len(fields) > 0 {
+		provider = store.CommandKeyProvider{Command: fields[0], Args: fields[1:]}
+	}
+
+	return provider.Key()
+}
+
+// openLocalStore opens the store at path, decrypting a store encrypted at rest with the key in
+// DISTNINJA_SNAPSHOT_KEY
+func openLocalStore(path string) (*store.NinjaStore, error) {
+	if !store.IsEncryptedStore(path) {
+		return store.NewNinjaStore(path)
+	}
+
+	key, err := snapshotKey("")
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%w: set %s", store.ErrStoreEncrypted, store.SnapshotKeyEnv)
+	}
+
+	return store.NewEncryptedNinjaStore(path, key)
+}
@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/distninja/distninja/utils"
 )
 
 var (
@@ -12,6 +15,11 @@ var (
 	CommitID  string
 )
 
+var (
+	logLevel  string
+	logFormat string
+)
+
 var rootCmd = &cobra.Command{
 	Use:     "distninja",
 	Short:   "distributed build system",
@@ -27,7 +35,20 @@ func Execute() {
 
 // nolint:gochecknoinits
 func init() {
-	cobra.OnInitialize()
+	cobra.OnInitialize(initLogger)
 
 	rootCmd.Root().CompletionOptions.DisableDefaultCmd = true
+
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "log format (text, json)")
+}
+
+func initLogger() {
+	logger, err := utils.NewLogger(os.Stderr, logLevel, logFormat)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	slog.SetDefault(logger)
 }
@@ -2,28 +2,84 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/distninja/distninja/parser"
 	"github.com/distninja/distninja/server"
+	"github.com/distninja/distninja/store"
 	"github.com/distninja/distninja/utils"
 )
 
 var (
-	grpcAddress string
-	httpAddress string
-	storePath   string
+	grpcAddress      string
+	httpAddress      string
+	storePath        string
+	apiKeys          []string
+	jwtSecret        string
+	defaultRole      string
+	admins           []string
+	tlsCert          string
+	tlsKey           string
+	tlsClientCA      string
+	corsOrigins      []string
+	maxBody          int64
+	maxLoad          int64
+	rateLimit        float64
+	rateBurst        int
+	rateLimitAddress float64
+
+	historyLimit  int
+	historyMaxAge time.Duration
+
+	workspace     string
+	watchInterval time.Duration
+	triggerSecret string
+
+	natsURL     string
+	natsSubject string
+	kafkaURL    string
+	kafkaTopic  string
+	sinkEvents  []string
+
+	loadWorkers int
+	loadBatch   int
+	repair      bool
+	forceUnlock bool
+	compact     bool
+	snapshotDir string
+	snapshotCmd string
+	encrypt     bool
+	cacheSize   int
+
+	grpcKeepalive        time.Duration
+	grpcKeepaliveTimeout time.Duration
+	grpcKeepaliveMinTime time.Duration
+	grpcMaxStreams       uint32
+	grpcMaxRecvBytes     int
+	grpcMaxSendBytes     int
+	grpcCompression      string
+
+	serveDaemon   bool
+	serveStop     bool
+	serveReload   bool
+	pidFile       string
+	logFile       string
+	logMaxBytes   int64
+	logMaxBackups int
 )
 
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Run api server",
 	Run: func(cmd *cobra.Command, args []string) {
-		ctx := context.Background()
-		_path := utils.ExpandTilde(storePath)
-		if err := runServe(ctx, _path); err != nil {
+		if err := serveProcess(cmd); err != nil {
 			_, _ = fmt.Fprintln(os.Stderr, err.Error())
 			os.Exit(1)
 		}
@@ -37,23 +93,217 @@ func init() {
 	serveCmd.PersistentFlags().StringVarP(&grpcAddress, "grpc", "g", "", "grpc address")
 	serveCmd.PersistentFlags().StringVarP(&httpAddress, "http", "t", "", "http address")
 	serveCmd.PersistentFlags().StringVarP(&storePath, "store", "s", "ninja.db", "store path")
+	serveCmd.PersistentFlags().StringSliceVar(&apiKeys, "api-key", nil, "api key as name=key (repeatable, env DISTNINJA_API_KEYS)")
+	serveCmd.PersistentFlags().StringVar(&jwtSecret, "jwt-secret", "", "hmac secret for HS256 bearer tokens (env DISTNINJA_JWT_SECRET)")
+	serveCmd.PersistentFlags().StringSliceVar(&admins, "admin", nil, "subjects always granted the admin role (repeatable)")
+	serveCmd.PersistentFlags().StringVar(&defaultRole, "default-role", "admin", "role of authenticated callers without a role binding (viewer, loader, operator, admin)")
+	serveCmd.PersistentFlags().StringVar(&tlsCert, "tls-cert", "", "tls certificate file, reloaded on rotation")
+	serveCmd.PersistentFlags().StringVar(&tlsKey, "tls-key", "", "tls private key file")
+	serveCmd.PersistentFlags().StringVar(&tlsClientCA, "tls-client-ca", "", "ca file for verifying client certificates (enables mutual tls)")
+	serveCmd.PersistentFlags().StringSliceVar(&corsOrigins, "cors-origin", nil, "origin browsers may call the api and open websockets from, * for any (repeatable, default any origin for the api and none for websockets)")
+	serveCmd.PersistentFlags().Int64Var(&maxBody, "max-body-bytes", 10<<20, "maximum http request body size, 0 disables the limit")
+	serveCmd.PersistentFlags().Int64Var(&maxLoad, "max-load-bytes", 0, "maximum ninja file upload size, 0 disables the limit")
+	serveCmd.PersistentFlags().Float64Var(&rateLimit, "rate-limit", 0, "requests per second allowed per client, 0 disables rate limiting")
+	serveCmd.PersistentFlags().IntVar(&rateBurst, "rate-burst", 20, "requests a client may send in a burst")
+	serveCmd.PersistentFlags().Float64Var(&rateLimitAddress, "rate-limit-address", 0, "requests per second allowed per address before authentication, 0 allows 10 times --rate-limit")
+	serveCmd.PersistentFlags().DurationVar(&grpcKeepalive, "grpc-keepalive", time.Minute, "ping grpc connections idle for this long, 0 keeps the grpc default of 2h")
+	serveCmd.PersistentFlags().DurationVar(&grpcKeepaliveTimeout, "grpc-keepalive-timeout", 20*time.Second, "close grpc connections whose ping is not acknowledged within this time")
+	serveCmd.PersistentFlags().DurationVar(&grpcKeepaliveMinTime, "grpc-keepalive-min-time", 10*time.Second, "shortest client ping interval allowed, 0 keeps the grpc default of 5m")
+	serveCmd.PersistentFlags().Uint32Var(&grpcMaxStreams, "grpc-max-streams", 0, "maximum concurrent streams per grpc connection, 0 for unlimited")
+	serveCmd.PersistentFlags().IntVar(&grpcMaxRecvBytes, "grpc-max-recv-bytes", 0, "maximum grpc message size received, 0 keeps the grpc default of 4 MiB")
+	serveCmd.PersistentFlags().IntVar(&grpcMaxSendBytes, "grpc-max-send-bytes", 0, "maximum grpc message size sent, 0 keeps the grpc default")
+	serveCmd.PersistentFlags().StringVar(&grpcCompression, "grpc-compression", "", "compress grpc responses to clients that accept it (gzip)")
+	serveCmd.PersistentFlags().IntVar(&historyLimit, "history-limit", 100, "status changes kept per target, 0 keeps all")
+	serveCmd.PersistentFlags().DurationVar(&historyMaxAge, "history-max-age", 0, "drop status changes older than this, 0 keeps them regardless of age")
+	serveCmd.PersistentFlags().IntVar(&loadWorkers, "load-workers", 0, "goroutines writing loaded ninja files to the store, the CPU count when 0")
+	serveCmd.PersistentFlags().IntVar(&loadBatch, "load-batch", 0, "builds written per store transaction during loads, 256 when 0")
+	serveCmd.PersistentFlags().IntVar(&cacheSize, "cache-size", 4096, "rules, builds and targets cached in memory, 0 disables the cache")
+	serveCmd.PersistentFlags().BoolVar(&repair, "repair", false, "remove what the startup integrity check finds broken in the store")
+	serveCmd.PersistentFlags().BoolVar(&compact, "compact", false, "shrink the store file to the pages in use when opening it, logging the reclaimed bytes")
+	serveCmd.PersistentFlags().BoolVar(&forceUnlock, "force-unlock", false, "remove the store lock left by a process that hangs or runs on another host")
+	serveCmd.PersistentFlags().StringVar(&snapshotDir, "snapshot-dir", "", "directory /api/v1/admin/snapshot writes snapshots to, <store>.snapshots when empty")
+	serveCmd.PersistentFlags().StringVar(&snapshotCmd, "snapshot-key-command", "", "command printing the base64 key snapshots are encrypted with, e.g. a kms client (default env DISTNINJA_SNAPSHOT_KEY)")
+	serveCmd.PersistentFlags().BoolVar(&encrypt, "encrypt-store", false, "keep the store file and its journal encrypted with the snapshot key, a store in the clear is encrypted when it is opened. While the server runs the store is decrypted to a directory only its user can read in /dev/shm, or $TMPDIR without it, which needs room for two copies of the store")
+	serveCmd.PersistentFlags().StringVar(&workspace, "workspace", "", "git checkout diffed by /api/v1/analysis/changed?since=<rev> and for pull request triggers")
+	serveCmd.PersistentFlags().DurationVar(&watchInterval, "watch-interval", 0, "poll --workspace this often for changed sources and mark the targets depending on them dirty, 0 disables watching")
+	serveCmd.PersistentFlags().StringVar(&triggerSecret, "trigger-secret", "", "secret of GitHub and GitLab webhooks sent to /api/v1/triggers/git, which is off without it (env DISTNINJA_TRIGGER_SECRET)")
+
+	serveCmd.PersistentFlags().StringVar(&natsURL, "nats-url", "", "publish events to this nats server, nats://[user:pass@]host:port or tls://... (env DISTNINJA_NATS_URL)")
+	serveCmd.PersistentFlags().StringVar(&natsSubject, "nats-subject", "distninja", "subject prefix of published events, followed by the event type")
+	serveCmd.PersistentFlags().StringVar(&kafkaURL, "kafka-rest-url", "", "produce events through this kafka rest proxy (v2 api)")
+	serveCmd.PersistentFlags().StringVar(&kafkaTopic, "kafka-topic", "distninja-events", "kafka topic events are produced to")
+	serveCmd.PersistentFlags().StringSliceVar(&sinkEvents, "sink-events", nil, "event types sent to nats and kafka, all when empty (repeatable)")
+
+	serveCmd.PersistentFlags().BoolVar(&serveDaemon, "daemon", false, "run in the background, logging to --log-file")
+	serveCmd.PersistentFlags().BoolVar(&serveStop, "stop", false, "stop the daemon of --pid-file")
+	serveCmd.PersistentFlags().BoolVar(&serveReload, "reload", false, "make the daemon of --pid-file reopen its log file")
+	serveCmd.PersistentFlags().StringVar(&pidFile, "pid-file", defaultPidFile, "pid file, written by daemons and when set explicitly")
+	serveCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "write logs to this file instead of stderr (daemon default "+defaultLogFile+")")
+	serveCmd.PersistentFlags().Int64Var(&logMaxBytes, "log-max-bytes", 100<<20, "rotate the log file at this size, 0 disables rotation")
+	serveCmd.PersistentFlags().IntVar(&logMaxBackups, "log-max-backups", 5, "rotated log files to keep")
+
+	serveCmd.MarkFlagsMutuallyExclusive("daemon", "stop", "reload")
+	serveCmd.MarkFlagsRequiredTogether("tls-cert", "tls-key")
+}
+
+// serveProcess runs the servers in the foreground or as a daemon, or controls a running daemon
+func serveProcess(cmd *cobra.Command) error {
+	pidFile = utils.ExpandTilde(pidFile)
+	logFile = utils.ExpandTilde(logFile)
+
+	switch {
+	case serveStop:
+		return stopDaemon()
+	case serveReload:
+		return reloadDaemon()
+	case grpcAddress == "" && httpAddress == "":
+		return fmt.Errorf("at least one of --grpc and --http is required")
+	case serveDaemon && os.Getenv(daemonEnv) == "":
+		return startDaemon()
+	}
+
+	cleanup, err := setupProcess(serveDaemon || cmd.Flags().Changed("pid-file"))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
 
-	serveCmd.MarkFlagsOneRequired("grpc", "http")
-	serveCmd.MarkFlagsMutuallyExclusive("grpc", "http")
+	return runServe(context.Background(), utils.ExpandTilde(storePath))
 }
 
 func runServe(ctx context.Context, _path string) error {
+	opts, err := serveOptions()
+	if err != nil {
+		return err
+	}
+
 	if grpcAddress != "" {
-		fmt.Printf("Starting gRPC server on %s\n", grpcAddress)
-		return server.StartGRPCServer(ctx, grpcAddress, _path)
+		slog.Info("starting grpc server", "address", grpcAddress, "store", _path, "tls", opts.TLS.Enabled())
 	}
 
 	if httpAddress != "" {
-		fmt.Printf("Starting HTTP server on %s\n", httpAddress)
-		return server.StartHTTPServer(ctx, httpAddress, _path)
+		slog.Info("starting http server", "address", httpAddress, "store", _path, "tls", opts.TLS.Enabled())
+	}
+
+	err = server.Serve(ctx, grpcAddress, httpAddress, _path, opts)
+	if errors.Is(err, store.ErrStoreLocked) {
+		return fmt.Errorf("%w, stop that process or pass --force-unlock if it is gone", err)
+	}
+
+	return err
+}
+
+func serveOptions() (server.Options, error) {
+	opts := server.Options{
+		BuildTime: BuildTime,
+		CommitID:  CommitID,
+	}
+
+	if len(apiKeys) == 0 {
+		if env := os.Getenv("DISTNINJA_API_KEYS"); env != "" {
+			apiKeys = strings.Split(env, ",")
+		}
+	}
+
+	if jwtSecret == "" {
+		jwtSecret = os.Getenv("DISTNINJA_JWT_SECRET")
+	}
+
+	keys, err := server.ParseAPIKeys(apiKeys)
+	if err != nil {
+		return opts, err
 	}
 
-	fmt.Printf("Starting HTTP server on %s\n", httpAddress)
+	if !store.ValidRole(defaultRole) {
+		return opts, fmt.Errorf("unknown default role %s", defaultRole)
+	}
+
+	opts.Auth = server.AuthConfig{
+		APIKeys:     keys,
+		JWTSecret:   jwtSecret,
+		DefaultRole: defaultRole,
+		Admins:      admins,
+	}
+
+	opts.TLS = server.TLSConfig{
+		CertFile:     utils.ExpandTilde(tlsCert),
+		KeyFile:      utils.ExpandTilde(tlsKey),
+		ClientCAFile: utils.ExpandTilde(tlsClientCA),
+	}
+
+	opts.CORSOrigins = corsOrigins
+
+	opts.Limits = server.LimitsConfig{
+		MaxBodyBytes:     maxBody,
+		MaxLoadBytes:     maxLoad,
+		RateLimit:        rateLimit,
+		RateBurst:        rateBurst,
+		AddressRateLimit: rateLimitAddress,
+	}
+
+	opts.History = store.HistoryRetention{
+		MaxEntries: historyLimit,
+		MaxAge:     historyMaxAge,
+	}
+
+	opts.Load = parser.LoadOptions{
+		Workers:   loadWorkers,
+		BatchSize: loadBatch,
+	}
+
+	opts.Repair = repair
+	opts.ForceUnlock = forceUnlock
+	opts.Compact = compact
+	opts.EncryptStore = encrypt
+	opts.CacheSize = cacheSize
+	opts.SnapshotDir = utils.ExpandTilde(snapshotDir)
+
+	if opts.SnapshotKey, err = snapshotKey(snapshotCmd); err != nil {
+		return opts, err
+	}
+
+	opts.Workspace = utils.ExpandTilde(workspace)
+	opts.WatchInterval = watchInterval
+
+	if triggerSecret == "" {
+		triggerSecret = os.Getenv("DISTNINJA_TRIGGER_SECRET")
+	}
+	opts.TriggerSecret = triggerSecret
+
+	if natsURL == "" {
+		natsURL = os.Getenv("DISTNINJA_NATS_URL")
+	}
+
+	if natsURL != "" {
+		sink, err := server.NewNATSSink(natsURL, natsSubject)
+		if err != nil {
+			return opts, err
+		}
+		opts.EventSinks = append(opts.EventSinks, sink)
+	}
+
+	if kafkaURL != "" {
+		sink, err := server.NewKafkaRESTSink(kafkaURL, kafkaTopic)
+		if err != nil {
+			return opts, err
+		}
+		opts.EventSinks = append(opts.EventSinks, sink)
+	}
+
+	if err := server.ValidateEventTypes(sinkEvents); err != nil {
+		return opts, err
+	}
+	opts.EventSinkTypes = sinkEvents
+
+	opts.GRPC = server.GRPCConfig{
+		KeepaliveTime:        grpcKeepalive,
+		KeepaliveTimeout:     grpcKeepaliveTimeout,
+		KeepaliveMinTime:     grpcKeepaliveMinTime,
+		MaxConcurrentStreams: grpcMaxStreams,
+		MaxRecvMsgBytes:      grpcMaxRecvBytes,
+		MaxSendMsgBytes:      grpcMaxSendBytes,
+		Compression:          grpcCompression,
+	}
 
-	return server.StartHTTPServer(ctx, httpAddress, _path)
+	return opts, nil
 }
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var statsRecount bool
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report graph statistics, targets per rule and the critical path",
+	Long: "Report the size of the build graph, the targets of each rule by status and the critical path, the\n" +
+		"longest chain of targets each depending on the next. Use --format json for dashboards. The counts are\n" +
+		"kept by the server as the graph changes, --recount counts them again from the store.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		if statsRecount {
+			if err := client.do(http.MethodPost, "/admin/recount", nil, nil); err != nil {
+				return err
+			}
+		}
+
+		var stats map[string]interface{}
+		if err := client.do(http.MethodGet, "/builds/stats", nil, &stats); err != nil {
+			return err
+		}
+
+		var report map[string]interface{}
+		if err := client.do(http.MethodGet, "/analysis/report", nil, &report); err != nil {
+			return err
+		}
+
+		if outputFormat != formatTable {
+			report["graph"] = stats
+			return printResult(cmd.Context(), report)
+		}
+
+		if err := printResult(cmd.Context(), stats, "rules", "builds", "targets", "files", "relationships"); err != nil {
+			return err
+		}
+
+		rules, _ := report["rules"].([]interface{})
+		for _, rule := range rules {
+			if rule, ok := rule.(map[string]interface{}); ok {
+				rule["statuses"] = formatCounts(rule["statuses"])
+			}
+		}
+
+		if len(rules) > 0 {
+			if err := printResult(cmd.Context(), rules, "rule", "targets", "statuses"); err != nil {
+				return err
+			}
+		}
+
+		path, _ := report["critical_path"].([]interface{})
+		parts := make([]string, len(path))
+		for i, target := range path {
+			parts[i] = fmt.Sprint(target)
+		}
+		_, _ = fmt.Fprintf(os.Stdout, "critical path (%d targets): %s\n", len(path), strings.Join(parts, " <- "))
+
+		return nil
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	addClientFlags(statsCmd)
+
+	statsCmd.Flags().BoolVar(&statsRecount, "recount", false, "count the graph again from the store first, needs the admin role")
+}
+
+// formatCounts prints a map of counts as key=count pairs in key order
+func formatCounts(v interface{}) string {
+	counts, _ := v.(map[string]interface{})
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", key, counts[key])
+	}
+
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	newStatus     string
+	statusMessage string
+	statusHistory int
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status <path>",
+	Short: "Show the status of a target or its history, or change it with --set",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		path := "/targets/" + escapePath(args[0])
+
+		if newStatus != "" {
+			body := map[string]string{"status": newStatus, "message": statusMessage}
+			if err := client.do(http.MethodPut, path+"/status", body, nil); err != nil {
+				return err
+			}
+		}
+
+		if statusHistory > 0 {
+			var history []interface{}
+			if err := client.do(http.MethodGet, path+"/history?limit="+strconv.Itoa(statusHistory), nil, &history); err != nil {
+				return err
+			}
+
+			return printResult(cmd.Context(), history, "time", "status", "previous_status", "run_id", "worker", "message")
+		}
+
+		var target interface{}
+		if err := client.do(http.MethodGet, path, nil, &target); err != nil {
+			return err
+		}
+
+		return printResult(cmd.Context(), target, "path", "status", "hash")
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	addClientFlags(statusCmd)
+
+	statusCmd.Flags().StringVar(&newStatus, "set", "", "new status of the target, e.g. building, done or failed")
+	statusCmd.Flags().StringVar(&statusMessage, "message", "", "reason for the --set change, kept in the status history")
+	statusCmd.Flags().IntVar(&statusHistory, "history", 0, "show the last N status changes instead of the target")
+}
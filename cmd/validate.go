@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/distninja/distninja/parser"
+	"github.com/distninja/distninja/utils"
+)
+
+var (
+	validateDir    string
+	validateFile   string
+	validateStrict bool
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check a ninja file and its includes without a server",
+	Long: "Check a ninja file and its includes for unknown rules, rules without a command, duplicate outputs,\n" +
+		"dependency cycles and undefined variables. Diagnostics are printed as file:line: severity: message\n" +
+		"and the command fails if there are errors, or warnings with --strict.",
+	Args: cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		diagnostics, err := parser.Validate(utils.ExpandTilde(validateDir), validateFile)
+		if err != nil {
+			return err
+		}
+
+		errorCount, warningCount := 0, 0
+
+		for _, diagnostic := range diagnostics {
+			_, _ = fmt.Fprintln(os.Stderr, diagnostic.String())
+
+			if diagnostic.Severity == parser.SeverityError {
+				errorCount++
+			} else {
+				warningCount++
+			}
+		}
+
+		if errorCount > 0 || validateStrict && warningCount > 0 {
+			return fmt.Errorf("validation failed with %d errors and %d warnings", errorCount, warningCount)
+		}
+
+		return nil
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringVarP(&validateDir, "directory", "C", ".", "directory to resolve the ninja file and its includes in")
+	validateCmd.Flags().StringVarP(&validateFile, "file", "f", "build.ninja", "ninja file to validate")
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "fail on warnings too")
+}
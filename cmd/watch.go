@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Target statuses the watch status line counts as finished
+var finishedStatuses = map[string]bool{
+	"built":   true,
+	"success": true,
+	"failed":  true,
+}
+
+var watchEvents []string
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Follow the events of a running server",
+	Long: "Follow the events of a running server. On a terminal a status line like ninja's shows the finished\n" +
+		"and total targets with the last status change, failures and other events are printed above it.\n" +
+		"With --format json or yaml each event is printed as it arrives.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		path := "/ws"
+		if len(watchEvents) > 0 {
+			path += "?" + url.Values{"events": {strings.Join(watchEvents, ",")}}.Encode()
+		}
+
+		conn, err := client.dial(path)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		view := &watchView{client: client, out: os.Stdout, tty: isTerminal(os.Stdout)}
+		if outputFormat == formatTable {
+			// Counted after subscribing so no change is missed
+			if err := view.refresh(); err != nil {
+				return err
+			}
+			view.draw("")
+		}
+
+		for {
+			var event watchEvent
+			if err := conn.ReadJSON(&event); err != nil {
+				view.finish()
+				return fmt.Errorf("event stream closed: %w", err)
+			}
+
+			if outputFormat != formatTable {
+				if err := printResult(cmd.Context(), event); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := view.handle(&event); err != nil {
+				view.finish()
+				return err
+			}
+		}
+	},
+}
+
+// nolint:gochecknoinits
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	addClientFlags(watchCmd)
+
+	watchCmd.Flags().StringSliceVar(&watchEvents, "events", nil, "event types to follow, e.g. target.status_changed,load.completed (default all)")
+}
+
+// watchEvent is an event of the server event stream
+type watchEvent struct {
+	ID   uint64                 `json:"id"`
+	Type string                 `json:"type"`
+	Time string                 `json:"time"`
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// watchView keeps the target statuses behind the status line
+type watchView struct {
+	client   *apiClient
+	out      io.Writer
+	tty      bool
+	statuses map[string]string
+	// last is the latest status change, kept on the status line
+	last string
+}
+
+// refresh loads the status of every target
+func (v *watchView) refresh() error {
+	var targets []struct {
+		Path   string `json:"path"`
+		Status string `json:"status"`
+	}
+	if err := v.client.do(http.MethodGet, "/targets", nil, &targets); err != nil {
+		return err
+	}
+
+	v.statuses = make(map[string]string, len(targets))
+	for _, target := range targets {
+		v.statuses[target.Path] = target.Status
+	}
+
+	return nil
+}
+
+func (v *watchView) handle(event *watchEvent) error {
+	path, _ := event.Data["path"].(string)
+
+	switch event.Type {
+	case "target.status_changed":
+		status, _ := event.Data["status"].(string)
+		v.statuses[path] = status
+		v.draw(status + " " + path)
+	case "target.failed":
+		v.println("FAILED: " + path)
+	case "target.deleted":
+		delete(v.statuses, path)
+		v.println(formatEvent(event))
+	case "build.created":
+		if outputs, ok := event.Data["outputs"].([]interface{}); ok {
+			for _, output := range outputs {
+				if output, ok := output.(string); ok && v.statuses[output] == "" {
+					v.statuses[output] = "clean"
+				}
+			}
+		}
+		v.println(formatEvent(event))
+	case "load.completed", "store.reset", "build.deleted":
+		// Changes too many targets to follow one by one
+		if err := v.refresh(); err != nil {
+			return err
+		}
+		v.println(formatEvent(event))
+	default:
+		v.println(formatEvent(event))
+	}
+
+	return nil
+}
+
+// draw replaces the status line showing change, without a terminal only changes are printed on lines
+// of their own
+func (v *watchView) draw(change string) {
+	if change != "" {
+		v.last = change
+	}
+
+	finished, failed := 0, 0
+	for _, status := range v.statuses {
+		if finishedStatuses[status] {
+			finished++
+		}
+		if status == "failed" {
+			failed++
+		}
+	}
+
+	line := fmt.Sprintf("[%d/%d]", finished, len(v.statuses))
+	if failed > 0 {
+		line += fmt.Sprintf(" %d failed", failed)
+	}
+	if v.last != "" {
+		line += " " + v.last
+	}
+
+	if v.tty {
+		_, _ = fmt.Fprint(v.out, "\r\033[K"+line)
+	} else if change != "" {
+		_, _ = fmt.Fprintln(v.out, line)
+	}
+}
+
+// println prints a line above the status line
+func (v *watchView) println(line string) {
+	if v.tty {
+		_, _ = fmt.Fprint(v.out, "\r\033[K"+line+"\n")
+		v.draw("")
+		return
+	}
+
+	_, _ = fmt.Fprintln(v.out, line)
+}
+
+// finish moves past the status line before an error is printed
+func (v *watchView) finish() {
+	if v.tty {
+		_, _ = fmt.Fprintln(v.out)
+	}
+}
+
+// formatEvent prints an event as its type followed by its data fields in key order
+func formatEvent(event *watchEvent) string {
+	keys := make([]string, 0, len(event.Data))
+	for key := range event.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := []string{event.Type}
+	for _, key := range keys {
+		value := event.Data[key]
+		if _, ok := value.(string); !ok {
+			data, _ := json.Marshal(value)
+			value = string(data)
+		}
+		fields = append(fields, fmt.Sprintf("%s=%v", key, value))
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// isTerminal reports whether f is a character device such as a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,520 @@
+// Subset of Bazel's src/main/protobuf/analysis_v2.proto read by the aquery importer, field numbers
+// match the upstream definition so `bazel aquery --output=proto` can be decoded directly.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.31.1
+// source: parser/analysis/analysis_v2.proto
+
+package analysis
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ActionGraphContainer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Artifacts     []*Artifact            `protobuf:"bytes,1,rep,name=artifacts,proto3" json:"artifacts,omitempty"`
+	Actions       []*Action              `protobuf:"bytes,2,rep,name=actions,proto3" json:"actions,omitempty"`
+	Targets       []*Target              `protobuf:"bytes,3,rep,name=targets,proto3" json:"targets,omitempty"`
+	DepSetOfFiles []*DepSetOfFiles       `protobuf:"bytes,4,rep,name=dep_set_of_files,json=depSetOfFiles,proto3" json:"dep_set_of_files,omitempty"`
+	PathFragments []*PathFragment        `protobuf:"bytes,8,rep,name=path_fragments,json=pathFragments,proto3" json:"path_fragments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActionGraphContainer) Reset() {
+	*x = ActionGraphContainer{}
+	mi := &file_parser_analysis_analysis_v2_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActionGraphContainer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionGraphContainer) ProtoMessage() {}
+
+func (x *ActionGraphContainer) ProtoReflect() protoreflect.Message {
+	mi := &file_parser_analysis_analysis_v2_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionGraphContainer.ProtoReflect.Descriptor instead.
+func (*ActionGraphContainer) Descriptor() ([]byte, []int) {
+	return file_parser_analysis_analysis_v2_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ActionGraphContainer) GetArtifacts() []*Artifact {
+	if x != nil {
+		return x.Artifacts
+	}
+	return nil
+}
+
+func (x *ActionGraphContainer) GetActions() []*Action {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+func (x *ActionGraphContainer) GetTargets() []*Target {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *ActionGraphContainer) GetDepSetOfFiles() []*DepSetOfFiles {
+	if x != nil {
+		return x.DepSetOfFiles
+	}
+	return nil
+}
+
+func (x *ActionGraphContainer) GetPathFragments() []*PathFragment {
+	if x != nil {
+		return x.PathFragments
+	}
+	return nil
+}
+
+type Artifact struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	PathFragmentId uint32                 `protobuf:"varint,2,opt,name=path_fragment_id,json=pathFragmentId,proto3" json:"path_fragment_id,omitempty"`
+	IsTreeArtifact bool                   `protobuf:"varint,3,opt,name=is_tree_artifact,json=isTreeArtifact,proto3" json:"is_tree_artifact,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Artifact) Reset() {
+	*x = Artifact{}
+	mi := &file_parser_analysis_analysis_v2_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Artifact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Artifact) ProtoMessage() {}
+
+func (x *Artifact) ProtoReflect() protoreflect.Message {
+	mi := &file_parser_analysis_analysis_v2_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Artifact.ProtoReflect.Descriptor instead.
+func (*Artifact) Descriptor() ([]byte, []int) {
+	return file_parser_analysis_analysis_v2_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Artifact) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Artifact) GetPathFragmentId() uint32 {
+	if x != nil {
+		return x.PathFragmentId
+	}
+	return 0
+}
+
+func (x *Artifact) GetIsTreeArtifact() bool {
+	if x != nil {
+		return x.IsTreeArtifact
+	}
+	return false
+}
+
+type Action struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TargetId        uint32                 `protobuf:"varint,1,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+	ActionKey       string                 `protobuf:"bytes,3,opt,name=action_key,json=actionKey,proto3" json:"action_key,omitempty"`
+	Mnemonic        string                 `protobuf:"bytes,4,opt,name=mnemonic,proto3" json:"mnemonic,omitempty"`
+	Arguments       []string               `protobuf:"bytes,6,rep,name=arguments,proto3" json:"arguments,omitempty"`
+	InputDepSetIds  []uint32               `protobuf:"varint,8,rep,packed,name=input_dep_set_ids,json=inputDepSetIds,proto3" json:"input_dep_set_ids,omitempty"`
+	OutputIds       []uint32               `protobuf:"varint,9,rep,packed,name=output_ids,json=outputIds,proto3" json:"output_ids,omitempty"`
+	PrimaryOutputId uint32                 `protobuf:"varint,12,opt,name=primary_output_id,json=primaryOutputId,proto3" json:"primary_output_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Action) Reset() {
+	*x = Action{}
+	mi := &file_parser_analysis_analysis_v2_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Action) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Action) ProtoMessage() {}
+
+func (x *Action) ProtoReflect() protoreflect.Message {
+	mi := &file_parser_analysis_analysis_v2_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Action.ProtoReflect.Descriptor instead.
+func (*Action) Descriptor() ([]byte, []int) {
+	return file_parser_analysis_analysis_v2_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Action) GetTargetId() uint32 {
+	if x != nil {
+		return x.TargetId
+	}
+	return 0
+}
+
+func (x *Action) GetActionKey() string {
+	if x != nil {
+		return x.ActionKey
+	}
+	return ""
+}
+
+func (x *Action) GetMnemonic() string {
+	if x != nil {
+		return x.Mnemonic
+	}
+	return ""
+}
+
+func (x *Action) GetArguments() []string {
+	if x != nil {
+		return x.Arguments
+	}
+	return nil
+}
+
+func (x *Action) GetInputDepSetIds() []uint32 {
+	if x != nil {
+		return x.InputDepSetIds
+	}
+	return nil
+}
+
+func (x *Action) GetOutputIds() []uint32 {
+	if x != nil {
+		return x.OutputIds
+	}
+	return nil
+}
+
+func (x *Action) GetPrimaryOutputId() uint32 {
+	if x != nil {
+		return x.PrimaryOutputId
+	}
+	return 0
+}
+
+type Target struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Label         string                 `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Target) Reset() {
+	*x = Target{}
+	mi := &file_parser_analysis_analysis_v2_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Target) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Target) ProtoMessage() {}
+
+func (x *Target) ProtoReflect() protoreflect.Message {
+	mi := &file_parser_analysis_analysis_v2_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Target.ProtoReflect.Descriptor instead.
+func (*Target) Descriptor() ([]byte, []int) {
+	return file_parser_analysis_analysis_v2_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Target) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Target) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+type DepSetOfFiles struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Id                  uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	TransitiveDepSetIds []uint32               `protobuf:"varint,2,rep,packed,name=transitive_dep_set_ids,json=transitiveDepSetIds,proto3" json:"transitive_dep_set_ids,omitempty"`
+	DirectArtifactIds   []uint32               `protobuf:"varint,3,rep,packed,name=direct_artifact_ids,json=directArtifactIds,proto3" json:"direct_artifact_ids,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *DepSetOfFiles) Reset() {
+	*x = DepSetOfFiles{}
+	mi := &file_parser_analysis_analysis_v2_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DepSetOfFiles) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DepSetOfFiles) ProtoMessage() {}
+
+func (x *DepSetOfFiles) ProtoReflect() protoreflect.Message {
+	mi := &file_parser_analysis_analysis_v2_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DepSetOfFiles.ProtoReflect.Descriptor instead.
+func (*DepSetOfFiles) Descriptor() ([]byte, []int) {
+	return file_parser_analysis_analysis_v2_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DepSetOfFiles) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DepSetOfFiles) GetTransitiveDepSetIds() []uint32 {
+	if x != nil {
+		return x.TransitiveDepSetIds
+	}
+	return nil
+}
+
+func (x *DepSetOfFiles) GetDirectArtifactIds() []uint32 {
+	if x != nil {
+		return x.DirectArtifactIds
+	}
+	return nil
+}
+
+type PathFragment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Label         string                 `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	ParentId      uint32                 `protobuf:"varint,3,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PathFragment) Reset() {
+	*x = PathFragment{}
+	mi := &file_parser_analysis_analysis_v2_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PathFragment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathFragment) ProtoMessage() {}
+
+func (x *PathFragment) ProtoReflect() protoreflect.Message {
+	mi := &file_parser_analysis_analysis_v2_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathFragment.ProtoReflect.Descriptor instead.
+func (*PathFragment) Descriptor() ([]byte, []int) {
+	return file_parser_analysis_analysis_v2_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PathFragment) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PathFragment) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *PathFragment) GetParentId() uint32 {
+	if x != nil {
+		return x.ParentId
+	}
+	return 0
+}
+
+var File_parser_analysis_analysis_v2_proto protoreflect.FileDescriptor
+
+const file_parser_analysis_analysis_v2_proto_rawDesc = "" +
+	"\n" +
+	"!parser/analysis/analysis_v2.proto\x12\banalysis\"\xa1\x02\n" +
+	"\x14ActionGraphContainer\x120\n" +
+	"\tartifacts\x18\x01 \x03(\v2\x12.analysis.ArtifactR\tartifacts\x12*\n" +
+	"\aactions\x18\x02 \x03(\v2\x10.analysis.ActionR\aactions\x12*\n" +
+	"\atargets\x18\x03 \x03(\v2\x10.analysis.TargetR\atargets\x12@\n" +
+	"\x10dep_set_of_files\x18\x04 \x03(\v2\x17.analysis.DepSetOfFilesR\rdepSetOfFiles\x12=\n" +
+	"\x0epath_fragments\x18\b \x03(\v2\x16.analysis.PathFragmentR\rpathFragments\"n\n" +
+	"\bArtifact\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12(\n" +
+	"\x10path_fragment_id\x18\x02 \x01(\rR\x0epathFragmentId\x12(\n" +
+	"\x10is_tree_artifact\x18\x03 \x01(\bR\x0eisTreeArtifact\"\xf4\x01\n" +
+	"\x06Action\x12\x1b\n" +
+	"\ttarget_id\x18\x01 \x01(\rR\btargetId\x12\x1d\n" +
+	"\n" +
+	"action_key\x18\x03 \x01(\tR\tactionKey\x12\x1a\n" +
+	"\bmnemonic\x18\x04 \x01(\tR\bmnemonic\x12\x1c\n" +
+	"\targuments\x18\x06 \x03(\tR\targuments\x12)\n" +
+	"\x11input_dep_set_ids\x18\b \x03(\rR\x0einputDepSetIds\x12\x1d\n" +
+	"\n" +
+	"output_ids\x18\t \x03(\rR\toutputIds\x12*\n" +
+	"\x11primary_output_id\x18\f \x01(\rR\x0fprimaryOutputId\".\n" +
+	"\x06Target\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\"\x84\x01\n" +
+	"\rDepSetOfFiles\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x123\n" +
+	"\x16transitive_dep_set_ids\x18\x02 \x03(\rR\x13transitiveDepSetIds\x12.\n" +
+	"\x13direct_artifact_ids\x18\x03 \x03(\rR\x11directArtifactIds\"Q\n" +
+	"\fPathFragment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\x12\x1b\n" +
+	"\tparent_id\x18\x03 \x01(\rR\bparentIdB9Z7github.com/distninja/distninja/parser/analysis;analysisb\x06proto3"
+
+var (
+	file_parser_analysis_analysis_v2_proto_rawDescOnce sync.Once
+	file_parser_analysis_analysis_v2_proto_rawDescData []byte
+)
+
+func file_parser_analysis_analysis_v2_proto_rawDescGZIP() []byte {
+	file_parser_analysis_analysis_v2_proto_rawDescOnce.Do(func() {
+		file_parser_analysis_analysis_v2_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_parser_analysis_analysis_v2_proto_rawDesc), len(file_parser_analysis_analysis_v2_proto_rawDesc)))
+	})
+	return file_parser_analysis_analysis_v2_proto_rawDescData
+}
+
+var file_parser_analysis_analysis_v2_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_parser_analysis_analysis_v2_proto_goTypes = []any{
+	(*ActionGraphContainer)(nil), // 0: analysis.ActionGraphContainer
+	(*Artifact)(nil),             // 1: analysis.Artifact
+	(*Action)(nil),               // 2: analysis.Action
+	(*Target)(nil),               // 3: analysis.Target
+	(*DepSetOfFiles)(nil),        // 4: analysis.DepSetOfFiles
+	(*PathFragment)(nil),         // 5: analysis.PathFragment
+}
+var file_parser_analysis_analysis_v2_proto_depIdxs = []int32{
+	1, // 0: analysis.ActionGraphContainer.artifacts:type_name -> analysis.Artifact
+	2, // 1: analysis.ActionGraphContainer.actions:type_name -> analysis.Action
+	3, // 2: analysis.ActionGraphContainer.targets:type_name -> analysis.Target
+	4, // 3: analysis.ActionGraphContainer.dep_set_of_files:type_name -> analysis.DepSetOfFiles
+	5, // 4: analysis.ActionGraphContainer.path_fragments:type_name -> analysis.PathFragment
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_parser_analysis_analysis_v2_proto_init() }
+func file_parser_analysis_analysis_v2_proto_init() {
+	if File_parser_analysis_analysis_v2_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_parser_analysis_analysis_v2_proto_rawDesc), len(file_parser_analysis_analysis_v2_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_parser_analysis_analysis_v2_proto_goTypes,
+		DependencyIndexes: file_parser_analysis_analysis_v2_proto_depIdxs,
+		MessageInfos:      file_parser_analysis_analysis_v2_proto_msgTypes,
+	}.Build()
+	File_parser_analysis_analysis_v2_proto = out.File
+	file_parser_analysis_analysis_v2_proto_goTypes = nil
+	file_parser_analysis_analysis_v2_proto_depIdxs = nil
+}
@@ -0,0 +1,214 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/distninja/distninja/parser/analysis"
+)
+
+var (
+	// invalidRuleChars are the characters ninja doesn't allow in rule names
+	invalidRuleChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+	// safeShellArg matches arguments that need no quoting
+	safeShellArg = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+)
+
+// ReadAquery converts the action graph written by bazel aquery --output=proto or --output=jsonproto into
+// a manifest. Each mnemonic becomes a rule running the command line of its actions, stored in the cmd
+// variable of each build along with the label of the Bazel target.
+func ReadAquery(r io.Reader) (*Manifest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read action graph: %w", err)
+	}
+
+	var container analysis.ActionGraphContainer
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(trimmed, &container)
+	} else {
+		err = proto.Unmarshal(data, &container)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode action graph: %w", err)
+	}
+
+	graph := newActionGraph(&container)
+
+	manifest := &Manifest{
+		Rules:     make(map[string]*ManifestRule),
+		Variables: make(map[string]string),
+	}
+
+	for _, action := range container.Actions {
+		outputs := make([]string, 0, len(action.OutputIds))
+		for _, id := range action.OutputIds {
+			outputs = append(outputs, escapeNinjaPath(graph.artifactPath(id)))
+		}
+		if len(outputs) == 0 {
+			continue
+		}
+
+		var inputs []string
+		for _, id := range graph.inputs(action.InputDepSetIds) {
+			inputs = append(inputs, escapeNinjaPath(graph.artifactPath(id)))
+		}
+
+		rule := invalidRuleChars.ReplaceAllString(action.Mnemonic, "_")
+		if rule == "" {
+			rule = "action"
+		}
+
+		if _, ok := manifest.Rules[rule]; !ok {
+			manifest.Rules[rule] = &ManifestRule{
+				Name:    rule,
+				Command: "$cmd",
+				Variables: map[string]string{
+					"command":     "$cmd",
+					"description": action.Mnemonic + " $out",
+				},
+			}
+		}
+
+		arguments := make([]string, len(action.Arguments))
+		for i, argument := range action.Arguments {
+			arguments[i] = shellQuote(argument)
+		}
+
+		manifest.Builds = append(manifest.Builds, &ParsedBuild{
+			Rule:    rule,
+			Outputs: outputs,
+			Inputs:  inputs,
+			Variables: map[string]string{
+				"cmd":   escapeNinjaValue(strings.Join(arguments, " ")),
+				"label": escapeNinjaValue(graph.labels[action.TargetId]),
+			},
+			Pool: "default",
+		})
+	}
+
+	return manifest, nil
+}
+
+// actionGraph resolves the ids of an aquery action graph
+type actionGraph struct {
+	fragments map[uint32]*analysis.PathFragment
+	artifacts map[uint32]uint32
+	depSets   map[uint32]*analysis.DepSetOfFiles
+	labels    map[uint32]string
+	paths     map[uint32]string
+}
+
+func newActionGraph(container *analysis.ActionGraphContainer) *actionGraph {
+	g := &actionGraph{
+		fragments: make(map[uint32]*analysis.PathFragment, len(container.PathFragments)),
+		artifacts: make(map[uint32]uint32, len(container.Artifacts)),
+		depSets:   make(map[uint32]*analysis.DepSetOfFiles, len(container.DepSetOfFiles)),
+		labels:    make(map[uint32]string, len(container.Targets)),
+		paths:     make(map[uint32]string),
+	}
+
+	for _, fragment := range container.PathFragments {
+		g.fragments[fragment.Id] = fragment
+	}
+	for _, artifact := range container.Artifacts {
+		g.artifacts[artifact.Id] = artifact.PathFragmentId
+	}
+	for _, depSet := range container.DepSetOfFiles {
+		g.depSets[depSet.Id] = depSet
+	}
+	for _, target := range container.Targets {
+		g.labels[target.Id] = target.Label
+	}
+
+	return g
+}
+
+// artifactPath joins the path fragments of an artifact, parents come first
+func (g *actionGraph) artifactPath(id uint32) string {
+	if path, ok := g.paths[id]; ok {
+		return path
+	}
+
+	var parts []string
+	seen := make(map[uint32]bool)
+	for fragmentID := g.artifacts[id]; fragmentID != 0 && !seen[fragmentID]; {
+		seen[fragmentID] = true
+		fragment := g.fragments[fragmentID]
+		if fragment == nil {
+			break
+		}
+		parts = append(parts, fragment.Label)
+		fragmentID = fragment.ParentId
+	}
+
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+
+	path := strings.Join(parts, "/")
+	g.paths[id] = path
+
+	return path
+}
+
+// inputs flattens dep sets into their artifacts, each listed once in the order first reached
+func (g *actionGraph) inputs(depSetIDs []uint32) []uint32 {
+	var result []uint32
+	artifacts := make(map[uint32]bool)
+	visited := make(map[uint32]bool)
+
+	var visit func(id uint32)
+	visit = func(id uint32) {
+		if visited[id] {
+			return
+		}
+		visited[id] = true
+
+		depSet := g.depSets[id]
+		if depSet == nil {
+			return
+		}
+
+		for _, artifact := range depSet.DirectArtifactIds {
+			if !artifacts[artifact] {
+				artifacts[artifact] = true
+				result = append(result, artifact)
+			}
+		}
+		for _, transitive := range depSet.TransitiveDepSetIds {
+			visit(transitive)
+		}
+	}
+
+	for _, id := range depSetIDs {
+		visit(id)
+	}
+
+	return result
+}
+
+// shellQuote quotes s for a POSIX shell if it contains anything but safe characters
+func shellQuote(s string) string {
+	if safeShellArg.MatchString(s) {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// escapeNinjaValue escapes s for use as a ninja variable value
+func escapeNinjaValue(s string) string {
+	return strings.NewReplacer("$", "$$", "\n", "$\n").Replace(s)
+}
+
+// escapeNinjaPath escapes s for use as a path in a build statement
+func escapeNinjaPath(s string) string {
+	return strings.NewReplacer("$", "$$", " ", "$ ", ":", "$:").Replace(s)
+}
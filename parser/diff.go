@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// ManifestChange is a rule or build that differs between two manifests, builds are named by their
+// outputs
+type ManifestChange struct {
+	Kind    string   `json:"kind"`
+	Name    string   `json:"name"`
+	Change  string   `json:"change"`
+	Details []string `json:"details,omitempty"`
+}
+
+// String formats the change as a line prefixed with +, - or ~
+func (c ManifestChange) String() string {
+	prefix := map[string]string{ChangeAdded: "+", ChangeRemoved: "-", ChangeChanged: "~"}[c.Change]
+	line := fmt.Sprintf("%s %s %s", prefix, c.Kind, c.Name)
+
+	for _, detail := range c.Details {
+		line += "\n    " + detail
+	}
+
+	return line
+}
+
+// DiffManifests returns the rules and then the builds added, removed or changed from one manifest to the other, each
+// in name order
+func DiffManifests(from, to *Manifest) []ManifestChange {
+	var changes []ManifestChange
+
+	for _, name := range unionNames(from.RuleNames(), to.RuleNames()) {
+		before, after := from.Rules[name], to.Rules[name]
+
+		switch {
+		case before == nil:
+			changes = append(changes, ManifestChange{Kind: "rule", Name: name, Change: ChangeAdded})
+		case after == nil:
+			changes = append(changes, ManifestChange{Kind: "rule", Name: name, Change: ChangeRemoved})
+		default:
+			// The command is one of the rule variables
+			details := appendVariableDetails(nil, before.Variables, after.Variables)
+			if len(details) > 0 {
+				changes = append(changes, ManifestChange{Kind: "rule", Name: name, Change: ChangeChanged, Details: details})
+			}
+		}
+	}
+
+	oldBuilds, oldNames := buildsByOutputs(from.Builds)
+	newBuilds, newNames := buildsByOutputs(to.Builds)
+
+	for _, name := range unionNames(oldNames, newNames) {
+		before, after := oldBuilds[name], newBuilds[name]
+
+		switch {
+		case before == nil:
+			changes = append(changes, ManifestChange{Kind: "build", Name: name, Change: ChangeAdded})
+		case after == nil:
+			changes = append(changes, ManifestChange{Kind: "build", Name: name, Change: ChangeRemoved})
+		default:
+			var details []string
+			details = appendValueDetail(details, "rule", before.Rule, after.Rule)
+			details = appendListDetail(details, "inputs", before.Inputs, after.Inputs)
+			details = appendListDetail(details, "implicit", before.ImplicitDeps, after.ImplicitDeps)
+			details = appendListDetail(details, "order-only", before.OrderDeps, after.OrderDeps)
+			details = appendValueDetail(details, "pool", before.Pool, after.Pool)
+			details = appendVariableDetails(details, before.Variables, after.Variables)
+			if len(details) > 0 {
+				changes = append(changes, ManifestChange{Kind: "build", Name: name, Change: ChangeChanged, Details: details})
+			}
+		}
+	}
+
+	return changes
+}
+
+// buildsByOutputs maps the outputs of each build to the build, the first one if several have the same,
+// and returns the names in file order
+func buildsByOutputs(builds []*ParsedBuild) (map[string]*ParsedBuild, []string) {
+	result := make(map[string]*ParsedBuild, len(builds))
+	names := make([]string, 0, len(builds))
+
+	for _, build := range builds {
+		name := strings.Join(build.Outputs, " ")
+		if _, ok := result[name]; !ok {
+			result[name] = build
+			names = append(names, name)
+		}
+	}
+
+	return result, names
+}
+
+// unionNames returns the names in a or b sorted and without duplicates
+func unionNames(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	names := make([]string, 0, len(a)+len(b))
+
+	for _, name := range append(append([]string{}, a...), b...) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func appendValueDetail(details []string, name, before, after string) []string {
+	if before == after {
+		return details
+	}
+
+	return append(details, fmt.Sprintf("%s: %q -> %q", name, before, after))
+}
+
+func appendListDetail(details []string, name string, before, after []string) []string {
+	if strings.Join(before, "\x00") == strings.Join(after, "\x00") {
+		return details
+	}
+
+	return append(details, fmt.Sprintf("%s: [%s] -> [%s]", name, strings.Join(before, " "), strings.Join(after, " ")))
+}
+
+func appendVariableDetails(details []string, before, after map[string]string) []string {
+	for _, name := range unionNames(sortedKeys(before), sortedKeys(after)) {
+		oldValue, hadOld := before[name]
+		newValue, hasNew := after[name]
+
+		switch {
+		case !hadOld:
+			details = append(details, fmt.Sprintf("$%s: added %q", name, newValue))
+		case !hasNew:
+			details = append(details, fmt.Sprintf("$%s: removed %q", name, oldValue))
+		default:
+			details = appendValueDetail(details, "$"+name, oldValue, newValue)
+		}
+	}
+
+	return details
+}
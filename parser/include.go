@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxIncludeDepth stops runaway include chains that the cycle check can't see, e.g. through symlinks
+const maxIncludeDepth = 64
+
+// NewIncludeReader returns the ninja file at path with its include and subninja statements replaced by
+// the files they name, relative paths are resolved against dir as ninja -C does. Rules of a subninja
+// that reuse the name of a rule of another scope are renamed, see ruleScope. The files are read while
+// the returned reader is consumed, so memory use does not grow with their size.
+func NewIncludeReader(dir, path string) io.ReadCloser {
+	reader, writer := io.Pipe()
+
+	go func() {
+		_ = writer.CloseWithError(expandIncludes(writer, dir, path, nil, newRuleScope(path)))
+	}()
+
+	return reader
+}
+
+// expandIncludes copies the file at path to w, expanding include statements in place, stack holds the
+// files being expanded and scope the rules of the file
+func expandIncludes(w io.Writer, dir, path string, stack []string, scope *ruleScope) error {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	for _, open := range stack {
+		if open == path {
+			return fmt.Errorf("include cycle: %s", strings.Join(append(stack, path), " -> "))
+		}
+	}
+
+	if len(stack) >= maxIncludeDepth {
+		return fmt.Errorf("includes nested deeper than %d levels at %s", maxIncludeDepth, path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	stack = append(stack, path)
+
+	reader := bufio.NewReader(file)
+
+	for {
+		line, readErr := reader.ReadString('\n')
+
+		if included, subninja, ok := includePath(line); ok {
+			includedScope := scope
+			if subninja {
+				includedScope = scope.subninja(included)
+			}
+			if err := expandIncludes(w, dir, included, stack, includedScope); err != nil {
+				return err
+			}
+			// Statements of the included file never continue on the next line of this one
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		} else if _, err := io.WriteString(w, scopeRuleNames(line, scope)); err != nil {
+			return err
+		}
+
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read %s: %w", path, readErr)
+		}
+	}
+}
+
+// includePath returns the file named by an include or subninja statement, both are inlined and only the
+// rules of a subninja are scoped
+func includePath(line string) (path string, subninja, ok bool) {
+	// Indented lines are variables of the statement above
+	if line == "" || line[0] == ' ' || line[0] == '\t' {
+		return "", false, false
+	}
+
+	for _, keyword := range []string{"include ", "subninja "} {
+		if strings.HasPrefix(line, keyword) {
+			return strings.TrimSpace(line[len(keyword):]), keyword == "subninja ", true
+		}
+	}
+
+	return "", false, false
+}
+
+// scopeRuleNames replaces the rule name of a rule statement or of a build statement whose rule is on
+// its first line with the name stored for it in scope
+func scopeRuleNames(line string, scope *ruleScope) string {
+	if strings.HasPrefix(line, "rule ") {
+		name := strings.TrimSpace(line[len("rule "):])
+		if stored, _ := scope.define(name); stored != name {
+			return strings.Replace(line, name, stored, 1)
+		}
+		return line
+	}
+
+	if !strings.HasPrefix(line, "build ") {
+		return line
+	}
+
+	// The rule follows the first colon that isn't escaped as $:
+	colon := -1
+	for i := 0; i < len(line); i++ {
+		if line[i] == '$' {
+			i++
+		} else if line[i] == ':' {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return line
+	}
+
+	start := colon + 1
+	for start < len(line) && line[start] == ' ' {
+		start++
+	}
+	end := start
+	for end < len(line) && !strings.ContainsRune(" \t\r\n$|", rune(line[end])) {
+		end++
+	}
+
+	name := line[start:end]
+	if stored := scope.resolve(name); stored != name {
+		return line[:start] + stored + line[end:]
+	}
+
+	return line
+}
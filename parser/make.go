@@ -0,0 +1,625 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// makeRule runs the recipe of a make target, stored in the cmd variable of its build
+	makeRule = "make"
+	// makeDirectives are not supported, the file can't be converted reliably without them
+	makeDirectives = "ifeq ifneq ifdef ifndef else endif include -include sinclude define endef"
+)
+
+// makeVariable is a make variable, simple ones were expanded when they were set
+type makeVariable struct {
+	value  string
+	simple bool
+}
+
+// makeTarget collects the rules of a target, each adds prerequisites and the last one with a recipe
+// sets it
+type makeTarget struct {
+	name      string
+	prereqs   []string
+	orderOnly []string
+	recipe    []string
+	// stem is the part matched by % in a static pattern rule
+	stem string
+}
+
+// makeGroup is an explicit rule, the recipe that follows belongs to each of its targets
+type makeGroup struct {
+	targets []*makeTarget
+	recipe  []string
+}
+
+// makePattern is a pattern rule such as %.o: %.c
+type makePattern struct {
+	target    string
+	prereqs   []string
+	orderOnly []string
+	recipe    []string
+}
+
+type makefileReader struct {
+	variables map[string]*makeVariable
+	targets   map[string]*makeTarget
+	order     []string
+	groups    []*makeGroup
+	patterns  []*makePattern
+	phony     map[string]bool
+	defaults  []string
+	line      int
+}
+
+// ReadMakefile converts the common subset of GNU make into a manifest: variables set with =, :=, ::=,
+// ?= and += and referenced as $(VAR), ${VAR} or $(VAR:.c=.o), explicit, pattern and static pattern rules,
+// order-only prerequisites and .PHONY. Targets with a recipe become builds of the make rule with the
+// expanded recipe in their cmd variable, the others become phony. Pattern rules apply to targets and
+// prerequisites without a recipe, without checking which files exist. Functions, conditionals and
+// includes are reported as errors.
+func ReadMakefile(r io.Reader) (*Manifest, error) {
+	mr := &makefileReader{
+		variables: make(map[string]*makeVariable),
+		targets:   make(map[string]*makeTarget),
+		phony:     make(map[string]bool),
+	}
+
+	if err := mr.read(r); err != nil {
+		return nil, err
+	}
+
+	return mr.manifest()
+}
+
+func (mr *makefileReader) read(r io.Reader) error {
+	lines := newLineReader(r)
+
+	// recipe receives the recipe lines following a rule
+	var recipe *[]string
+
+	for {
+		line, ok := lines.next()
+		if !ok {
+			break
+		}
+		mr.line++
+
+		for strings.HasSuffix(line, `\`) {
+			next, ok := lines.next()
+			if !ok {
+				break
+			}
+			mr.line++
+			line = strings.TrimSuffix(line, `\`) + " " + strings.TrimSpace(next)
+		}
+
+		if strings.HasPrefix(line, "\t") && recipe != nil {
+			if command := strings.TrimSpace(line); command != "" {
+				*recipe = append(*recipe, command)
+			}
+			continue
+		}
+
+		line = strings.TrimSpace(stripMakeComment(line))
+		if line == "" {
+			continue
+		}
+
+		if directive := strings.Fields(line)[0]; strings.Contains(" "+makeDirectives+" ", " "+directive+" ") {
+			return mr.errorf("%s is not supported", directive)
+		}
+
+		line = strings.TrimPrefix(strings.TrimPrefix(line, "export "), "override ")
+
+		if strings.HasPrefix(line, "vpath") || strings.HasPrefix(line, "unexport") {
+			recipe = nil
+			continue
+		}
+
+		if name, op, value, ok := splitMakeAssignment(line); ok {
+			if err := mr.assign(name, op, value); err != nil {
+				return err
+			}
+			recipe = nil
+			continue
+		}
+
+		var err error
+		if recipe, err = mr.rule(line); err != nil {
+			return err
+		}
+	}
+
+	return lines.Err()
+}
+
+func (mr *makefileReader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", mr.line, fmt.Sprintf(format, args...))
+}
+
+// stripMakeComment removes a comment that is not escaped as \#
+func stripMakeComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '#' && (i == 0 || line[i-1] != '\\') {
+			return line[:i]
+		}
+	}
+
+	return strings.ReplaceAll(line, `\#`, "#")
+}
+
+// splitMakeAssignment splits a variable assignment, it is one if = comes before any : that isn't part
+// of := or ::=
+func splitMakeAssignment(line string) (name, op, value string, ok bool) {
+	eq := strings.Index(line, "=")
+	if eq <= 0 {
+		return "", "", "", false
+	}
+
+	op = "="
+	nameEnd := eq
+	for _, prefix := range []string{"::", ":", "?", "+", "!"} {
+		if strings.HasSuffix(line[:eq], prefix) {
+			op = prefix + "="
+			nameEnd = eq - len(prefix)
+			break
+		}
+	}
+
+	if strings.Contains(line[:nameEnd], ":") {
+		return "", "", "", false
+	}
+
+	name = strings.TrimSpace(line[:nameEnd])
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", "", false
+	}
+
+	return name, op, strings.TrimSpace(line[eq+1:]), true
+}
+
+func (mr *makefileReader) assign(name, op, value string) error {
+	switch op {
+	case "=":
+		mr.variables[name] = &makeVariable{value: value}
+	case ":=", "::=":
+		expanded, err := mr.expand(value, nil)
+		if err != nil {
+			return err
+		}
+		mr.variables[name] = &makeVariable{value: expanded, simple: true}
+	case "?=":
+		if _, ok := mr.variables[name]; !ok {
+			mr.variables[name] = &makeVariable{value: value}
+		}
+	case "+=":
+		variable, ok := mr.variables[name]
+		if !ok {
+			mr.variables[name] = &makeVariable{value: value}
+			break
+		}
+		if variable.simple {
+			expanded, err := mr.expand(value, nil)
+			if err != nil {
+				return err
+			}
+			value = expanded
+		}
+		variable.value = strings.TrimSpace(variable.value + " " + value)
+	default:
+		return mr.errorf("%s assignments are not supported", op)
+	}
+
+	return nil
+}
+
+// rule records a rule line and returns where its recipe lines go
+func (mr *makefileReader) rule(line string) (*[]string, error) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return nil, mr.errorf("expected a rule or variable assignment, got %q", line)
+	}
+
+	targetText, rest := line[:colon], strings.TrimPrefix(line[colon+1:], ":")
+
+	var inline []string
+	if semicolon := strings.Index(rest, ";"); semicolon >= 0 {
+		if command := strings.TrimSpace(rest[semicolon+1:]); command != "" {
+			inline = append(inline, command)
+		}
+		rest = rest[:semicolon]
+	}
+
+	if strings.Contains(rest, "=") {
+		return nil, mr.errorf("target specific variables are not supported")
+	}
+
+	expandedTargets, err := mr.expand(targetText, nil)
+	if err != nil {
+		return nil, err
+	}
+	targets := strings.Fields(expandedTargets)
+
+	// Static pattern rules: targets: target-pattern: prereq-patterns
+	targetPattern := ""
+	if second := strings.Index(rest, ":"); second >= 0 {
+		expandedPattern, err := mr.expand(rest[:second], nil)
+		if err != nil {
+			return nil, err
+		}
+		targetPattern = strings.TrimSpace(expandedPattern)
+		rest = rest[second+1:]
+	}
+
+	expandedPrereqs, err := mr.expand(rest, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	prereqText, orderText, _ := strings.Cut(expandedPrereqs, "|")
+	prereqs, orderOnly := strings.Fields(prereqText), strings.Fields(orderText)
+
+	if len(targets) == 1 && targets[0] == ".PHONY" {
+		for _, prereq := range prereqs {
+			mr.phony[prereq] = true
+		}
+		return nil, nil
+	}
+
+	if targetPattern == "" && len(targets) > 0 && strings.Contains(targets[0], "%") {
+		pattern := &makePattern{target: targets[0], prereqs: prereqs, orderOnly: orderOnly, recipe: inline}
+		mr.patterns = append(mr.patterns, pattern)
+		return &pattern.recipe, nil
+	}
+
+	// A rule with several targets is a rule for each of them, they share the recipe lines that follow
+	group := &makeGroup{recipe: inline}
+	for _, name := range targets {
+		if strings.HasPrefix(name, ".") {
+			continue // Special targets such as .SUFFIXES
+		}
+
+		target := mr.target(name)
+		if targetPattern != "" {
+			stem, ok := matchMakePattern(targetPattern, name)
+			if !ok {
+				continue
+			}
+			target.stem = stem
+			target.prereqs = append(target.prereqs, substituteMakePattern(prereqs, stem)...)
+			target.orderOnly = append(target.orderOnly, substituteMakePattern(orderOnly, stem)...)
+		} else {
+			target.prereqs = append(target.prereqs, prereqs...)
+			target.orderOnly = append(target.orderOnly, orderOnly...)
+		}
+
+		if len(mr.defaults) == 0 {
+			mr.defaults = []string{name}
+		}
+
+		group.targets = append(group.targets, target)
+	}
+	mr.groups = append(mr.groups, group)
+
+	return &group.recipe, nil
+}
+
+func (mr *makefileReader) target(name string) *makeTarget {
+	target, ok := mr.targets[name]
+	if !ok {
+		target = &makeTarget{name: name}
+		mr.targets[name] = target
+		mr.order = append(mr.order, name)
+	}
+
+	return target
+}
+
+// matchMakePattern returns the stem matched by % if name matches pattern
+func matchMakePattern(pattern, name string) (string, bool) {
+	prefix, suffix, ok := strings.Cut(pattern, "%")
+	if !ok {
+		return "", pattern == name
+	}
+
+	if len(name) < len(prefix)+len(suffix) || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return "", false
+	}
+
+	return name[len(prefix) : len(name)-len(suffix)], true
+}
+
+func substituteMakePattern(patterns []string, stem string) []string {
+	result := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		result[i] = strings.Replace(pattern, "%", stem, 1)
+	}
+
+	return result
+}
+
+// expand expands variable references in s like make does, automatic variables are looked up in auto
+// and are empty outside of recipes. $$ becomes $.
+func (mr *makefileReader) expand(s string, auto map[string]string) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+
+		var name string
+		switch s[i] {
+		case '$':
+			b.WriteByte('$')
+			continue
+		case '(', '{':
+			end := matchingMakeParen(s, i)
+			if end < 0 {
+				return "", mr.errorf("unterminated variable reference in %q", s)
+			}
+			name = s[i+1 : end]
+			i = end
+		default:
+			name = s[i : i+1]
+		}
+
+		value, err := mr.lookup(name, auto)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(value)
+	}
+
+	return b.String(), nil
+}
+
+// matchingMakeParen returns the index of the parenthesis closing the one at open
+func matchingMakeParen(s string, open int) int {
+	closing := map[byte]byte{'(': ')', '{': '}'}[s[open]]
+	depth := 0
+
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case s[open]:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// lookup returns the value of a variable reference without its $( ), automatic variables included
+func (mr *makefileReader) lookup(name string, auto map[string]string) (string, error) {
+	if isAutomaticMakeVariable(name) {
+		return automaticMakeValue(name, auto), nil
+	}
+
+	if strings.ContainsAny(name, " \t,") {
+		return "", mr.errorf("function $(%s) is not supported", name)
+	}
+
+	// Substitution references such as $(SRCS:.c=.o)
+	name, substitution, hasSubstitution := strings.Cut(name, ":")
+
+	expandedName, err := mr.expand(name, auto)
+	if err != nil {
+		return "", err
+	}
+
+	variable := mr.variables[expandedName]
+	if variable == nil {
+		return "", nil
+	}
+
+	value := variable.value
+	if !variable.simple {
+		if value, err = mr.expand(value, auto); err != nil {
+			return "", err
+		}
+	}
+
+	if hasSubstitution {
+		from, to, ok := strings.Cut(substitution, "=")
+		if !ok {
+			return "", mr.errorf("invalid substitution reference $(%s:%s)", name, substitution)
+		}
+		if !strings.Contains(from, "%") {
+			from, to = "%"+from, "%"+to
+		}
+
+		words := strings.Fields(value)
+		for i, word := range words {
+			if stem, ok := matchMakePattern(from, word); ok {
+				words[i] = strings.Replace(to, "%", stem, 1)
+			}
+		}
+		value = strings.Join(words, " ")
+	}
+
+	return value, nil
+}
+
+func isAutomaticMakeVariable(name string) bool {
+	if len(name) == 0 || !strings.ContainsRune("@<^+?*|", rune(name[0])) {
+		return false
+	}
+
+	return len(name) == 1 || len(name) == 2 && (name[1] == 'D' || name[1] == 'F')
+}
+
+// automaticMakeValue returns the value of an automatic variable, the D and F forms give the directory
+// and file parts of each word
+func automaticMakeValue(name string, auto map[string]string) string {
+	value := auto[name[:1]]
+	if len(name) == 1 {
+		return value
+	}
+
+	words := strings.Fields(value)
+	for i, word := range words {
+		dir, file := ".", word
+		if slash := strings.LastIndex(word, "/"); slash >= 0 {
+			dir, file = word[:slash], word[slash+1:]
+		}
+		if name[1] == 'D' {
+			words[i] = dir
+		} else {
+			words[i] = file
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// manifest applies the pattern rules and converts the targets into builds
+func (mr *makefileReader) manifest() (*Manifest, error) {
+	manifest := &Manifest{
+		Rules:     make(map[string]*ManifestRule),
+		Variables: make(map[string]string),
+	}
+
+	for _, group := range mr.groups {
+		if len(group.recipe) == 0 {
+			continue
+		}
+		for _, target := range group.targets {
+			target.recipe = group.recipe
+		}
+	}
+
+	// Prerequisites without a rule of their own may be made by a pattern rule
+	for i := 0; i < len(mr.order); i++ {
+		target := mr.targets[mr.order[i]]
+		for _, prereq := range append(append([]string{}, target.prereqs...), target.orderOnly...) {
+			if _, ok := mr.targets[prereq]; !ok && mr.findPattern(prereq) != nil {
+				mr.target(prereq)
+			}
+		}
+	}
+
+	for _, name := range mr.order {
+		target := mr.targets[name]
+
+		if len(target.recipe) == 0 && !mr.phony[name] {
+			if pattern := mr.findPattern(name); pattern != nil {
+				target.stem, _ = matchMakePattern(pattern.target, name)
+				target.prereqs = append(substituteMakePattern(pattern.prereqs, target.stem), target.prereqs...)
+				target.orderOnly = append(substituteMakePattern(pattern.orderOnly, target.stem), target.orderOnly...)
+				target.recipe = pattern.recipe
+			}
+		}
+
+		prereqs := uniqueStrings(target.prereqs)
+
+		build := &ParsedBuild{
+			Rule:      phonyRule,
+			Outputs:   []string{escapeNinjaPath(name)},
+			Inputs:    escapeNinjaPaths(prereqs),
+			OrderDeps: escapeNinjaPaths(uniqueStrings(target.orderOnly)),
+			Variables: make(map[string]string),
+			Pool:      "default",
+		}
+
+		if len(target.recipe) == 0 {
+			// Empty rules only mention files, such as header dependencies
+			if len(prereqs) > 0 || mr.phony[name] {
+				manifest.Builds = append(manifest.Builds, build)
+			}
+			continue
+		}
+
+		first := ""
+		if len(prereqs) > 0 {
+			first = prereqs[0]
+		}
+
+		auto := map[string]string{
+			"@": name,
+			"<": first,
+			"^": strings.Join(prereqs, " "),
+			"+": strings.Join(target.prereqs, " "),
+			"?": strings.Join(prereqs, " "),
+			"*": target.stem,
+			"|": strings.Join(uniqueStrings(target.orderOnly), " "),
+		}
+
+		commands := make([]string, 0, len(target.recipe))
+		for _, line := range target.recipe {
+			command, err := mr.expand(line, auto)
+			if err != nil {
+				return nil, fmt.Errorf("recipe of %s: %w", name, err)
+			}
+
+			// @ only silences make, - ignores failures
+			command = strings.TrimLeft(command, "@+ ")
+			if command == "" || strings.HasPrefix(command, "#") {
+				continue // Comments would hide the commands joined after them
+			}
+			if strings.HasPrefix(command, "-") {
+				command = "(" + strings.TrimSpace(command[1:]) + ") || true"
+			}
+			commands = append(commands, command)
+		}
+
+		build.Rule = makeRule
+		build.Variables["cmd"] = escapeNinjaValue(strings.Join(commands, " && "))
+		manifest.Builds = append(manifest.Builds, build)
+
+		manifest.Rules[makeRule] = &ManifestRule{
+			Name:      makeRule,
+			Command:   "$cmd",
+			Variables: map[string]string{"command": "$cmd", "description": "MAKE $out"},
+		}
+	}
+
+	manifest.Defaults = escapeNinjaPaths(mr.defaults)
+
+	return manifest, nil
+}
+
+// findPattern returns the first pattern rule with a recipe that can make name
+func (mr *makefileReader) findPattern(name string) *makePattern {
+	for _, pattern := range mr.patterns {
+		if _, ok := matchMakePattern(pattern.target, name); ok && len(pattern.recipe) > 0 {
+			return pattern
+		}
+	}
+
+	return nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+func escapeNinjaPaths(paths []string) []string {
+	result := make([]string, len(paths))
+	for i, path := range paths {
+		result[i] = escapeNinjaPath(path)
+	}
+
+	return result
+}
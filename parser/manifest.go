@@ -0,0 +1,265 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ManifestRule is a rule statement of a ninja file
+type ManifestRule struct {
+	Name      string
+	Command   string
+	Variables map[string]string
+}
+
+// Manifest holds the statements of a ninja file and the files it includes, in file order
+type Manifest struct {
+	Rules     map[string]*ManifestRule
+	Builds    []*ParsedBuild
+	Defaults  []string
+	Variables map[string]string
+}
+
+// ReadManifest reads the ninja file at path and the files it includes without loading them into a
+// store, relative paths are resolved against dir as ninja -C does. Statements that can't be parsed
+// are skipped and returned as diagnostics, semantic checks are left to Validate.
+func ReadManifest(dir, path string) (*Manifest, []Diagnostic, error) {
+	v := newValidator(dir)
+	if err := v.read(path); err != nil {
+		return nil, nil, err
+	}
+
+	manifest := &Manifest{
+		Rules:     make(map[string]*ManifestRule, len(v.rules)),
+		Builds:    make([]*ParsedBuild, 0, len(v.builds)),
+		Defaults:  v.defaults,
+		Variables: v.globals,
+	}
+
+	for name, rule := range v.rules {
+		manifest.Rules[name] = &ManifestRule{Name: name, Command: rule.command, Variables: rule.variables}
+	}
+
+	for _, b := range v.builds {
+		manifest.Builds = append(manifest.Builds, b.build)
+	}
+
+	return manifest, v.diagnostics, nil
+}
+
+// Producers maps each output to the build producing it, the first one if several do
+func (m *Manifest) Producers() map[string]*ParsedBuild {
+	producers := make(map[string]*ParsedBuild)
+
+	for _, build := range m.Builds {
+		for _, output := range build.Outputs {
+			if _, ok := producers[output]; !ok {
+				producers[output] = build
+			}
+		}
+	}
+
+	return producers
+}
+
+// Consumers maps each file to the builds using it as an explicit, implicit or order-only input
+func (m *Manifest) Consumers() map[string][]*ParsedBuild {
+	consumers := make(map[string][]*ParsedBuild)
+
+	for _, build := range m.Builds {
+		for _, input := range build.AllInputs() {
+			consumers[input] = append(consumers[input], build)
+		}
+	}
+
+	return consumers
+}
+
+// RuleNames returns the names of the rules in alphabetical order
+func (m *Manifest) RuleNames() []string {
+	names := make([]string, 0, len(m.Rules))
+	for name := range m.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// AllInputs returns the explicit, implicit and order-only inputs of the build
+func (pb *ParsedBuild) AllInputs() []string {
+	inputs := make([]string, 0, len(pb.Inputs)+len(pb.ImplicitDeps)+len(pb.OrderDeps))
+	inputs = append(inputs, pb.Inputs...)
+	inputs = append(inputs, pb.ImplicitDeps...)
+
+	return append(inputs, pb.OrderDeps...)
+}
+
+// WriteNinja writes the manifest as a ninja file: variables, rules in name order, builds and defaults.
+// Paths and values are written as they are, so they must already be escaped for ninja.
+func (m *Manifest) WriteNinja(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	for _, name := range sortedKeys(m.Variables) {
+		_, _ = fmt.Fprintf(bw, "%s = %s\n", name, m.Variables[name])
+	}
+
+	for _, name := range m.RuleNames() {
+		rule := m.Rules[name]
+
+		_, _ = fmt.Fprintf(bw, "\nrule %s\n  command = %s\n", name, rule.Command)
+		for _, key := range sortedKeys(rule.Variables) {
+			if key != "command" {
+				_, _ = fmt.Fprintf(bw, "  %s = %s\n", key, rule.Variables[key])
+			}
+		}
+	}
+
+	for _, build := range m.Builds {
+		line := "\nbuild " + strings.Join(build.Outputs, " ") + ": " + build.Rule
+		if len(build.Inputs) > 0 {
+			line += " " + strings.Join(build.Inputs, " ")
+		}
+		if len(build.ImplicitDeps) > 0 {
+			line += " | " + strings.Join(build.ImplicitDeps, " ")
+		}
+		if len(build.OrderDeps) > 0 {
+			line += " || " + strings.Join(build.OrderDeps, " ")
+		}
+		_, _ = fmt.Fprintln(bw, line)
+
+		if build.Pool != "" && build.Pool != "default" {
+			_, _ = fmt.Fprintf(bw, "  pool = %s\n", build.Pool)
+		}
+		for _, key := range sortedKeys(build.Variables) {
+			_, _ = fmt.Fprintf(bw, "  %s = %s\n", key, build.Variables[key])
+		}
+	}
+
+	if len(m.Defaults) > 0 {
+		_, _ = fmt.Fprintf(bw, "\ndefault %s\n", strings.Join(m.Defaults, " "))
+	}
+
+	return bw.Flush()
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Command returns the command of build with the variables of the build, its rule and the file expanded
+// like ninja does, $in and $out being the explicit inputs and the outputs. Phony builds have none.
+func (m *Manifest) Command(build *ParsedBuild) (string, error) {
+	if build.Rule == "phony" {
+		return "", nil
+	}
+
+	rule, ok := m.Rules[build.Rule]
+	if !ok {
+		return "", fmt.Errorf("unknown rule '%s'", build.Rule)
+	}
+
+	// Variables of the file and of builds are expanded in the file scope, those of rules in the scope
+	// of the build using them
+	expandingFile := make(map[string]bool)
+
+	var file func(name string) string
+	file = func(name string) string {
+		value, ok := m.Variables[name]
+		if !ok || expandingFile[name] {
+			return ""
+		}
+		expandingFile[name] = true
+		defer delete(expandingFile, name)
+
+		return expandNinja(value, file)
+	}
+
+	expanding := make(map[string]bool)
+
+	var scope func(name string) string
+	scope = func(name string) string {
+		switch name {
+		case "in":
+			return strings.Join(build.Inputs, " ")
+		case "in_newline":
+			return strings.Join(build.Inputs, "\n")
+		case "out":
+			return strings.Join(build.Outputs, " ")
+		}
+
+		if value, ok := build.Variables[name]; ok {
+			return expandNinja(value, file)
+		}
+
+		if value, ok := rule.Variables[name]; ok && !expanding[name] {
+			// A variable referring to itself expands to nothing, like an undefined one
+			expanding[name] = true
+			defer delete(expanding, name)
+
+			return expandNinja(value, scope)
+		}
+
+		return file(name)
+	}
+
+	expanding["command"] = true
+
+	return expandNinja(rule.Command, scope), nil
+}
+
+// expandNinja replaces the $name and ${name} references in value with what lookup returns for them and
+// unescapes $$, "$ " and "$:"
+func expandNinja(value string, lookup func(name string) string) string {
+	if !strings.Contains(value, "$") {
+		return value
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 >= len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		next := value[i+1]
+		switch {
+		case next == '$' || next == ' ' || next == ':':
+			b.WriteByte(next)
+			i++
+		case next == '{':
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				b.WriteString(value[i:])
+				return b.String()
+			}
+			b.WriteString(lookup(value[i+2 : i+2+end]))
+			i += end + 2
+		case isNinjaVarChar(next):
+			j := i + 1
+			for j < len(value) && isNinjaVarChar(value[j]) {
+				j++
+			}
+			b.WriteString(lookup(value[i+1 : j]))
+			i = j - 1
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+
+	return b.String()
+}
+
+func isNinjaVarChar(c byte) bool {
+	return c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
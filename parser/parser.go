@@ -1,11 +1,12 @@
 package parser
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"strings"
 
-	"github.com/cayleygraph/quad"
-
 	"github.com/distninja/distninja/store"
 )
 
@@ -23,6 +24,7 @@ type ParsedBuild struct {
 // NinjaParser handles parsing of Ninja build files
 type NinjaParser struct {
 	store *store.NinjaStore
+	opts  LoadOptions
 }
 
 // NewNinjaParser creates a new parser instance
@@ -32,15 +34,36 @@ func NewNinjaParser(ninjaStore *store.NinjaStore) *NinjaParser {
 	}
 }
 
+// SetLoadOptions sets the number of store writers and the builds written per transaction
+func (p *NinjaParser) SetLoadOptions(opts LoadOptions) {
+	p.opts = opts
+}
+
 // ParseAndLoad parses ninja file content and loads it into the store
-func (p *NinjaParser) ParseAndLoad(content string) error {
-	lines := strings.Split(content, "\n")
+func (p *NinjaParser) ParseAndLoad(ctx context.Context, content string) error {
+	return p.ParseAndLoadReader(ctx, strings.NewReader(content))
+}
+
+// ParseAndLoadReader parses ninja file content line by line from r and loads it into the store,
+// so memory use does not grow with the file size. Parsed statements are written in batches by a pool
+// of writers while parsing goes on. Loading stops with the error of ctx once it is done, batches written
+// before stay in the store.
+func (p *NinjaParser) ParseAndLoadReader(ctx context.Context, r io.Reader) error {
+	lines := newLineReader(r)
+
+	pipeline := newLoadPipeline(ctx, p.store, p.opts)
+	defer pipeline.stop()
 
 	var currentRule *store.NinjaRule
 	var currentBuild *ParsedBuild
 
-	for i := 0; i < len(lines); i++ {
-		line := strings.TrimSpace(lines[i])
+	for {
+		originalLine, ok := lines.next()
+		if !ok {
+			break
+		}
+
+		line := strings.TrimSpace(originalLine)
 
 		// Skip empty lines and comments
 		if line == "" || strings.HasPrefix(line, "#") {
@@ -48,11 +71,13 @@ func (p *NinjaParser) ParseAndLoad(content string) error {
 		}
 
 		// Handle line continuations
-		for strings.HasSuffix(line, "$") && i+1 < len(lines) {
-			i++
-			if i < len(lines) {
-				line = line[:len(line)-1] + " " + strings.TrimSpace(lines[i])
+		for strings.HasSuffix(line, "$") {
+			next, ok := lines.next()
+			if !ok {
+				break
 			}
+			originalLine = next
+			line = line[:len(line)-1] + " " + strings.TrimSpace(next)
 		}
 
 		// Parse rule definitions
@@ -62,9 +87,7 @@ func (p *NinjaParser) ParseAndLoad(content string) error {
 				if currentRule.Command == "" {
 					return fmt.Errorf("rule %s is missing required command", currentRule.Name)
 				}
-				if _, err := p.store.AddRule(currentRule); err != nil {
-					return fmt.Errorf("failed to add rule %s: %w", currentRule.Name, err)
-				}
+				pipeline.addRule(currentRule)
 			}
 
 			ruleName := strings.TrimSpace(line[5:])
@@ -82,73 +105,21 @@ func (p *NinjaParser) ParseAndLoad(content string) error {
 				if currentRule.Command == "" {
 					return fmt.Errorf("rule %s is missing required command", currentRule.Name)
 				}
-				if _, err := p.store.AddRule(currentRule); err != nil {
-					return fmt.Errorf("failed to add rule %s: %w", currentRule.Name, err)
-				}
+				pipeline.addRule(currentRule)
 				currentRule = nil
 			}
 
 			// Save previous build if exists
 			if currentBuild != nil {
-				if err := p.saveBuild(currentBuild); err != nil {
-					return fmt.Errorf("failed to save build: %w", err)
+				if err := pipeline.addBuild(currentBuild); err != nil {
+					return err
 				}
 			}
 
-			// Parse build line: build outputs: rule inputs | implicit_deps || order_deps
-			buildLine := strings.TrimSpace(line[6:]) // Remove "build "
-
-			// Split by colon to separate outputs and rest
-			colonParts := strings.SplitN(buildLine, ":", 2)
-			if len(colonParts) != 2 {
+			currentBuild = parseBuildLine(line[6:]) // Remove "build "
+			if currentBuild == nil {
 				continue // Skip invalid build lines
 			}
-
-			outputs := p.parseFilePaths(colonParts[0])
-			rest := strings.TrimSpace(colonParts[1])
-
-			// Parse rule and dependencies
-			parts := strings.Fields(rest)
-			if len(parts) == 0 {
-				continue // Skip if no rule specified
-			}
-
-			rule := parts[0]
-			var inputs, implicitDeps, orderDeps []string
-
-			// Join remaining parts and split by dependency separators
-			if len(parts) > 1 {
-				depString := strings.Join(parts[1:], " ")
-
-				// Split by || for order dependencies
-				orderParts := strings.Split(depString, "||")
-				if len(orderParts) > 1 {
-					orderDeps = p.parseFilePaths(strings.TrimSpace(orderParts[1]))
-					depString = strings.TrimSpace(orderParts[0])
-				}
-
-				// Split by | for implicit dependencies
-				implicitParts := strings.Split(depString, "|")
-				if len(implicitParts) > 1 {
-					implicitDeps = p.parseFilePaths(strings.TrimSpace(implicitParts[1]))
-					depString = strings.TrimSpace(implicitParts[0])
-				}
-
-				// Remaining are regular inputs
-				if depString != "" {
-					inputs = p.parseFilePaths(depString)
-				}
-			}
-
-			currentBuild = &ParsedBuild{
-				Rule:         rule,
-				Outputs:      outputs,
-				Inputs:       inputs,
-				ImplicitDeps: implicitDeps,
-				OrderDeps:    orderDeps,
-				Variables:    make(map[string]string),
-				Pool:         "default", // Default pool
-			}
 			continue
 		}
 
@@ -159,16 +130,14 @@ func (p *NinjaParser) ParseAndLoad(content string) error {
 				if currentRule.Command == "" {
 					return fmt.Errorf("rule %s is missing required command", currentRule.Name)
 				}
-				if _, err := p.store.AddRule(currentRule); err != nil {
-					return fmt.Errorf("failed to add rule %s: %w", currentRule.Name, err)
-				}
+				pipeline.addRule(currentRule)
 				currentRule = nil
 			}
 
 			// Save current build if we're switching contexts
 			if currentBuild != nil {
-				if err := p.saveBuild(currentBuild); err != nil {
-					return fmt.Errorf("failed to save build: %w", err)
+				if err := pipeline.addBuild(currentBuild); err != nil {
+					return err
 				}
 				currentBuild = nil
 			}
@@ -177,7 +146,6 @@ func (p *NinjaParser) ParseAndLoad(content string) error {
 		}
 
 		// Check if this is an indented line
-		originalLine := lines[i] // Get the original line to check indentation
 		if strings.HasPrefix(originalLine, "  ") || strings.HasPrefix(originalLine, "\t") {
 			// Parse rule properties (indented lines after rule declaration)
 			if currentRule != nil {
@@ -222,49 +190,132 @@ func (p *NinjaParser) ParseAndLoad(content string) error {
 		}
 	}
 
+	// Don't store a truncated trailing statement
+	if err := lines.Err(); err != nil {
+		return fmt.Errorf("failed to read ninja file: %w", err)
+	}
+
 	// Save any remaining rule or build
 	if currentRule != nil {
 		if currentRule.Command == "" {
 			return fmt.Errorf("rule %s is missing required command", currentRule.Name)
 		}
-		if _, err := p.store.AddRule(currentRule); err != nil {
-			return fmt.Errorf("failed to add final rule %s: %w", currentRule.Name, err)
-		}
+		pipeline.addRule(currentRule)
 	}
 
 	if currentBuild != nil {
-		if err := p.saveBuild(currentBuild); err != nil {
-			return fmt.Errorf("failed to save final build: %w", err)
+		if err := pipeline.addBuild(currentBuild); err != nil {
+			return err
 		}
 	}
 
-	return nil
+	return pipeline.close()
 }
 
-// saveBuild converts ParsedBuild to store.NinjaBuild and saves it
-func (p *NinjaParser) saveBuild(pb *ParsedBuild) error {
-	if len(pb.Outputs) == 0 {
-		return fmt.Errorf("build must have at least one output")
+// lineReader yields lines without their terminators, it has no line length limit unlike bufio.Scanner
+type lineReader struct {
+	reader *bufio.Reader
+	err    error
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{reader: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// next returns the next line, ok is false at end of input or on a read error
+func (lr *lineReader) next() (string, bool) {
+	if lr.err != nil {
+		return "", false
+	}
+
+	line, err := lr.reader.ReadString('\n')
+	if err != nil {
+		if err != io.EOF {
+			lr.err = err
+			return "", false
+		}
+		if line == "" {
+			lr.err = io.EOF
+			return "", false
+		}
+		// Final line without a terminator
+		lr.err = io.EOF
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), true
+}
+
+// Err returns the read error that ended the input, nil at end of input
+func (lr *lineReader) Err() error {
+	if lr.err == io.EOF {
+		return nil
+	}
+
+	return lr.err
+}
+
+// parseBuildLine parses the part of a build statement after "build ":
+// outputs | implicit_outputs: rule inputs | implicit_deps || order_deps. It returns nil if the outputs or
+// the rule are missing.
+func parseBuildLine(buildLine string) *ParsedBuild {
+	buildLine = strings.TrimSpace(buildLine)
+
+	// Split by colon to separate outputs and rest
+	colonParts := strings.SplitN(buildLine, ":", 2)
+	if len(colonParts) != 2 {
+		return nil
 	}
 
-	// Generate a unique build ID based on outputs
-	buildID := strings.Join(pb.Outputs, ",")
+	// Implicit outputs after | are outputs of the build like the explicit ones
+	outputs := parseFilePaths(strings.Replace(colonParts[0], "|", " ", 1))
+	rest := strings.TrimSpace(colonParts[1])
 
-	build := &store.NinjaBuild{
-		BuildID: buildID,
-		Rule:    quad.IRI(fmt.Sprintf("rule:%s", pb.Rule)),
-		Pool:    pb.Pool,
+	// Parse rule and dependencies
+	parts := strings.Fields(rest)
+	if len(parts) == 0 {
+		return nil
 	}
 
-	if err := build.SetVariables(pb.Variables); err != nil {
-		return fmt.Errorf("failed to set build variables: %w", err)
+	rule := parts[0]
+	var inputs, implicitDeps, orderDeps []string
+
+	// Join remaining parts and split by dependency separators
+	if len(parts) > 1 {
+		depString := strings.Join(parts[1:], " ")
+
+		// Split by || for order dependencies
+		orderParts := strings.Split(depString, "||")
+		if len(orderParts) > 1 {
+			orderDeps = parseFilePaths(strings.TrimSpace(orderParts[1]))
+			depString = strings.TrimSpace(orderParts[0])
+		}
+
+		// Split by | for implicit dependencies
+		implicitParts := strings.Split(depString, "|")
+		if len(implicitParts) > 1 {
+			implicitDeps = parseFilePaths(strings.TrimSpace(implicitParts[1]))
+			depString = strings.TrimSpace(implicitParts[0])
+		}
+
+		// Remaining are regular inputs
+		if depString != "" {
+			inputs = parseFilePaths(depString)
+		}
 	}
 
-	return p.store.AddBuild(build, pb.Inputs, pb.Outputs, pb.ImplicitDeps, pb.OrderDeps)
+	return &ParsedBuild{
+		Rule:         rule,
+		Outputs:      outputs,
+		Inputs:       inputs,
+		ImplicitDeps: implicitDeps,
+		OrderDeps:    orderDeps,
+		Variables:    make(map[string]string),
+		Pool:         "default", // Default pool
+	}
 }
 
 // parseFilePaths parses space-separated file paths, handling escaped spaces
-func (p *NinjaParser) parseFilePaths(input string) []string {
+func parseFilePaths(input string) []string {
 	if strings.TrimSpace(input) == "" {
 		return nil
 	}
@@ -0,0 +1,262 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/cayleygraph/quad"
+
+	"github.com/distninja/distninja/store"
+)
+
+// defaultLoadBatchSize is the number of builds written per store transaction by default
+const defaultLoadBatchSize = 256
+
+// LoadOptions tunes how ParseAndLoadReader writes to the store
+type LoadOptions struct {
+	// Workers is the number of goroutines writing batches, the CPU count when zero
+	Workers int
+	// BatchSize is the number of builds written per transaction, defaultLoadBatchSize when zero
+	BatchSize int
+}
+
+func (o LoadOptions) workers() int {
+	if o.Workers > 0 {
+		return o.Workers
+	}
+
+	return runtime.NumCPU()
+}
+
+func (o LoadOptions) batchSize() int {
+	if o.BatchSize > 0 {
+		return o.BatchSize
+	}
+
+	return defaultLoadBatchSize
+}
+
+// loadBatch holds the rules and builds parsed since the previous batch
+type loadBatch struct {
+	rules  []*store.NinjaRule
+	builds []*ParsedBuild
+}
+
+// loadPipeline passes the statements parsed from a ninja file to a pool of writers, which store them
+// in batches while parsing goes on. The quads of rules and builds only add to the graph, so batches
+// may be written in any order.
+type loadPipeline struct {
+	ctx       context.Context
+	store     *store.NinjaStore
+	batchSize int
+	current   *loadBatch
+	batches   chan *loadBatch
+	wg        sync.WaitGroup
+	closed    bool
+
+	// done is closed on the first write error, err holds it
+	done     chan struct{}
+	failOnce sync.Once
+	err      error
+}
+
+func newLoadPipeline(ctx context.Context, ninjaStore *store.NinjaStore, opts LoadOptions) *loadPipeline {
+	workers := opts.workers()
+
+	lp := &loadPipeline{
+		ctx:       ctx,
+		store:     ninjaStore,
+		batchSize: opts.batchSize(),
+		current:   &loadBatch{},
+		batches:   make(chan *loadBatch, workers),
+		done:      make(chan struct{}),
+	}
+
+	lp.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go lp.writer()
+	}
+
+	return lp
+}
+
+func (lp *loadPipeline) addRule(rule *store.NinjaRule) {
+	lp.current.rules = append(lp.current.rules, rule)
+}
+
+func (lp *loadPipeline) addBuild(pb *ParsedBuild) error {
+	lp.current.builds = append(lp.current.builds, pb)
+	if len(lp.current.builds) < lp.batchSize {
+		return nil
+	}
+
+	return lp.flush()
+}
+
+// flush hands the current batch to the writers, it returns the write error that stopped them if any
+func (lp *loadPipeline) flush() error {
+	if len(lp.current.rules) == 0 && len(lp.current.builds) == 0 {
+		return nil
+	}
+
+	if err := lp.ctx.Err(); err != nil {
+		return err
+	}
+
+	batch := lp.current
+	lp.current = &loadBatch{}
+
+	select {
+	case lp.batches <- batch:
+		return nil
+	case <-lp.done:
+		return lp.err
+	case <-lp.ctx.Done():
+		return lp.ctx.Err()
+	}
+}
+
+// close writes the remaining statements and waits for the writers
+func (lp *loadPipeline) close() error {
+	err := lp.flush()
+	lp.stop()
+
+	select {
+	case <-lp.done:
+		return lp.err
+	default:
+		return err
+	}
+}
+
+// stop waits for the writers without writing the current batch, so a failed parse stores no more
+func (lp *loadPipeline) stop() {
+	if lp.closed {
+		return
+	}
+	lp.closed = true
+
+	close(lp.batches)
+	lp.wg.Wait()
+}
+
+func (lp *loadPipeline) fail(err error) {
+	lp.failOnce.Do(func() {
+		lp.err = err
+		close(lp.done)
+	})
+}
+
+func (lp *loadPipeline) writer() {
+	defer lp.wg.Done()
+
+	for batch := range lp.batches {
+		select {
+		case <-lp.done:
+			// Drain the batches sent before the failure
+			continue
+		default:
+		}
+
+		if err := lp.write(batch); err != nil {
+			lp.fail(err)
+		}
+	}
+}
+
+func (lp *loadPipeline) write(batch *loadBatch) error {
+	if err := lp.ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, rule := range batch.rules {
+		variables, _ := rule.GetVariables()
+		if err := store.ValidateResources(variables); err != nil {
+			return fmt.Errorf("failed to add rule %s: %w", rule.Name, err)
+		}
+
+		if _, err := lp.store.AddRule(lp.ctx, rule); err != nil {
+			return fmt.Errorf("failed to add rule %s: %w", rule.Name, err)
+		}
+	}
+
+	if len(batch.builds) == 0 {
+		return nil
+	}
+
+	specs := make([]*store.BuildSpec, 0, len(batch.builds))
+	for _, pb := range batch.builds {
+		spec, err := buildSpec(pb)
+		if err != nil {
+			return fmt.Errorf("failed to save build: %w", err)
+		}
+		specs = append(specs, spec)
+	}
+
+	results, err := lp.store.AddBuilds(lp.ctx, specs)
+	if err != nil {
+		return fmt.Errorf("failed to save builds: %w", err)
+	}
+
+	for i, err := range results {
+		if err != nil {
+			return fmt.Errorf("failed to save build %s: %w", specs[i].Build.BuildID, err)
+		}
+	}
+
+	return nil
+}
+
+// buildSpec converts ParsedBuild to the store.BuildSpec saving it
+func buildSpec(pb *ParsedBuild) (*store.BuildSpec, error) {
+	if len(pb.Outputs) == 0 {
+		return nil, fmt.Errorf("build must have at least one output")
+	}
+
+	// Generate a unique build ID based on outputs
+	buildID := strings.Join(pb.Outputs, ",")
+
+	build := &store.NinjaBuild{
+		BuildID: buildID,
+		Rule:    quad.IRI(fmt.Sprintf("rule:%s", pb.Rule)),
+		Pool:    pb.Pool,
+	}
+
+	// The owner and labels variables annotate the build and its outputs instead of being build variables
+	variables := make(map[string]string, len(pb.Variables))
+	for key, value := range pb.Variables {
+		switch key {
+		case store.OwnerVariable:
+			build.Owner = value
+		case store.LabelsVariable:
+			labels, err := store.ParseLabels(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse labels of build %s: %w", buildID, err)
+			}
+			if err := build.SetLabels(labels); err != nil {
+				return nil, fmt.Errorf("failed to set build labels: %w", err)
+			}
+		default:
+			variables[key] = value
+		}
+	}
+
+	if err := store.ValidateResources(variables); err != nil {
+		return nil, fmt.Errorf("build %s: %w", buildID, err)
+	}
+
+	if err := build.SetVariables(variables); err != nil {
+		return nil, fmt.Errorf("failed to set build variables: %w", err)
+	}
+
+	return &store.BuildSpec{
+		Build:        build,
+		Inputs:       pb.Inputs,
+		Outputs:      pb.Outputs,
+		ImplicitDeps: pb.ImplicitDeps,
+		OrderDeps:    pb.OrderDeps,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ruleScope tracks the rules of a ninja file and of the files it pulls in with subninja. Ninja gives
+// each subninja a scope of its own, so a rule there may reuse the name of a rule of another scope, as GN
+// does for the rules of every toolchain. The graph has a single namespace of rules, such rules are
+// renamed after the file defining them, e.g. cc of clang_x64/toolchain.ninja becomes clang_x64_cc.
+type ruleScope struct {
+	parent  *ruleScope
+	prefix  string
+	renames map[string]string
+	// owners maps each rule name in use to its scope, it is shared by all scopes of a file tree
+	owners map[string]*ruleScope
+}
+
+func newRuleScope(path string) *ruleScope {
+	return &ruleScope{
+		prefix:  scopePrefix(path),
+		renames: make(map[string]string),
+		owners:  make(map[string]*ruleScope),
+	}
+}
+
+// subninja returns the scope of the file at path, named by a subninja statement in s
+func (s *ruleScope) subninja(path string) *ruleScope {
+	return &ruleScope{
+		parent:  s,
+		prefix:  scopePrefix(path),
+		renames: make(map[string]string),
+		owners:  s.owners,
+	}
+}
+
+// define returns the name under which rule name defined in s is stored, ok is false if s defines it
+// already
+func (s *ruleScope) define(name string) (string, bool) {
+	if renamed, ok := s.renames[name]; ok {
+		return renamed, false
+	}
+
+	owner, ok := s.owners[name]
+	if !ok {
+		s.owners[name] = s
+		return name, true
+	}
+	if owner == s {
+		return name, false
+	}
+
+	renamed := s.prefix + "_" + name
+	for i := 2; s.owners[renamed] != nil; i++ {
+		renamed = fmt.Sprintf("%s_%s_%d", s.prefix, name, i)
+	}
+
+	s.owners[renamed] = s
+	s.renames[name] = renamed
+
+	return renamed, true
+}
+
+// resolve returns the stored name of the rule name used by a build in s
+func (s *ruleScope) resolve(name string) string {
+	for scope := s; scope != nil; scope = scope.parent {
+		if renamed, ok := scope.renames[name]; ok {
+			return renamed
+		}
+		if s.owners[name] == scope {
+			return name
+		}
+	}
+
+	return name
+}
+
+// scopePrefix names the scope of the file at path after its directory, or after the file itself at the
+// top level, keeping the characters allowed in rule names
+func scopePrefix(path string) string {
+	name := filepath.Dir(path)
+	if name == "." || filepath.IsAbs(path) {
+		name = strings.TrimSuffix(filepath.Base(path), ".ninja")
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,372 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/distninja/distninja/store"
+)
+
+// Severities of validation diagnostics
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// phonyRule is built into ninja and needs no definition
+const phonyRule = "phony"
+
+// Diagnostic is a problem found by Validate at a line of a ninja file
+type Diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// String formats the diagnostic as file:line: severity: message like compilers do
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Severity, d.Message)
+}
+
+// position is the location of a statement, seq orders statements across included files
+type position struct {
+	file string
+	line int
+	seq  int
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%s:%d", p.file, p.line)
+}
+
+type validatedRule struct {
+	name      string
+	pos       position
+	command   string
+	variables map[string]string
+}
+
+type validatedBuild struct {
+	pos   position
+	build *ParsedBuild
+}
+
+// validator collects the statements of a ninja file tree, the checks run once all files are read
+type validator struct {
+	dir         string
+	seq         int
+	rules       map[string]*validatedRule
+	builds      []*validatedBuild
+	globals     map[string]string
+	defaults    []string
+	diagnostics []Diagnostic
+}
+
+func newValidator(dir string) *validator {
+	return &validator{
+		dir:     dir,
+		rules:   make(map[string]*validatedRule),
+		globals: make(map[string]string),
+	}
+}
+
+// read collects the statements of the file at path, relative to the directory of the validator
+func (v *validator) read(path string) error {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(v.dir, path)
+	}
+
+	return v.readFile(path, nil, newRuleScope(path))
+}
+
+// Validate checks the ninja file at path and the files it includes without loading them into a store.
+// It reports unknown rules, rules without a command, duplicate outputs, dependency cycles and, as
+// warnings, variables used by rule commands that no scope defines. Relative paths are resolved against
+// dir as ninja -C does. The error is only set if the files could not be read.
+func Validate(dir, path string) ([]Diagnostic, error) {
+	v := newValidator(dir)
+	if err := v.read(path); err != nil {
+		return nil, err
+	}
+
+	v.checkBuilds()
+	v.checkCycles()
+
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		a, b := v.diagnostics[i], v.diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	return v.diagnostics, nil
+}
+
+func (v *validator) report(pos position, severity, format string, args ...interface{}) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		File:     pos.file,
+		Line:     pos.line,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// include reads the file named by an include or subninja statement at pos with the rules scoped by
+// scope, files that can't be included are reported instead of ending the validation
+func (v *validator) include(pos position, path string, stack []string, scope *ruleScope) error {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(v.dir, path)
+	}
+
+	for _, open := range stack {
+		if open == path {
+			v.report(pos, SeverityError, "include cycle: %s", strings.Join(append(stack, path), " -> "))
+			return nil
+		}
+	}
+
+	if len(stack) >= maxIncludeDepth {
+		v.report(pos, SeverityError, "includes nested deeper than %d levels", maxIncludeDepth)
+		return nil
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		v.report(pos, SeverityError, "failed to include %s: %v", path, err)
+		return nil
+	}
+
+	return v.readFile(path, stack, scope)
+}
+
+// readFile collects the statements of the file at path and the files it includes, stack holds the
+// files being read and scope the rules of the file
+func (v *validator) readFile(path string, stack []string, scope *ruleScope) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	stack = append(stack, path)
+	lines := newLineReader(file)
+	lineNumber := 0
+
+	// Exactly one of them is set while indented variables belong to a statement
+	var currentRule *validatedRule
+	var currentBuild *validatedBuild
+	inPool := false
+
+	for {
+		originalLine, ok := lines.next()
+		if !ok {
+			break
+		}
+		lineNumber++
+
+		pos := position{file: path, line: lineNumber}
+		line := strings.TrimSpace(originalLine)
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		for strings.HasSuffix(line, "$") {
+			next, ok := lines.next()
+			if !ok {
+				break
+			}
+			lineNumber++
+			line = line[:len(line)-1] + " " + strings.TrimSpace(next)
+		}
+
+		if originalLine[0] == ' ' || originalLine[0] == '\t' {
+			key, value, found := strings.Cut(line, "=")
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+			switch {
+			case !found:
+				v.report(pos, SeverityError, "expected variable assignment, got %q", line)
+			case currentRule != nil:
+				if key == "command" {
+					currentRule.command = value
+				}
+				currentRule.variables[key] = value
+			case currentBuild != nil:
+				if key == "pool" {
+					currentBuild.build.Pool = value
+				} else {
+					currentBuild.build.Variables[key] = value
+				}
+			case !inPool:
+				v.report(pos, SeverityError, "indented variable %s does not belong to a rule, build or pool", key)
+			}
+			continue
+		}
+
+		v.finishRule(currentRule)
+		currentRule, currentBuild, inPool = nil, nil, false
+
+		v.seq++
+		pos.seq = v.seq
+
+		keyword, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+
+		switch keyword {
+		case "rule":
+			name, ok := scope.define(rest)
+			if existing := v.rules[name]; !ok && existing != nil {
+				v.report(pos, SeverityError, "duplicate rule %s, first defined at %s", rest, existing.pos)
+				continue
+			}
+			currentRule = &validatedRule{name: name, pos: pos, variables: make(map[string]string)}
+			v.rules[name] = currentRule
+		case "build":
+			build := parseBuildLine(rest)
+			if build == nil || len(build.Outputs) == 0 {
+				v.report(pos, SeverityError, "build statement needs outputs, a colon and a rule")
+				continue
+			}
+			build.Rule = scope.resolve(build.Rule)
+			currentBuild = &validatedBuild{pos: pos, build: build}
+			v.builds = append(v.builds, currentBuild)
+		case "include":
+			if err := v.include(pos, rest, stack, scope); err != nil {
+				return err
+			}
+		case "subninja":
+			// Only rules are scoped, as when loading
+			if err := v.include(pos, rest, stack, scope.subninja(rest)); err != nil {
+				return err
+			}
+		case "pool":
+			inPool = true
+		case "default":
+			v.defaults = append(v.defaults, parseFilePaths(rest)...)
+		default:
+			key, value, found := strings.Cut(line, "=")
+			if !found {
+				v.report(pos, SeverityError, "unknown statement %q", keyword)
+				continue
+			}
+			v.globals[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	v.finishRule(currentRule)
+
+	if err := lines.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (v *validator) finishRule(rule *validatedRule) {
+	if rule != nil && rule.command == "" {
+		v.report(rule.pos, SeverityError, "rule %s is missing required command", rule.name)
+	}
+}
+
+// checkBuilds reports unknown rules, duplicate outputs and undefined variables of each build
+func (v *validator) checkBuilds() {
+	outputs := make(map[string]position)
+
+	for _, b := range v.builds {
+		for _, output := range b.build.Outputs {
+			if first, ok := outputs[output]; ok {
+				v.report(b.pos, SeverityError, "output %s is already built at %s", output, first)
+				continue
+			}
+			outputs[output] = b.pos
+		}
+
+		if b.build.Rule == phonyRule {
+			continue
+		}
+
+		rule, ok := v.rules[b.build.Rule]
+		switch {
+		case !ok:
+			v.report(b.pos, SeverityError, "unknown rule %s", b.build.Rule)
+			continue
+		case rule.pos.seq > b.pos.seq:
+			v.report(b.pos, SeverityError, "rule %s is used before its definition at %s", b.build.Rule, rule.pos)
+		}
+
+		for _, name := range store.UndefinedVariables(rule.command, rule.variables, b.build.Variables, v.globals) {
+			v.report(b.pos, SeverityWarning, "command of rule %s uses undefined variable $%s", b.build.Rule, name)
+		}
+	}
+}
+
+// checkCycles reports each dependency cycle between build outputs once
+func (v *validator) checkCycles() {
+	producers := make(map[string]*validatedBuild)
+	var outputs []string
+
+	for _, b := range v.builds {
+		for _, output := range b.build.Outputs {
+			if _, ok := producers[output]; !ok {
+				producers[output] = b
+				outputs = append(outputs, output)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[*validatedBuild]int)
+	var path []string
+
+	var visit func(output string)
+	visit = func(output string) {
+		b := producers[output]
+
+		switch state[b] {
+		case visited:
+			return
+		case visiting:
+			start := 0
+			for i, p := range path {
+				if producers[p] == b {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), output)
+			v.report(b.pos, SeverityError, "dependency cycle: %s", strings.Join(cycle, " -> "))
+			return
+		}
+
+		state[b] = visiting
+		path = append(path, output)
+
+		for _, deps := range [][]string{b.build.Inputs, b.build.ImplicitDeps, b.build.OrderDeps} {
+			for _, dep := range deps {
+				if _, ok := producers[dep]; ok {
+					visit(dep)
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[b] = visited
+	}
+
+	for _, output := range outputs {
+		visit(output)
+	}
+}
@@ -319,6 +319,26 @@ build app: link main.o'
     fi
 }
 
+# Function to test streaming ninja file upload in chunks
+test_load_ninja_file_stream() {
+    print_info "Testing LoadNinjaFileStream endpoint..."
+
+    local chunk1=$(printf 'rule stream\n  command = echo stream\n  description = STREAM\n' | base64 -w0)
+    local chunk2=$(printf 'build stream.out: stream stream.in\n' | base64 -w0)
+
+    local response=$(printf '{"file_name": "stream.ninja", "content": "%s"}\n{"content": "%s"}\n' "$chunk1" "$chunk2" \
+        | grpcurl -plaintext -d @ "$GRPC_SERVER_ADDR" distninja.DistNinjaService/LoadNinjaFileStream 2>&1)
+
+    if echo "$response" | grep -q '"success"'; then
+        print_success "LoadNinjaFileStream test passed"
+        echo "Response: $response"
+    else
+        print_error "LoadNinjaFileStream test failed"
+        echo "Response: $response"
+        return 1
+    fi
+}
+
 # Function to debug JSON formatting
 test_json_formatting() {
     print_info "Testing JSON formatting for LoadNinjaFile..."
@@ -405,6 +425,74 @@ test_create_rule() {
     fi
 }
 
+# Function to test update and delete rule
+test_update_delete_rule() {
+    print_info "Testing UpdateRule and DeleteRule endpoints..."
+
+    local response=$(grpcurl -plaintext -d '{
+        "name": "test_rule",
+        "command": "echo Rebuilding $out",
+        "description": "Updated test rule"
+    }' "$GRPC_SERVER_ADDR" distninja.DistNinjaService/UpdateRule 2>/dev/null)
+
+    if ! echo "$response" | grep -q '"status": "updated"'; then
+        print_error "UpdateRule test failed"
+        echo "Response: $response"
+        return 1
+    fi
+
+    response=$(grpcurl -plaintext -d '{"name": "test_rule", "force": true}' \
+        "$GRPC_SERVER_ADDR" distninja.DistNinjaService/DeleteRule 2>/dev/null)
+
+    if echo "$response" | grep -q '"status": "deleted"'; then
+        print_success "UpdateRule and DeleteRule test passed"
+        echo "Response: $response"
+    else
+        print_error "DeleteRule test failed"
+        echo "Response: $response"
+        return 1
+    fi
+}
+
+# Function to test create and update rule without a description
+test_rule_without_description() {
+    print_info "Testing CreateRule and UpdateRule without a description..."
+
+    local response=$(grpcurl -plaintext -d '{
+        "name": "bare_rule",
+        "command": "echo Building $out"
+    }' "$GRPC_SERVER_ADDR" distninja.DistNinjaService/CreateRule 2>/dev/null)
+
+    if ! echo "$response" | grep -q '"status": "created"'; then
+        print_error "CreateRule without description test failed"
+        echo "Response: $response"
+        return 1
+    fi
+
+    response=$(grpcurl -plaintext -d '{
+        "name": "bare_rule",
+        "command": "echo Rebuilding $out"
+    }' "$GRPC_SERVER_ADDR" distninja.DistNinjaService/UpdateRule 2>/dev/null)
+
+    if ! echo "$response" | grep -q '"status": "updated"'; then
+        print_error "UpdateRule without description test failed"
+        echo "Response: $response"
+        return 1
+    fi
+
+    response=$(grpcurl -plaintext -d '{"name": "bare_rule"}' \
+        "$GRPC_SERVER_ADDR" distninja.DistNinjaService/DeleteRule 2>/dev/null)
+
+    if echo "$response" | grep -q '"status": "deleted"'; then
+        print_success "Rule without description test passed"
+        echo "Response: $response"
+    else
+        print_error "DeleteRule without description test failed"
+        echo "Response: $response"
+        return 1
+    fi
+}
+
 # Function to test get rule
 test_get_rule() {
     print_info "Testing GetRule endpoint..."
@@ -422,6 +510,25 @@ test_get_rule() {
     fi
 }
 
+# Function to test bulk target status updates
+test_bulk_update_target_status() {
+    print_info "Testing BulkUpdateTargetStatus endpoint..."
+
+    local response=$(grpcurl -plaintext -d '{"updates": [
+        {"path": "stream.out", "status": "done"},
+        {"path": "missing.out", "status": "done"}
+    ]}' "$GRPC_SERVER_ADDR" distninja.DistNinjaService/BulkUpdateTargetStatus 2>/dev/null)
+
+    if echo "$response" | grep -q '"updated": 1'; then
+        print_success "BulkUpdateTargetStatus test passed"
+        echo "Response: $response"
+    else
+        print_error "BulkUpdateTargetStatus test failed"
+        echo "Response: $response"
+        return 1
+    fi
+}
+
 # Function to test get target
 test_get_target() {
     print_info "Testing GetTarget endpoint..."
@@ -883,7 +990,7 @@ test_debug_quads() {
     local response=$(grpcurl -plaintext -d '{"limit": 10}' \
         "$GRPC_SERVER_ADDR" distninja.DistNinjaService/DebugQuads 2>/dev/null)
 
-    if echo "$response" | grep -q '"message"'; then
+    if echo "$response" | grep -q '"quads"'; then
         print_success "DebugQuads test passed"
         echo "Response: $response"
     else
@@ -893,6 +1000,86 @@ test_debug_quads() {
     fi
 }
 
+# Function to test streaming targets
+test_stream_targets() {
+    print_info "Testing StreamTargets endpoint..."
+
+    local response=$(grpcurl -plaintext -d '{}' \
+        "$GRPC_SERVER_ADDR" distninja.DistNinjaService/StreamTargets 2>&1)
+
+    if [ $? -eq 0 ]; then
+        print_success "StreamTargets test passed"
+        local target_count=$(echo "$response" | grep -o '"path"' | wc -l)
+        print_info "Streamed $target_count targets"
+    else
+        print_error "StreamTargets test failed"
+        echo "Response: $response"
+        return 1
+    fi
+}
+
+# Function to test streaming quads
+test_stream_quads() {
+    print_info "Testing StreamQuads endpoint..."
+
+    local response=$(grpcurl -plaintext -d '{}' \
+        "$GRPC_SERVER_ADDR" distninja.DistNinjaService/StreamQuads 2>&1)
+
+    if echo "$response" | grep -q '"predicate"'; then
+        print_success "StreamQuads test passed"
+        local quad_count=$(echo "$response" | grep -o '"predicate"' | wc -l)
+        print_info "Streamed $quad_count quads"
+    else
+        print_error "StreamQuads test failed"
+        echo "Response: $response"
+        return 1
+    fi
+}
+
+# Function to test watching target status changes
+test_watch_targets() {
+    print_info "Testing WatchTargets endpoint..."
+
+    local output=$(mktemp)
+    grpcurl -plaintext -max-time 3 -d '{"paths": ["stream.out"]}' \
+        "$GRPC_SERVER_ADDR" distninja.DistNinjaService/WatchTargets > "$output" 2>&1 &
+    local watch_pid=$!
+
+    sleep 1
+    grpcurl -plaintext -d '{"path": "stream.out", "status": "building"}' \
+        "$GRPC_SERVER_ADDR" distninja.DistNinjaService/UpdateTargetStatus > /dev/null 2>&1
+    wait $watch_pid
+
+    local response=$(cat "$output")
+    rm -f "$output"
+
+    if echo "$response" | grep -q '"building"'; then
+        print_success "WatchTargets test passed"
+        echo "Response: $response"
+    else
+        print_error "WatchTargets test failed"
+        echo "Response: $response"
+        return 1
+    fi
+}
+
+# Function to test gizmo queries
+test_gizmo_query() {
+    print_info "Testing GizmoQuery endpoint..."
+
+    local response=$(grpcurl -plaintext -d '{"query": "g.V().Has(\"<rdf:type>\", \"<NinjaRule>\").All()", "limit": 10}' \
+        "$GRPC_SERVER_ADDR" distninja.DistNinjaService/GizmoQuery 2>/dev/null)
+
+    if echo "$response" | grep -q '"results"'; then
+        print_success "GizmoQuery test passed"
+        echo "Response: $response"
+    else
+        print_error "GizmoQuery test failed"
+        echo "Response: $response"
+        return 1
+    fi
+}
+
 # Function to test invalid requests
 test_error_handling() {
     print_info "Testing error handling..."
@@ -1033,6 +1220,7 @@ run_tests() {
     test_json_formatting || ((failed_tests++))
     test_load_ninja_content || ((failed_tests++))
     test_load_ninja_content_variations || ((failed_tests++))
+    test_load_ninja_file_stream || ((failed_tests++))
 
     # Data retrieval tests
     test_build_stats || ((failed_tests++))
@@ -1041,6 +1229,8 @@ run_tests() {
     # CRUD operation tests (order matters - create rules before builds)
     test_create_rule || ((failed_tests++))
     test_get_rule || ((failed_tests++))
+    test_update_delete_rule || ((failed_tests++))
+    test_rule_without_description || ((failed_tests++))
 
     # Test pool requirements before build creation
     test_pool_requirements || ((failed_tests++))
@@ -1050,10 +1240,15 @@ run_tests() {
     test_get_build || ((failed_tests++))
     test_build_order || ((failed_tests++))
     test_get_target || ((failed_tests++))
+    test_bulk_update_target_status || ((failed_tests++))
 
     # Analysis tests
     test_find_cycles || ((failed_tests++))
     test_debug_quads || ((failed_tests++))
+    test_stream_targets || ((failed_tests++))
+    test_stream_quads || ((failed_tests++))
+    test_watch_targets || ((failed_tests++))
+    test_gizmo_query || ((failed_tests++))
 
     # Error handling and performance tests
     test_error_handling || ((failed_tests++))
@@ -67,6 +67,8 @@ test_endpoint "GET" "$BASE_URL/health" "" "200" "Health check"
 
 # Status check
 test_endpoint "GET" "$API_BASE/status" "" "200" "Service status"
+test_endpoint "GET" "$API_BASE/openapi.json" "" "200" "OpenAPI document"
+test_endpoint "GET" "$API_BASE/docs" "" "200" "Swagger UI"
 
 # Create a rule first (needed for builds)
 rule_data='{
@@ -79,6 +81,20 @@ rule_data='{
 }'
 test_endpoint "POST" "$API_BASE/rules" "$rule_data" "201" "Create rule"
 
+# Create rules in batch
+rules_batch='[
+    {"name": "batch_cc", "command": "gcc -c $in -o $out", "description": "Batch compile"},
+    {"name": "", "command": "true", "description": "Missing name"}
+]'
+test_endpoint "POST" "$API_BASE/rules:batch" "$rules_batch" "200" "Create rules in batch"
+
+# Create builds in batch
+builds_batch='[
+    {"build_id": "batch_001", "rule": "batch_cc", "pool": "default", "inputs": ["batch_a.c"], "outputs": ["batch_a.o"]},
+    {"build_id": "batch_002", "rule": "batch_cc", "pool": "default", "inputs": ["batch_b.c"], "outputs": ["batch_b.o"]}
+]'
+test_endpoint "POST" "$API_BASE/builds:batch" "$builds_batch" "200" "Create builds in batch"
+
 # Get the created rule
 test_endpoint "GET" "$API_BASE/rules/test_compile" "" "200" "Get rule"
 
@@ -115,10 +131,27 @@ test_endpoint "GET" "$API_BASE/builds/stats" "" "200" "Get build statistics"
 
 # Get build order
 test_endpoint "GET" "$API_BASE/builds/order" "" "200" "Get build order"
+test_endpoint "GET" "$API_BASE/builds/order?policy=critical_path" "" "200" "Get critical path build order"
+test_endpoint "GET" "$API_BASE/builds/order?policy=unknown" "" "400" "Get build order with unknown policy"
 
 # Get targets by rule
 test_endpoint "GET" "$API_BASE/rules/test_compile/targets" "" "200" "Get targets by rule"
 
+# Update the rule
+rule_update='{
+    "command": "gcc $cflags -o $out $in",
+    "description": "Compile C file with flags"
+}'
+test_endpoint "PUT" "$API_BASE/rules/test_compile" "$rule_update" "200" "Update rule"
+
+# Delete a rule still referenced by builds
+test_endpoint "DELETE" "$API_BASE/rules/test_compile" "" "409" "Delete rule in use"
+
+# Create and delete an unused rule
+unused_rule='{"name": "unused_rule", "command": "true"}'
+test_endpoint "POST" "$API_BASE/rules" "$unused_rule" "201" "Create unused rule"
+test_endpoint "DELETE" "$API_BASE/rules/unused_rule" "" "200" "Delete unused rule"
+
 # Get all targets
 test_endpoint "GET" "$API_BASE/targets" "" "200" "Get all targets"
 
@@ -137,9 +170,69 @@ test_endpoint "PUT" "$API_BASE/targets/main.o/status" "$status_data" "200" "Upda
 
 # Find cycles
 test_endpoint "GET" "$API_BASE/analysis/cycles" "" "200" "Find dependency cycles"
+test_endpoint "GET" "$API_BASE/analysis/impact?files=main.c,utils.h" "" "200" "Impact of changed files"
+test_endpoint "GET" "$API_BASE/analysis/impact" "" "400" "Impact without files"
+
+# Graph export
+test_endpoint "GET" "$API_BASE/graph" "" "200" "Export graph as DOT"
+test_endpoint "GET" "$API_BASE/graph?format=cyjs&root=main.o&depth=1" "" "200" "Export target subgraph as Cytoscape JSON"
+test_endpoint "GET" "$API_BASE/graph?format=graphml" "" "200" "Export graph as GraphML"
+test_endpoint "GET" "$API_BASE/graph?format=png" "" "400" "Export graph in unknown format"
+
+# GraphQL
+graphql_query='{"query": "{ targets { path status build { id rule { name } inputs { path } } } }"}'
+test_endpoint "POST" "$API_BASE/graphql" "$graphql_query" "200" "GraphQL targets query"
+test_endpoint "POST" "$API_BASE/graphql" '{}' "400" "GraphQL missing query"
 
 # Debug quads (limited)
 test_endpoint "GET" "$API_BASE/debug/quads?limit=10" "" "200" "Debug quads (limited)"
+test_endpoint "GET" "$API_BASE/debug/quads?limit=5&offset=5" "" "200" "Debug quads (paged)"
+test_endpoint "GET" "$API_BASE/debug/quads?subject=rule:batch_cc" "" "200" "Debug quads (subject)"
+test_endpoint "GET" "$API_BASE/debug/quads?offset=-1" "" "400" "Debug quads (invalid offset)"
+
+# Gizmo query
+gizmo_query='{"query": "g.V().Has(\"<rdf:type>\", \"<NinjaRule>\").All()", "limit": 10}'
+test_endpoint "POST" "$API_BASE/query/gizmo" "$gizmo_query" "200" "Gizmo query"
+test_endpoint "POST" "$API_BASE/query/gizmo" '{"query": "g.V(("}' "400" "Gizmo query syntax error"
+
+# Idempotency keys
+idempotent_rule='{"name": "idempotent_cc", "command": "gcc -c $in -o $out", "description": "Idempotent compile"}'
+print_test "Retry rule creation with the same Idempotency-Key"
+first=$(curl -s -X POST -H "Content-Type: application/json" -H "Idempotency-Key: http-test-rule" -d "$idempotent_rule" "$API_BASE/rules")
+replayed=$(curl -s -D - -o /dev/null -X POST -H "Content-Type: application/json" -H "Idempotency-Key: http-test-rule" -d "$idempotent_rule" "$API_BASE/rules" | grep -i "Idempotent-Replayed: true")
+if [ -n "$replayed" ]; then
+    print_success "Second request replayed the original response"
+else
+    print_error "Second request was not replayed"
+fi
+echo "Response: $first"
+echo "---"
+
+print_test "Reuse the Idempotency-Key with a different body"
+changed_rule='{"name": "idempotent_ld", "command": "gcc $in -o $out", "description": "Idempotent link"}'
+status=$(curl -s -o /dev/null -w "%{http_code}" -X POST -H "Content-Type: application/json" -H "Idempotency-Key: http-test-rule" -d "$changed_rule" "$API_BASE/rules")
+if [ "$status" = "422" ]; then
+    print_success "Status: $status"
+else
+    print_error "Expected status 422, got $status"
+fi
+echo "---"
+
+# Audit log
+test_endpoint "GET" "$API_BASE/audit?since=2000-01-01T00:00:00Z&limit=10" "" "200" "List audit entries"
+test_endpoint "GET" "$API_BASE/audit?since=yesterday" "" "400" "List audit entries with invalid since"
+
+# Webhooks
+webhook_data='{"url": "http://127.0.0.1:9/hook", "secret": "test-secret", "events": ["target.failed", "load.completed"]}'
+test_endpoint "POST" "$API_BASE/webhooks" "$webhook_data" "201" "Register webhook"
+test_endpoint "POST" "$API_BASE/webhooks" '{"url": "ftp://example.com"}' "400" "Register webhook with invalid url"
+test_endpoint "POST" "$API_BASE/webhooks" '{"url": "http://127.0.0.1:9/hook", "events": ["unknown"]}' "400" "Register webhook with unknown event"
+test_endpoint "GET" "$API_BASE/webhooks" "" "200" "List webhooks"
+webhook_id=$(curl -s "$API_BASE/webhooks" | jq -r '.[0].id')
+test_endpoint "GET" "$API_BASE/webhooks/$webhook_id" "" "200" "Get webhook"
+test_endpoint "GET" "$API_BASE/webhooks/$webhook_id/deliveries" "" "200" "Get webhook deliveries"
+test_endpoint "DELETE" "$API_BASE/webhooks/$webhook_id" "" "200" "Delete webhook"
+test_endpoint "GET" "$API_BASE/webhooks/$webhook_id" "" "404" "Get deleted webhook"
 
 # Test Ninja file loading endpoint
 echo -e "${YELLOW}Testing Ninja file loading...${NC}"
@@ -278,6 +371,26 @@ EOF
 large_ninja_path="{\"file_path\": \"$temp_ninja_large\"}"
 test_endpoint "POST" "$API_BASE/load" "$large_ninja_path" "200" "Load large realistic Ninja file (CMake-style)"
 
+# Streaming uploads, test_endpoint always sends JSON
+print_test "Load Ninja file as multipart upload"
+upload_status=$(curl -s -o /dev/null -w "%{http_code}" -F "file=@$temp_ninja_large" "$API_BASE/load")
+if [ "$upload_status" = "200" ]; then
+    print_success "Status: $upload_status"
+else
+    print_error "Expected status 200, got $upload_status"
+fi
+echo "---"
+
+print_test "Load Ninja file as chunked text/plain body"
+upload_status=$(curl -s -o /dev/null -w "%{http_code}" -H "Content-Type: text/plain" -H "Transfer-Encoding: chunked" \
+    --data-binary "@$temp_ninja_large" "$API_BASE/load")
+if [ "$upload_status" = "200" ]; then
+    print_success "Status: $upload_status"
+else
+    print_error "Expected status 200, got $upload_status"
+fi
+echo "---"
+
 # Test error cases for load endpoint
 echo -e "${YELLOW}Testing load endpoint error cases...${NC}"
 
@@ -346,6 +459,11 @@ test_endpoint "POST" "$API_BASE/load" "$incremental_ninja_path" "200" "Load addi
 # Final verification after all loads
 test_endpoint "GET" "$API_BASE/builds/stats" "" "200" "Final build stats after all loads"
 
+# Compact the store file, reads and writes go on afterwards
+test_endpoint "POST" "$API_BASE/admin/compact" "" "200" "Compact the store"
+test_endpoint "GET" "$API_BASE/builds/stats" "" "200" "Build stats after compacting"
+test_endpoint "POST" "$API_BASE/rules" '{"name": "after_compact", "command": "true"}' "201" "Create rule after compacting"
+
 # Clean up temporary files
 rm -f "$temp_ninja_simple" "$temp_ninja_complex" "$temp_ninja_file" "$temp_ninja_large" "$temp_ninja_perf" "$temp_ninja_continuation" "$temp_ninja_incremental"
 
@@ -361,6 +479,12 @@ test_endpoint "GET" "$API_BASE/builds/nonexistent" "" "404" "Get non-existent bu
 # Non-existent rule
 test_endpoint "GET" "$API_BASE/rules/nonexistent" "" "404" "Get non-existent rule"
 
+# Update non-existent rule
+test_endpoint "PUT" "$API_BASE/rules/nonexistent" '{"command": "true"}' "404" "Update non-existent rule"
+
+# Delete non-existent rule
+test_endpoint "DELETE" "$API_BASE/rules/nonexistent" "" "404" "Delete non-existent rule"
+
 # Non-existent target
 test_endpoint "GET" "$API_BASE/targets/nonexistent.o" "" "404" "Get non-existent target"
 
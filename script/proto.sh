@@ -10,3 +10,4 @@
 
 # Build proto
 protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative server/proto/grpc.proto
+protoc --go_out=. --go_opt=paths=source_relative parser/analysis/analysis_v2.proto
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/distninja/distninja/server/proto"
+	"github.com/distninja/distninja/store"
+	"github.com/distninja/distninja/utils"
+)
+
+const (
+	auditAnonymous    = "anonymous"
+	auditResultOK     = "success"
+	auditResultError  = "error"
+	auditMaxBodyBytes = 4096
+
+	auditDefaultLimit = 100
+	auditMaxLimit     = 1000
+)
+
+// auditRedactedFields are request fields never written to the audit log
+var auditRedactedFields = []string{"secret", "content"}
+
+// auditSkippedMediaTypes are the upload formats of /api/v1/load
+var auditSkippedMediaTypes = map[string]bool{
+	"multipart/form-data":      true,
+	"text/plain":               true,
+	"application/octet-stream": true,
+}
+
+// auditReadOnlyRoutes accept POST but don't change the graph
+var auditReadOnlyRoutes = map[string]bool{
+	"/api/v1/graphql":     true,
+	"/api/v1/query/gizmo": true,
+}
+
+// grpcMutations are the DistNinjaService methods recorded in the audit log
+var grpcMutations = map[string]bool{
+	"CreateBuild":            true,
+	"CreateRule":             true,
+	"UpdateRule":             true,
+	"DeleteRule":             true,
+	"DeleteBuild":            true,
+	"LoadNinjaFile":          true,
+	"LoadNinjaFileStream":    true,
+	"UpdateTargetStatus":     true,
+	"BulkUpdateTargetStatus": true,
+	"DeleteTarget":           true,
+	"SetTargetLabels":        true,
+	"SetBuildLabels":         true,
+}
+
+// recordAudit stores an audit entry, failures are logged but never fail the audited request
+func recordAudit(ctx context.Context, ninjaStore *store.NinjaStore, entry *store.NinjaAuditEntry) {
+	entry.Subject = auditAnonymous
+	if identity, ok := IdentityFromContext(ctx); ok && identity.Subject != "" {
+		entry.Subject = identity.Subject
+	}
+
+	if id, ok := utils.RequestIDFromContext(ctx); ok {
+		entry.RequestID = id
+	}
+
+	if err := ninjaStore.AddAuditEntry(ctx, entry); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit entry", "action", entry.Action, "resource", entry.Resource, "error", err)
+	}
+}
+
+// auditDetails encodes details as JSON
+func auditDetails(details map[string]interface{}) string {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return "{}"
+	}
+
+	return string(data)
+}
+
+// cappedBuffer keeps the first bytes written to it and remembers whether more were written
+type cappedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (cb *cappedBuffer) Write(p []byte) (int, error) {
+	if room := cb.limit - cb.Len(); len(p) > room {
+		cb.truncated = true
+		if room > 0 {
+			cb.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+
+	return cb.Buffer.Write(p)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// auditMiddleware records mutating HTTP requests that passed authentication, small JSON bodies are
+// kept in the details with secrets and ninja file content removed
+func auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		template := ""
+		if route := mux.CurrentRoute(r); route != nil {
+			template, _ = route.GetPathTemplate()
+		}
+
+		if auditReadOnlyRoutes[template] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Uploaded ninja files are never kept
+		var body *cappedBuffer
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); !auditSkippedMediaTypes[mediaType] {
+			body = &cappedBuffer{limit: auditMaxBodyBytes}
+			r.Body = readCloser{Reader: io.TeeReader(r.Body, body), Closer: r.Body}
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		details := map[string]interface{}{}
+		if body != nil && !body.truncated && body.Len() > 0 {
+			var request interface{}
+			if json.Unmarshal(body.Bytes(), &request) == nil {
+				if fields, ok := request.(map[string]interface{}); ok {
+					for _, field := range auditRedactedFields {
+						delete(fields, field)
+					}
+				}
+				details["request"] = request
+			}
+		}
+		if query := r.URL.RawQuery; query != "" {
+			details["query"] = query
+		}
+		details["status_code"] = recorder.status
+
+		result := auditResultOK
+		if recorder.status >= http.StatusBadRequest {
+			result = auditResultError
+		}
+
+		recordAudit(r.Context(), ninjaStore, &store.NinjaAuditEntry{
+			Protocol: "http",
+			Action:   r.Method + " " + strings.ReplaceAll(template, ":.*", ""),
+			Resource: r.URL.Path,
+			Result:   result,
+			Details:  auditDetails(details),
+		})
+	})
+}
+
+// auditInterceptor records mutating gRPC calls that passed authentication
+func auditInterceptor(ninjaStore *store.NinjaStore) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := strings.TrimPrefix(info.FullMethod, grpcServicePrefix)
+		if !grpcMutations[method] {
+			return handler(ctx, req)
+		}
+
+		resp, err := handler(ctx, req)
+
+		resource, details := grpcAuditRequest(req)
+		details["code"] = status.Code(err).String()
+
+		result := auditResultOK
+		if err != nil {
+			result = auditResultError
+		}
+
+		recordAudit(ctx, ninjaStore, &store.NinjaAuditEntry{
+			Protocol: "grpc",
+			Action:   method,
+			Resource: resource,
+			Result:   result,
+			Details:  auditDetails(details),
+		})
+
+		return resp, err
+	}
+}
+
+// auditStreamInterceptor records mutating gRPC streams once they end
+func auditStreamInterceptor(ninjaStore *store.NinjaStore) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		method := strings.TrimPrefix(info.FullMethod, grpcServicePrefix)
+		if !grpcMutations[method] {
+			return handler(srv, ss)
+		}
+
+		err := handler(srv, ss)
+
+		result := auditResultOK
+		if err != nil {
+			result = auditResultError
+		}
+
+		recordAudit(ss.Context(), ninjaStore, &store.NinjaAuditEntry{
+			Protocol: "grpc",
+			Action:   method,
+			Resource: "load:stream",
+			Result:   result,
+			Details:  auditDetails(map[string]interface{}{"code": status.Code(err).String()}),
+		})
+
+		return err
+	}
+}
+
+// grpcAuditRequest returns the resource a request changes and the request fields worth keeping
+func grpcAuditRequest(req interface{}) (string, map[string]interface{}) {
+	switch r := req.(type) {
+	case *proto.CreateBuildRequest:
+		return "build:" + r.BuildId, map[string]interface{}{"rule": r.Rule, "outputs": r.Outputs, "pool": r.Pool}
+	case *proto.CreateRuleRequest:
+		return "rule:" + r.Name, map[string]interface{}{"command": r.Command}
+	case *proto.UpdateTargetStatusRequest:
+		return "target:" + r.Path, map[string]interface{}{"status": r.Status}
+	case *proto.UpdateRuleRequest:
+		return "rule:" + r.Name, map[string]interface{}{"command": r.Command}
+	case *proto.DeleteRuleRequest:
+		return "rule:" + r.Name, map[string]interface{}{"force": r.Force}
+	case *proto.DeleteBuildRequest:
+		return "build:" + r.Id, map[string]interface{}{}
+	case *proto.DeleteTargetRequest:
+		return "target:" + r.Path, map[string]interface{}{}
+	case *proto.SetTargetLabelsRequest:
+		return "target:" + r.Path, map[string]interface{}{"owner": r.Owner, "labels": r.Labels}
+	case *proto.SetBuildLabelsRequest:
+		return "build:" + r.Id, map[string]interface{}{"owner": r.Owner, "labels": r.Labels}
+	case *proto.BulkUpdateTargetStatusRequest:
+		return "targets", map[string]interface{}{"updates": len(r.Updates)}
+	case *proto.LoadNinjaFileRequest:
+		return "load:" + r.FilePath, map[string]interface{}{"file_path": r.FilePath, "content_bytes": len(r.Content)}
+	default:
+		return "", map[string]interface{}{}
+	}
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/distninja/distninja/store"
+)
+
+const (
+	authMethodAPIKey = "api_key"
+	authMethodJWT    = "jwt"
+)
+
+type identityKey struct{}
+
+// AuthConfig configures request authentication, auth is disabled when neither keys nor secret are set
+type AuthConfig struct {
+	// APIKeys maps key names to key values
+	APIKeys map[string]string
+	// JWTSecret is the HMAC secret used to validate HS256 bearer tokens
+	JWTSecret string
+	// DefaultRole applies to authenticated callers without a stored role binding or role claim
+	DefaultRole string
+	// Admins are subjects that are always granted the admin role
+	Admins []string
+}
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	Subject string                 `json:"subject"`
+	Method  string                 `json:"method"`
+	Claims  map[string]interface{} `json:"claims,omitempty"`
+}
+
+// Enabled reports whether any credentials are configured
+func (c *AuthConfig) Enabled() bool {
+	return c != nil && (len(c.APIKeys) > 0 || c.JWTSecret != "")
+}
+
+// ParseAPIKeys parses "name=key" pairs, bare keys are named after their position
+func ParseAPIKeys(values []string) (map[string]string, error) {
+	keys := make(map[string]string, len(values))
+
+	for i, value := range values {
+		name, key, found := strings.Cut(value, "=")
+		if !found {
+			name, key = fmt.Sprintf("key%d", i), value
+		}
+
+		if name == "" || key == "" {
+			return nil, fmt.Errorf("invalid api key %q, expected name=key", value)
+		}
+
+		if _, exists := keys[name]; exists {
+			return nil, fmt.Errorf("duplicate api key name %s", name)
+		}
+
+		keys[name] = key
+	}
+
+	return keys, nil
+}
+
+// Authenticate validates a bearer token against the configured API keys and JWT secret
+func (c *AuthConfig) Authenticate(token string) (*Identity, error) {
+	if token == "" {
+		return nil, fmt.Errorf("missing credentials")
+	}
+
+	for name, key := range c.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+			return &Identity{Subject: name, Method: authMethodAPIKey}, nil
+		}
+	}
+
+	if c.JWTSecret != "" && strings.Count(token, ".") == 2 {
+		claims, err := validateJWT(token, []byte(c.JWTSecret), time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		subject, _ := claims["sub"].(string)
+
+		return &Identity{Subject: subject, Method: authMethodJWT, Claims: claims}, nil
+	}
+
+	return nil, fmt.Errorf("invalid credentials")
+}
+
+// IdentityFromContext returns the identity stored by the auth middleware or interceptor
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
+
+func contextWithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// authMiddleware authenticates callers and enforces the role required by the matched route
+func authMiddleware(config *AuthConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity := certIdentity(r.TLS)
+
+			if !config.Enabled() || r.URL.Path == "/health" || r.URL.Path == triggerPath {
+				if identity != nil {
+					r = r.WithContext(contextWithIdentity(r.Context(), identity))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if token := bearerToken(r); token != "" {
+				var err error
+				identity, err = config.Authenticate(token)
+				if err != nil {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="distninja"`)
+					writeError(w, fmt.Sprintf("Unauthorized: %v", err), http.StatusUnauthorized)
+					return
+				}
+			}
+
+			required := httpPermission(r)
+			role := resolveRole(r.Context(), config, ninjaStore, identity)
+
+			if !store.RoleAllows(role, required) {
+				if identity == nil {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="distninja"`)
+					writeError(w, "Unauthorized: missing credentials", http.StatusUnauthorized)
+					return
+				}
+				writeError(w, fmt.Sprintf("Forbidden: role %s requires %s", role, required), http.StatusForbidden)
+				return
+			}
+
+			if identity != nil {
+				r = r.WithContext(contextWithIdentity(r.Context(), identity))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+
+	return ""
+}
+
+// validateJWT checks an HS256 token signature and its time claims
+func validateJWT(token string, secret []byte, now time.Time) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+
+	if header.Alg != "HS256" {
+		return nil, fmt.Errorf("unsupported token algorithm %s", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, fmt.Errorf("invalid token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token payload: %w", err)
+	}
+
+	claims := make(map[string]interface{})
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed token payload: %w", err)
+	}
+
+	if exp, ok := claims["exp"].(float64); ok && now.Unix() >= int64(exp) {
+		return nil, fmt.Errorf("token expired")
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now.Unix() < int64(nbf) {
+		return nil, fmt.Errorf("token not valid yet")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// gitDiffTimeout bounds the git diff run for /analysis/changed?since=
+const gitDiffTimeout = 30 * time.Second
+
+// changedHandler returns the targets affected by a change. The change is the git diff or the list of
+// changed paths in the request body, or with since=<rev> the changes of workspace since that revision.
+func changedHandler(workspace string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var paths []string
+
+		if since := r.URL.Query().Get("since"); since != "" {
+			if workspace == "" {
+				writeError(w, "No git workspace is configured, post the diff instead", http.StatusBadRequest)
+				return
+			}
+
+			var err error
+			if paths, err = gitChangedPaths(r.Context(), workspace, since); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		} else {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeError(w, "Failed to read request body", http.StatusBadRequest)
+				return
+			}
+			if len(strings.TrimSpace(string(body))) == 0 {
+				writeError(w, "A diff body or the since parameter is required", http.StatusBadRequest)
+				return
+			}
+			paths = parseDiffPaths(string(body))
+		}
+
+		result, err := ninjaStore.GetChangedImpact(r.Context(), paths)
+		if err != nil {
+			writeError(w, fmt.Sprintf("Failed to analyze changes: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	}
+}
+
+// gitChangedPaths returns the paths changed in the working tree of workspace since revision since, or
+// between two revisions for a since of the form a..b or a...b. Renames are reported as their old and
+// new path.
+func gitChangedPaths(ctx context.Context, workspace, since string) ([]string, error) {
+	if strings.HasPrefix(since, "-") {
+		return nil, fmt.Errorf("invalid revision %s", since)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gitDiffTimeout)
+	defer cancel()
+
+	diff := exec.CommandContext(ctx, "git", "-C", workspace, "diff", "--name-only", "--no-renames", since, "--")
+
+	output, err := diff.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("git diff since %s failed: %s", since, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git diff since %s failed: %w", since, err)
+	}
+
+	return parseDiffPaths(string(output)), nil
+}
+
+// parseDiffPaths returns the paths changed by a unified git diff, or the paths listed one per line as
+// by git diff --name-only
+func parseDiffPaths(diff string) []string {
+	seen := make(map[string]bool)
+	isDiff := false
+
+	add := func(path string) {
+		path = strings.TrimSpace(path)
+		if path != "" && path != "/dev/null" {
+			seen[path] = true
+		}
+	}
+
+	lines := strings.Split(diff, "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			isDiff = true
+		}
+	}
+
+	for _, line := range lines {
+		if !isDiff {
+			add(line)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git a/"):
+			// Mode changes and binary files have no --- and +++ lines
+			if from, to, ok := strings.Cut(strings.TrimPrefix(line, "diff --git a/"), " b/"); ok {
+				add(from)
+				add(to)
+			}
+		case strings.HasPrefix(line, "--- a/"):
+			add(strings.TrimPrefix(line, "--- a/"))
+		case strings.HasPrefix(line, "+++ b/"):
+			add(strings.TrimPrefix(line, "+++ b/"))
+		case strings.HasPrefix(line, "rename from "):
+			add(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			add(strings.TrimPrefix(line, "rename to "))
+		}
+	}
+
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
@@ -0,0 +1,303 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/distninja/distninja/store"
+)
+
+const (
+	compactRoute = "/api/v1/admin/compact"
+
+	// storeGateWait bounds how long a request waits for a compaction to end
+	storeGateWait = time.Minute
+	// compactDrainWait bounds how long a compaction waits for the requests using the store to end, new
+	// requests wait meanwhile
+	compactDrainWait = 10 * time.Second
+)
+
+var (
+	// errStoreBusy is returned when the gate isn't free within its wait
+	errStoreBusy = errors.New("store is busy")
+	// errCompacting is returned when a compaction is asked for while one runs
+	errCompacting = errors.New("store is already being compacted")
+)
+
+// gate keeps requests out of the store while it is compacted. Requests using the store enter it,
+// compaction waits for them to leave and keeps new ones out until it is done. Both waits are bounded, so
+// a long load or stream fails the compaction instead of stalling every request behind it.
+type gate struct {
+	mu      sync.Mutex
+	readers int
+	closed  bool
+	// changed is closed and replaced whenever readers or closed change
+	changed chan struct{}
+	// generation counts the compactions that ran, see gatedStream
+	generation uint64
+	// compacting is held by the running compaction
+	compacting sync.Mutex
+}
+
+// storeGate is the gate of the package level store
+var storeGate = newGate()
+
+func newGate() *gate {
+	return &gate{changed: make(chan struct{})}
+}
+
+// enter waits until no compaction runs, for at most storeGateWait, and returns the generation entered
+func (g *gate) enter(ctx context.Context) (uint64, error) {
+	timer := time.NewTimer(storeGateWait)
+	defer timer.Stop()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for g.closed {
+		if err := g.wait(ctx, timer.C); err != nil {
+			return 0, err
+		}
+	}
+
+	g.readers++
+
+	return g.generation, nil
+}
+
+// leave ends what enter started
+func (g *gate) leave() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.readers--
+	g.notify()
+}
+
+// exclusive runs fn once the requests in the store other than the caller's have left, keeping new ones
+// out until fn returns. The caller has entered the gate.
+func (g *gate) exclusive(ctx context.Context, fn func() error) error {
+	if !g.compacting.TryLock() {
+		return errCompacting
+	}
+	defer g.compacting.Unlock()
+
+	timer := time.NewTimer(compactDrainWait)
+	defer timer.Stop()
+
+	g.mu.Lock()
+	g.closed = true
+
+	for g.readers > 1 {
+		if err := g.wait(ctx, timer.C); err != nil {
+			g.open()
+			return err
+		}
+	}
+
+	g.generation++
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		g.open()
+	}()
+
+	return fn()
+}
+
+// open lets requests in again and releases g.mu, which is held
+func (g *gate) open() {
+	g.closed = false
+	g.notify()
+	g.mu.Unlock()
+}
+
+// wait releases g.mu until the gate changes, ctx ends or timeout fires, g.mu is held
+func (g *gate) wait(ctx context.Context, timeout <-chan time.Time) error {
+	changed := g.changed
+
+	g.mu.Unlock()
+	defer g.mu.Lock()
+
+	select {
+	case <-changed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeout:
+		return errStoreBusy
+	}
+}
+
+// notify wakes the waiters, g.mu is held
+func (g *gate) notify() {
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+// ungatedRoutes are long lived and don't use the store, holding the gate would hold compaction off
+var ungatedRoutes = map[string]bool{
+	"/api/v1/ws": true,
+}
+
+// ungatedMethods are the DistNinjaService streams that only relay events
+var ungatedMethods = map[string]bool{
+	"WatchTargets": true,
+}
+
+// releasedMethods are the DistNinjaService streams that leave the gate while they wait for the client,
+// the others write from a goroutine of their own and hold it until they end
+var releasedMethods = map[string]bool{
+	"StreamTargets": true,
+	"StreamQuads":   true,
+}
+
+// storeGateMiddleware keeps the request in the store gate, answering 503 when a compaction doesn't end
+// in time
+func storeGateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		template := ""
+		if route := mux.CurrentRoute(r); route != nil {
+			template, _ = route.GetPathTemplate()
+		}
+
+		if ungatedRoutes[template] || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if _, err := storeGate.enter(r.Context()); err != nil {
+			w.Header().Set("Retry-After", strconv.Itoa(int(compactDrainWait.Seconds())))
+			writeError(w, "Store is being compacted, retry later", http.StatusServiceUnavailable)
+			return
+		}
+		defer storeGate.leave()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// storeGateInterceptor keeps unary calls in the store gate
+func storeGateInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if _, err := storeGate.enter(ctx); err != nil {
+		return nil, status.Error(codes.Unavailable, "store is being compacted, retry later")
+	}
+	defer storeGate.leave()
+
+	return handler(ctx, req)
+}
+
+// storeGateStreamInterceptor keeps streams using the store in the store gate
+func storeGateStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	method := strings.TrimPrefix(info.FullMethod, grpcServicePrefix)
+	if ungatedMethods[method] {
+		return handler(srv, ss)
+	}
+
+	generation, err := storeGate.enter(ss.Context())
+	if err != nil {
+		return status.Error(codes.Unavailable, "store is being compacted, retry later")
+	}
+
+	if !releasedMethods[method] {
+		defer storeGate.leave()
+		return handler(srv, ss)
+	}
+
+	stream := &gatedStream{ServerStream: ss, generation: generation, entered: true}
+	defer func() {
+		if stream.entered {
+			storeGate.leave()
+		}
+	}()
+
+	return handler(srv, stream)
+}
+
+// gatedStream leaves the store gate while a message is sent, so a slow client doesn't hold compaction
+// off. Streams that a compaction ran during end with Unavailable, what they read may belong to the
+// closed store.
+type gatedStream struct {
+	grpc.ServerStream
+	generation uint64
+	entered    bool
+}
+
+func (s *gatedStream) SendMsg(m interface{}) error {
+	storeGate.leave()
+	s.entered = false
+
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+
+	generation, err := storeGate.enter(s.Context())
+	if err != nil || generation != s.generation {
+		if err == nil {
+			storeGate.leave()
+		}
+		return status.Error(codes.Unavailable, "store was compacted during the stream, retry it")
+	}
+	s.entered = true
+
+	return nil
+}
+
+// storeFailure receives the error of a store that can't be used any more, Run shuts down on it
+var storeFailure = make(chan error, 1)
+
+// compactStoreHandler rewrites the store file without its free pages while the server runs, requests
+// wait until it is done
+func compactStoreHandler(w http.ResponseWriter, r *http.Request) {
+	var stats *store.CompactStats
+
+	err := storeGate.exclusive(r.Context(), func() error {
+		var err error
+		stats, err = ninjaStore.Compact(r.Context())
+		return err
+	})
+
+	switch {
+	case errors.Is(err, errCompacting):
+		writeError(w, "The store is already being compacted", http.StatusConflict)
+		return
+	case errors.Is(err, errStoreBusy), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		w.Header().Set("Retry-After", strconv.Itoa(int(compactDrainWait.Seconds())))
+		writeError(w, "Requests using the store did not end in time, retry later", http.StatusServiceUnavailable)
+		return
+	case errors.Is(err, store.ErrStoreClosed):
+		select {
+		case storeFailure <- err:
+		default:
+		}
+		writeError(w, fmt.Sprintf("Failed to compact store, shutting down: %v", err), http.StatusInternalServerError)
+		return
+	case err != nil:
+		writeError(w, fmt.Sprintf("Failed to compact store: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "compacted store", "bytes_before", stats.BytesBefore, "bytes_after", stats.BytesAfter,
+		"reclaimed_bytes", stats.BytesBefore-stats.BytesAfter)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"bytes_before":    stats.BytesBefore,
+		"bytes_after":     stats.BytesAfter,
+		"reclaimed_bytes": stats.BytesBefore - stats.BytesAfter,
+	})
+}
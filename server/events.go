@@ -0,0 +1,288 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/distninja/distninja/store"
+)
+
+// Event types published on the event bus
+const (
+	EventBuildCreated        = "build.created"
+	EventBuildDeleted        = "build.deleted"
+	EventRuleCreated         = "rule.created"
+	EventRuleUpdated         = "rule.updated"
+	EventRuleDeleted         = "rule.deleted"
+	EventTargetStatusChanged = "target.status_changed"
+	EventTargetFailed        = "target.failed"
+	EventTargetDeleted       = "target.deleted"
+	EventTargetMoved         = "target.moved"
+	EventLoadCompleted       = "load.completed"
+	EventStoreReset          = "store.reset"
+	EventTriggerReceived     = "trigger.received"
+	EventScheduleFired       = "schedule.fired"
+	EventWorkspaceChanged    = "workspace.changed"
+)
+
+// eventTypes lists the event types subscribers may filter on
+var eventTypes = map[string]bool{
+	EventBuildCreated:        true,
+	EventBuildDeleted:        true,
+	EventRuleCreated:         true,
+	EventRuleUpdated:         true,
+	EventRuleDeleted:         true,
+	EventTargetStatusChanged: true,
+	EventTargetFailed:        true,
+	EventTargetDeleted:       true,
+	EventTargetMoved:         true,
+	EventLoadCompleted:       true,
+	EventStoreReset:          true,
+	EventTriggerReceived:     true,
+	EventScheduleFired:       true,
+	EventWorkspaceChanged:    true,
+}
+
+// ValidateEventTypes checks that every type is a known event type
+func ValidateEventTypes(types []string) error {
+	for _, t := range types {
+		if !eventTypes[t] {
+			return fmt.Errorf("unknown event type %s", t)
+		}
+	}
+
+	return nil
+}
+
+// targetFailedStatus is the target status reported as a failure
+const targetFailedStatus = "failed"
+
+// Event is a graph or status change delivered to subscribers
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// EventBus fans out published events to subscribers without blocking publishers. Streams to clients
+// subscribe for as long as they are connected, sinks forwarding events elsewhere run for the life of the
+// server.
+type EventBus struct {
+	mu          sync.RWMutex
+	seq         atomic.Uint64
+	nextID      int
+	subscribers map[int]*subscription
+	sinks       map[int]*runningSink
+}
+
+type subscription struct {
+	ch      chan Event
+	types   map[string]bool
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// NewEventBus creates an empty event bus
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[int]*subscription), sinks: make(map[int]*runningSink)}
+}
+
+// Publish delivers an event to every matching subscriber, events are dropped for subscribers whose buffer is full
+func (b *EventBus) Publish(eventType string, data interface{}) {
+	event := Event{
+		ID:   b.seq.Add(1),
+		Type: eventType,
+		Time: time.Now().UTC(),
+		Data: data,
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		if len(sub.types) > 0 && !sub.types[eventType] {
+			continue
+		}
+
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for the given event types, all types when empty, the returned
+// function unsubscribes and closes the channel
+func (b *EventBus) Subscribe(buffer int, types ...string) (<-chan Event, func()) {
+	id, sub := b.subscribe(buffer, types)
+
+	return sub.ch, func() {
+		b.unsubscribe(id, sub)
+	}
+}
+
+func (b *EventBus) subscribe(buffer int, types []string) (int, *subscription) {
+	sub := &subscription{
+		ch:    make(chan Event, buffer),
+		types: make(map[string]bool, len(types)),
+	}
+
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = sub
+	b.mu.Unlock()
+
+	return id, sub
+}
+
+func (b *EventBus) unsubscribe(id int, sub *subscription) {
+	b.mu.Lock()
+	delete(b.subscribers, id)
+	b.mu.Unlock()
+	sub.once.Do(func() { close(sub.ch) })
+}
+
+// CloseSubscribers closes the channel of every subscriber, so long lived streams end on shutdown
+func (b *EventBus) CloseSubscribers() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id, sub := range b.subscribers {
+		delete(b.subscribers, id)
+		sub.once.Do(func() { close(sub.ch) })
+	}
+}
+
+// Subscribers returns the number of active subscribers other than sinks
+func (b *EventBus) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.subscribers) - len(b.sinks)
+}
+
+// EventSink receives the events of the bus in the background, e.g. to forward them to another system.
+// Events reach a sink one at a time in the order they were published.
+type EventSink interface {
+	// Name identifies the sink in logs and the server status
+	Name() string
+	// Send delivers an event, failures are logged and counted and later events are still sent
+	Send(event Event) error
+	// Close releases the sink once no more events are sent
+	Close() error
+}
+
+// EventSinkStatus counts the events handed to a sink, dropped events arrived while its buffer was full
+type EventSinkStatus struct {
+	Name    string `json:"name"`
+	Sent    uint64 `json:"sent"`
+	Failed  uint64 `json:"failed"`
+	Dropped uint64 `json:"dropped"`
+}
+
+// runningSink sends the events of a subscription to a sink
+type runningSink struct {
+	sink   EventSink
+	sub    *subscription
+	sent   atomic.Uint64
+	failed atomic.Uint64
+	done   chan struct{}
+}
+
+func (r *runningSink) run() {
+	defer close(r.done)
+
+	for event := range r.sub.ch {
+		if err := r.sink.Send(event); err != nil {
+			r.failed.Add(1)
+			slog.Warn("event sink failed", "sink", r.sink.Name(), "event", event.Type, "id", event.ID, "error", err)
+			continue
+		}
+		r.sent.Add(1)
+	}
+}
+
+// StartSink subscribes sink to the given event types, all types when empty, and sends it their events
+// until the returned function is called. Stopping sends the events already buffered, then closes the sink.
+func (b *EventBus) StartSink(sink EventSink, buffer int, types ...string) func() {
+	id, sub := b.subscribe(buffer, types)
+	running := &runningSink{sink: sink, sub: sub, done: make(chan struct{})}
+
+	b.mu.Lock()
+	b.sinks[id] = running
+	b.mu.Unlock()
+
+	go running.run()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			b.unsubscribe(id, sub)
+			<-running.done
+
+			b.mu.Lock()
+			delete(b.sinks, id)
+			b.mu.Unlock()
+
+			if err := sink.Close(); err != nil {
+				slog.Warn("failed to close event sink", "sink", sink.Name(), "error", err)
+			}
+		})
+	}
+}
+
+// Sinks returns the counters of the running sinks by name
+func (b *EventBus) Sinks() []*EventSinkStatus {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	sinks := make([]*EventSinkStatus, 0, len(b.sinks))
+	for _, running := range b.sinks {
+		sinks = append(sinks, &EventSinkStatus{
+			Name:    running.sink.Name(),
+			Sent:    running.sent.Load(),
+			Failed:  running.failed.Load(),
+			Dropped: running.sub.dropped.Load(),
+		})
+	}
+
+	sort.Slice(sinks, func(i, j int) bool { return sinks[i].Name < sinks[j].Name })
+
+	return sinks
+}
+
+// publishStatusChange publishes a status change of target, and a failure event when the target failed.
+// The owner and labels of the target are passed on so subscribers can route failures to its team.
+func publishStatusChange(b *EventBus, target *store.NinjaTarget, status string) {
+	data := map[string]string{
+		"path":            target.Path,
+		"status":          status,
+		"previous_status": target.Status,
+		"hash":            target.Hash,
+	}
+
+	if target.Owner != "" {
+		data["owner"] = target.Owner
+	}
+	if target.Labels != "" {
+		data["labels"] = target.Labels
+	}
+
+	b.Publish(EventTargetStatusChanged, data)
+
+	if status == targetFailedStatus {
+		b.Publish(EventTargetFailed, data)
+	}
+}
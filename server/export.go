@@ -0,0 +1,217 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/distninja/distninja/store"
+)
+
+// Graph export formats
+const (
+	GraphFormatDOT     = "dot"
+	GraphFormatGraphML = "graphml"
+	GraphFormatCyJS    = "cyjs"
+)
+
+// graphContentTypes maps export formats to response content types
+var graphContentTypes = map[string]string{
+	GraphFormatDOT:     "text/vnd.graphviz; charset=utf-8",
+	GraphFormatGraphML: "application/graphml+xml",
+	GraphFormatCyJS:    "application/json",
+}
+
+// writeGraph encodes a dependency graph in the given format
+func writeGraph(w io.Writer, graph *store.DependencyGraph, format string) error {
+	switch format {
+	case GraphFormatDOT:
+		return writeDOT(w, graph)
+	case GraphFormatGraphML:
+		return writeGraphML(w, graph)
+	case GraphFormatCyJS:
+		return writeCyJS(w, graph)
+	default:
+		return fmt.Errorf("unknown graph format %s", format)
+	}
+}
+
+func writeDOT(w io.Writer, graph *store.DependencyGraph) error {
+	var b strings.Builder
+
+	b.WriteString("digraph distninja {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+
+	for _, node := range graph.Nodes {
+		attrs := []string{"label=" + dotQuote(node.ID)}
+		if node.Kind == store.NodeKindTarget {
+			attrs = append(attrs, "shape=box", "tooltip="+dotQuote(fmt.Sprintf("%s (%s)", node.Rule, node.Status)))
+			if color, ok := dotStatusColors[node.Status]; ok {
+				attrs = append(attrs, "style=filled", "fillcolor="+color)
+			}
+		} else {
+			attrs = append(attrs, "shape=ellipse")
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(node.ID), strings.Join(attrs, ", "))
+	}
+
+	for _, edge := range graph.Edges {
+		style := ""
+		switch edge.Kind {
+		case store.EdgeKindImplicit:
+			style = " [style=dashed]"
+		case store.EdgeKindOrder:
+			style = " [style=dotted]"
+		}
+		fmt.Fprintf(&b, "  %s -> %s%s;\n", dotQuote(edge.Source), dotQuote(edge.Target), style)
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// dotQuote quotes a DOT identifier, unlike strconv.Quote it keeps non-ASCII paths readable
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+var dotStatusColors = map[string]string{
+	"building": "lightblue",
+	"built":    "palegreen",
+	"success":  "palegreen",
+	"failed":   "salmon",
+	"dirty":    "khaki",
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func writeGraphML(w io.Writer, graph *store.DependencyGraph) error {
+	doc := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "kind", For: "node", AttrName: "kind", AttrType: "string"},
+			{ID: "status", For: "node", AttrName: "status", AttrType: "string"},
+			{ID: "rule", For: "node", AttrName: "rule", AttrType: "string"},
+			{ID: "file_type", For: "node", AttrName: "file_type", AttrType: "string"},
+			{ID: "edge_kind", For: "edge", AttrName: "kind", AttrType: "string"},
+		},
+		Graph: graphMLGraph{ID: "distninja", EdgeDefault: "directed"},
+	}
+
+	for _, node := range graph.Nodes {
+		data := []graphMLData{{Key: "kind", Value: node.Kind}}
+		if node.Status != "" {
+			data = append(data, graphMLData{Key: "status", Value: node.Status})
+		}
+		if node.Rule != "" {
+			data = append(data, graphMLData{Key: "rule", Value: node.Rule})
+		}
+		if node.FileType != "" {
+			data = append(data, graphMLData{Key: "file_type", Value: node.FileType})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: node.ID, Data: data})
+	}
+
+	for _, edge := range graph.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: edge.Source,
+			Target: edge.Target,
+			Data:   []graphMLData{{Key: "edge_kind", Value: edge.Kind}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// writeCyJS writes the Cytoscape.js elements JSON format
+func writeCyJS(w io.Writer, graph *store.DependencyGraph) error {
+	type element struct {
+		Data map[string]string `json:"data"`
+	}
+
+	nodes := make([]element, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		data := map[string]string{"id": node.ID, "kind": node.Kind}
+		if node.Status != "" {
+			data["status"] = node.Status
+		}
+		if node.Rule != "" {
+			data["rule"] = node.Rule
+		}
+		if node.FileType != "" {
+			data["file_type"] = node.FileType
+		}
+		nodes = append(nodes, element{Data: data})
+	}
+
+	edges := make([]element, 0, len(graph.Edges))
+	for i, edge := range graph.Edges {
+		edges = append(edges, element{Data: map[string]string{
+			"id":     fmt.Sprintf("e%d", i),
+			"source": edge.Source,
+			"target": edge.Target,
+			"kind":   edge.Kind,
+		}})
+	}
+
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"elements": map[string]interface{}{
+			"nodes": nodes,
+			"edges": edges,
+		},
+	})
+}
@@ -0,0 +1,394 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/distninja/distninja/store"
+)
+
+// GraphQLRequest is the body of a GraphQL POST request
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+}
+
+var graphQLSchema graphql.Schema
+
+func init() {
+	var err error
+
+	graphQLSchema, err = newGraphQLSchema()
+	if err != nil {
+		panic(fmt.Sprintf("invalid graphql schema: %v", err))
+	}
+}
+
+// newGraphQLSchema builds the read-only schema over targets, builds, rules and files
+func newGraphQLSchema() (graphql.Schema, error) {
+	variableType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Variable",
+		Fields: graphql.Fields{
+			"name":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"value": &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	targetType := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "Target",
+		Fields: graphql.Fields{},
+	})
+
+	fileType := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "File",
+		Fields: graphql.Fields{},
+	})
+
+	ruleType := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "Rule",
+		Fields: graphql.Fields{},
+	})
+
+	buildType := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "Build",
+		Fields: graphql.Fields{},
+	})
+
+	targetFilterArgs := graphql.FieldConfigArgument{
+		"status":     &graphql.ArgumentConfig{Type: graphql.String},
+		"rule":       &graphql.ArgumentConfig{Type: graphql.String},
+		"pathPrefix": &graphql.ArgumentConfig{Type: graphql.String},
+		"owner":      &graphql.ArgumentConfig{Type: graphql.String},
+		"labels":     &graphql.ArgumentConfig{Type: graphql.NewList(graphql.String)},
+		"limit":      &graphql.ArgumentConfig{Type: graphql.Int},
+	}
+
+	// Fields are added after creation since the types reference each other
+	targetType.AddFieldConfig("path", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	targetType.AddFieldConfig("status", &graphql.Field{Type: graphql.String})
+	targetType.AddFieldConfig("hash", &graphql.Field{Type: graphql.String})
+	targetType.AddFieldConfig("owner", &graphql.Field{Type: graphql.String})
+	targetType.AddFieldConfig("labels", &graphql.Field{
+		Type: graphql.NewList(variableType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			labels, err := p.Source.(*store.NinjaTarget).GetLabels()
+			if err != nil {
+				return nil, err
+			}
+			return graphQLVariables(labels), nil
+		},
+	})
+	targetType.AddFieldConfig("build", &graphql.Field{
+		Type: buildType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			target := p.Source.(*store.NinjaTarget)
+			return ninjaStore.GetBuild(p.Context, strings.TrimPrefix(string(target.Build), "build:"))
+		},
+	})
+	targetType.AddFieldConfig("dependencies", &graphql.Field{
+		Type: graphql.NewList(fileType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return ninjaStore.GetBuildDependencies(p.Context, p.Source.(*store.NinjaTarget).Path)
+		},
+	})
+	targetType.AddFieldConfig("dependents", &graphql.Field{
+		Type: graphql.NewList(targetType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return ninjaStore.GetReverseDependencies(p.Context, p.Source.(*store.NinjaTarget).Path)
+		},
+	})
+
+	fileType.AddFieldConfig("path", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	fileType.AddFieldConfig("fileType", &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*store.NinjaFile).FileType, nil
+		},
+	})
+	fileType.AddFieldConfig("target", &graphql.Field{
+		Type:        targetType,
+		Description: "Target producing this file, null for sources",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			target, err := ninjaStore.GetTarget(p.Context, p.Source.(*store.NinjaFile).Path)
+			if err != nil {
+				return nil, nil
+			}
+			return target, nil
+		},
+	})
+	fileType.AddFieldConfig("dependents", &graphql.Field{
+		Type: graphql.NewList(targetType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return ninjaStore.GetReverseDependencies(p.Context, p.Source.(*store.NinjaFile).Path)
+		},
+	})
+
+	ruleType.AddFieldConfig("name", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	ruleType.AddFieldConfig("command", &graphql.Field{Type: graphql.String})
+	ruleType.AddFieldConfig("description", &graphql.Field{Type: graphql.String})
+	ruleType.AddFieldConfig("variables", &graphql.Field{
+		Type: graphql.NewList(variableType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			variables, err := p.Source.(*store.NinjaRule).GetVariables()
+			if err != nil {
+				return nil, err
+			}
+			return graphQLVariables(variables), nil
+		},
+	})
+	ruleType.AddFieldConfig("builds", &graphql.Field{
+		Type: graphql.NewList(buildType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return ninjaStore.GetBuildsByRule(p.Context, p.Source.(*store.NinjaRule).Name)
+		},
+	})
+	ruleType.AddFieldConfig("targets", &graphql.Field{
+		Type: graphql.NewList(targetType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return ninjaStore.GetTargetsByRule(p.Context, p.Source.(*store.NinjaRule).Name)
+		},
+	})
+
+	buildType.AddFieldConfig("id", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.String),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*store.NinjaBuild).BuildID, nil
+		},
+	})
+	buildType.AddFieldConfig("pool", &graphql.Field{Type: graphql.String})
+	buildType.AddFieldConfig("owner", &graphql.Field{Type: graphql.String})
+	buildType.AddFieldConfig("labels", &graphql.Field{
+		Type: graphql.NewList(variableType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			labels, err := p.Source.(*store.NinjaBuild).GetLabels()
+			if err != nil {
+				return nil, err
+			}
+			return graphQLVariables(labels), nil
+		},
+	})
+	buildType.AddFieldConfig("rule", &graphql.Field{
+		Type: ruleType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return ninjaStore.GetRule(p.Context, strings.TrimPrefix(string(p.Source.(*store.NinjaBuild).Rule), "rule:"))
+		},
+	})
+	buildType.AddFieldConfig("variables", &graphql.Field{
+		Type: graphql.NewList(variableType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			variables, err := p.Source.(*store.NinjaBuild).GetVariables()
+			if err != nil {
+				return nil, err
+			}
+			return graphQLVariables(variables), nil
+		},
+	})
+	buildType.AddFieldConfig("outputs", &graphql.Field{
+		Type: graphql.NewList(targetType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return ninjaStore.GetBuildOutputs(p.Context, p.Source.(*store.NinjaBuild).BuildID)
+		},
+	})
+
+	for name, predicate := range map[string]string{
+		"inputs":       store.PredicateHasInput,
+		"implicitDeps": store.PredicateHasImplicitDep,
+		"orderDeps":    store.PredicateHasOrderDep,
+	} {
+		predicate := predicate
+		buildType.AddFieldConfig(name, &graphql.Field{
+			Type: graphql.NewList(fileType),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return ninjaStore.GetBuildFiles(p.Context, p.Source.(*store.NinjaBuild).BuildID, predicate)
+			},
+		})
+	}
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"targets": &graphql.Field{
+				Type: graphql.NewList(targetType),
+				Args: targetFilterArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveTargets(p.Context, p.Args)
+				},
+			},
+			"target": &graphql.Field{
+				Type: targetType,
+				Args: graphql.FieldConfigArgument{
+					"path": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return ninjaStore.GetTarget(p.Context, p.Args["path"].(string))
+				},
+			},
+			"builds": &graphql.Field{
+				Type: graphql.NewList(buildType),
+				Args: graphql.FieldConfigArgument{
+					"rule": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if rule, ok := p.Args["rule"].(string); ok {
+						return ninjaStore.GetBuildsByRule(p.Context, rule)
+					}
+					return ninjaStore.GetAllBuilds(p.Context)
+				},
+			},
+			"build": &graphql.Field{
+				Type: buildType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return ninjaStore.GetBuild(p.Context, p.Args["id"].(string))
+				},
+			},
+			"rules": &graphql.Field{
+				Type: graphql.NewList(ruleType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return ninjaStore.GetAllRules(p.Context)
+				},
+			},
+			"rule": &graphql.Field{
+				Type: ruleType,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return ninjaStore.GetRule(p.Context, p.Args["name"].(string))
+				},
+			},
+			"file": &graphql.Field{
+				Type: fileType,
+				Args: graphql.FieldConfigArgument{
+					"path": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return ninjaStore.GetFile(p.Context, p.Args["path"].(string))
+				},
+			},
+			"cycles": &graphql.Field{
+				Type: graphql.NewList(graphql.NewList(graphql.String)),
+				Args: graphql.FieldConfigArgument{
+					"first": &graphql.ArgumentConfig{Type: graphql.Boolean},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					first, _ := p.Args["first"].(bool)
+					return ninjaStore.FindCycles(p.Context, first)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// resolveTargets returns all targets matching the status, rule, path prefix, owner and labels filters
+func resolveTargets(ctx context.Context, args map[string]interface{}) ([]*store.NinjaTarget, error) {
+	owner, _ := args["owner"].(string)
+
+	var exprs []string
+	if labels, ok := args["labels"].([]interface{}); ok {
+		for _, label := range labels {
+			if expr, ok := label.(string); ok {
+				exprs = append(exprs, expr)
+			}
+		}
+	}
+
+	selector, err := store.ParseLabelSelector(owner, exprs)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []*store.NinjaTarget
+
+	if rule, ok := args["rule"].(string); ok {
+		targets, err = ninjaStore.GetTargetsByRule(ctx, rule)
+	} else {
+		targets, err = ninjaStore.GetAllTargets(ctx)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	status, _ := args["status"].(string)
+	prefix, _ := args["pathPrefix"].(string)
+
+	filtered := make([]*store.NinjaTarget, 0, len(targets))
+	for _, target := range targets {
+		if status != "" && target.Status != status {
+			continue
+		}
+		if !strings.HasPrefix(target.Path, prefix) || !selector.MatchesTarget(target) {
+			continue
+		}
+		filtered = append(filtered, target)
+	}
+
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Path < filtered[j].Path })
+
+	if limit, ok := args["limit"].(int); ok && limit >= 0 && limit < len(filtered) {
+		filtered = filtered[:limit]
+	}
+
+	return filtered, nil
+}
+
+func graphQLVariables(variables map[string]string) []map[string]string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]map[string]string, 0, len(names))
+	for _, name := range names {
+		result = append(result, map[string]string{"name": name, "value": variables[name]})
+	}
+
+	return result
+}
+
+func graphQLHandler(w http.ResponseWriter, r *http.Request) {
+	var req GraphQLRequest
+
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				writeError(w, "Invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.Query == "" {
+		writeError(w, "Query field is required", http.StatusBadRequest)
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         graphQLSchema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        r.Context(),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
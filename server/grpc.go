@@ -2,18 +2,24 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"net"
+	"io"
+	"log/slog"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
 
 	"github.com/cayleygraph/quad"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 
 	"github.com/distninja/distninja/parser"
 	"github.com/distninja/distninja/server/proto"
@@ -22,59 +28,70 @@ import (
 
 type DistNinjaService struct {
 	proto.UnimplementedDistNinjaServiceServer
-	store *store.NinjaStore
+	store  *store.NinjaStore
+	events *EventBus
 }
 
-func StartGRPCServer(ctx context.Context, address, storeDir string) error {
-	listener, err := net.Listen("tcp", address)
-	if err != nil {
-		return fmt.Errorf("failed to listen on %s: %w", address, err)
+// StartGRPCServer serves the gRPC API until interrupted
+func StartGRPCServer(ctx context.Context, address, storeDir string, opts Options) error {
+	return Serve(ctx, address, "", storeDir, opts)
+}
+
+// newGRPCServer creates the gRPC server with the DistNinjaService, health and reflection services
+func newGRPCServer(ninjaStore *store.NinjaStore, opts *Options, addressLimiter, limiter *rateLimiter) (*grpc.Server, error) {
+	if err := opts.GRPC.validate(); err != nil {
+		return nil, err
 	}
 
-	server := grpc.NewServer(
-		grpc.UnaryInterceptor(loggingInterceptor),
-	)
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			requestIDInterceptor,
+			loggingInterceptor,
+			compressionInterceptor(opts.GRPC.Compression),
+			rateLimitInterceptor(addressLimiter),
+			storeGateInterceptor,
+			authInterceptor(&opts.Auth, ninjaStore),
+			rateLimitInterceptor(limiter),
+			auditInterceptor(ninjaStore),
+		),
+		grpc.ChainStreamInterceptor(
+			requestIDStreamInterceptor,
+			streamLoggingInterceptor,
+			compressionStreamInterceptor(opts.GRPC.Compression),
+			rateLimitStreamInterceptor(addressLimiter),
+			storeGateStreamInterceptor,
+			authStreamInterceptor(&opts.Auth, ninjaStore),
+			rateLimitStreamInterceptor(limiter),
+			auditStreamInterceptor(ninjaStore),
+		),
+	}
 
-	// Initialize store
-	ninjaStore, err := store.NewNinjaStore(storeDir)
-	if err != nil {
-		return fmt.Errorf("failed to initialize ninja store: %w", err)
+	serverOpts = append(serverOpts, opts.GRPC.serverOptions()...)
+
+	if opts.TLS.Enabled() {
+		tlsConfig, err := serverTLSConfig(&opts.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure tls: %w", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
 	}
 
+	server := grpc.NewServer(serverOpts...)
+
 	// Register services
 	healthServer := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(server, healthServer)
 	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
 
 	distNinjaService := &DistNinjaService{
-		store: ninjaStore,
+		store:  ninjaStore,
+		events: eventBus,
 	}
 	proto.RegisterDistNinjaServiceServer(server, distNinjaService)
 
 	reflection.Register(server)
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	serverErr := make(chan error, 1)
-
-	go func() {
-		if err := server.Serve(listener); err != nil {
-			serverErr <- err
-		}
-	}()
-
-	select {
-	case <-ctx.Done():
-	case <-quit:
-	case err := <-serverErr:
-		return fmt.Errorf("gRPC server error: %w", err)
-	}
-
-	server.GracefulStop()
-	_ = ninjaStore.Close()
-
-	return nil
+	return server, nil
 }
 
 // Admin methods
@@ -86,17 +103,65 @@ func (s *DistNinjaService) Health(ctx context.Context, req *proto.HealthRequest)
 }
 
 func (s *DistNinjaService) Status(ctx context.Context, req *proto.StatusRequest) (*proto.StatusResponse, error) {
+	serverStatus, err := collectStatus(ctx, s.store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
 	return &proto.StatusResponse{
-		Service: "distninja",
-		Uptime:  time.Since(time.Now()).String(), // This would normally be calculated from start time
+		Service:          serverStatus.Service,
+		Uptime:           serverStatus.Uptime,
+		Version:          serverStatus.Version,
+		BuildTime:        serverStatus.BuildTime,
+		CommitId:         serverStatus.CommitID,
+		StartTime:        serverStatus.StartTime.Format(time.RFC3339),
+		StorePath:        serverStatus.Store.Path,
+		StoreBackend:     serverStatus.Store.Backend,
+		QuadCount:        serverStatus.Store.Quads,
+		NodeCount:        serverStatus.Store.Nodes,
+		TargetCount:      serverStatus.Store.Targets,
+		RuleCount:        serverStatus.Store.Rules,
+		BuildCount:       serverStatus.Store.Builds,
+		ActiveRuns:       int32(serverStatus.ActiveRuns),
+		ConnectedWorkers: int32(serverStatus.ConnectedWorkers),
+		EventSubscribers: int32(serverStatus.EventSubscribers),
+		UptimeSeconds:    serverStatus.UptimeSeconds,
+		Process: &proto.ProcessStatus{
+			Pid:            int32(serverStatus.Process.PID),
+			Goroutines:     int32(serverStatus.Process.Goroutines),
+			HeapAllocBytes: serverStatus.Process.HeapAllocBytes,
+			SysBytes:       serverStatus.Process.SysBytes,
+			GcCycles:       serverStatus.Process.GCCycles,
+			OpenFiles:      int32(serverStatus.Process.OpenFiles),
+			HttpRequests:   serverStatus.Process.HTTPRequests,
+			HttpErrors:     serverStatus.Process.HTTPErrors,
+			GrpcRequests:   serverStatus.Process.GRPCRequests,
+			GrpcErrors:     serverStatus.Process.GRPCErrors,
+		},
+		EventSinks: protoEventSinks(serverStatus.EventSinks),
 	}, nil
 }
 
+func protoEventSinks(sinks []*EventSinkStatus) []*proto.EventSinkStatus {
+	result := make([]*proto.EventSinkStatus, len(sinks))
+	for i, sink := range sinks {
+		result[i] = &proto.EventSinkStatus{
+			Name:    sink.Name,
+			Sent:    sink.Sent,
+			Failed:  sink.Failed,
+			Dropped: sink.Dropped,
+		}
+	}
+
+	return result
+}
+
 // Build methods
 func (s *DistNinjaService) CreateBuild(ctx context.Context, req *proto.CreateBuildRequest) (*proto.CreateBuildResponse, error) {
 	build := &store.NinjaBuild{
 		BuildID: req.BuildId,
 		Pool:    req.Pool,
+		Owner:   req.Owner,
 	}
 
 	if req.Rule != "" {
@@ -107,10 +172,20 @@ func (s *DistNinjaService) CreateBuild(ctx context.Context, req *proto.CreateBui
 		return nil, fmt.Errorf("failed to set variables: %w", err)
 	}
 
-	if err := s.store.AddBuild(build, req.Inputs, req.Outputs, req.ImplicitDeps, req.OrderDeps); err != nil {
+	if err := build.SetLabels(req.Labels); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.store.AddBuild(ctx, build, req.Inputs, req.Outputs, req.ImplicitDeps, req.OrderDeps); err != nil {
 		return nil, fmt.Errorf("failed to create build: %w", err)
 	}
 
+	s.events.Publish(EventBuildCreated, map[string]interface{}{
+		"build_id": req.BuildId,
+		"rule":     req.Rule,
+		"outputs":  req.Outputs,
+	})
+
 	return &proto.CreateBuildResponse{
 		Status:  "created",
 		BuildId: req.BuildId,
@@ -118,7 +193,7 @@ func (s *DistNinjaService) CreateBuild(ctx context.Context, req *proto.CreateBui
 }
 
 func (s *DistNinjaService) GetBuild(ctx context.Context, req *proto.GetBuildRequest) (*proto.NinjaBuild, error) {
-	build, err := s.store.GetBuild(req.Id)
+	build, err := s.store.GetBuild(ctx, req.Id)
 	if err != nil {
 		return nil, fmt.Errorf("build not found: %w", err)
 	}
@@ -130,32 +205,24 @@ func (s *DistNinjaService) GetBuild(ctx context.Context, req *proto.GetBuildRequ
 		Rule:      string(build.Rule),
 		Variables: build.Variables,
 		Pool:      build.Pool,
+		Owner:     build.Owner,
+		Labels:    build.Labels,
 	}, nil
 }
 
 func (s *DistNinjaService) GetBuildStats(ctx context.Context, req *proto.BuildStatsRequest) (*proto.BuildStatsResponse, error) {
-	stats, err := s.store.GetBuildStats()
+	stats, err := s.store.GetBuildStats(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get build stats: %w", err)
 	}
 
-	// Convert map[string]interface{} to map[string]int64
-	protoStats := make(map[string]int64)
-	for k, v := range stats {
-		if intVal, ok := v.(int); ok {
-			protoStats[k] = int64(intVal)
-		} else if int64Val, ok := v.(int64); ok {
-			protoStats[k] = int64Val
-		}
-	}
-
 	return &proto.BuildStatsResponse{
-		Stats: protoStats,
+		Stats: protoBuildStats(stats),
 	}, nil
 }
 
 func (s *DistNinjaService) GetBuildOrder(ctx context.Context, req *proto.BuildOrderRequest) (*proto.BuildOrderResponse, error) {
-	order, err := s.store.GetBuildOrder()
+	order, err := s.store.GetBuildOrder(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get build order: %w", err)
 	}
@@ -165,6 +232,37 @@ func (s *DistNinjaService) GetBuildOrder(ctx context.Context, req *proto.BuildOr
 	}, nil
 }
 
+func (s *DistNinjaService) DeleteBuild(ctx context.Context, req *proto.DeleteBuildRequest) (*proto.DeleteBuildResponse, error) {
+	if err := s.store.DeleteBuild(ctx, req.Id); err != nil {
+		return nil, storeError("failed to delete build", err)
+	}
+
+	s.events.Publish(EventBuildDeleted, map[string]string{"build_id": req.Id})
+
+	return &proto.DeleteBuildResponse{
+		Status:  "deleted",
+		BuildId: req.Id,
+	}, nil
+}
+
+func (s *DistNinjaService) SetBuildLabels(ctx context.Context, req *proto.SetBuildLabelsRequest) (*proto.NinjaBuild, error) {
+	build, err := s.store.SetBuildLabels(ctx, req.Id, &store.NodeLabels{Owner: req.Owner, Labels: req.Labels})
+	if err != nil {
+		return nil, storeError("failed to set build labels", err)
+	}
+
+	return &proto.NinjaBuild{
+		Id:        string(build.ID),
+		Type:      string(build.Type),
+		BuildId:   build.BuildID,
+		Rule:      string(build.Rule),
+		Variables: build.Variables,
+		Pool:      build.Pool,
+		Owner:     build.Owner,
+		Labels:    build.Labels,
+	}, nil
+}
+
 // Rule methods
 func (s *DistNinjaService) CreateRule(ctx context.Context, req *proto.CreateRuleRequest) (*proto.CreateRuleResponse, error) {
 	rule := &store.NinjaRule{
@@ -177,10 +275,12 @@ func (s *DistNinjaService) CreateRule(ctx context.Context, req *proto.CreateRule
 		return nil, fmt.Errorf("failed to set variables: %w", err)
 	}
 
-	if _, err := s.store.AddRule(rule); err != nil {
+	if _, err := s.store.AddRule(ctx, rule); err != nil {
 		return nil, fmt.Errorf("failed to create rule: %w", err)
 	}
 
+	s.events.Publish(EventRuleCreated, map[string]string{"name": req.Name})
+
 	return &proto.CreateRuleResponse{
 		Status: "created",
 		Name:   req.Name,
@@ -188,7 +288,7 @@ func (s *DistNinjaService) CreateRule(ctx context.Context, req *proto.CreateRule
 }
 
 func (s *DistNinjaService) GetRule(ctx context.Context, req *proto.GetRuleRequest) (*proto.NinjaRule, error) {
-	rule, err := s.store.GetRule(req.Name)
+	rule, err := s.store.GetRule(ctx, req.Name)
 	if err != nil {
 		return nil, fmt.Errorf("rule not found: %w", err)
 	}
@@ -204,13 +304,18 @@ func (s *DistNinjaService) GetRule(ctx context.Context, req *proto.GetRuleReques
 }
 
 func (s *DistNinjaService) GetTargetsByRule(ctx context.Context, req *proto.GetTargetsByRuleRequest) (*proto.GetTargetsByRuleResponse, error) {
-	targets, err := s.store.GetTargetsByRule(req.RuleName)
+	selector, err := store.ParseLabelSelector(req.Owner, req.Labels)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	targets, err := s.store.GetTargetsByRule(ctx, req.RuleName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get targets by rule: %w", err)
 	}
 
 	var protoTargets []*proto.NinjaTarget
-	for _, target := range targets {
+	for _, target := range store.FilterTargets(targets, selector) {
 		protoTargets = append(protoTargets, &proto.NinjaTarget{
 			Id:     string(target.ID),
 			Type:   string(target.Type),
@@ -218,6 +323,8 @@ func (s *DistNinjaService) GetTargetsByRule(ctx context.Context, req *proto.GetT
 			Status: target.Status,
 			Hash:   target.Hash,
 			Build:  string(target.Build),
+			Owner:  target.Owner,
+			Labels: target.Labels,
 		})
 	}
 
@@ -226,15 +333,76 @@ func (s *DistNinjaService) GetTargetsByRule(ctx context.Context, req *proto.GetT
 	}, nil
 }
 
+func (s *DistNinjaService) UpdateRule(ctx context.Context, req *proto.UpdateRuleRequest) (*proto.UpdateRuleResponse, error) {
+	if req.Command == "" {
+		return nil, status.Error(codes.InvalidArgument, "command field is required")
+	}
+
+	rule := &store.NinjaRule{
+		Name:        req.Name,
+		Command:     req.Command,
+		Description: req.Description,
+	}
+
+	if err := rule.SetVariables(req.Variables); err != nil {
+		return nil, fmt.Errorf("failed to set variables: %w", err)
+	}
+
+	if err := s.store.UpdateRule(ctx, rule); err != nil {
+		return nil, storeError("failed to update rule", err)
+	}
+
+	// Re-validate builds referencing the rule against the new command
+	builds, err := s.store.GetBuildsByRule(ctx, req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get builds by rule: %w", err)
+	}
+
+	var warnings []string
+	for _, build := range builds {
+		buildVars, _ := build.GetVariables()
+		for _, name := range store.UndefinedVariables(req.Command, req.Variables, buildVars) {
+			warnings = append(warnings, fmt.Sprintf("build %s: undefined variable $%s", build.BuildID, name))
+		}
+	}
+
+	s.events.Publish(EventRuleUpdated, map[string]interface{}{"name": req.Name, "builds": len(builds)})
+
+	return &proto.UpdateRuleResponse{
+		Status:   "updated",
+		Name:     req.Name,
+		Builds:   int32(len(builds)),
+		Warnings: warnings,
+	}, nil
+}
+
+func (s *DistNinjaService) DeleteRule(ctx context.Context, req *proto.DeleteRuleRequest) (*proto.DeleteRuleResponse, error) {
+	if err := s.store.DeleteRule(ctx, req.Name, req.Force); err != nil {
+		return nil, storeError("failed to delete rule", err)
+	}
+
+	s.events.Publish(EventRuleDeleted, map[string]interface{}{"name": req.Name, "force": req.Force})
+
+	return &proto.DeleteRuleResponse{
+		Status: "deleted",
+		Name:   req.Name,
+	}, nil
+}
+
 // Target methods
 func (s *DistNinjaService) GetAllTargets(ctx context.Context, req *proto.GetAllTargetsRequest) (*proto.GetAllTargetsResponse, error) {
-	targets, err := s.store.GetAllTargets()
+	selector, err := store.ParseLabelSelector(req.Owner, req.Labels)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	targets, err := s.store.GetAllTargets(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all targets: %w", err)
 	}
 
 	var protoTargets []*proto.NinjaTarget
-	for _, target := range targets {
+	for _, target := range store.FilterTargets(targets, selector) {
 		protoTargets = append(protoTargets, &proto.NinjaTarget{
 			Id:     string(target.ID),
 			Type:   string(target.Type),
@@ -242,6 +410,8 @@ func (s *DistNinjaService) GetAllTargets(ctx context.Context, req *proto.GetAllT
 			Status: target.Status,
 			Hash:   target.Hash,
 			Build:  string(target.Build),
+			Owner:  target.Owner,
+			Labels: target.Labels,
 		})
 	}
 
@@ -251,7 +421,7 @@ func (s *DistNinjaService) GetAllTargets(ctx context.Context, req *proto.GetAllT
 }
 
 func (s *DistNinjaService) GetTarget(ctx context.Context, req *proto.GetTargetRequest) (*proto.NinjaTarget, error) {
-	target, err := s.store.GetTarget(req.Path)
+	target, err := s.store.GetTarget(ctx, req.Path)
 	if err != nil {
 		return nil, fmt.Errorf("target not found: %w", err)
 	}
@@ -263,11 +433,13 @@ func (s *DistNinjaService) GetTarget(ctx context.Context, req *proto.GetTargetRe
 		Status: target.Status,
 		Hash:   target.Hash,
 		Build:  string(target.Build),
+		Owner:  target.Owner,
+		Labels: target.Labels,
 	}, nil
 }
 
 func (s *DistNinjaService) GetTargetDependencies(ctx context.Context, req *proto.GetTargetDependenciesRequest) (*proto.GetTargetDependenciesResponse, error) {
-	dependencies, err := s.store.GetBuildDependencies(req.Path)
+	dependencies, err := s.store.GetBuildDependencies(ctx, req.Path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get target dependencies: %w", err)
 	}
@@ -288,7 +460,7 @@ func (s *DistNinjaService) GetTargetDependencies(ctx context.Context, req *proto
 }
 
 func (s *DistNinjaService) GetTargetReverseDependencies(ctx context.Context, req *proto.GetTargetReverseDependenciesRequest) (*proto.GetTargetReverseDependenciesResponse, error) {
-	reverseDeps, err := s.store.GetReverseDependencies(req.Path)
+	reverseDeps, err := s.store.GetReverseDependencies(ctx, req.Path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reverse dependencies: %w", err)
 	}
@@ -302,6 +474,8 @@ func (s *DistNinjaService) GetTargetReverseDependencies(ctx context.Context, req
 			Status: target.Status,
 			Hash:   target.Hash,
 			Build:  string(target.Build),
+			Owner:  target.Owner,
+			Labels: target.Labels,
 		})
 	}
 
@@ -310,28 +484,177 @@ func (s *DistNinjaService) GetTargetReverseDependencies(ctx context.Context, req
 	}, nil
 }
 
+func (s *DistNinjaService) TraverseDependencies(ctx context.Context, req *proto.TraverseDependenciesRequest) (*proto.TraverseDependenciesResponse, error) {
+	switch req.Direction {
+	case "", store.DirectionDependencies, store.DirectionDependents:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "direction must be %s or %s", store.DirectionDependencies, store.DirectionDependents)
+	}
+
+	if req.Depth < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "depth must be a non-negative integer")
+	}
+
+	nodes, err := s.store.TraverseDependencies(ctx, req.Path, store.TraversalOptions{
+		Direction:       req.Direction,
+		Depth:           int(req.Depth),
+		OrderOnly:       req.OrderOnly,
+		ImplicitOutputs: req.ImplicitOutputs,
+	})
+	if err != nil {
+		return nil, storeError("failed to traverse dependencies", err)
+	}
+
+	protoNodes := make([]*proto.DependencyNode, 0, len(nodes))
+	for _, node := range nodes {
+		protoNodes = append(protoNodes, &proto.DependencyNode{
+			Id:       node.ID,
+			Kind:     node.Kind,
+			Status:   node.Status,
+			Rule:     node.Rule,
+			FileType: node.FileType,
+			Depth:    int32(node.Depth),
+			Edge:     node.Edge,
+			From:     node.From,
+		})
+	}
+
+	return &proto.TraverseDependenciesResponse{Nodes: protoNodes}, nil
+}
+
 func (s *DistNinjaService) UpdateTargetStatus(ctx context.Context, req *proto.UpdateTargetStatusRequest) (*proto.UpdateTargetStatusResponse, error) {
 	if req.Status == "" {
 		return nil, fmt.Errorf("status field is required")
 	}
 
 	// Check if target exists
-	if _, err := s.store.GetTarget(req.Path); err != nil {
+	target, err := s.store.GetTarget(ctx, req.Path)
+	if err != nil {
 		return nil, fmt.Errorf("target not found: %w", err)
 	}
 
-	if err := s.store.UpdateTargetStatus(req.Path, req.Status); err != nil {
+	origin := &store.StatusOrigin{RunID: req.RunId, Worker: req.Worker, Message: req.Message}
+	if err := s.store.UpdateTargetStatus(ctx, req.Path, req.Status, origin); err != nil {
 		return nil, fmt.Errorf("failed to update target status: %w", err)
 	}
 
+	publishStatusChange(s.events, target, req.Status)
+
 	return &proto.UpdateTargetStatusResponse{
 		Status: "updated",
 	}, nil
 }
 
+func (s *DistNinjaService) BulkUpdateTargetStatus(ctx context.Context, req *proto.BulkUpdateTargetStatusRequest) (*proto.BulkUpdateTargetStatusResponse, error) {
+	response := &proto.BulkUpdateTargetStatusResponse{
+		Results: make([]*proto.TargetStatusResult, 0, len(req.Updates)),
+	}
+
+	for _, update := range req.Updates {
+		result := &proto.TargetStatusResult{Path: update.Path, Status: update.Status}
+		response.Results = append(response.Results, result)
+
+		if update.Status == "" {
+			result.Error = "status field is required"
+			continue
+		}
+
+		target, err := s.store.GetTarget(ctx, update.Path)
+		if err != nil {
+			result.Error = fmt.Sprintf("target not found: %v", err)
+			continue
+		}
+
+		origin := &store.StatusOrigin{RunID: update.RunId, Worker: update.Worker, Message: update.Message}
+		if err := s.store.UpdateTargetStatus(ctx, update.Path, update.Status, origin); err != nil {
+			result.Error = fmt.Sprintf("failed to update target status: %v", err)
+			continue
+		}
+
+		publishStatusChange(s.events, target, update.Status)
+		response.Updated++
+	}
+
+	return response, nil
+}
+
+func (s *DistNinjaService) GetTargetHistory(ctx context.Context, req *proto.GetTargetHistoryRequest) (*proto.GetTargetHistoryResponse, error) {
+	history, err := s.store.GetTargetHistory(ctx, req.Path, req.Status, int(req.Limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target history: %w", err)
+	}
+
+	response := &proto.GetTargetHistoryResponse{
+		Changes: make([]*proto.TargetStatusChange, 0, len(history)),
+	}
+
+	for _, change := range history {
+		response.Changes = append(response.Changes, &proto.TargetStatusChange{
+			Target:         change.Target,
+			Status:         change.Status,
+			PreviousStatus: change.PreviousStatus,
+			Time:           change.Time,
+			RunId:          change.RunID,
+			Worker:         change.Worker,
+			Message:        change.Message,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *DistNinjaService) DeleteTarget(ctx context.Context, req *proto.DeleteTargetRequest) (*proto.DeleteTargetResponse, error) {
+	if err := s.store.DeleteTarget(ctx, req.Path); err != nil {
+		return nil, storeError("failed to delete target", err)
+	}
+
+	s.events.Publish(EventTargetDeleted, map[string]string{"path": req.Path})
+
+	return &proto.DeleteTargetResponse{
+		Status: "deleted",
+		Path:   req.Path,
+	}, nil
+}
+
+func (s *DistNinjaService) MoveTarget(ctx context.Context, req *proto.MoveTargetRequest) (*proto.MoveTargetResponse, error) {
+	result, err := s.store.MoveTarget(ctx, req.Path, req.NewPath)
+	if err != nil {
+		return nil, storeError("failed to move target", err)
+	}
+
+	s.events.Publish(EventTargetMoved, result)
+
+	return &proto.MoveTargetResponse{
+		Status:       "moved",
+		Path:         result.From,
+		NewPath:      result.To,
+		BuildId:      result.Build,
+		FileMoved:    result.File,
+		HistoryMoved: int32(result.History),
+	}, nil
+}
+
+func (s *DistNinjaService) SetTargetLabels(ctx context.Context, req *proto.SetTargetLabelsRequest) (*proto.NinjaTarget, error) {
+	target, err := s.store.SetTargetLabels(ctx, req.Path, &store.NodeLabels{Owner: req.Owner, Labels: req.Labels})
+	if err != nil {
+		return nil, storeError("failed to set target labels", err)
+	}
+
+	return &proto.NinjaTarget{
+		Id:     string(target.ID),
+		Type:   string(target.Type),
+		Path:   target.Path,
+		Status: target.Status,
+		Hash:   target.Hash,
+		Build:  string(target.Build),
+		Owner:  target.Owner,
+		Labels: target.Labels,
+	}, nil
+}
+
 // Analysis methods
 func (s *DistNinjaService) FindCycles(ctx context.Context, req *proto.FindCyclesRequest) (*proto.FindCyclesResponse, error) {
-	cycles, err := s.store.FindCycles()
+	cycles, err := s.store.FindCycles(ctx, req.GetFirstOnly())
 	if err != nil {
 		return nil, fmt.Errorf("failed to find cycles: %w", err)
 	}
@@ -349,21 +672,67 @@ func (s *DistNinjaService) FindCycles(ctx context.Context, req *proto.FindCycles
 	}, nil
 }
 
+// Query methods
+func (s *DistNinjaService) GizmoQuery(ctx context.Context, req *proto.GizmoQueryRequest) (*proto.GizmoQueryResponse, error) {
+	if req.Query == "" {
+		return nil, fmt.Errorf("query field is required")
+	}
+
+	limit, timeout := queryLimits(int64(req.Limit), int64(req.TimeoutSeconds))
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := s.store.RunGizmo(ctx, req.Query, limit)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, status.Errorf(codes.DeadlineExceeded, "query timed out after %s", timeout)
+		}
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	results := make([]string, 0, len(result.Results))
+	for _, value := range result.Results {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode query result: %w", err)
+		}
+		results = append(results, string(encoded))
+	}
+
+	return &proto.GizmoQueryResponse{
+		Results:   results,
+		Truncated: result.Truncated,
+	}, nil
+}
+
 // Debug methods
 func (s *DistNinjaService) DebugQuads(ctx context.Context, req *proto.DebugQuadsRequest) (*proto.DebugQuadsResponse, error) {
-	// Call the debug function which prints to stdout
-	if err := s.store.DebugQuads(); err != nil {
-		return nil, fmt.Errorf("failed to debug quads: %w", err)
+	if req.Offset < 0 {
+		return nil, fmt.Errorf("offset must not be negative")
+	}
+
+	page, err := s.store.GetQuads(ctx, req.Subject, debugQuadsLimit(int64(req.Limit)), int(req.Offset))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quads: %w", err)
 	}
 
-	limit := req.Limit
-	if limit <= 0 {
-		limit = 100
+	quads := make([]*proto.Quad, 0, len(page.Quads))
+	for _, q := range page.Quads {
+		quads = append(quads, &proto.Quad{
+			Subject:   q.Subject,
+			Predicate: q.Predicate,
+			Object:    q.Object,
+			Label:     q.Label,
+		})
 	}
 
 	return &proto.DebugQuadsResponse{
-		Message: "Debug endpoint - check server logs for quad dump",
-		Limit:   limit,
+		Message: fmt.Sprintf("%d quads", len(quads)),
+		Limit:   int32(page.Limit),
+		Quads:   quads,
+		Offset:  int32(page.Offset),
+		HasMore: page.HasMore,
 	}, nil
 }
 
@@ -391,23 +760,283 @@ func (s *DistNinjaService) LoadNinjaFile(ctx context.Context, req *proto.LoadNin
 	}
 
 	// Parse and load the Ninja file
-	ninjaParser := parser.NewNinjaParser(s.store)
-	err = ninjaParser.ParseAndLoad(content)
+	ninjaParser := newNinjaParser(s.store)
+	err = ninjaParser.ParseAndLoad(ctx, content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse and load Ninja file: %w", err)
 	}
 
 	// Get statistics after loading
-	stats, err := s.store.GetBuildStats()
+	stats, err := s.store.GetBuildStats(ctx)
+	if err != nil {
+		// Log the error but don't fail the request
+		slog.WarnContext(ctx, "failed to get build stats", "error", err)
+		stats = map[string]interface{}{"error": "stats unavailable"}
+	}
+
+	buildTime := time.Since(startTime)
+
+	s.events.Publish(EventLoadCompleted, map[string]interface{}{
+		"file_path":  req.FilePath,
+		"stats":      stats,
+		"build_time": buildTime.String(),
+	})
+
+	return &proto.LoadNinjaFileResponse{
+		Status:    "success",
+		Message:   "Ninja file loaded successfully",
+		Stats:     protoBuildStats(stats),
+		BuildTime: buildTime.String(),
+	}, nil
+}
+
+// LoadNinjaFileStream loads ninja files sent in chunks, so files of any size fit in the gRPC message
+// limit, and reports progress while they are parsed
+func (s *DistNinjaService) LoadNinjaFileStream(stream proto.DistNinjaService_LoadNinjaFileStreamServer) error {
+	startTime := time.Now()
+	ctx := stream.Context()
+
+	ninjaParser := newNinjaParser(s.store)
+
+	var current *streamedFile
+	var fileNames []string
+	var received, reported, filesLoaded int64
+
+	progress := func() error {
+		reported = received
+		return stream.Send(&proto.LoadNinjaFileProgress{
+			FileName:      current.name,
+			BytesReceived: received,
+			FilesLoaded:   filesLoaded,
+		})
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if current != nil {
+				current.abort(err)
+			}
+			return err
+		}
+
+		if current == nil || (chunk.FileName != "" && chunk.FileName != current.name) {
+			if current != nil {
+				if err := current.finish(); err != nil {
+					return status.Errorf(codes.InvalidArgument, "failed to parse and load Ninja file %s: %v", current.name, err)
+				}
+				filesLoaded++
+				if err := progress(); err != nil {
+					return err
+				}
+			}
+
+			current = newStreamedFile(ctx, ninjaParser, chunk.FileName)
+			fileNames = append(fileNames, chunk.FileName)
+		}
+
+		if _, err := current.writer.Write(chunk.Content); err != nil {
+			// The parser stopped early, finish returns its error
+			if err := current.finish(); err != nil {
+				return status.Errorf(codes.InvalidArgument, "failed to parse and load Ninja file %s: %v", current.name, err)
+			}
+			return status.Errorf(codes.Internal, "failed to load Ninja file %s: parser stopped reading", current.name)
+		}
+
+		received += int64(len(chunk.Content))
+		if received-reported >= loadProgressInterval {
+			if err := progress(); err != nil {
+				current.abort(err)
+				return err
+			}
+		}
+	}
+
+	if current == nil {
+		return status.Error(codes.InvalidArgument, "no content received")
+	}
+
+	if err := current.finish(); err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to parse and load Ninja file %s: %v", current.name, err)
+	}
+	filesLoaded++
+
+	stats, err := s.store.GetBuildStats(ctx)
 	if err != nil {
 		// Log the error but don't fail the request
-		fmt.Printf("Warning: Failed to get build stats: %v\n", err)
+		slog.WarnContext(ctx, "failed to get build stats", "error", err)
 		stats = map[string]interface{}{"error": "stats unavailable"}
 	}
 
 	buildTime := time.Since(startTime)
 
-	// Convert stats to protobuf format
+	s.events.Publish(EventLoadCompleted, map[string]interface{}{
+		"file_path":  strings.Join(fileNames, ","),
+		"stats":      stats,
+		"build_time": buildTime.String(),
+	})
+
+	return stream.Send(&proto.LoadNinjaFileProgress{
+		FileName:      current.name,
+		BytesReceived: received,
+		FilesLoaded:   filesLoaded,
+		Result: &proto.LoadNinjaFileResponse{
+			Status:    "success",
+			Message:   "Ninja file loaded successfully",
+			Stats:     protoBuildStats(stats),
+			BuildTime: buildTime.String(),
+		},
+	})
+}
+
+// loadProgressInterval is how many bytes LoadNinjaFileStream receives between progress messages
+const loadProgressInterval = 4 << 20
+
+// streamedFile feeds the chunks of one file to a parser running in the background
+type streamedFile struct {
+	name   string
+	writer *io.PipeWriter
+	done   chan error
+}
+
+func newStreamedFile(ctx context.Context, ninjaParser *parser.NinjaParser, name string) *streamedFile {
+	reader, writer := io.Pipe()
+
+	file := &streamedFile{
+		name:   name,
+		writer: writer,
+		done:   make(chan error, 1),
+	}
+
+	go func() {
+		err := ninjaParser.ParseAndLoadReader(ctx, reader)
+		// Unblocks the writer if the parser returned before the end of the file
+		_ = reader.CloseWithError(err)
+		file.done <- err
+	}()
+
+	return file
+}
+
+// finish ends the file and waits for the parser
+func (f *streamedFile) finish() error {
+	_ = f.writer.Close()
+	return <-f.done
+}
+
+// abort stops the parser with err
+func (f *streamedFile) abort(err error) {
+	_ = f.writer.CloseWithError(err)
+	<-f.done
+}
+
+// Streaming methods
+func (s *DistNinjaService) StreamTargets(req *proto.StreamTargetsRequest, stream proto.DistNinjaService_StreamTargetsServer) error {
+	selector, err := store.ParseLabelSelector(req.Owner, req.Labels)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Send blocks while the client's flow control window is full, so the store is read at the client's pace
+	err = s.store.EachTarget(stream.Context(), req.RuleName, req.Status, func(target *store.NinjaTarget) error {
+		if !selector.MatchesTarget(target) {
+			return nil
+		}
+		return stream.Send(&proto.NinjaTarget{
+			Id:     string(target.ID),
+			Type:   string(target.Type),
+			Path:   target.Path,
+			Status: target.Status,
+			Hash:   target.Hash,
+			Build:  string(target.Build),
+			Owner:  target.Owner,
+			Labels: target.Labels,
+		})
+	})
+	if err != nil {
+		return streamError("failed to stream targets", err)
+	}
+
+	return nil
+}
+
+func (s *DistNinjaService) StreamQuads(req *proto.StreamQuadsRequest, stream proto.DistNinjaService_StreamQuadsServer) error {
+	err := s.store.EachQuad(stream.Context(), req.Subject, func(q *store.QuadRecord) error {
+		return stream.Send(&proto.Quad{
+			Subject:   q.Subject,
+			Predicate: q.Predicate,
+			Object:    q.Object,
+			Label:     q.Label,
+		})
+	})
+	if err != nil {
+		return streamError("failed to stream quads", err)
+	}
+
+	return nil
+}
+
+// watchBufferSize is how many events a slow WatchTargets client may fall behind before events are dropped
+const watchBufferSize = 256
+
+// WatchTargets sends target status changes matching the filter until the client disconnects
+func (s *DistNinjaService) WatchTargets(req *proto.WatchTargetsRequest, stream proto.DistNinjaService_WatchTargetsServer) error {
+	paths := make(map[string]bool, len(req.Paths))
+	for _, path := range req.Paths {
+		paths[path] = true
+	}
+
+	statuses := make(map[string]bool, len(req.Statuses))
+	for _, targetStatus := range req.Statuses {
+		statuses[targetStatus] = true
+	}
+
+	events, unsubscribe := s.events.Subscribe(watchBufferSize, EventTargetStatusChanged)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "server is shutting down")
+			}
+
+			data, ok := event.Data.(map[string]string)
+			if !ok {
+				continue
+			}
+
+			if len(paths) > 0 && !paths[data["path"]] {
+				continue
+			}
+			if !strings.HasPrefix(data["path"], req.PathPrefix) {
+				continue
+			}
+			if len(statuses) > 0 && !statuses[data["status"]] {
+				continue
+			}
+
+			if err := stream.Send(&proto.TargetEvent{
+				Id:             event.ID,
+				Path:           data["path"],
+				Status:         data["status"],
+				PreviousStatus: data["previous_status"],
+				Hash:           data["hash"],
+				Time:           event.Time.Format(time.RFC3339Nano),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// protoBuildStats converts build statistics to their protobuf form
+func protoBuildStats(stats map[string]interface{}) map[string]int64 {
 	protoStats := make(map[string]int64)
 	for k, v := range stats {
 		if intVal, ok := v.(int); ok {
@@ -417,12 +1046,36 @@ func (s *DistNinjaService) LoadNinjaFile(ctx context.Context, req *proto.LoadNin
 		}
 	}
 
-	return &proto.LoadNinjaFileResponse{
-		Status:    "success",
-		Message:   "Ninja file loaded successfully",
-		Stats:     protoStats,
-		BuildTime: buildTime.String(),
-	}, nil
+	return protoStats
+}
+
+// storeError maps store errors to gRPC status codes
+func storeError(message string, err error) error {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return status.Errorf(codes.NotFound, "%s: %v", message, err)
+	case errors.Is(err, store.ErrRuleInUse):
+		return status.Errorf(codes.FailedPrecondition, "%s, retry with force: %v", message, err)
+	case errors.Is(err, store.ErrExists):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", message, err)
+	case errors.Is(err, store.ErrInvalidLabel):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", message, err)
+	default:
+		return fmt.Errorf("%s: %w", message, err)
+	}
+}
+
+// streamError keeps the status of errors returned by Send and of cancelled streams
+func streamError(message string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	return fmt.Errorf("%s: %w", message, err)
 }
 
 func loggingInterceptor(
@@ -431,12 +1084,56 @@ func loggingInterceptor(
 	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
-	fmt.Printf("gRPC request: %s\n", info.FullMethod)
+	start := time.Now()
 
 	resp, err := handler(ctx, req)
+	countGRPCRequest(status.Code(err))
+
+	attrs := []any{
+		"method", info.FullMethod,
+		"code", status.Code(err).String(),
+		"duration", time.Since(start),
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		attrs = append(attrs, "remote", p.Addr.String())
+	}
+
 	if err != nil {
-		fmt.Printf("gRPC error: %v\n", err)
+		slog.ErrorContext(ctx, "grpc request", append(attrs, "error", err)...)
+	} else {
+		slog.InfoContext(ctx, "grpc request", attrs...)
 	}
 
 	return resp, err
 }
+
+func streamLoggingInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+
+	err := handler(srv, ss)
+	countGRPCRequest(status.Code(err))
+
+	attrs := []any{
+		"method", info.FullMethod,
+		"code", status.Code(err).String(),
+		"duration", time.Since(start),
+	}
+
+	if p, ok := peer.FromContext(ss.Context()); ok {
+		attrs = append(attrs, "remote", p.Addr.String())
+	}
+
+	if err != nil {
+		slog.ErrorContext(ss.Context(), "grpc stream", append(attrs, "error", err)...)
+	} else {
+		slog.InfoContext(ss.Context(), "grpc stream", attrs...)
+	}
+
+	return err
+}
@@ -1,15 +1,20 @@
 package server
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	_errors "errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
 	"net/http"
 	"os"
-	"os/signal"
+	"slices"
 	"strconv"
-	"syscall"
+	"strings"
 	"time"
 
 	"github.com/cayleygraph/quad"
@@ -24,10 +29,28 @@ const (
 	httpIdleTimeout  = 60 * time.Second
 	httpReadTimeout  = 15 * time.Second
 	httpWriteTimeout = 15 * time.Second
+	httpLoadTimeout  = 30 * time.Minute
+)
+
+// Gizmo query limits
+const (
+	queryDefaultLimit   = 100
+	queryMaxLimit       = 1000
+	queryDefaultTimeout = 5 * time.Second
+	queryMaxTimeout     = 10 * time.Second
+)
+
+// Debug quads page sizes
+const (
+	debugQuadsDefaultLimit = 100
+	debugQuadsMaxLimit     = 10000
 )
 
 var (
 	ninjaStore *store.NinjaStore
+	eventBus   = NewEventBus()
+	webhooks   *WebhookDispatcher
+	scheduler  *Scheduler
 )
 
 type HealthResponse struct {
@@ -38,6 +61,8 @@ type HealthResponse struct {
 type ErrorResponse struct {
 	Error string `json:"error"`
 	Code  int    `json:"code"`
+	// Reason is the machine readable reason of the error, e.g. not_found or rule_in_use
+	Reason string `json:"reason"`
 }
 
 type LoadNinjaRequest struct {
@@ -52,44 +77,145 @@ type LoadNinjaResponse struct {
 	BuildTime string                 `json:"build_time"`
 }
 
-func StartHTTPServer(ctx context.Context, address, _store string) error {
-	var err error
+type CreateBuildRequest struct {
+	BuildID      string            `json:"build_id"`
+	Rule         string            `json:"rule"`
+	Variables    map[string]string `json:"variables,omitempty"`
+	Pool         string            `json:"pool,omitempty"`
+	Inputs       []string          `json:"inputs"`
+	Outputs      []string          `json:"outputs"`
+	ImplicitDeps []string          `json:"implicit_deps,omitempty"`
+	OrderDeps    []string          `json:"order_deps,omitempty"`
+	Owner        string            `json:"owner,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
 
-	ninjaStore, err = store.NewNinjaStore(_store)
-	if err != nil {
-		return errors.Wrap(err, "failed to open ninja store\n")
-	}
+type CreateRuleRequest struct {
+	Name        string            `json:"name"`
+	Command     string            `json:"command"`
+	Description string            `json:"description,omitempty"`
+	Variables   map[string]string `json:"variables,omitempty"`
+}
+
+type GizmoQueryRequest struct {
+	Query          string `json:"query"`
+	Limit          int    `json:"limit,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+}
+
+type BatchItemResult struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Status  string            `json:"status"`
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Results []BatchItemResult `json:"results"`
+}
+
+// StartHTTPServer serves the HTTP API until interrupted
+func StartHTTPServer(ctx context.Context, address, _store string, opts Options) error {
+	return Serve(ctx, "", address, _store, opts)
+}
 
+// newHTTPServer creates the HTTP API server, handlers use the package level store
+func newHTTPServer(address string, opts *Options, addressLimiter, limiter *rateLimiter) (*http.Server, error) {
 	router := mux.NewRouter()
 
 	// Admin endpoints
 	router.HandleFunc("/health", healthHandler).Methods("GET")
 	v1 := router.PathPrefix("/api/v1").Subrouter()
 	v1.HandleFunc("/status", statusHandler).Methods("GET")
+	v1.HandleFunc("/admin/reset", resetStoreHandler).Methods("POST")
+	v1.HandleFunc("/admin/reset", optionsHandler).Methods("OPTIONS")
+	v1.HandleFunc("/admin/recount", recountStatsHandler).Methods("POST")
+	v1.HandleFunc("/admin/gc", collectGarbageHandler).Methods("POST")
+	v1.HandleFunc("/admin/compact", compactStoreHandler).Methods("POST")
+	v1.HandleFunc("/admin/snapshot", snapshotHandler(opts.SnapshotDir)).Methods("POST")
 
 	// Build endpoints
 	v1.HandleFunc("/builds", createBuildHandler).Methods("POST")
 	v1.HandleFunc("/builds", optionsHandler).Methods("OPTIONS")
+	v1.HandleFunc("/builds:batch", createBuildsBatchHandler).Methods("POST")
+	v1.HandleFunc("/builds:batch", optionsHandler).Methods("OPTIONS")
 	v1.HandleFunc("/builds/stats", getBuildStatsHandler).Methods("GET")
 	v1.HandleFunc("/builds/order", getBuildOrderHandler).Methods("GET")
+	v1.HandleFunc("/builds/plan", getBuildPlanHandler).Methods("GET")
+	v1.HandleFunc("/builds/{id:.*}/labels", setBuildLabelsHandler).Methods("PUT")
+	v1.HandleFunc("/builds/{id:.*}/labels", optionsHandler).Methods("OPTIONS")
 	v1.HandleFunc("/builds/{id}", getBuildHandler).Methods("GET")
 
 	// Rule endpoints
 	v1.HandleFunc("/rules", createRuleHandler).Methods("POST")
 	v1.HandleFunc("/rules", optionsHandler).Methods("OPTIONS")
+	v1.HandleFunc("/rules:batch", createRulesBatchHandler).Methods("POST")
+	v1.HandleFunc("/rules:batch", optionsHandler).Methods("OPTIONS")
 	v1.HandleFunc("/rules/{name}/targets", getTargetsByRuleHandler).Methods("GET")
 	v1.HandleFunc("/rules/{name}", getRuleHandler).Methods("GET")
+	v1.HandleFunc("/rules/{name}", updateRuleHandler).Methods("PUT")
+	v1.HandleFunc("/rules/{name}", deleteRuleHandler).Methods("DELETE")
+	v1.HandleFunc("/rules/{name}", optionsHandler).Methods("OPTIONS")
 
 	// Target endpoints
 	v1.HandleFunc("/targets", getAllTargetsHandler).Methods("GET")
+	v1.HandleFunc("/targets/search", searchTargetsHandler).Methods("GET")
 	v1.HandleFunc("/targets/{path:.*}/dependencies", getTargetDependenciesHandler).Methods("GET")
 	v1.HandleFunc("/targets/{path:.*}/reverse_dependencies", getTargetReverseDependenciesHandler).Methods("GET")
+	v1.HandleFunc("/targets/{path:.*}/traverse", traverseDependenciesHandler).Methods("GET")
 	v1.HandleFunc("/targets/{path:.*}/status", updateTargetStatusHandler).Methods("PUT")
 	v1.HandleFunc("/targets/{path:.*}/status", optionsHandler).Methods("OPTIONS")
+	v1.HandleFunc("/targets/{path:.*}/history", getTargetHistoryHandler).Methods("GET")
+	v1.HandleFunc("/targets/{path:.*}/move", moveTargetHandler).Methods("POST")
+	v1.HandleFunc("/targets/{path:.*}/move", optionsHandler).Methods("OPTIONS")
+	v1.HandleFunc("/targets/{path:.*}/labels", setTargetLabelsHandler).Methods("PUT")
+	v1.HandleFunc("/targets/{path:.*}/labels", optionsHandler).Methods("OPTIONS")
 	v1.HandleFunc("/targets/{path:.*}", getTargetHandler).Methods("GET")
 
+	// Role endpoints
+	v1.HandleFunc("/roles", listRolesHandler).Methods("GET")
+	v1.HandleFunc("/roles/{subject}", setRoleHandler).Methods("PUT")
+	v1.HandleFunc("/roles/{subject}", deleteRoleHandler).Methods("DELETE")
+	v1.HandleFunc("/roles/{subject}", optionsHandler).Methods("OPTIONS")
+
+	// Audit log
+	v1.HandleFunc("/audit", auditHandler).Methods("GET")
+
+	// Webhook endpoints
+	v1.HandleFunc("/webhooks", createWebhookHandler).Methods("POST")
+	v1.HandleFunc("/webhooks", listWebhooksHandler).Methods("GET")
+	v1.HandleFunc("/webhooks", optionsHandler).Methods("OPTIONS")
+	v1.HandleFunc("/webhooks/{id}/deliveries", getWebhookDeliveriesHandler).Methods("GET")
+	v1.HandleFunc("/webhooks/{id}", getWebhookHandler).Methods("GET")
+	v1.HandleFunc("/webhooks/{id}", deleteWebhookHandler).Methods("DELETE")
+	v1.HandleFunc("/webhooks/{id}", optionsHandler).Methods("OPTIONS")
+
+	// Schedule routes
+	v1.HandleFunc("/schedules", createScheduleHandler).Methods("POST")
+	v1.HandleFunc("/schedules", listSchedulesHandler).Methods("GET")
+	v1.HandleFunc("/schedules", optionsHandler).Methods("OPTIONS")
+	v1.HandleFunc("/schedules/{id}", getScheduleHandler).Methods("GET")
+	v1.HandleFunc("/schedules/{id}", deleteScheduleHandler).Methods("DELETE")
+	v1.HandleFunc("/schedules/{id}", optionsHandler).Methods("OPTIONS")
+
+	// Graph export
+	v1.HandleFunc("/graph", exportGraphHandler).Methods("GET")
+
 	// Analysis endpoints
 	v1.HandleFunc("/analysis/cycles", findCyclesHandler).Methods("GET")
+	v1.HandleFunc("/analysis/impact", impactHandler).Methods("GET")
+	v1.HandleFunc("/analysis/path", dependencyPathHandler).Methods("GET")
+	v1.HandleFunc("/analysis/report", graphReportHandler).Methods("GET")
+	v1.HandleFunc("/analysis/shape", graphShapeHandler).Methods("GET")
+	v1.HandleFunc("/analysis/changed", changedHandler(opts.Workspace)).Methods("POST")
+
+	// Git webhook trigger, authenticated by the webhook signature
+	if opts.TriggerSecret != "" {
+		v1.HandleFunc(strings.TrimPrefix(triggerPath, "/api/v1"), triggerHandler(opts.TriggerSecret, opts.Workspace)).Methods("POST")
+	}
 
 	// Debug endpoints
 	v1.HandleFunc("/debug/quads", debugQuadsHandler).Methods("GET")
@@ -98,89 +224,132 @@ func StartHTTPServer(ctx context.Context, address, _store string) error {
 	v1.HandleFunc("/load", loadNinjaFileHandler).Methods("POST")
 	v1.HandleFunc("/load", optionsHandler).Methods("OPTIONS")
 
-	router.Use(corsMiddleware)
+	// Query API
+	v1.HandleFunc("/query/gizmo", gizmoQueryHandler).Methods("POST")
+	v1.HandleFunc("/query/gizmo", optionsHandler).Methods("OPTIONS")
+
+	// GraphQL API
+	v1.HandleFunc("/graphql", graphQLHandler).Methods("GET", "POST")
+	v1.HandleFunc("/graphql", optionsHandler).Methods("OPTIONS")
+
+	// Live events
+	v1.HandleFunc("/ws", eventsWebSocketHandler(opts.CORSOrigins)).Methods("GET")
+
+	// API documentation
+	v1.HandleFunc("/openapi.json", openAPIHandler).Methods("GET")
+	v1.HandleFunc("/docs", swaggerUIHandler).Methods("GET")
+
+	router.Use(loggingMiddleware)
+	router.Use(corsMiddleware(opts.CORSOrigins))
+	router.Use(rateLimitMiddleware(addressLimiter))
+	router.Use(storeGateMiddleware)
+	router.Use(authMiddleware(&opts.Auth))
+	router.Use(rateLimitMiddleware(limiter))
+	router.Use(bodyLimitMiddleware(&opts.Limits))
+	router.Use(timeoutMiddleware)
+	router.Use(auditMiddleware)
+	router.Use(idempotencyMiddleware(newIdempotencyCache()))
 
 	server := &http.Server{
 		Addr:         address,
-		Handler:      router,
+		Handler:      requestIDMiddleware(apiVersionMiddleware(router)),
 		ReadTimeout:  httpReadTimeout,
 		WriteTimeout: httpWriteTimeout,
 		IdleTimeout:  httpIdleTimeout,
 	}
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	serverErr := make(chan error, 1)
-
-	go func() {
-		if err := server.ListenAndServe(); err != nil {
-			serverErr <- err
-		}
-	}()
-
-	select {
-	case <-ctx.Done():
-	case <-quit:
-	case err := <-serverErr:
-		if !_errors.Is(err, http.ErrServerClosed) {
-			fmt.Printf("HTTP server error: %v\n", err)
+	if opts.TLS.Enabled() {
+		var err error
+		server.TLSConfig, err = serverTLSConfig(&opts.TLS)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to configure tls\n")
 		}
 	}
 
-	_ = server.Shutdown(ctx)
-
-	return nil
+	return server, nil
 }
 
 func loadNinjaFileHandler(w http.ResponseWriter, r *http.Request) {
 	startTime := time.Now()
 
-	var req LoadNinjaRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, "Invalid JSON request", http.StatusBadRequest)
-		return
-	}
-
-	// Check if neither file_path nor content field were provided
-	if req.FilePath == "" && req.Content == nil {
-		writeError(w, "Either file_path or content must be provided", http.StatusBadRequest)
-		return
-	}
+	ninjaParser := newNinjaParser(ninjaStore)
 
-	var content string
+	var filePath string
 	var err error
 
-	// Read file content if file_path is provided
-	if req.FilePath != "" {
-		contentBytes, err := os.ReadFile(req.FilePath)
-		if err != nil {
-			writeError(w, fmt.Sprintf("Failed to read file %s: %v", req.FilePath, err), http.StatusBadRequest)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "multipart/form-data":
+		filePath, err = loadMultipart(r, ninjaParser)
+		if _errors.Is(err, errMissingFilePart) {
+			writeError(w, "Multipart upload requires a file field", http.StatusBadRequest)
+			return
+		}
+	case "text/plain", "application/octet-stream":
+		// Raw body, possibly sent with chunked transfer encoding
+		err = ninjaParser.ParseAndLoadReader(r.Context(), r.Body)
+	default:
+		var req LoadNinjaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			if isBodyTooLarge(err) {
+				writeError(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			writeError(w, "Invalid JSON request", http.StatusBadRequest)
+			return
+		}
+
+		// Check if neither file_path nor content field were provided
+		if req.FilePath == "" && req.Content == nil {
+			writeError(w, "Either file_path or content must be provided", http.StatusBadRequest)
 			return
 		}
-		content = string(contentBytes)
-	} else if req.Content != nil {
-		content = *req.Content
+
+		// Read file content if file_path is provided
+		if req.FilePath != "" {
+			file, openErr := os.Open(req.FilePath)
+			if openErr != nil {
+				writeError(w, fmt.Sprintf("Failed to read file %s: %v", req.FilePath, openErr), http.StatusBadRequest)
+				return
+			}
+			defer func(file *os.File) {
+				_ = file.Close()
+			}(file)
+
+			filePath = req.FilePath
+			err = ninjaParser.ParseAndLoadReader(r.Context(), file)
+		} else {
+			err = ninjaParser.ParseAndLoad(r.Context(), *req.Content)
+		}
+	}
+
+	if isBodyTooLarge(err) {
+		writeError(w, "Ninja file too large", http.StatusRequestEntityTooLarge)
+		return
 	}
 
-	// Use the shared parser
-	ninjaParser := parser.NewNinjaParser(ninjaStore)
-	err = ninjaParser.ParseAndLoad(content)
 	if err != nil {
 		writeError(w, fmt.Sprintf("Failed to parse and load Ninja file: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	// Get statistics after loading
-	stats, err := ninjaStore.GetBuildStats()
+	stats, err := ninjaStore.GetBuildStats(r.Context())
 	if err != nil {
 		// Log the error but don't fail the request
-		fmt.Printf("Warning: Failed to get build stats: %v\n", err)
+		slog.WarnContext(r.Context(), "failed to get build stats", "error", err)
 		stats = map[string]interface{}{"error": "stats unavailable"}
 	}
 
 	buildTime := time.Since(startTime)
 
+	eventBus.Publish(EventLoadCompleted, map[string]interface{}{
+		"file_path":  filePath,
+		"stats":      stats,
+		"build_time": buildTime.String(),
+	})
+
 	response := LoadNinjaResponse{
 		Status:    "success",
 		Message:   "Ninja file loaded successfully",
@@ -193,6 +362,36 @@ func loadNinjaFileHandler(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(response)
 }
 
+var errMissingFilePart = _errors.New("missing file part")
+
+// loadMultipart streams the "file" part of a multipart upload into the parser without buffering it
+func loadMultipart(r *http.Request, ninjaParser *parser.NinjaParser) (string, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return "", errMissingFilePart
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if part.FormName() != "file" {
+			_ = part.Close()
+			continue
+		}
+
+		err = ninjaParser.ParseAndLoadReader(r.Context(), part)
+		_ = part.Close()
+
+		return part.FileName(), err
+	}
+}
+
 func healthHandler(w http.ResponseWriter, r *http.Request) {
 	response := HealthResponse{
 		Status:    "healthy",
@@ -206,9 +405,10 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func statusHandler(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
-		"service": "distninja",
-		"uptime":  time.Since(time.Now()).String(),
+	response, err := collectStatus(r.Context(), ninjaStore)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to get status: %v", err), http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -218,16 +418,7 @@ func statusHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func createBuildHandler(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		BuildID      string            `json:"build_id"`
-		Rule         string            `json:"rule"`
-		Variables    map[string]string `json:"variables,omitempty"`
-		Pool         string            `json:"pool,omitempty"`
-		Inputs       []string          `json:"inputs"`
-		Outputs      []string          `json:"outputs"`
-		ImplicitDeps []string          `json:"implicit_deps,omitempty"`
-		OrderDeps    []string          `json:"order_deps,omitempty"`
-	}
+	var req CreateBuildRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, "Invalid JSON", http.StatusBadRequest)
@@ -238,6 +429,7 @@ func createBuildHandler(w http.ResponseWriter, r *http.Request) {
 		BuildID: req.BuildID,
 		Rule:    quad.IRI(fmt.Sprintf("rule:%s", req.Rule)),
 		Pool:    req.Pool,
+		Owner:   req.Owner,
 	}
 
 	if err := build.SetVariables(req.Variables); err != nil {
@@ -245,21 +437,77 @@ func createBuildHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := ninjaStore.AddBuild(build, req.Inputs, req.Outputs, req.ImplicitDeps, req.OrderDeps); err != nil {
+	if err := build.SetLabels(req.Labels); err != nil {
+		writeError(w, fmt.Sprintf("Failed to set labels: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := ninjaStore.AddBuild(r.Context(), build, req.Inputs, req.Outputs, req.ImplicitDeps, req.OrderDeps); err != nil {
 		writeError(w, fmt.Sprintf("Failed to create build: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	eventBus.Publish(EventBuildCreated, buildEventData(&req))
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	_ = json.NewEncoder(w).Encode(map[string]string{"status": "created", "build_id": req.BuildID})
 }
 
+func createBuildsBatchHandler(w http.ResponseWriter, r *http.Request) {
+	var reqs []CreateBuildRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		writeError(w, "Invalid JSON, expected an array of builds", http.StatusBadRequest)
+		return
+	}
+
+	specs := make([]*store.BuildSpec, len(reqs))
+	for i, req := range reqs {
+		build := &store.NinjaBuild{
+			BuildID: req.BuildID,
+			Rule:    quad.IRI(fmt.Sprintf("rule:%s", req.Rule)),
+			Pool:    req.Pool,
+			Owner:   req.Owner,
+		}
+		_ = build.SetVariables(req.Variables)
+
+		if err := build.SetLabels(req.Labels); err != nil {
+			writeError(w, fmt.Sprintf("Failed to set labels of build %d: %v", i, err), http.StatusBadRequest)
+			return
+		}
+
+		specs[i] = &store.BuildSpec{
+			Build:        build,
+			Inputs:       req.Inputs,
+			Outputs:      req.Outputs,
+			ImplicitDeps: req.ImplicitDeps,
+			OrderDeps:    req.OrderDeps,
+		}
+	}
+
+	results, err := ninjaStore.AddBuilds(r.Context(), specs)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to create builds: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	ids := make([]string, len(reqs))
+	for i, req := range reqs {
+		ids[i] = req.BuildID
+		if results[i] == nil {
+			eventBus.Publish(EventBuildCreated, buildEventData(&reqs[i]))
+		}
+	}
+
+	writeBatchResponse(w, ids, results)
+}
+
 func getBuildHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	buildID := vars["id"]
 
-	build, err := ninjaStore.GetBuild(buildID)
+	build, err := ninjaStore.GetBuild(r.Context(), buildID)
 	if err != nil {
 		writeError(w, fmt.Sprintf("Build not found: %v", err), http.StatusNotFound)
 		return
@@ -269,150 +517,237 @@ func getBuildHandler(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(build)
 }
 
-func getBuildStatsHandler(w http.ResponseWriter, r *http.Request) {
-	stats, err := ninjaStore.GetBuildStats()
+func setBuildLabelsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	buildID := vars["id"]
+
+	var req store.NodeLabels
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	build, err := ninjaStore.SetBuildLabels(r.Context(), buildID, &req)
 	if err != nil {
-		writeError(w, fmt.Sprintf("Failed to get stats: %v", err), http.StatusInternalServerError)
+		switch {
+		case _errors.Is(err, store.ErrNotFound):
+			writeStoreError(w, fmt.Sprintf("Build not found: %v", err), err, http.StatusNotFound)
+		case _errors.Is(err, store.ErrInvalidLabel):
+			writeStoreError(w, err.Error(), err, http.StatusBadRequest)
+		default:
+			writeError(w, fmt.Sprintf("Failed to set labels: %v", err), http.StatusInternalServerError)
+		}
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(stats)
+	_ = json.NewEncoder(w).Encode(build)
 }
 
-func getBuildOrderHandler(w http.ResponseWriter, r *http.Request) {
-	order, err := ninjaStore.GetBuildOrder()
+func getBuildStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if notModified(w, r) {
+		return
+	}
+
+	stats, err := ninjaStore.GetBuildStats(r.Context())
 	if err != nil {
-		writeError(w, fmt.Sprintf("Failed to get build order: %v", err), http.StatusInternalServerError)
+		writeError(w, fmt.Sprintf("Failed to get stats: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string][]string{"build_order": order})
+	_ = json.NewEncoder(w).Encode(stats)
 }
 
-func createRuleHandler(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Name        string            `json:"name"`
-		Command     string            `json:"command"`
-		Description string            `json:"description,omitempty"`
-		Variables   map[string]string `json:"variables,omitempty"`
+// schedulingPolicy returns the policy named by ?policy=, critical_path weighs by the durations recorded
+// in the status history, as returned by durations
+func schedulingPolicy(r *http.Request, durations func(context.Context) (map[string]time.Duration, error)) (store.SchedulingPolicy, error) {
+	name := r.URL.Query().Get("policy")
+	if name != store.PolicyCriticalPath {
+		return store.NewSchedulingPolicy(name, nil)
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, "Invalid JSON", http.StatusBadRequest)
+	recorded, err := durations(r.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load build durations: %w", err)
+	}
+
+	return store.NewCriticalPathPolicy(recorded), nil
+}
+
+// writePolicyError writes the error of schedulingPolicy
+func writePolicyError(w http.ResponseWriter, err error) {
+	if _errors.Is(err, store.ErrUnknownPolicy) {
+		writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	rule := &store.NinjaRule{
-		Name:        req.Name,
-		Command:     req.Command,
-		Description: req.Description,
+	writeError(w, err.Error(), http.StatusInternalServerError)
+}
+
+func getBuildOrderHandler(w http.ResponseWriter, r *http.Request) {
+	if notModified(w, r) {
+		return
 	}
 
-	if err := rule.SetVariables(req.Variables); err != nil {
-		writeError(w, "Failed to set variables", http.StatusBadRequest)
+	policy, err := schedulingPolicy(r, ninjaStore.GetTargetDurations)
+	if err != nil {
+		writePolicyError(w, err)
 		return
 	}
 
-	_, err := ninjaStore.AddRule(rule)
+	order, err := ninjaStore.GetBuildOrderWithPolicy(r.Context(), policy)
 	if err != nil {
-		writeError(w, fmt.Sprintf("Failed to create rule: %v", err), http.StatusInternalServerError)
+		writeError(w, fmt.Sprintf("Failed to get build order: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(map[string]string{"status": "created", "name": req.Name})
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"build_order": order, "policy": policy.Name()})
 }
 
-func getRuleHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	ruleName := vars["name"]
+// getBuildPlanHandler packs the builds onto ?workers= workers offering ?cpu=, ?memory= and ?disk= each
+func getBuildPlanHandler(w http.ResponseWriter, r *http.Request) {
+	if notModified(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
 
-	rule, err := ninjaStore.GetRule(ruleName)
+	policy, err := schedulingPolicy(r, ninjaStore.GetBuildDurations)
 	if err != nil {
-		writeError(w, fmt.Sprintf("Rule not found: %v", err), http.StatusNotFound)
+		writePolicyError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(rule)
-}
+	workers := 1
+	if workersStr := query.Get("workers"); workersStr != "" {
+		if workers, err = strconv.Atoi(workersStr); err != nil || workers < 1 {
+			writeError(w, "Workers must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
 
-func getTargetsByRuleHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	ruleName := vars["name"]
+	capacity := store.Resources{CPU: 1}
+	if cpuStr := query.Get("cpu"); cpuStr != "" {
+		if capacity.CPU, err = strconv.ParseFloat(cpuStr, 64); err != nil || !(capacity.CPU > 0) {
+			writeError(w, "Cpu must be a positive number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	for name, field := range map[string]*int64{"memory": &capacity.MemoryBytes, "disk": &capacity.DiskBytes} {
+		if sizeStr := query.Get(name); sizeStr != "" {
+			if *field, err = store.ParseSize(sizeStr); err != nil {
+				writeError(w, fmt.Sprintf("Invalid %s: %v", name, err), http.StatusBadRequest)
+				return
+			}
+		}
+	}
 
-	targets, err := ninjaStore.GetTargetsByRule(ruleName)
+	plan, err := ninjaStore.GetBuildPlan(r.Context(), workers, capacity, policy)
 	if err != nil {
-		writeError(w, fmt.Sprintf("Failed to get targets by rule: %v", err), http.StatusInternalServerError)
+		if _errors.Is(err, store.ErrInvalidResources) {
+			writeStoreError(w, err.Error(), err, http.StatusUnprocessableEntity)
+			return
+		}
+		writeError(w, fmt.Sprintf("Failed to plan builds: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(targets)
+	_ = json.NewEncoder(w).Encode(plan)
 }
 
-func getAllTargetsHandler(w http.ResponseWriter, r *http.Request) {
-	targets, err := ninjaStore.GetAllTargets()
-	if err != nil {
-		writeError(w, fmt.Sprintf("Failed to get targets: %v", err), http.StatusInternalServerError)
+func createRuleHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateRuleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(targets)
-}
+	rule := &store.NinjaRule{
+		Name:        req.Name,
+		Command:     req.Command,
+		Description: req.Description,
+	}
 
-func getTargetHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	targetPath := vars["path"]
+	if err := rule.SetVariables(req.Variables); err != nil {
+		writeError(w, "Failed to set variables", http.StatusBadRequest)
+		return
+	}
 
-	target, err := ninjaStore.GetTarget(targetPath)
+	_, err := ninjaStore.AddRule(r.Context(), rule)
 	if err != nil {
-		writeError(w, fmt.Sprintf("Target not found: %v", err), http.StatusNotFound)
+		writeError(w, fmt.Sprintf("Failed to create rule: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	eventBus.Publish(EventRuleCreated, map[string]string{"name": req.Name})
+
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(target)
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "created", "name": req.Name})
 }
 
-func getTargetDependenciesHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	targetPath := vars["path"]
+func createRulesBatchHandler(w http.ResponseWriter, r *http.Request) {
+	var reqs []CreateRuleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		writeError(w, "Invalid JSON, expected an array of rules", http.StatusBadRequest)
+		return
+	}
+
+	rules := make([]*store.NinjaRule, len(reqs))
+	for i, req := range reqs {
+		rules[i] = &store.NinjaRule{
+			Name:        req.Name,
+			Command:     req.Command,
+			Description: req.Description,
+		}
+		_ = rules[i].SetVariables(req.Variables)
+	}
 
-	dependencies, err := ninjaStore.GetBuildDependencies(targetPath)
+	results, err := ninjaStore.AddRules(r.Context(), rules)
 	if err != nil {
-		writeError(w, fmt.Sprintf("Failed to get dependencies: %v", err), http.StatusInternalServerError)
+		writeError(w, fmt.Sprintf("Failed to create rules: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(dependencies)
+	ids := make([]string, len(reqs))
+	for i, req := range reqs {
+		ids[i] = req.Name
+		if results[i] == nil {
+			eventBus.Publish(EventRuleCreated, map[string]string{"name": req.Name})
+		}
+	}
+
+	writeBatchResponse(w, ids, results)
 }
 
-func getTargetReverseDependenciesHandler(w http.ResponseWriter, r *http.Request) {
+func getRuleHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	targetPath := vars["path"]
+	ruleName := vars["name"]
 
-	reverseDependencies, err := ninjaStore.GetReverseDependencies(targetPath)
+	rule, err := ninjaStore.GetRule(r.Context(), ruleName)
 	if err != nil {
-		writeError(w, fmt.Sprintf("Failed to get reverse dependencies: %v", err), http.StatusInternalServerError)
+		writeError(w, fmt.Sprintf("Rule not found: %v", err), http.StatusNotFound)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(reverseDependencies)
+	_ = json.NewEncoder(w).Encode(rule)
 }
 
-func updateTargetStatusHandler(w http.ResponseWriter, r *http.Request) {
+func updateRuleHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	targetPath := vars["path"]
+	ruleName := vars["name"]
 
 	var req struct {
-		Status string `json:"status"`
+		Command     string            `json:"command"`
+		Description string            `json:"description,omitempty"`
+		Variables   map[string]string `json:"variables,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -420,86 +755,1134 @@ func updateTargetStatusHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Status == "" {
-		writeError(w, "Status field is required", http.StatusBadRequest)
+	if req.Command == "" {
+		writeError(w, "Command field is required", http.StatusBadRequest)
 		return
 	}
 
-	if _, err := ninjaStore.GetTarget(targetPath); err != nil {
-		writeError(w, "Target not found", http.StatusNotFound)
-		return
+	rule := &store.NinjaRule{
+		Name:        ruleName,
+		Command:     req.Command,
+		Description: req.Description,
 	}
 
-	if err := ninjaStore.UpdateTargetStatus(targetPath, req.Status); err != nil {
-		writeError(w, fmt.Sprintf("Failed to update status: %v", err), http.StatusInternalServerError)
+	if err := rule.SetVariables(req.Variables); err != nil {
+		writeError(w, "Failed to set variables", http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
-}
+	if err := ninjaStore.UpdateRule(r.Context(), rule); err != nil {
+		if _errors.Is(err, store.ErrNotFound) {
+			writeStoreError(w, fmt.Sprintf("Rule not found: %v", err), err, http.StatusNotFound)
+			return
+		}
+		writeError(w, fmt.Sprintf("Failed to update rule: %v", err), http.StatusInternalServerError)
+		return
+	}
 
-func findCyclesHandler(w http.ResponseWriter, r *http.Request) {
-	cycles, err := ninjaStore.FindCycles()
+	// Re-validate builds referencing the rule against the new command
+	builds, err := ninjaStore.GetBuildsByRule(r.Context(), ruleName)
 	if err != nil {
-		writeError(w, fmt.Sprintf("Failed to find cycles: %v", err), http.StatusInternalServerError)
+		writeError(w, fmt.Sprintf("Failed to get builds by rule: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"cycles":      cycles,
-		"cycle_count": len(cycles),
-	})
-}
-
-func debugQuadsHandler(w http.ResponseWriter, r *http.Request) {
-	// Get limit parameter
-	limitStr := r.URL.Query().Get("limit")
-	limit := 100 // default limit
-	if limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
+	warnings := []string{}
+	for _, build := range builds {
+		buildVars, _ := build.GetVariables()
+		for _, name := range store.UndefinedVariables(req.Command, req.Variables, buildVars) {
+			warnings = append(warnings, fmt.Sprintf("build %s: undefined variable $%s", build.BuildID, name))
 		}
 	}
 
-	// This would need to be implemented in the store to return quad data
-	// For now, just return a placeholder
+	eventBus.Publish(EventRuleUpdated, map[string]interface{}{"name": ruleName, "builds": len(builds)})
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "Debug endpoint - check server logs for quad dump",
-		"limit":   limit,
+		"status":   "updated",
+		"name":     ruleName,
+		"builds":   len(builds),
+		"warnings": warnings,
 	})
-
-	// Call the debug function which prints to stdout
-	_ = ninjaStore.DebugQuads()
 }
 
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
+// resetStoreHandler removes the build graph, the store file is compacted by compactStoreHandler
+func resetStoreHandler(w http.ResponseWriter, r *http.Request) {
+	stats, err := ninjaStore.Reset(r.Context())
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to reset store: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	eventBus.Publish(EventStoreReset, stats)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "reset", "removed": stats})
+}
+
+func recountStatsHandler(w http.ResponseWriter, r *http.Request) {
+	counts, err := ninjaStore.Recount(r.Context())
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to recount stats: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(counts)
+}
+
+func collectGarbageHandler(w http.ResponseWriter, r *http.Request) {
+	stats, err := ninjaStore.CollectGarbage(r.Context())
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to collect garbage: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(stats)
+}
+
+// snapshotHandler writes a snapshot of the store to dir, writes wait while it is taken
+func snapshotHandler(dir string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info, err := ninjaStore.Snapshot(r.Context(), dir)
+		if err != nil {
+			writeError(w, fmt.Sprintf("Failed to snapshot store: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(info)
+	}
+}
+
+func deleteRuleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ruleName := vars["name"]
+
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+
+	if err := ninjaStore.DeleteRule(r.Context(), ruleName, force); err != nil {
+		switch {
+		case _errors.Is(err, store.ErrNotFound):
+			writeStoreError(w, fmt.Sprintf("Rule not found: %v", err), err, http.StatusNotFound)
+		case _errors.Is(err, store.ErrRuleInUse):
+			writeStoreError(w, fmt.Sprintf("Rule still in use, retry with force=true: %v", err), err, http.StatusConflict)
+		default:
+			writeError(w, fmt.Sprintf("Failed to delete rule: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	eventBus.Publish(EventRuleDeleted, map[string]interface{}{"name": ruleName, "force": force})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted", "name": ruleName})
+}
+
+func getTargetsByRuleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ruleName := vars["name"]
+
+	selector, err := labelSelector(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	targets, err := ninjaStore.GetTargetsByRule(r.Context(), ruleName)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to get targets by rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(store.FilterTargets(targets, selector))
+}
+
+func getAllTargetsHandler(w http.ResponseWriter, r *http.Request) {
+	selector, err := labelSelector(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if notModified(w, r) {
+		return
+	}
+
+	targets, err := ninjaStore.GetAllTargets(r.Context())
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to get targets: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(store.FilterTargets(targets, selector))
+}
+
+// notModified sets the ETag of the store revision and answers 304 when the client sent it in
+// If-None-Match, so polling clients don't get the same response serialized again. Call it before
+// reading the store.
+func notModified(w http.ResponseWriter, r *http.Request) bool {
+	etag := `"` + ninjaStore.Revision() + `"`
+	w.Header().Set("ETag", etag)
+
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// labelSelector reads the owner and label query parameters filtering list endpoints, label may be
+// repeated and is key=value or a key alone
+func labelSelector(r *http.Request) (*store.LabelSelector, error) {
+	query := r.URL.Query()
+
+	return store.ParseLabelSelector(query.Get("owner"), query["label"])
+}
+
+func searchTargetsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	selector, err := labelSelector(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	search := store.TargetQuery{
+		Glob:   query.Get("glob"),
+		Regex:  query.Get("regex"),
+		Status: query.Get("status"),
+		Labels: selector,
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if search.Limit, err = strconv.Atoi(limitStr); err != nil || search.Limit < 0 {
+			writeError(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	targets, err := ninjaStore.SearchTargets(r.Context(), search)
+	if err != nil {
+		if _errors.Is(err, store.ErrInvalidPattern) {
+			writeStoreError(w, err.Error(), err, http.StatusBadRequest)
+			return
+		}
+		writeError(w, fmt.Sprintf("Failed to search targets: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(targets)
+}
+
+func getTargetHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetPath := vars["path"]
+
+	target, err := ninjaStore.GetTarget(r.Context(), targetPath)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Target not found: %v", err), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(target)
+}
+
+func getTargetDependenciesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetPath := vars["path"]
+
+	transitive, depth, err := traversalParams(r)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Invalid query: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var dependencies []*store.NinjaFile
+	if transitive {
+		dependencies, err = ninjaStore.GetTransitiveDependencies(r.Context(), targetPath, depth)
+	} else {
+		dependencies, err = ninjaStore.GetBuildDependencies(r.Context(), targetPath)
+	}
+	if err != nil {
+		if _errors.Is(err, store.ErrNotFound) {
+			writeStoreError(w, fmt.Sprintf("Target not found: %v", err), err, http.StatusNotFound)
+			return
+		}
+		writeError(w, fmt.Sprintf("Failed to get dependencies: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(dependencies)
+}
+
+func getTargetReverseDependenciesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetPath := vars["path"]
+
+	transitive, depth, err := traversalParams(r)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Invalid query: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var reverseDependencies []*store.NinjaTarget
+	if transitive {
+		reverseDependencies, err = ninjaStore.GetTransitiveReverseDependencies(r.Context(), targetPath, depth)
+	} else {
+		reverseDependencies, err = ninjaStore.GetReverseDependencies(r.Context(), targetPath)
+	}
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to get reverse dependencies: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(reverseDependencies)
+}
+
+func traverseDependenciesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetPath := vars["path"]
+
+	opts, err := traversalOptions(r)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Invalid query: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	nodes, err := ninjaStore.TraverseDependencies(r.Context(), targetPath, opts)
+	if err != nil {
+		if _errors.Is(err, store.ErrNotFound) {
+			writeStoreError(w, fmt.Sprintf("Target not found: %v", err), err, http.StatusNotFound)
+			return
+		}
+		writeError(w, fmt.Sprintf("Failed to traverse dependencies: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(nodes)
+}
+
+func updateTargetStatusHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetPath := vars["path"]
+
+	var req struct {
+		Status  string `json:"status"`
+		RunID   string `json:"run_id"`
+		Worker  string `json:"worker"`
+		Message string `json:"message"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.Status == "" {
+		writeError(w, "Status field is required", http.StatusBadRequest)
+		return
+	}
+
+	target, err := ninjaStore.GetTarget(r.Context(), targetPath)
+	if err != nil {
+		writeError(w, "Target not found", http.StatusNotFound)
+		return
+	}
+
+	origin := &store.StatusOrigin{RunID: req.RunID, Worker: req.Worker, Message: req.Message}
+	if err := ninjaStore.UpdateTargetStatus(r.Context(), targetPath, req.Status, origin); err != nil {
+		writeError(w, fmt.Sprintf("Failed to update status: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	publishStatusChange(eventBus, target, req.Status)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
+}
+
+func moveTargetHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetPath := vars["path"]
+
+	var req struct {
+		NewPath string `json:"new_path"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.NewPath == "" {
+		writeError(w, "new_path field is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := ninjaStore.MoveTarget(r.Context(), targetPath, req.NewPath)
+	if err != nil {
+		switch {
+		case _errors.Is(err, store.ErrNotFound):
+			writeStoreError(w, fmt.Sprintf("Target not found: %v", err), err, http.StatusNotFound)
+		case _errors.Is(err, store.ErrExists):
+			writeStoreError(w, fmt.Sprintf("New path is taken: %v", err), err, http.StatusConflict)
+		default:
+			writeError(w, fmt.Sprintf("Failed to move target: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	eventBus.Publish(EventTargetMoved, result)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func setTargetLabelsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetPath := vars["path"]
+
+	var req store.NodeLabels
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	target, err := ninjaStore.SetTargetLabels(r.Context(), targetPath, &req)
+	if err != nil {
+		switch {
+		case _errors.Is(err, store.ErrNotFound):
+			writeStoreError(w, fmt.Sprintf("Target not found: %v", err), err, http.StatusNotFound)
+		case _errors.Is(err, store.ErrInvalidLabel):
+			writeStoreError(w, err.Error(), err, http.StatusBadRequest)
+		default:
+			writeError(w, fmt.Sprintf("Failed to set labels: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(target)
+}
+
+func getTargetHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetPath := vars["path"]
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 0 {
+			writeError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	history, err := ninjaStore.GetTargetHistory(r.Context(), targetPath, r.URL.Query().Get("status"), limit)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to get history: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(history)
+}
+
+func listRolesHandler(w http.ResponseWriter, r *http.Request) {
+	bindings, err := ninjaStore.ListRoles(r.Context())
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to list roles: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(bindings)
+}
+
+func setRoleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	subject := vars["subject"]
+
+	var req struct {
+		Role string `json:"role"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if !store.ValidRole(req.Role) {
+		writeError(w, fmt.Sprintf("Unknown role %q, expected viewer, loader, operator or admin", req.Role), http.StatusBadRequest)
+		return
+	}
+
+	if err := ninjaStore.SetRole(r.Context(), subject, req.Role); err != nil {
+		writeError(w, fmt.Sprintf("Failed to set role: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "updated", "subject": subject, "role": req.Role})
+}
+
+func deleteRoleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	subject := vars["subject"]
+
+	if err := ninjaStore.DeleteRole(r.Context(), subject); err != nil {
+		if _errors.Is(err, store.ErrNotFound) {
+			writeStoreError(w, fmt.Sprintf("Role binding not found: %v", err), err, http.StatusNotFound)
+			return
+		}
+		writeError(w, fmt.Sprintf("Failed to delete role: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted", "subject": subject})
+}
+
+func auditHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var since time.Time
+	if sinceStr := query.Get("since"); sinceStr != "" {
+		parsed, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			writeError(w, "Invalid since, expected an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	limit := auditDefaultLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			writeError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, auditMaxLimit)
+	}
+
+	entries, err := ninjaStore.GetAuditEntries(r.Context(), since, limit)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to get audit entries: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(entries)
+}
+
+func createWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req WebhookRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := webhooks.Register(r.Context(), &req)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to register webhook: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(webhook)
+}
+
+func listWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := webhooks.List(r.Context())
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to list webhooks: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+func getWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	webhook, err := webhooks.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(webhook)
+}
+
+func deleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := webhooks.Delete(r.Context(), id); err != nil {
+		if _errors.Is(err, store.ErrNotFound) {
+			writeStoreError(w, "Webhook not found", err, http.StatusNotFound)
+			return
+		}
+		writeError(w, fmt.Sprintf("Failed to delete webhook: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted", "id": id})
+}
+
+func getWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	deliveries, err := webhooks.Deliveries(id)
+	if err != nil {
+		writeError(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(deliveries)
+}
+
+func createScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	var req ScheduleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := scheduler.Create(r.Context(), &req)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to create schedule: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(schedule)
+}
+
+func listSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	list, err := scheduler.List(r.Context())
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to list schedules: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+func getScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	schedule, err := scheduler.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, "Schedule not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(schedule)
+}
+
+func deleteScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := scheduler.Delete(r.Context(), id); err != nil {
+		if _errors.Is(err, store.ErrNotFound) {
+			writeStoreError(w, "Schedule not found", err, http.StatusNotFound)
+			return
+		}
+		writeError(w, fmt.Sprintf("Failed to delete schedule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted", "id": id})
+}
+
+func exportGraphHandler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = GraphFormatDOT
+	}
+
+	contentType, ok := graphContentTypes[format]
+	if !ok {
+		writeError(w, fmt.Sprintf("Unknown format %q, expected dot, graphml or cyjs", format), http.StatusBadRequest)
+		return
+	}
+
+	depth := 0
+	if depthStr := r.URL.Query().Get("depth"); depthStr != "" {
+		var err error
+		if depth, err = strconv.Atoi(depthStr); err != nil || depth < 0 {
+			writeError(w, "Depth must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	graph, err := ninjaStore.GetDependencyGraph(r.Context(), r.URL.Query().Get("root"), depth)
+	if err != nil {
+		if _errors.Is(err, store.ErrNotFound) {
+			writeStoreError(w, fmt.Sprintf("Root target not found: %v", err), err, http.StatusNotFound)
+			return
+		}
+		writeError(w, fmt.Sprintf("Failed to get dependency graph: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if err := writeGraph(w, graph, format); err != nil {
+		slog.WarnContext(r.Context(), "failed to write graph", "format", format, "error", err)
+	}
+}
+
+func findCyclesHandler(w http.ResponseWriter, r *http.Request) {
+	first := false
+	if firstStr := r.URL.Query().Get("first"); firstStr != "" {
+		var err error
+		if first, err = strconv.ParseBool(firstStr); err != nil {
+			writeError(w, "first must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	cycles, err := ninjaStore.FindCycles(r.Context(), first)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to find cycles: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"cycles":      cycles,
+		"cycle_count": len(cycles),
+	})
+}
+
+func gizmoQueryHandler(w http.ResponseWriter, r *http.Request) {
+	var req GizmoQueryRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.Query == "" {
+		writeError(w, "Query field is required", http.StatusBadRequest)
+		return
+	}
+
+	limit, timeout := queryLimits(int64(req.Limit), int64(req.TimeoutSeconds))
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	result, err := ninjaStore.RunGizmo(ctx, req.Query, limit)
+	if err != nil {
+		if _errors.Is(err, context.DeadlineExceeded) {
+			writeStoreError(w, fmt.Sprintf("Query timed out after %s", timeout), err, http.StatusGatewayTimeout)
+			return
+		}
+		writeError(w, fmt.Sprintf("Query failed: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// queryLimits clamps the requested result limit and timeout to the server maximums
+func queryLimits(limit, timeoutSeconds int64) (int, time.Duration) {
+	if limit <= 0 {
+		limit = queryDefaultLimit
+	}
+	if limit > queryMaxLimit {
+		limit = queryMaxLimit
+	}
+
+	timeout := time.Duration(timeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = queryDefaultTimeout
+	}
+	if timeout > queryMaxTimeout {
+		timeout = queryMaxTimeout
+	}
+
+	return int(limit), timeout
+}
+
+func impactHandler(w http.ResponseWriter, r *http.Request) {
+	var files []string
+	for _, value := range r.URL.Query()["files"] {
+		for _, file := range strings.Split(value, ",") {
+			if file = strings.TrimSpace(file); file != "" {
+				files = append(files, file)
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		writeError(w, "Files parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	impact, err := ninjaStore.GetImpact(r.Context(), files)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to analyze impact: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(impact)
+}
+
+func dependencyPathHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	from, to := query.Get("from"), query.Get("to")
+	if from == "" || to == "" {
+		writeError(w, "From and to parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	all := false
+	if allStr := query.Get("all"); allStr != "" {
+		var err error
+		if all, err = strconv.ParseBool(allStr); err != nil {
+			writeError(w, "all must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	result, err := ninjaStore.GetDependencyChains(r.Context(), from, to, all)
+	if err != nil {
+		if _errors.Is(err, store.ErrNotFound) {
+			writeStoreError(w, fmt.Sprintf("Node not found: %v", err), err, http.StatusNotFound)
+			return
+		}
+		writeError(w, fmt.Sprintf("Failed to find dependency path: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func graphReportHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := ninjaStore.GetGraphReport(r.Context())
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to build report: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+func graphShapeHandler(w http.ResponseWriter, r *http.Request) {
+	top := store.DefaultShapeTop
+	if topStr := r.URL.Query().Get("top"); topStr != "" {
+		var err error
+		if top, err = strconv.Atoi(topStr); err != nil || top < 0 {
+			writeError(w, "top must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	shape, err := ninjaStore.GetGraphShape(r.Context(), top)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to analyze graph shape: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(shape)
+}
+
+func debugQuadsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var limit, offset int64
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.ParseInt(limitStr, 10, 32)
+		if err != nil {
+			writeError(w, "Invalid limit", http.StatusBadRequest)
 			return
 		}
+		limit = parsed
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		parsed, err := strconv.ParseInt(offsetStr, 10, 32)
+		if err != nil || parsed < 0 {
+			writeError(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+		offset = parsed
+	}
+
+	page, err := ninjaStore.GetQuads(r.Context(), query.Get("subject"), debugQuadsLimit(limit), int(offset))
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to get quads: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	// Keep the N-Quads angle brackets readable
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(page)
+}
+
+// debugQuadsLimit clamps a requested page size, zero or less selects the default
+func debugQuadsLimit(limit int64) int {
+	if limit <= 0 {
+		return debugQuadsDefaultLimit
+	}
+	if limit > debugQuadsMaxLimit {
+		return debugQuadsMaxLimit
+	}
+
+	return int(limit)
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	sr.status = code
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// Hijack lets WebSocket upgrades take over the connection
+func (sr *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := sr.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+
+	sr.status = http.StatusSwitchingProtocols
+
+	return hijacker.Hijack()
+}
+
+func loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+		countHTTPRequest(recorder.status)
+
+		attrs := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"duration", time.Since(start),
+			"remote", r.RemoteAddr,
+		}
+
+		if identity, ok := IdentityFromContext(r.Context()); ok {
+			attrs = append(attrs, "subject", identity.Subject)
+		}
 
-		next.ServeHTTP(w, r)
+		switch {
+		case recorder.status >= http.StatusInternalServerError:
+			slog.ErrorContext(r.Context(), "http request", attrs...)
+		case recorder.status >= http.StatusBadRequest:
+			slog.WarnContext(r.Context(), "http request", attrs...)
+		default:
+			slog.InfoContext(r.Context(), "http request", attrs...)
+		}
 	})
 }
 
+// corsMiddleware lets browsers call the API from origins, any origin when it is empty
+func corsMiddleware(origins []string) func(http.Handler) http.Handler {
+	anyOrigin := len(origins) == 0 || slices.Contains(origins, "*")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if anyOrigin {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Add("Vary", "Origin")
+				if origin := r.Header.Get("Origin"); allowedOrigin(origins, origin) {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key, If-None-Match, X-Request-ID")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
+
+			if r.Method == "OPTIONS" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allowedOrigin reports whether origin is one of origins, or they contain "*"
+func allowedOrigin(origins []string, origin string) bool {
+	for _, allowed := range origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func optionsHandler(w http.ResponseWriter, r *http.Request) {
 	// CORS headers are already set by the corsMiddleware
 	w.WriteHeader(http.StatusOK)
 }
 
+func writeBatchResponse(w http.ResponseWriter, ids []string, results []error) {
+	response := BatchResponse{
+		Status:  "completed",
+		Results: make([]BatchItemResult, len(results)),
+	}
+
+	for i, err := range results {
+		result := BatchItemResult{
+			Index:  i,
+			ID:     ids[i],
+			Status: "created",
+		}
+		if err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+			response.Failed++
+		} else {
+			response.Created++
+		}
+		response.Results[i] = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// buildEventData summarizes a created build for event subscribers
+// traversalParams parses the transitive and depth query parameters of the dependency endpoints, a
+// depth implies a transitive traversal
+func traversalParams(r *http.Request) (bool, int, error) {
+	query := r.URL.Query()
+
+	transitive := false
+	if transitiveStr := query.Get("transitive"); transitiveStr != "" {
+		var err error
+		if transitive, err = strconv.ParseBool(transitiveStr); err != nil {
+			return false, 0, fmt.Errorf("transitive must be true or false")
+		}
+	}
+
+	depth := 0
+	if depthStr := query.Get("depth"); depthStr != "" {
+		var err error
+		if depth, err = strconv.Atoi(depthStr); err != nil || depth < 0 {
+			return false, 0, fmt.Errorf("depth must be a non-negative integer")
+		}
+	}
+
+	return transitive || depth > 0, depth, nil
+}
+
+// traversalOptions parses direction, depth, order_only and implicit_outputs of a traversal
+func traversalOptions(r *http.Request) (store.TraversalOptions, error) {
+	query := r.URL.Query()
+
+	opts := store.TraversalOptions{Direction: query.Get("direction")}
+
+	switch opts.Direction {
+	case "", store.DirectionDependencies, store.DirectionDependents:
+	default:
+		return opts, fmt.Errorf("direction must be %s or %s", store.DirectionDependencies, store.DirectionDependents)
+	}
+
+	if depthStr := query.Get("depth"); depthStr != "" {
+		var err error
+		if opts.Depth, err = strconv.Atoi(depthStr); err != nil || opts.Depth < 0 {
+			return opts, fmt.Errorf("depth must be a non-negative integer")
+		}
+	}
+
+	for name, value := range map[string]*bool{"order_only": &opts.OrderOnly, "implicit_outputs": &opts.ImplicitOutputs} {
+		if str := query.Get(name); str != "" {
+			var err error
+			if *value, err = strconv.ParseBool(str); err != nil {
+				return opts, fmt.Errorf("%s must be true or false", name)
+			}
+		}
+	}
+
+	return opts, nil
+}
+
+func buildEventData(req *CreateBuildRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"build_id": req.BuildID,
+		"rule":     req.Rule,
+		"outputs":  req.Outputs,
+	}
+}
+
 func writeError(w http.ResponseWriter, message string, code int) {
+	writeErrorReason(w, message, code, statusReason(code))
+}
+
+// writeStoreError writes an error response with the reason of err, so clients can tell e.g. a rule in
+// use from other conflicts
+func writeStoreError(w http.ResponseWriter, message string, err error, code int) {
+	writeErrorReason(w, message, code, errorReason(err, code))
+}
+
+func writeErrorReason(w http.ResponseWriter, message string, code int, reason string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	_ = json.NewEncoder(w).Encode(ErrorResponse{
-		Error: message,
-		Code:  code,
+		Error:  message,
+		Code:   code,
+		Reason: reason,
 	})
 }
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxLength   = 255
+	idempotencyTTL            = 24 * time.Hour
+	idempotencyMaxEntries     = 10000
+)
+
+// idempotentRoutes are the POST routes whose responses are replayed for a repeated Idempotency-Key. The
+// body of these requests is read into memory to be hashed, /load streams uploads of any size and isn't one.
+var idempotentRoutes = map[string]bool{
+	"/api/v1/builds":                 true,
+	"/api/v1/builds:batch":           true,
+	"/api/v1/rules":                  true,
+	"/api/v1/rules:batch":            true,
+	"/api/v1/webhooks":               true,
+	"/api/v1/schedules":              true,
+	"/api/v1/targets/{path:.*}/move": true,
+}
+
+// idempotencyCache remembers recent responses by caller and key
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*idempotentResponse
+	// order holds entries by insertion, which is also expiry order
+	order []idempotencyOrder
+}
+
+// idempotencyOrder is an entry in insertion order, the key may hold a newer entry since
+type idempotencyOrder struct {
+	key   string
+	entry *idempotentResponse
+}
+
+type idempotentResponse struct {
+	// bodyHash is the hash of the request body, a reused key must come with the same body
+	bodyHash [sha256.Size]byte
+	done     chan struct{}
+	status   int
+	header   http.Header
+	body     []byte
+	expires  time.Time
+}
+
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{entries: make(map[string]*idempotentResponse)}
+}
+
+// begin returns the entry of key and whether it already existed, a new entry for a request with bodyHash
+// is pending until finish
+func (c *idempotencyCache) begin(key string, bodyHash [sha256.Size]byte) (*idempotentResponse, bool) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evict(now)
+
+	if entry, ok := c.entries[key]; ok {
+		return entry, true
+	}
+
+	entry := &idempotentResponse{
+		bodyHash: bodyHash,
+		done:     make(chan struct{}),
+		expires:  now.Add(idempotencyTTL),
+	}
+	c.entries[key] = entry
+	c.order = append(c.order, idempotencyOrder{key: key, entry: entry})
+
+	return entry, false
+}
+
+// finish stores the response of a pending entry, server errors are forgotten so the request can be retried
+func (c *idempotencyCache) finish(key string, entry *idempotentResponse, recorder *idempotencyRecorder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if recorder.status >= http.StatusInternalServerError {
+		delete(c.entries, key)
+	} else {
+		entry.status = recorder.status
+		entry.header = recorder.Header().Clone()
+		entry.body = recorder.body.Bytes()
+	}
+
+	close(entry.done)
+}
+
+// evict drops expired entries and the oldest entries beyond the size limit, callers hold the lock.
+// Requests in progress are kept, entries behind them are still evicted.
+func (c *idempotencyCache) evict(now time.Time) {
+	kept := c.order[:0]
+
+	for i, item := range c.order {
+		// Keys that were forgotten or hold a newer entry only leave order
+		if c.entries[item.key] != item.entry {
+			continue
+		}
+
+		if !c.isDone(item.entry) {
+			kept = append(kept, item)
+			continue
+		}
+
+		// Entries are in expiry order, the rest are kept once one is neither expired nor over the limit
+		if item.entry.expires.After(now) && len(c.entries) < idempotencyMaxEntries {
+			kept = append(kept, c.order[i:]...)
+			break
+		}
+
+		delete(c.entries, item.key)
+	}
+
+	clear(c.order[len(kept):])
+	c.order = kept
+}
+
+func (c *idempotencyCache) isDone(entry *idempotentResponse) bool {
+	select {
+	case <-entry.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// idempotencyRecorder captures a response while passing it through
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (ir *idempotencyRecorder) WriteHeader(code int) {
+	ir.status = code
+	ir.ResponseWriter.WriteHeader(code)
+}
+
+func (ir *idempotencyRecorder) Write(b []byte) (int, error) {
+	ir.body.Write(b)
+	return ir.ResponseWriter.Write(b)
+}
+
+func (ir *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return ir.ResponseWriter
+}
+
+// idempotencyMiddleware replays the original response when a client retries a POST with the same
+// Idempotency-Key, keys are scoped to the caller and request path and kept for 24 hours. A key reused
+// with a different body is rejected with 422.
+func idempotencyMiddleware(cache *idempotencyCache) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" || r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			template := ""
+			if route := mux.CurrentRoute(r); route != nil {
+				template, _ = route.GetPathTemplate()
+			}
+
+			if !idempotentRoutes[template] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > idempotencyKeyMaxLength {
+				writeError(w, "Idempotency-Key is too long", http.StatusBadRequest)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeError(w, "Failed to read request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			bodyHash := sha256.Sum256(body)
+
+			scope := rateLimitKey(r.Context(), r.RemoteAddr) + " " + r.URL.Path + " " + key
+
+			entry, exists := cache.begin(scope, bodyHash)
+			if exists && cache.isDone(entry) && entry.status == 0 {
+				// The original request failed with a server error and was forgotten, run it again
+				entry, exists = cache.begin(scope, bodyHash)
+			}
+
+			if exists {
+				if entry.bodyHash != bodyHash {
+					writeError(w, "Idempotency-Key was already used with a different request body", http.StatusUnprocessableEntity)
+					return
+				}
+				if !cache.isDone(entry) || entry.status == 0 {
+					writeError(w, "A request with this Idempotency-Key is still in progress", http.StatusConflict)
+					return
+				}
+
+				for name, values := range entry.header {
+					w.Header()[name] = values
+				}
+				w.Header().Set(idempotencyReplayedHeader, "true")
+				w.WriteHeader(entry.status)
+				_, _ = w.Write(entry.body)
+				return
+			}
+
+			recorder := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer cache.finish(scope, entry, recorder)
+
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// httpReadRouteTimeout bounds read only requests, mutations keep httpWriteTimeout
+	httpReadRouteTimeout = 10 * time.Second
+
+	// rateLimiterIdle is how long an idle client keeps its bucket
+	rateLimiterIdle = 10 * time.Minute
+
+	// addressRateFactor scales the client rate limit to the address rate limit when none is configured,
+	// clients with keys of their own may share an address
+	addressRateFactor = 10
+)
+
+// LimitsConfig protects the shared store from oversized requests and abusive clients
+type LimitsConfig struct {
+	// MaxBodyBytes caps request bodies, zero disables the cap
+	MaxBodyBytes int64
+	// MaxLoadBytes caps ninja file uploads to /api/v1/load, zero disables the cap
+	MaxLoadBytes int64
+	// RateLimit is the sustained number of requests per second allowed per client, zero disables rate limiting
+	RateLimit float64
+	// RateBurst is the number of requests a client may send at once
+	RateBurst int
+	// AddressRateLimit is the number of requests per second allowed per address before authentication,
+	// zero allows addressRateFactor times RateLimit
+	AddressRateLimit float64
+}
+
+// maintenanceRoutes are the admin routes whose duration grows with the store
+var maintenanceRoutes = map[string]bool{
+	"/api/v1/admin/reset":   true,
+	"/api/v1/admin/gc":      true,
+	"/api/v1/admin/compact": true,
+}
+
+// routeTimeout returns the read and write deadline of the matched route, zero leaves the connection deadlines alone
+func routeTimeout(r *http.Request) time.Duration {
+	template := ""
+	if route := mux.CurrentRoute(r); route != nil {
+		template, _ = route.GetPathTemplate()
+	}
+
+	switch {
+	case template == "/api/v1/ws":
+		// Long lived, the handler manages its own deadlines
+		return 0
+	case template == "/api/v1/load":
+		// Large uploads need more time than the server wide timeouts allow
+		return httpLoadTimeout
+	case maintenanceRoutes[template]:
+		// Rewrite the whole store, which takes long on large graphs
+		return httpLoadTimeout
+	case r.Method == http.MethodGet || r.Method == http.MethodHead || template == "/api/v1/graphql":
+		return httpReadRouteTimeout
+	default:
+		return httpWriteTimeout
+	}
+}
+
+// timeoutMiddleware applies per route deadlines to the connection and the request context
+func timeoutMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := routeTimeout(r)
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		deadline := time.Now().Add(timeout)
+
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(deadline)
+		_ = rc.SetWriteDeadline(deadline)
+
+		ctx, cancel := context.WithDeadline(r.Context(), deadline)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// bodyLimitMiddleware rejects request bodies larger than the configured limits
+func bodyLimitMiddleware(config *LimitsConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := config.MaxBodyBytes
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, _ := route.GetPathTemplate(); template == "/api/v1/load" {
+					limit = config.MaxLoadBytes
+				}
+			}
+
+			if limit > 0 && r.Body != nil && r.Body != http.NoBody {
+				if r.ContentLength > limit {
+					writeError(w, fmt.Sprintf("Request body exceeds %d bytes", limit), http.StatusRequestEntityTooLarge)
+					return
+				}
+				// Chunked bodies are cut off once they pass the limit
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isBodyTooLarge reports whether err was caused by a body exceeding its limit
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// rateLimiter keeps a token bucket per client
+type rateLimiter struct {
+	limit rate.Limit
+	burst int
+	// byAddress keys the buckets by address alone, for limits applied before authentication
+	byAddress bool
+
+	mu        sync.Mutex
+	clients   map[string]*clientBucket
+	lastSweep time.Time
+}
+
+type clientBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// newRateLimiter returns nil when rate limiting is disabled
+func newRateLimiter(config *LimitsConfig) *rateLimiter {
+	if config.RateLimit <= 0 {
+		return nil
+	}
+
+	burst := config.RateBurst
+	if burst <= 0 {
+		burst = int(math.Ceil(config.RateLimit))
+	}
+
+	return &rateLimiter{
+		limit:     rate.Limit(config.RateLimit),
+		burst:     burst,
+		clients:   make(map[string]*clientBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// newAddressRateLimiter returns the limiter applied per address before authentication, so credentials
+// can't be guessed at an unlimited rate. It returns nil when rate limiting is disabled.
+func newAddressRateLimiter(config *LimitsConfig) *rateLimiter {
+	limit, burst := config.AddressRateLimit, 0
+	if limit <= 0 {
+		limit, burst = config.RateLimit*addressRateFactor, config.RateBurst*addressRateFactor
+	}
+
+	limiter := newRateLimiter(&LimitsConfig{RateLimit: limit, RateBurst: burst})
+	if limiter != nil {
+		limiter.byAddress = true
+	}
+
+	return limiter
+}
+
+// allow takes a token from the client's bucket, or returns how long to wait for the next one
+func (rl *rateLimiter) allow(client string) (bool, time.Duration) {
+	now := time.Now()
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if now.Sub(rl.lastSweep) > rateLimiterIdle {
+		for key, bucket := range rl.clients {
+			if now.Sub(bucket.lastSeen) > rateLimiterIdle {
+				delete(rl.clients, key)
+			}
+		}
+		rl.lastSweep = now
+	}
+
+	bucket, ok := rl.clients[client]
+	if !ok {
+		bucket = &clientBucket{limiter: rate.NewLimiter(rl.limit, rl.burst)}
+		rl.clients[client] = bucket
+	}
+	bucket.lastSeen = now
+
+	reservation := bucket.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+
+	return true, 0
+}
+
+// key returns the bucket of a request, by address for address limiters and else by rateLimitKey
+func (rl *rateLimiter) key(ctx context.Context, remoteAddr string) string {
+	if rl.byAddress {
+		return addressKey(remoteAddr)
+	}
+
+	return rateLimitKey(ctx, remoteAddr)
+}
+
+// rateLimitKey identifies a client by its authenticated subject, or else by its address
+func rateLimitKey(ctx context.Context, remoteAddr string) string {
+	if identity, ok := IdentityFromContext(ctx); ok && identity.Subject != "" {
+		return "subject:" + identity.Subject
+	}
+
+	return addressKey(remoteAddr)
+}
+
+// addressKey identifies a client by the host of its address
+func addressKey(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	return "addr:" + host
+}
+
+// rateLimitMiddleware rejects requests from clients that exhausted their bucket. The client limiter
+// runs after authentication so API keys sharing an address are limited separately, the address limiter
+// runs before it.
+func rateLimitMiddleware(limiter *rateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limiter == nil || r.URL.Path == "/health" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if ok, delay := limiter.allow(limiter.key(r.Context(), r.RemoteAddr)); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+				writeError(w, "Rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitInterceptor applies the per client rate limit to gRPC calls
+func rateLimitInterceptor(limiter *rateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := limiter.allowGRPC(ctx); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// rateLimitStreamInterceptor charges one token when a stream is opened
+func rateLimitStreamInterceptor(limiter *rateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := limiter.allowGRPC(ss.Context()); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// allowGRPC takes a token for the caller of a gRPC call, a nil limiter allows everything
+func (rl *rateLimiter) allowGRPC(ctx context.Context) error {
+	if rl == nil {
+		return nil
+	}
+
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+
+	if ok, delay := rl.allow(rl.key(ctx, remoteAddr)); !ok {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %s", delay.Round(time.Millisecond))
+	}
+
+	return nil
+}
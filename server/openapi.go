@@ -0,0 +1,26 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec documents the /api/v1 surface, keep it in sync with the routes in StartHTTPServer
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+//go:embed swagger.html
+var swaggerUIPage []byte
+
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(openAPISpec)
+}
+
+func swaggerUIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(swaggerUIPage)
+}
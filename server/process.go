@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"runtime"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ProcessStatus describes the serving process for monitoring
+type ProcessStatus struct {
+	PID        int `json:"pid"`
+	Goroutines int `json:"goroutines"`
+	// HeapAllocBytes is the memory of live heap objects, SysBytes all memory obtained from the OS
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes"`
+	SysBytes       uint64 `json:"sys_bytes"`
+	GCCycles       uint32 `json:"gc_cycles"`
+	// OpenFiles counts the open file descriptors, the store file among them, -1 where unknown
+	OpenFiles int `json:"open_files"`
+	// Requests served since start; HTTP errors are 5xx responses, gRPC errors calls failing with a
+	// code other than OK
+	HTTPRequests int64 `json:"http_requests"`
+	HTTPErrors   int64 `json:"http_errors"`
+	GRPCRequests int64 `json:"grpc_requests"`
+	GRPCErrors   int64 `json:"grpc_errors"`
+}
+
+// requestCounters counts the requests of both servers, updated by their logging middleware
+var requestCounters struct {
+	http       atomic.Int64
+	httpErrors atomic.Int64
+	grpc       atomic.Int64
+	grpcErrors atomic.Int64
+}
+
+func countHTTPRequest(status int) {
+	requestCounters.http.Add(1)
+	if status >= http.StatusInternalServerError {
+		requestCounters.httpErrors.Add(1)
+	}
+}
+
+func countGRPCRequest(code codes.Code) {
+	requestCounters.grpc.Add(1)
+	if code != codes.OK {
+		requestCounters.grpcErrors.Add(1)
+	}
+}
+
+// collectProcessStatus reads the runtime and request counters of the process
+func collectProcessStatus() *ProcessStatus {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return &ProcessStatus{
+		PID:            os.Getpid(),
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		SysBytes:       mem.Sys,
+		GCCycles:       mem.NumGC,
+		OpenFiles:      openFiles(),
+		HTTPRequests:   requestCounters.http.Load(),
+		HTTPErrors:     requestCounters.httpErrors.Load(),
+		GRPCRequests:   requestCounters.grpc.Load(),
+		GRPCErrors:     requestCounters.grpcErrors.Load(),
+	}
+}
+
+// openFiles counts the file descriptors of the process where /proc lists them
+func openFiles() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+
+	// The descriptor reading the directory is listed too
+	return len(entries) - 1
+}
@@ -147,11 +147,28 @@ func (*StatusRequest) Descriptor() ([]byte, []int) {
 }
 
 type StatusResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
-	Uptime        string                 `protobuf:"bytes,2,opt,name=uptime,proto3" json:"uptime,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Service          string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Uptime           string                 `protobuf:"bytes,2,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	Version          string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	BuildTime        string                 `protobuf:"bytes,4,opt,name=build_time,json=buildTime,proto3" json:"build_time,omitempty"`
+	CommitId         string                 `protobuf:"bytes,5,opt,name=commit_id,json=commitId,proto3" json:"commit_id,omitempty"`
+	StartTime        string                 `protobuf:"bytes,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	StorePath        string                 `protobuf:"bytes,7,opt,name=store_path,json=storePath,proto3" json:"store_path,omitempty"`
+	StoreBackend     string                 `protobuf:"bytes,8,opt,name=store_backend,json=storeBackend,proto3" json:"store_backend,omitempty"`
+	QuadCount        int64                  `protobuf:"varint,9,opt,name=quad_count,json=quadCount,proto3" json:"quad_count,omitempty"`
+	NodeCount        int64                  `protobuf:"varint,10,opt,name=node_count,json=nodeCount,proto3" json:"node_count,omitempty"`
+	TargetCount      int64                  `protobuf:"varint,11,opt,name=target_count,json=targetCount,proto3" json:"target_count,omitempty"`
+	RuleCount        int64                  `protobuf:"varint,12,opt,name=rule_count,json=ruleCount,proto3" json:"rule_count,omitempty"`
+	BuildCount       int64                  `protobuf:"varint,13,opt,name=build_count,json=buildCount,proto3" json:"build_count,omitempty"`
+	ActiveRuns       int32                  `protobuf:"varint,14,opt,name=active_runs,json=activeRuns,proto3" json:"active_runs,omitempty"`
+	ConnectedWorkers int32                  `protobuf:"varint,15,opt,name=connected_workers,json=connectedWorkers,proto3" json:"connected_workers,omitempty"`
+	EventSubscribers int32                  `protobuf:"varint,16,opt,name=event_subscribers,json=eventSubscribers,proto3" json:"event_subscribers,omitempty"`
+	UptimeSeconds    int64                  `protobuf:"varint,17,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	Process          *ProcessStatus         `protobuf:"bytes,18,opt,name=process,proto3" json:"process,omitempty"`
+	EventSinks       []*EventSinkStatus     `protobuf:"bytes,19,rep,name=event_sinks,json=eventSinks,proto3" json:"event_sinks,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *StatusResponse) Reset() {
@@ -198,24 +215,332 @@ func (x *StatusResponse) GetUptime() string {
 	return ""
 }
 
+func (x *StatusResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetBuildTime() string {
+	if x != nil {
+		return x.BuildTime
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetCommitId() string {
+	if x != nil {
+		return x.CommitId
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetStorePath() string {
+	if x != nil {
+		return x.StorePath
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetStoreBackend() string {
+	if x != nil {
+		return x.StoreBackend
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetQuadCount() int64 {
+	if x != nil {
+		return x.QuadCount
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetNodeCount() int64 {
+	if x != nil {
+		return x.NodeCount
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetTargetCount() int64 {
+	if x != nil {
+		return x.TargetCount
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetRuleCount() int64 {
+	if x != nil {
+		return x.RuleCount
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetBuildCount() int64 {
+	if x != nil {
+		return x.BuildCount
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetActiveRuns() int32 {
+	if x != nil {
+		return x.ActiveRuns
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetConnectedWorkers() int32 {
+	if x != nil {
+		return x.ConnectedWorkers
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetEventSubscribers() int32 {
+	if x != nil {
+		return x.EventSubscribers
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetProcess() *ProcessStatus {
+	if x != nil {
+		return x.Process
+	}
+	return nil
+}
+
+func (x *StatusResponse) GetEventSinks() []*EventSinkStatus {
+	if x != nil {
+		return x.EventSinks
+	}
+	return nil
+}
+
+type EventSinkStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Sent          uint64                 `protobuf:"varint,2,opt,name=sent,proto3" json:"sent,omitempty"`
+	Failed        uint64                 `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	Dropped       uint64                 `protobuf:"varint,4,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventSinkStatus) Reset() {
+	*x = EventSinkStatus{}
+	mi := &file_server_proto_grpc_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventSinkStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventSinkStatus) ProtoMessage() {}
+
+func (x *EventSinkStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventSinkStatus.ProtoReflect.Descriptor instead.
+func (*EventSinkStatus) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EventSinkStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *EventSinkStatus) GetSent() uint64 {
+	if x != nil {
+		return x.Sent
+	}
+	return 0
+}
+
+func (x *EventSinkStatus) GetFailed() uint64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *EventSinkStatus) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+type ProcessStatus struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Pid            int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Goroutines     int32                  `protobuf:"varint,2,opt,name=goroutines,proto3" json:"goroutines,omitempty"`
+	HeapAllocBytes uint64                 `protobuf:"varint,3,opt,name=heap_alloc_bytes,json=heapAllocBytes,proto3" json:"heap_alloc_bytes,omitempty"`
+	SysBytes       uint64                 `protobuf:"varint,4,opt,name=sys_bytes,json=sysBytes,proto3" json:"sys_bytes,omitempty"`
+	GcCycles       uint32                 `protobuf:"varint,5,opt,name=gc_cycles,json=gcCycles,proto3" json:"gc_cycles,omitempty"`
+	// -1 where unknown
+	OpenFiles int32 `protobuf:"varint,6,opt,name=open_files,json=openFiles,proto3" json:"open_files,omitempty"`
+	// Requests served since start, errors are HTTP 5xx and gRPC codes other than OK
+	HttpRequests  int64 `protobuf:"varint,7,opt,name=http_requests,json=httpRequests,proto3" json:"http_requests,omitempty"`
+	HttpErrors    int64 `protobuf:"varint,8,opt,name=http_errors,json=httpErrors,proto3" json:"http_errors,omitempty"`
+	GrpcRequests  int64 `protobuf:"varint,9,opt,name=grpc_requests,json=grpcRequests,proto3" json:"grpc_requests,omitempty"`
+	GrpcErrors    int64 `protobuf:"varint,10,opt,name=grpc_errors,json=grpcErrors,proto3" json:"grpc_errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessStatus) Reset() {
+	*x = ProcessStatus{}
+	mi := &file_server_proto_grpc_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessStatus) ProtoMessage() {}
+
+func (x *ProcessStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessStatus.ProtoReflect.Descriptor instead.
+func (*ProcessStatus) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProcessStatus) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ProcessStatus) GetGoroutines() int32 {
+	if x != nil {
+		return x.Goroutines
+	}
+	return 0
+}
+
+func (x *ProcessStatus) GetHeapAllocBytes() uint64 {
+	if x != nil {
+		return x.HeapAllocBytes
+	}
+	return 0
+}
+
+func (x *ProcessStatus) GetSysBytes() uint64 {
+	if x != nil {
+		return x.SysBytes
+	}
+	return 0
+}
+
+func (x *ProcessStatus) GetGcCycles() uint32 {
+	if x != nil {
+		return x.GcCycles
+	}
+	return 0
+}
+
+func (x *ProcessStatus) GetOpenFiles() int32 {
+	if x != nil {
+		return x.OpenFiles
+	}
+	return 0
+}
+
+func (x *ProcessStatus) GetHttpRequests() int64 {
+	if x != nil {
+		return x.HttpRequests
+	}
+	return 0
+}
+
+func (x *ProcessStatus) GetHttpErrors() int64 {
+	if x != nil {
+		return x.HttpErrors
+	}
+	return 0
+}
+
+func (x *ProcessStatus) GetGrpcRequests() int64 {
+	if x != nil {
+		return x.GrpcRequests
+	}
+	return 0
+}
+
+func (x *ProcessStatus) GetGrpcErrors() int64 {
+	if x != nil {
+		return x.GrpcErrors
+	}
+	return 0
+}
+
 // Build
 type CreateBuildRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	BuildId       string                 `protobuf:"bytes,1,opt,name=build_id,json=buildId,proto3" json:"build_id,omitempty"`
-	Rule          string                 `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
-	Variables     map[string]string      `protobuf:"bytes,3,rep,name=variables,proto3" json:"variables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Pool          string                 `protobuf:"bytes,4,opt,name=pool,proto3" json:"pool,omitempty"`
-	Inputs        []string               `protobuf:"bytes,5,rep,name=inputs,proto3" json:"inputs,omitempty"`
-	Outputs       []string               `protobuf:"bytes,6,rep,name=outputs,proto3" json:"outputs,omitempty"`
-	ImplicitDeps  []string               `protobuf:"bytes,7,rep,name=implicit_deps,json=implicitDeps,proto3" json:"implicit_deps,omitempty"`
-	OrderDeps     []string               `protobuf:"bytes,8,rep,name=order_deps,json=orderDeps,proto3" json:"order_deps,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	BuildId      string                 `protobuf:"bytes,1,opt,name=build_id,json=buildId,proto3" json:"build_id,omitempty"`
+	Rule         string                 `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	Variables    map[string]string      `protobuf:"bytes,3,rep,name=variables,proto3" json:"variables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Pool         string                 `protobuf:"bytes,4,opt,name=pool,proto3" json:"pool,omitempty"`
+	Inputs       []string               `protobuf:"bytes,5,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Outputs      []string               `protobuf:"bytes,6,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	ImplicitDeps []string               `protobuf:"bytes,7,rep,name=implicit_deps,json=implicitDeps,proto3" json:"implicit_deps,omitempty"`
+	OrderDeps    []string               `protobuf:"bytes,8,rep,name=order_deps,json=orderDeps,proto3" json:"order_deps,omitempty"`
+	// Given to the outputs of the build too
+	Owner         string            `protobuf:"bytes,9,opt,name=owner,proto3" json:"owner,omitempty"`
+	Labels        map[string]string `protobuf:"bytes,10,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateBuildRequest) Reset() {
 	*x = CreateBuildRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[4]
+	mi := &file_server_proto_grpc_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -227,7 +552,7 @@ func (x *CreateBuildRequest) String() string {
 func (*CreateBuildRequest) ProtoMessage() {}
 
 func (x *CreateBuildRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[4]
+	mi := &file_server_proto_grpc_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -240,7 +565,7 @@ func (x *CreateBuildRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateBuildRequest.ProtoReflect.Descriptor instead.
 func (*CreateBuildRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{4}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *CreateBuildRequest) GetBuildId() string {
@@ -299,6 +624,20 @@ func (x *CreateBuildRequest) GetOrderDeps() []string {
 	return nil
 }
 
+func (x *CreateBuildRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *CreateBuildRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
 type CreateBuildResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
@@ -309,7 +648,7 @@ type CreateBuildResponse struct {
 
 func (x *CreateBuildResponse) Reset() {
 	*x = CreateBuildResponse{}
-	mi := &file_server_proto_grpc_proto_msgTypes[5]
+	mi := &file_server_proto_grpc_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -321,7 +660,7 @@ func (x *CreateBuildResponse) String() string {
 func (*CreateBuildResponse) ProtoMessage() {}
 
 func (x *CreateBuildResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[5]
+	mi := &file_server_proto_grpc_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -334,7 +673,7 @@ func (x *CreateBuildResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateBuildResponse.ProtoReflect.Descriptor instead.
 func (*CreateBuildResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{5}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *CreateBuildResponse) GetStatus() string {
@@ -360,7 +699,7 @@ type GetBuildRequest struct {
 
 func (x *GetBuildRequest) Reset() {
 	*x = GetBuildRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[6]
+	mi := &file_server_proto_grpc_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -372,7 +711,7 @@ func (x *GetBuildRequest) String() string {
 func (*GetBuildRequest) ProtoMessage() {}
 
 func (x *GetBuildRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[6]
+	mi := &file_server_proto_grpc_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -385,7 +724,7 @@ func (x *GetBuildRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBuildRequest.ProtoReflect.Descriptor instead.
 func (*GetBuildRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{6}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *GetBuildRequest) GetId() string {
@@ -403,7 +742,7 @@ type BuildStatsRequest struct {
 
 func (x *BuildStatsRequest) Reset() {
 	*x = BuildStatsRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[7]
+	mi := &file_server_proto_grpc_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -415,7 +754,7 @@ func (x *BuildStatsRequest) String() string {
 func (*BuildStatsRequest) ProtoMessage() {}
 
 func (x *BuildStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[7]
+	mi := &file_server_proto_grpc_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -428,7 +767,7 @@ func (x *BuildStatsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildStatsRequest.ProtoReflect.Descriptor instead.
 func (*BuildStatsRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{7}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{9}
 }
 
 type BuildStatsResponse struct {
@@ -440,7 +779,7 @@ type BuildStatsResponse struct {
 
 func (x *BuildStatsResponse) Reset() {
 	*x = BuildStatsResponse{}
-	mi := &file_server_proto_grpc_proto_msgTypes[8]
+	mi := &file_server_proto_grpc_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -452,7 +791,7 @@ func (x *BuildStatsResponse) String() string {
 func (*BuildStatsResponse) ProtoMessage() {}
 
 func (x *BuildStatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[8]
+	mi := &file_server_proto_grpc_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -465,7 +804,7 @@ func (x *BuildStatsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildStatsResponse.ProtoReflect.Descriptor instead.
 func (*BuildStatsResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{8}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *BuildStatsResponse) GetStats() map[string]int64 {
@@ -483,7 +822,7 @@ type BuildOrderRequest struct {
 
 func (x *BuildOrderRequest) Reset() {
 	*x = BuildOrderRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[9]
+	mi := &file_server_proto_grpc_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -495,7 +834,7 @@ func (x *BuildOrderRequest) String() string {
 func (*BuildOrderRequest) ProtoMessage() {}
 
 func (x *BuildOrderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[9]
+	mi := &file_server_proto_grpc_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -508,7 +847,7 @@ func (x *BuildOrderRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildOrderRequest.ProtoReflect.Descriptor instead.
 func (*BuildOrderRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{9}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{11}
 }
 
 type BuildOrderResponse struct {
@@ -520,7 +859,7 @@ type BuildOrderResponse struct {
 
 func (x *BuildOrderResponse) Reset() {
 	*x = BuildOrderResponse{}
-	mi := &file_server_proto_grpc_proto_msgTypes[10]
+	mi := &file_server_proto_grpc_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -532,7 +871,7 @@ func (x *BuildOrderResponse) String() string {
 func (*BuildOrderResponse) ProtoMessage() {}
 
 func (x *BuildOrderResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[10]
+	mi := &file_server_proto_grpc_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -545,7 +884,7 @@ func (x *BuildOrderResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildOrderResponse.ProtoReflect.Descriptor instead.
 func (*BuildOrderResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{10}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *BuildOrderResponse) GetBuildOrder() []string {
@@ -555,6 +894,103 @@ func (x *BuildOrderResponse) GetBuildOrder() []string {
 	return nil
 }
 
+// Deletes the build and the targets it outputs
+type DeleteBuildRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBuildRequest) Reset() {
+	*x = DeleteBuildRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBuildRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBuildRequest) ProtoMessage() {}
+
+func (x *DeleteBuildRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBuildRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBuildRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteBuildRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteBuildResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	BuildId       string                 `protobuf:"bytes,2,opt,name=build_id,json=buildId,proto3" json:"build_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBuildResponse) Reset() {
+	*x = DeleteBuildResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBuildResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBuildResponse) ProtoMessage() {}
+
+func (x *DeleteBuildResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBuildResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBuildResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DeleteBuildResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DeleteBuildResponse) GetBuildId() string {
+	if x != nil {
+		return x.BuildId
+	}
+	return ""
+}
+
 // Rule
 type CreateRuleRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -568,7 +1004,7 @@ type CreateRuleRequest struct {
 
 func (x *CreateRuleRequest) Reset() {
 	*x = CreateRuleRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[11]
+	mi := &file_server_proto_grpc_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -580,7 +1016,7 @@ func (x *CreateRuleRequest) String() string {
 func (*CreateRuleRequest) ProtoMessage() {}
 
 func (x *CreateRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[11]
+	mi := &file_server_proto_grpc_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -593,7 +1029,7 @@ func (x *CreateRuleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateRuleRequest.ProtoReflect.Descriptor instead.
 func (*CreateRuleRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{11}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *CreateRuleRequest) GetName() string {
@@ -634,7 +1070,7 @@ type CreateRuleResponse struct {
 
 func (x *CreateRuleResponse) Reset() {
 	*x = CreateRuleResponse{}
-	mi := &file_server_proto_grpc_proto_msgTypes[12]
+	mi := &file_server_proto_grpc_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -646,7 +1082,7 @@ func (x *CreateRuleResponse) String() string {
 func (*CreateRuleResponse) ProtoMessage() {}
 
 func (x *CreateRuleResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[12]
+	mi := &file_server_proto_grpc_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -659,7 +1095,7 @@ func (x *CreateRuleResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateRuleResponse.ProtoReflect.Descriptor instead.
 func (*CreateRuleResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{12}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *CreateRuleResponse) GetStatus() string {
@@ -685,7 +1121,7 @@ type GetRuleRequest struct {
 
 func (x *GetRuleRequest) Reset() {
 	*x = GetRuleRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[13]
+	mi := &file_server_proto_grpc_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -697,7 +1133,7 @@ func (x *GetRuleRequest) String() string {
 func (*GetRuleRequest) ProtoMessage() {}
 
 func (x *GetRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[13]
+	mi := &file_server_proto_grpc_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -710,7 +1146,7 @@ func (x *GetRuleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetRuleRequest.ProtoReflect.Descriptor instead.
 func (*GetRuleRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{13}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *GetRuleRequest) GetName() string {
@@ -721,27 +1157,1202 @@ func (x *GetRuleRequest) GetName() string {
 }
 
 type GetTargetsByRuleRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	RuleName      string                 `protobuf:"bytes,1,opt,name=rule_name,json=ruleName,proto3" json:"rule_name,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	RuleName string                 `protobuf:"bytes,1,opt,name=rule_name,json=ruleName,proto3" json:"rule_name,omitempty"`
+	// Only targets with this owner when set
+	Owner string `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	// Only targets with all these labels when set, key=value or a key alone for any value
+	Labels        []string `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetTargetsByRuleRequest) Reset() {
 	*x = GetTargetsByRuleRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[14]
+	mi := &file_server_proto_grpc_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTargetsByRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTargetsByRuleRequest) ProtoMessage() {}
+
+func (x *GetTargetsByRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTargetsByRuleRequest.ProtoReflect.Descriptor instead.
+func (*GetTargetsByRuleRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetTargetsByRuleRequest) GetRuleName() string {
+	if x != nil {
+		return x.RuleName
+	}
+	return ""
+}
+
+func (x *GetTargetsByRuleRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *GetTargetsByRuleRequest) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type GetTargetsByRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Targets       []*NinjaTarget         `protobuf:"bytes,1,rep,name=targets,proto3" json:"targets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTargetsByRuleResponse) Reset() {
+	*x = GetTargetsByRuleResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTargetsByRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTargetsByRuleResponse) ProtoMessage() {}
+
+func (x *GetTargetsByRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTargetsByRuleResponse.ProtoReflect.Descriptor instead.
+func (*GetTargetsByRuleResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetTargetsByRuleResponse) GetTargets() []*NinjaTarget {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+type UpdateRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Command       string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Variables     map[string]string      `protobuf:"bytes,4,rep,name=variables,proto3" json:"variables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRuleRequest) Reset() {
+	*x = UpdateRuleRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRuleRequest) ProtoMessage() {}
+
+func (x *UpdateRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRuleRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRuleRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UpdateRuleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateRuleRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *UpdateRuleRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UpdateRuleRequest) GetVariables() map[string]string {
+	if x != nil {
+		return x.Variables
+	}
+	return nil
+}
+
+type UpdateRuleResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Status string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Name   string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Builds int32                  `protobuf:"varint,3,opt,name=builds,proto3" json:"builds,omitempty"`
+	// Variables of the new command that builds referencing the rule don't define
+	Warnings      []string `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRuleResponse) Reset() {
+	*x = UpdateRuleResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRuleResponse) ProtoMessage() {}
+
+func (x *UpdateRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRuleResponse.ProtoReflect.Descriptor instead.
+func (*UpdateRuleResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *UpdateRuleResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdateRuleResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateRuleResponse) GetBuilds() int32 {
+	if x != nil {
+		return x.Builds
+	}
+	return 0
+}
+
+func (x *UpdateRuleResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type DeleteRuleRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Delete the rule even while builds reference it
+	Force         bool `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRuleRequest) Reset() {
+	*x = DeleteRuleRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRuleRequest) ProtoMessage() {}
+
+func (x *DeleteRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRuleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRuleRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DeleteRuleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeleteRuleRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type DeleteRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRuleResponse) Reset() {
+	*x = DeleteRuleResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRuleResponse) ProtoMessage() {}
+
+func (x *DeleteRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRuleResponse.ProtoReflect.Descriptor instead.
+func (*DeleteRuleResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DeleteRuleResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DeleteRuleResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Target
+type GetAllTargetsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only targets with this owner when set
+	Owner string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	// Only targets with all these labels when set, key=value or a key alone for any value
+	Labels        []string `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllTargetsRequest) Reset() {
+	*x = GetAllTargetsRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllTargetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllTargetsRequest) ProtoMessage() {}
+
+func (x *GetAllTargetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllTargetsRequest.ProtoReflect.Descriptor instead.
+func (*GetAllTargetsRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetAllTargetsRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *GetAllTargetsRequest) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type GetAllTargetsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Targets       []*NinjaTarget         `protobuf:"bytes,1,rep,name=targets,proto3" json:"targets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllTargetsResponse) Reset() {
+	*x = GetAllTargetsResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllTargetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllTargetsResponse) ProtoMessage() {}
+
+func (x *GetAllTargetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllTargetsResponse.ProtoReflect.Descriptor instead.
+func (*GetAllTargetsResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetAllTargetsResponse) GetTargets() []*NinjaTarget {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+type GetTargetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTargetRequest) Reset() {
+	*x = GetTargetRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTargetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTargetRequest) ProtoMessage() {}
+
+func (x *GetTargetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTargetRequest.ProtoReflect.Descriptor instead.
+func (*GetTargetRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetTargetRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type GetTargetDependenciesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTargetDependenciesRequest) Reset() {
+	*x = GetTargetDependenciesRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTargetDependenciesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTargetDependenciesRequest) ProtoMessage() {}
+
+func (x *GetTargetDependenciesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTargetDependenciesRequest.ProtoReflect.Descriptor instead.
+func (*GetTargetDependenciesRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetTargetDependenciesRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type GetTargetDependenciesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Dependencies  []*NinjaFile           `protobuf:"bytes,1,rep,name=dependencies,proto3" json:"dependencies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTargetDependenciesResponse) Reset() {
+	*x = GetTargetDependenciesResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTargetDependenciesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTargetDependenciesResponse) ProtoMessage() {}
+
+func (x *GetTargetDependenciesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTargetDependenciesResponse.ProtoReflect.Descriptor instead.
+func (*GetTargetDependenciesResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetTargetDependenciesResponse) GetDependencies() []*NinjaFile {
+	if x != nil {
+		return x.Dependencies
+	}
+	return nil
+}
+
+type GetTargetReverseDependenciesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTargetReverseDependenciesRequest) Reset() {
+	*x = GetTargetReverseDependenciesRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTargetReverseDependenciesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTargetReverseDependenciesRequest) ProtoMessage() {}
+
+func (x *GetTargetReverseDependenciesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTargetReverseDependenciesRequest.ProtoReflect.Descriptor instead.
+func (*GetTargetReverseDependenciesRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetTargetReverseDependenciesRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type GetTargetReverseDependenciesResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ReverseDependencies []*NinjaTarget         `protobuf:"bytes,1,rep,name=reverse_dependencies,json=reverseDependencies,proto3" json:"reverse_dependencies,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *GetTargetReverseDependenciesResponse) Reset() {
+	*x = GetTargetReverseDependenciesResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTargetReverseDependenciesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTargetReverseDependenciesResponse) ProtoMessage() {}
+
+func (x *GetTargetReverseDependenciesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTargetReverseDependenciesResponse.ProtoReflect.Descriptor instead.
+func (*GetTargetReverseDependenciesResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetTargetReverseDependenciesResponse) GetReverseDependencies() []*NinjaTarget {
+	if x != nil {
+		return x.ReverseDependencies
+	}
+	return nil
+}
+
+type TraverseDependenciesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// dependencies (default) or dependents
+	Direction string `protobuf:"bytes,2,opt,name=direction,proto3" json:"direction,omitempty"`
+	// Levels followed, all when 0
+	Depth     int32 `protobuf:"varint,3,opt,name=depth,proto3" json:"depth,omitempty"`
+	OrderOnly bool  `protobuf:"varint,4,opt,name=order_only,json=orderOnly,proto3" json:"order_only,omitempty"`
+	// Add the other outputs of the builds of the targets reached
+	ImplicitOutputs bool `protobuf:"varint,5,opt,name=implicit_outputs,json=implicitOutputs,proto3" json:"implicit_outputs,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TraverseDependenciesRequest) Reset() {
+	*x = TraverseDependenciesRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraverseDependenciesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraverseDependenciesRequest) ProtoMessage() {}
+
+func (x *TraverseDependenciesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraverseDependenciesRequest.ProtoReflect.Descriptor instead.
+func (*TraverseDependenciesRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *TraverseDependenciesRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *TraverseDependenciesRequest) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *TraverseDependenciesRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *TraverseDependenciesRequest) GetOrderOnly() bool {
+	if x != nil {
+		return x.OrderOnly
+	}
+	return false
+}
+
+func (x *TraverseDependenciesRequest) GetImplicitOutputs() bool {
+	if x != nil {
+		return x.ImplicitOutputs
+	}
+	return false
+}
+
+type DependencyNode struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// target or file
+	Kind     string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Status   string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Rule     string `protobuf:"bytes,4,opt,name=rule,proto3" json:"rule,omitempty"`
+	FileType string `protobuf:"bytes,5,opt,name=file_type,json=fileType,proto3" json:"file_type,omitempty"`
+	Depth    int32  `protobuf:"varint,6,opt,name=depth,proto3" json:"depth,omitempty"`
+	// input, implicit, order or output
+	Edge          string `protobuf:"bytes,7,opt,name=edge,proto3" json:"edge,omitempty"`
+	From          string `protobuf:"bytes,8,opt,name=from,proto3" json:"from,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DependencyNode) Reset() {
+	*x = DependencyNode{}
+	mi := &file_server_proto_grpc_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DependencyNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DependencyNode) ProtoMessage() {}
+
+func (x *DependencyNode) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DependencyNode.ProtoReflect.Descriptor instead.
+func (*DependencyNode) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DependencyNode) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DependencyNode) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *DependencyNode) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DependencyNode) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *DependencyNode) GetFileType() string {
+	if x != nil {
+		return x.FileType
+	}
+	return ""
+}
+
+func (x *DependencyNode) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *DependencyNode) GetEdge() string {
+	if x != nil {
+		return x.Edge
+	}
+	return ""
+}
+
+func (x *DependencyNode) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+type TraverseDependenciesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*DependencyNode      `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TraverseDependenciesResponse) Reset() {
+	*x = TraverseDependenciesResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraverseDependenciesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraverseDependenciesResponse) ProtoMessage() {}
+
+func (x *TraverseDependenciesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraverseDependenciesResponse.ProtoReflect.Descriptor instead.
+func (*TraverseDependenciesResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *TraverseDependenciesResponse) GetNodes() []*DependencyNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type UpdateTargetStatusRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Path   string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Status string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// Recorded in the status history of the target
+	RunId         string `protobuf:"bytes,3,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Worker        string `protobuf:"bytes,4,opt,name=worker,proto3" json:"worker,omitempty"`
+	Message       string `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTargetStatusRequest) Reset() {
+	*x = UpdateTargetStatusRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTargetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTargetStatusRequest) ProtoMessage() {}
+
+func (x *UpdateTargetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTargetStatusRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTargetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *UpdateTargetStatusRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *UpdateTargetStatusRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdateTargetStatusRequest) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *UpdateTargetStatusRequest) GetWorker() string {
+	if x != nil {
+		return x.Worker
+	}
+	return ""
+}
+
+func (x *UpdateTargetStatusRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type UpdateTargetStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTargetStatusResponse) Reset() {
+	*x = UpdateTargetStatusResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTargetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTargetStatusResponse) ProtoMessage() {}
+
+func (x *UpdateTargetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTargetStatusResponse.ProtoReflect.Descriptor instead.
+func (*UpdateTargetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *UpdateTargetStatusResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type BulkUpdateTargetStatusRequest struct {
+	state         protoimpl.MessageState       `protogen:"open.v1"`
+	Updates       []*UpdateTargetStatusRequest `protobuf:"bytes,1,rep,name=updates,proto3" json:"updates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkUpdateTargetStatusRequest) Reset() {
+	*x = BulkUpdateTargetStatusRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkUpdateTargetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkUpdateTargetStatusRequest) ProtoMessage() {}
+
+func (x *BulkUpdateTargetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkUpdateTargetStatusRequest.ProtoReflect.Descriptor instead.
+func (*BulkUpdateTargetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *BulkUpdateTargetStatusRequest) GetUpdates() []*UpdateTargetStatusRequest {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
+type BulkUpdateTargetStatusResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Updated int32                  `protobuf:"varint,1,opt,name=updated,proto3" json:"updated,omitempty"`
+	// One entry per update, in request order
+	Results       []*TargetStatusResult `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkUpdateTargetStatusResponse) Reset() {
+	*x = BulkUpdateTargetStatusResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkUpdateTargetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkUpdateTargetStatusResponse) ProtoMessage() {}
+
+func (x *BulkUpdateTargetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkUpdateTargetStatusResponse.ProtoReflect.Descriptor instead.
+func (*BulkUpdateTargetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *BulkUpdateTargetStatusResponse) GetUpdated() int32 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *BulkUpdateTargetStatusResponse) GetResults() []*TargetStatusResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type TargetStatusResult struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Path   string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Status string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// Set when this update failed, the other updates are still applied
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetStatusResult) Reset() {
+	*x = TargetStatusResult{}
+	mi := &file_server_proto_grpc_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetStatusResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetStatusResult) ProtoMessage() {}
+
+func (x *TargetStatusResult) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetStatusResult.ProtoReflect.Descriptor instead.
+func (*TargetStatusResult) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *TargetStatusResult) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *TargetStatusResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TargetStatusResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetTargetHistoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Only changes to this status when set
+	Status string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// All changes when zero
+	Limit         int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTargetHistoryRequest) Reset() {
+	*x = GetTargetHistoryRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTargetsByRuleRequest) String() string {
+func (x *GetTargetHistoryRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTargetsByRuleRequest) ProtoMessage() {}
+func (*GetTargetHistoryRequest) ProtoMessage() {}
 
-func (x *GetTargetsByRuleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[14]
+func (x *GetTargetHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -752,40 +2363,55 @@ func (x *GetTargetsByRuleRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTargetsByRuleRequest.ProtoReflect.Descriptor instead.
-func (*GetTargetsByRuleRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use GetTargetHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetTargetHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *GetTargetsByRuleRequest) GetRuleName() string {
+func (x *GetTargetHistoryRequest) GetPath() string {
 	if x != nil {
-		return x.RuleName
+		return x.Path
 	}
 	return ""
 }
 
-type GetTargetsByRuleResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Targets       []*NinjaTarget         `protobuf:"bytes,1,rep,name=targets,proto3" json:"targets,omitempty"`
+func (x *GetTargetHistoryRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GetTargetHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetTargetHistoryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first
+	Changes       []*TargetStatusChange `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTargetsByRuleResponse) Reset() {
-	*x = GetTargetsByRuleResponse{}
-	mi := &file_server_proto_grpc_proto_msgTypes[15]
+func (x *GetTargetHistoryResponse) Reset() {
+	*x = GetTargetHistoryResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTargetsByRuleResponse) String() string {
+func (x *GetTargetHistoryResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTargetsByRuleResponse) ProtoMessage() {}
+func (*GetTargetHistoryResponse) ProtoMessage() {}
 
-func (x *GetTargetsByRuleResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[15]
+func (x *GetTargetHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -796,40 +2422,46 @@ func (x *GetTargetsByRuleResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTargetsByRuleResponse.ProtoReflect.Descriptor instead.
-func (*GetTargetsByRuleResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use GetTargetHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetTargetHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *GetTargetsByRuleResponse) GetTargets() []*NinjaTarget {
+func (x *GetTargetHistoryResponse) GetChanges() []*TargetStatusChange {
 	if x != nil {
-		return x.Targets
+		return x.Changes
 	}
 	return nil
 }
 
-// Target
-type GetAllTargetsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type TargetStatusChange struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Target         string                 `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	Status         string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	PreviousStatus string                 `protobuf:"bytes,3,opt,name=previous_status,json=previousStatus,proto3" json:"previous_status,omitempty"`
+	Time           string                 `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	RunId          string                 `protobuf:"bytes,5,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Worker         string                 `protobuf:"bytes,6,opt,name=worker,proto3" json:"worker,omitempty"`
+	Message        string                 `protobuf:"bytes,7,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *GetAllTargetsRequest) Reset() {
-	*x = GetAllTargetsRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[16]
+func (x *TargetStatusChange) Reset() {
+	*x = TargetStatusChange{}
+	mi := &file_server_proto_grpc_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAllTargetsRequest) String() string {
+func (x *TargetStatusChange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAllTargetsRequest) ProtoMessage() {}
+func (*TargetStatusChange) ProtoMessage() {}
 
-func (x *GetAllTargetsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[16]
+func (x *TargetStatusChange) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -840,77 +2472,82 @@ func (x *GetAllTargetsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAllTargetsRequest.ProtoReflect.Descriptor instead.
-func (*GetAllTargetsRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use TargetStatusChange.ProtoReflect.Descriptor instead.
+func (*TargetStatusChange) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{41}
 }
 
-type GetAllTargetsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Targets       []*NinjaTarget         `protobuf:"bytes,1,rep,name=targets,proto3" json:"targets,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *TargetStatusChange) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
 }
 
-func (x *GetAllTargetsResponse) Reset() {
-	*x = GetAllTargetsResponse{}
-	mi := &file_server_proto_grpc_proto_msgTypes[17]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *TargetStatusChange) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
 }
 
-func (x *GetAllTargetsResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *TargetStatusChange) GetPreviousStatus() string {
+	if x != nil {
+		return x.PreviousStatus
+	}
+	return ""
 }
 
-func (*GetAllTargetsResponse) ProtoMessage() {}
+func (x *TargetStatusChange) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
 
-func (x *GetAllTargetsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[17]
+func (x *TargetStatusChange) GetRunId() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.RunId
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use GetAllTargetsResponse.ProtoReflect.Descriptor instead.
-func (*GetAllTargetsResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{17}
+func (x *TargetStatusChange) GetWorker() string {
+	if x != nil {
+		return x.Worker
+	}
+	return ""
 }
 
-func (x *GetAllTargetsResponse) GetTargets() []*NinjaTarget {
+func (x *TargetStatusChange) GetMessage() string {
 	if x != nil {
-		return x.Targets
+		return x.Message
 	}
-	return nil
+	return ""
 }
 
-type GetTargetRequest struct {
+type DeleteTargetRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTargetRequest) Reset() {
-	*x = GetTargetRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[18]
+func (x *DeleteTargetRequest) Reset() {
+	*x = DeleteTargetRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTargetRequest) String() string {
+func (x *DeleteTargetRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTargetRequest) ProtoMessage() {}
+func (*DeleteTargetRequest) ProtoMessage() {}
 
-func (x *GetTargetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[18]
+func (x *DeleteTargetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -921,40 +2558,41 @@ func (x *GetTargetRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTargetRequest.ProtoReflect.Descriptor instead.
-func (*GetTargetRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use DeleteTargetRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTargetRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *GetTargetRequest) GetPath() string {
+func (x *DeleteTargetRequest) GetPath() string {
 	if x != nil {
 		return x.Path
 	}
 	return ""
 }
 
-type GetTargetDependenciesRequest struct {
+type DeleteTargetResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTargetDependenciesRequest) Reset() {
-	*x = GetTargetDependenciesRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[19]
+func (x *DeleteTargetResponse) Reset() {
+	*x = DeleteTargetResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTargetDependenciesRequest) String() string {
+func (x *DeleteTargetResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTargetDependenciesRequest) ProtoMessage() {}
+func (*DeleteTargetResponse) ProtoMessage() {}
 
-func (x *GetTargetDependenciesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[19]
+func (x *DeleteTargetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -965,40 +2603,48 @@ func (x *GetTargetDependenciesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTargetDependenciesRequest.ProtoReflect.Descriptor instead.
-func (*GetTargetDependenciesRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use DeleteTargetResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTargetResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *GetTargetDependenciesRequest) GetPath() string {
+func (x *DeleteTargetResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DeleteTargetResponse) GetPath() string {
 	if x != nil {
 		return x.Path
 	}
 	return ""
 }
 
-type GetTargetDependenciesResponse struct {
+type MoveTargetRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Dependencies  []*NinjaFile           `protobuf:"bytes,1,rep,name=dependencies,proto3" json:"dependencies,omitempty"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	NewPath       string                 `protobuf:"bytes,2,opt,name=new_path,json=newPath,proto3" json:"new_path,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTargetDependenciesResponse) Reset() {
-	*x = GetTargetDependenciesResponse{}
-	mi := &file_server_proto_grpc_proto_msgTypes[20]
+func (x *MoveTargetRequest) Reset() {
+	*x = MoveTargetRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTargetDependenciesResponse) String() string {
+func (x *MoveTargetRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTargetDependenciesResponse) ProtoMessage() {}
+func (*MoveTargetRequest) ProtoMessage() {}
 
-func (x *GetTargetDependenciesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[20]
+func (x *MoveTargetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1009,40 +2655,54 @@ func (x *GetTargetDependenciesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTargetDependenciesResponse.ProtoReflect.Descriptor instead.
-func (*GetTargetDependenciesResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use MoveTargetRequest.ProtoReflect.Descriptor instead.
+func (*MoveTargetRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *GetTargetDependenciesResponse) GetDependencies() []*NinjaFile {
+func (x *MoveTargetRequest) GetPath() string {
 	if x != nil {
-		return x.Dependencies
+		return x.Path
 	}
-	return nil
+	return ""
 }
 
-type GetTargetReverseDependenciesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+func (x *MoveTargetRequest) GetNewPath() string {
+	if x != nil {
+		return x.NewPath
+	}
+	return ""
+}
+
+type MoveTargetResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Status  string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Path    string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	NewPath string                 `protobuf:"bytes,3,opt,name=new_path,json=newPath,proto3" json:"new_path,omitempty"`
+	// New id of the build of the target when it was named after its outputs
+	BuildId string `protobuf:"bytes,4,opt,name=build_id,json=buildId,proto3" json:"build_id,omitempty"`
+	// Builds reading the target as an input were pointed at the new path
+	FileMoved     bool  `protobuf:"varint,5,opt,name=file_moved,json=fileMoved,proto3" json:"file_moved,omitempty"`
+	HistoryMoved  int32 `protobuf:"varint,6,opt,name=history_moved,json=historyMoved,proto3" json:"history_moved,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTargetReverseDependenciesRequest) Reset() {
-	*x = GetTargetReverseDependenciesRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[21]
+func (x *MoveTargetResponse) Reset() {
+	*x = MoveTargetResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTargetReverseDependenciesRequest) String() string {
+func (x *MoveTargetResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTargetReverseDependenciesRequest) ProtoMessage() {}
+func (*MoveTargetResponse) ProtoMessage() {}
 
-func (x *GetTargetReverseDependenciesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[21]
+func (x *MoveTargetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1053,85 +2713,78 @@ func (x *GetTargetReverseDependenciesRequest) ProtoReflect() protoreflect.Messag
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTargetReverseDependenciesRequest.ProtoReflect.Descriptor instead.
-func (*GetTargetReverseDependenciesRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use MoveTargetResponse.ProtoReflect.Descriptor instead.
+func (*MoveTargetResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{45}
 }
 
-func (x *GetTargetReverseDependenciesRequest) GetPath() string {
+func (x *MoveTargetResponse) GetStatus() string {
 	if x != nil {
-		return x.Path
+		return x.Status
 	}
 	return ""
 }
 
-type GetTargetReverseDependenciesResponse struct {
-	state               protoimpl.MessageState `protogen:"open.v1"`
-	ReverseDependencies []*NinjaTarget         `protobuf:"bytes,1,rep,name=reverse_dependencies,json=reverseDependencies,proto3" json:"reverse_dependencies,omitempty"`
-	unknownFields       protoimpl.UnknownFields
-	sizeCache           protoimpl.SizeCache
-}
-
-func (x *GetTargetReverseDependenciesResponse) Reset() {
-	*x = GetTargetReverseDependenciesResponse{}
-	mi := &file_server_proto_grpc_proto_msgTypes[22]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *MoveTargetResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
 }
 
-func (x *GetTargetReverseDependenciesResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *MoveTargetResponse) GetNewPath() string {
+	if x != nil {
+		return x.NewPath
+	}
+	return ""
 }
 
-func (*GetTargetReverseDependenciesResponse) ProtoMessage() {}
-
-func (x *GetTargetReverseDependenciesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[22]
+func (x *MoveTargetResponse) GetBuildId() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.BuildId
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use GetTargetReverseDependenciesResponse.ProtoReflect.Descriptor instead.
-func (*GetTargetReverseDependenciesResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{22}
+func (x *MoveTargetResponse) GetFileMoved() bool {
+	if x != nil {
+		return x.FileMoved
+	}
+	return false
 }
 
-func (x *GetTargetReverseDependenciesResponse) GetReverseDependencies() []*NinjaTarget {
+func (x *MoveTargetResponse) GetHistoryMoved() int32 {
 	if x != nil {
-		return x.ReverseDependencies
+		return x.HistoryMoved
 	}
-	return nil
+	return 0
 }
 
-type UpdateTargetStatusRequest struct {
+// An empty owner or no labels remove them
+type SetTargetLabelsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Owner         string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateTargetStatusRequest) Reset() {
-	*x = UpdateTargetStatusRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[23]
+func (x *SetTargetLabelsRequest) Reset() {
+	*x = SetTargetLabelsRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateTargetStatusRequest) String() string {
+func (x *SetTargetLabelsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateTargetStatusRequest) ProtoMessage() {}
+func (*SetTargetLabelsRequest) ProtoMessage() {}
 
-func (x *UpdateTargetStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[23]
+func (x *SetTargetLabelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1142,47 +2795,56 @@ func (x *UpdateTargetStatusRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateTargetStatusRequest.ProtoReflect.Descriptor instead.
-func (*UpdateTargetStatusRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use SetTargetLabelsRequest.ProtoReflect.Descriptor instead.
+func (*SetTargetLabelsRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *UpdateTargetStatusRequest) GetPath() string {
+func (x *SetTargetLabelsRequest) GetPath() string {
 	if x != nil {
 		return x.Path
 	}
 	return ""
 }
 
-func (x *UpdateTargetStatusRequest) GetStatus() string {
+func (x *SetTargetLabelsRequest) GetOwner() string {
 	if x != nil {
-		return x.Status
+		return x.Owner
 	}
 	return ""
 }
 
-type UpdateTargetStatusResponse struct {
+func (x *SetTargetLabelsRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type SetBuildLabelsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Owner         string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateTargetStatusResponse) Reset() {
-	*x = UpdateTargetStatusResponse{}
-	mi := &file_server_proto_grpc_proto_msgTypes[24]
+func (x *SetBuildLabelsRequest) Reset() {
+	*x = SetBuildLabelsRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateTargetStatusResponse) String() string {
+func (x *SetBuildLabelsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateTargetStatusResponse) ProtoMessage() {}
+func (*SetBuildLabelsRequest) ProtoMessage() {}
 
-func (x *UpdateTargetStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[24]
+func (x *SetBuildLabelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1193,28 +2855,44 @@ func (x *UpdateTargetStatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateTargetStatusResponse.ProtoReflect.Descriptor instead.
-func (*UpdateTargetStatusResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use SetBuildLabelsRequest.ProtoReflect.Descriptor instead.
+func (*SetBuildLabelsRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{47}
 }
 
-func (x *UpdateTargetStatusResponse) GetStatus() string {
+func (x *SetBuildLabelsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SetBuildLabelsRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *SetBuildLabelsRequest) GetLabels() map[string]string {
 	if x != nil {
-		return x.Status
+		return x.Labels
 	}
-	return ""
+	return nil
 }
 
 // Analysis
 type FindCyclesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Stop at the first cycle found
+	FirstOnly     bool `protobuf:"varint,1,opt,name=first_only,json=firstOnly,proto3" json:"first_only,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *FindCyclesRequest) Reset() {
 	*x = FindCyclesRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[25]
+	mi := &file_server_proto_grpc_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1226,7 +2904,7 @@ func (x *FindCyclesRequest) String() string {
 func (*FindCyclesRequest) ProtoMessage() {}
 
 func (x *FindCyclesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[25]
+	mi := &file_server_proto_grpc_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1239,7 +2917,14 @@ func (x *FindCyclesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FindCyclesRequest.ProtoReflect.Descriptor instead.
 func (*FindCyclesRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{25}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *FindCyclesRequest) GetFirstOnly() bool {
+	if x != nil {
+		return x.FirstOnly
+	}
+	return false
 }
 
 type FindCyclesResponse struct {
@@ -1252,7 +2937,7 @@ type FindCyclesResponse struct {
 
 func (x *FindCyclesResponse) Reset() {
 	*x = FindCyclesResponse{}
-	mi := &file_server_proto_grpc_proto_msgTypes[26]
+	mi := &file_server_proto_grpc_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1264,7 +2949,7 @@ func (x *FindCyclesResponse) String() string {
 func (*FindCyclesResponse) ProtoMessage() {}
 
 func (x *FindCyclesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[26]
+	mi := &file_server_proto_grpc_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1277,7 +2962,7 @@ func (x *FindCyclesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FindCyclesResponse.ProtoReflect.Descriptor instead.
 func (*FindCyclesResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{26}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{49}
 }
 
 func (x *FindCyclesResponse) GetCycles() []*Cycle {
@@ -1303,7 +2988,7 @@ type Cycle struct {
 
 func (x *Cycle) Reset() {
 	*x = Cycle{}
-	mi := &file_server_proto_grpc_proto_msgTypes[27]
+	mi := &file_server_proto_grpc_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1315,7 +3000,7 @@ func (x *Cycle) String() string {
 func (*Cycle) ProtoMessage() {}
 
 func (x *Cycle) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[27]
+	mi := &file_server_proto_grpc_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1328,7 +3013,7 @@ func (x *Cycle) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Cycle.ProtoReflect.Descriptor instead.
 func (*Cycle) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{27}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{50}
 }
 
 func (x *Cycle) GetNodes() []string {
@@ -1338,17 +3023,133 @@ func (x *Cycle) GetNodes() []string {
 	return nil
 }
 
+// Query
+type GizmoQueryRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Query          string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Limit          int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	TimeoutSeconds int32                  `protobuf:"varint,3,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GizmoQueryRequest) Reset() {
+	*x = GizmoQueryRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GizmoQueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GizmoQueryRequest) ProtoMessage() {}
+
+func (x *GizmoQueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GizmoQueryRequest.ProtoReflect.Descriptor instead.
+func (*GizmoQueryRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *GizmoQueryRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *GizmoQueryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GizmoQueryRequest) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+type GizmoQueryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// JSON encoded results
+	Results       []string `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Truncated     bool     `protobuf:"varint,2,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GizmoQueryResponse) Reset() {
+	*x = GizmoQueryResponse{}
+	mi := &file_server_proto_grpc_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GizmoQueryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GizmoQueryResponse) ProtoMessage() {}
+
+func (x *GizmoQueryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GizmoQueryResponse.ProtoReflect.Descriptor instead.
+func (*GizmoQueryResponse) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *GizmoQueryResponse) GetResults() []string {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *GizmoQueryResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
 // Debug
 type DebugQuadsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Subject       string                 `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DebugQuadsRequest) Reset() {
 	*x = DebugQuadsRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[28]
+	mi := &file_server_proto_grpc_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1360,7 +3161,7 @@ func (x *DebugQuadsRequest) String() string {
 func (*DebugQuadsRequest) ProtoMessage() {}
 
 func (x *DebugQuadsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[28]
+	mi := &file_server_proto_grpc_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1373,7 +3174,7 @@ func (x *DebugQuadsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DebugQuadsRequest.ProtoReflect.Descriptor instead.
 func (*DebugQuadsRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{28}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{53}
 }
 
 func (x *DebugQuadsRequest) GetLimit() int32 {
@@ -1383,17 +3184,34 @@ func (x *DebugQuadsRequest) GetLimit() int32 {
 	return 0
 }
 
+func (x *DebugQuadsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *DebugQuadsRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
 type DebugQuadsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
 	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Quads         []*Quad                `protobuf:"bytes,3,rep,name=quads,proto3" json:"quads,omitempty"`
+	Offset        int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	HasMore       bool                   `protobuf:"varint,5,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DebugQuadsResponse) Reset() {
 	*x = DebugQuadsResponse{}
-	mi := &file_server_proto_grpc_proto_msgTypes[29]
+	mi := &file_server_proto_grpc_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1405,7 +3223,7 @@ func (x *DebugQuadsResponse) String() string {
 func (*DebugQuadsResponse) ProtoMessage() {}
 
 func (x *DebugQuadsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[29]
+	mi := &file_server_proto_grpc_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1418,7 +3236,7 @@ func (x *DebugQuadsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DebugQuadsResponse.ProtoReflect.Descriptor instead.
 func (*DebugQuadsResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{29}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{54}
 }
 
 func (x *DebugQuadsResponse) GetMessage() string {
@@ -1435,6 +3253,95 @@ func (x *DebugQuadsResponse) GetLimit() int32 {
 	return 0
 }
 
+func (x *DebugQuadsResponse) GetQuads() []*Quad {
+	if x != nil {
+		return x.Quads
+	}
+	return nil
+}
+
+func (x *DebugQuadsResponse) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *DebugQuadsResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+type Quad struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subject       string                 `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Predicate     string                 `protobuf:"bytes,2,opt,name=predicate,proto3" json:"predicate,omitempty"`
+	Object        string                 `protobuf:"bytes,3,opt,name=object,proto3" json:"object,omitempty"`
+	Label         string                 `protobuf:"bytes,4,opt,name=label,proto3" json:"label,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Quad) Reset() {
+	*x = Quad{}
+	mi := &file_server_proto_grpc_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Quad) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Quad) ProtoMessage() {}
+
+func (x *Quad) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Quad.ProtoReflect.Descriptor instead.
+func (*Quad) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *Quad) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *Quad) GetPredicate() string {
+	if x != nil {
+		return x.Predicate
+	}
+	return ""
+}
+
+func (x *Quad) GetObject() string {
+	if x != nil {
+		return x.Object
+	}
+	return ""
+}
+
+func (x *Quad) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
 // Load
 type LoadNinjaFileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1446,7 +3353,7 @@ type LoadNinjaFileRequest struct {
 
 func (x *LoadNinjaFileRequest) Reset() {
 	*x = LoadNinjaFileRequest{}
-	mi := &file_server_proto_grpc_proto_msgTypes[30]
+	mi := &file_server_proto_grpc_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1458,7 +3365,7 @@ func (x *LoadNinjaFileRequest) String() string {
 func (*LoadNinjaFileRequest) ProtoMessage() {}
 
 func (x *LoadNinjaFileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[30]
+	mi := &file_server_proto_grpc_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1471,7 +3378,7 @@ func (x *LoadNinjaFileRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LoadNinjaFileRequest.ProtoReflect.Descriptor instead.
 func (*LoadNinjaFileRequest) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{30}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{56}
 }
 
 func (x *LoadNinjaFileRequest) GetFilePath() string {
@@ -1500,7 +3407,7 @@ type LoadNinjaFileResponse struct {
 
 func (x *LoadNinjaFileResponse) Reset() {
 	*x = LoadNinjaFileResponse{}
-	mi := &file_server_proto_grpc_proto_msgTypes[31]
+	mi := &file_server_proto_grpc_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1512,7 +3419,7 @@ func (x *LoadNinjaFileResponse) String() string {
 func (*LoadNinjaFileResponse) ProtoMessage() {}
 
 func (x *LoadNinjaFileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[31]
+	mi := &file_server_proto_grpc_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1525,53 +3432,179 @@ func (x *LoadNinjaFileResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LoadNinjaFileResponse.ProtoReflect.Descriptor instead.
 func (*LoadNinjaFileResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{31}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *LoadNinjaFileResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *LoadNinjaFileResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LoadNinjaFileResponse) GetStats() map[string]int64 {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *LoadNinjaFileResponse) GetBuildTime() string {
+	if x != nil {
+		return x.BuildTime
+	}
+	return ""
+}
+
+type LoadNinjaFileChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Starts a new file when it differs from the previous chunk, files are loaded in the order sent
+	FileName string `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	// Keep chunks well below the 4 MiB message limit, e.g. 1 MiB
+	Content       []byte `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoadNinjaFileChunk) Reset() {
+	*x = LoadNinjaFileChunk{}
+	mi := &file_server_proto_grpc_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoadNinjaFileChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadNinjaFileChunk) ProtoMessage() {}
+
+func (x *LoadNinjaFileChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadNinjaFileChunk.ProtoReflect.Descriptor instead.
+func (*LoadNinjaFileChunk) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *LoadNinjaFileChunk) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *LoadNinjaFileChunk) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type LoadNinjaFileProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	BytesReceived int64                  `protobuf:"varint,2,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"`
+	FilesLoaded   int64                  `protobuf:"varint,3,opt,name=files_loaded,json=filesLoaded,proto3" json:"files_loaded,omitempty"`
+	// Set on the last message, once every file was loaded
+	Result        *LoadNinjaFileResponse `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoadNinjaFileProgress) Reset() {
+	*x = LoadNinjaFileProgress{}
+	mi := &file_server_proto_grpc_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoadNinjaFileProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadNinjaFileProgress) ProtoMessage() {}
+
+func (x *LoadNinjaFileProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadNinjaFileProgress.ProtoReflect.Descriptor instead.
+func (*LoadNinjaFileProgress) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{59}
 }
 
-func (x *LoadNinjaFileResponse) GetStatus() string {
+func (x *LoadNinjaFileProgress) GetFileName() string {
 	if x != nil {
-		return x.Status
+		return x.FileName
 	}
 	return ""
 }
 
-func (x *LoadNinjaFileResponse) GetMessage() string {
+func (x *LoadNinjaFileProgress) GetBytesReceived() int64 {
 	if x != nil {
-		return x.Message
+		return x.BytesReceived
 	}
-	return ""
+	return 0
 }
 
-func (x *LoadNinjaFileResponse) GetStats() map[string]int64 {
+func (x *LoadNinjaFileProgress) GetFilesLoaded() int64 {
 	if x != nil {
-		return x.Stats
+		return x.FilesLoaded
 	}
-	return nil
+	return 0
 }
 
-func (x *LoadNinjaFileResponse) GetBuildTime() string {
+func (x *LoadNinjaFileProgress) GetResult() *LoadNinjaFileResponse {
 	if x != nil {
-		return x.BuildTime
+		return x.Result
 	}
-	return ""
+	return nil
 }
 
 // Ninja
 type NinjaBuild struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
-	BuildId       string                 `protobuf:"bytes,3,opt,name=build_id,json=buildId,proto3" json:"build_id,omitempty"`
-	Rule          string                 `protobuf:"bytes,4,opt,name=rule,proto3" json:"rule,omitempty"`
-	Variables     string                 `protobuf:"bytes,5,opt,name=variables,proto3" json:"variables,omitempty"`
-	Pool          string                 `protobuf:"bytes,6,opt,name=pool,proto3" json:"pool,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type      string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	BuildId   string                 `protobuf:"bytes,3,opt,name=build_id,json=buildId,proto3" json:"build_id,omitempty"`
+	Rule      string                 `protobuf:"bytes,4,opt,name=rule,proto3" json:"rule,omitempty"`
+	Variables string                 `protobuf:"bytes,5,opt,name=variables,proto3" json:"variables,omitempty"`
+	Pool      string                 `protobuf:"bytes,6,opt,name=pool,proto3" json:"pool,omitempty"`
+	Owner     string                 `protobuf:"bytes,7,opt,name=owner,proto3" json:"owner,omitempty"`
+	// JSON object of the labels like variables
+	Labels        string `protobuf:"bytes,8,opt,name=labels,proto3" json:"labels,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *NinjaBuild) Reset() {
 	*x = NinjaBuild{}
-	mi := &file_server_proto_grpc_proto_msgTypes[32]
+	mi := &file_server_proto_grpc_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1583,7 +3616,7 @@ func (x *NinjaBuild) String() string {
 func (*NinjaBuild) ProtoMessage() {}
 
 func (x *NinjaBuild) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[32]
+	mi := &file_server_proto_grpc_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1596,7 +3629,7 @@ func (x *NinjaBuild) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NinjaBuild.ProtoReflect.Descriptor instead.
 func (*NinjaBuild) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{32}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{60}
 }
 
 func (x *NinjaBuild) GetId() string {
@@ -1641,6 +3674,20 @@ func (x *NinjaBuild) GetPool() string {
 	return ""
 }
 
+func (x *NinjaBuild) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *NinjaBuild) GetLabels() string {
+	if x != nil {
+		return x.Labels
+	}
+	return ""
+}
+
 type NinjaFile struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -1653,7 +3700,7 @@ type NinjaFile struct {
 
 func (x *NinjaFile) Reset() {
 	*x = NinjaFile{}
-	mi := &file_server_proto_grpc_proto_msgTypes[33]
+	mi := &file_server_proto_grpc_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1665,7 +3712,7 @@ func (x *NinjaFile) String() string {
 func (*NinjaFile) ProtoMessage() {}
 
 func (x *NinjaFile) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[33]
+	mi := &file_server_proto_grpc_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1678,7 +3725,7 @@ func (x *NinjaFile) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NinjaFile.ProtoReflect.Descriptor instead.
 func (*NinjaFile) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{33}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{61}
 }
 
 func (x *NinjaFile) GetId() string {
@@ -1723,7 +3770,7 @@ type NinjaRule struct {
 
 func (x *NinjaRule) Reset() {
 	*x = NinjaRule{}
-	mi := &file_server_proto_grpc_proto_msgTypes[34]
+	mi := &file_server_proto_grpc_proto_msgTypes[62]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1735,7 +3782,7 @@ func (x *NinjaRule) String() string {
 func (*NinjaRule) ProtoMessage() {}
 
 func (x *NinjaRule) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[34]
+	mi := &file_server_proto_grpc_proto_msgTypes[62]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1748,7 +3795,7 @@ func (x *NinjaRule) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NinjaRule.ProtoReflect.Descriptor instead.
 func (*NinjaRule) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{34}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{62}
 }
 
 func (x *NinjaRule) GetId() string {
@@ -1794,20 +3841,23 @@ func (x *NinjaRule) GetVariables() string {
 }
 
 type NinjaTarget struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
-	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
-	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
-	Hash          string                 `protobuf:"bytes,5,opt,name=hash,proto3" json:"hash,omitempty"`
-	Build         string                 `protobuf:"bytes,6,opt,name=build,proto3" json:"build,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type   string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Path   string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Status string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Hash   string                 `protobuf:"bytes,5,opt,name=hash,proto3" json:"hash,omitempty"`
+	Build  string                 `protobuf:"bytes,6,opt,name=build,proto3" json:"build,omitempty"`
+	Owner  string                 `protobuf:"bytes,7,opt,name=owner,proto3" json:"owner,omitempty"`
+	// JSON object of the labels like the build variables
+	Labels        string `protobuf:"bytes,8,opt,name=labels,proto3" json:"labels,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *NinjaTarget) Reset() {
 	*x = NinjaTarget{}
-	mi := &file_server_proto_grpc_proto_msgTypes[35]
+	mi := &file_server_proto_grpc_proto_msgTypes[63]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1819,7 +3869,7 @@ func (x *NinjaTarget) String() string {
 func (*NinjaTarget) ProtoMessage() {}
 
 func (x *NinjaTarget) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_grpc_proto_msgTypes[35]
+	mi := &file_server_proto_grpc_proto_msgTypes[63]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1832,7 +3882,7 @@ func (x *NinjaTarget) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NinjaTarget.ProtoReflect.Descriptor instead.
 func (*NinjaTarget) Descriptor() ([]byte, []int) {
-	return file_server_proto_grpc_proto_rawDescGZIP(), []int{35}
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{63}
 }
 
 func (x *NinjaTarget) GetId() string {
@@ -1877,6 +3927,285 @@ func (x *NinjaTarget) GetBuild() string {
 	return ""
 }
 
+func (x *NinjaTarget) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *NinjaTarget) GetLabels() string {
+	if x != nil {
+		return x.Labels
+	}
+	return ""
+}
+
+// Streaming
+type StreamTargetsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only targets built by this rule when set
+	RuleName string `protobuf:"bytes,1,opt,name=rule_name,json=ruleName,proto3" json:"rule_name,omitempty"`
+	// Only targets with this status when set
+	Status string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// Only targets with this owner when set
+	Owner string `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	// Only targets with all these labels when set, key=value or a key alone for any value
+	Labels        []string `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTargetsRequest) Reset() {
+	*x = StreamTargetsRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTargetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTargetsRequest) ProtoMessage() {}
+
+func (x *StreamTargetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTargetsRequest.ProtoReflect.Descriptor instead.
+func (*StreamTargetsRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *StreamTargetsRequest) GetRuleName() string {
+	if x != nil {
+		return x.RuleName
+	}
+	return ""
+}
+
+func (x *StreamTargetsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *StreamTargetsRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *StreamTargetsRequest) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type StreamQuadsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only quads of this subject IRI when set, e.g. target:app
+	Subject       string `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamQuadsRequest) Reset() {
+	*x = StreamQuadsRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamQuadsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamQuadsRequest) ProtoMessage() {}
+
+func (x *StreamQuadsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamQuadsRequest.ProtoReflect.Descriptor instead.
+func (*StreamQuadsRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *StreamQuadsRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+type WatchTargetsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only these target paths when set
+	Paths []string `protobuf:"bytes,1,rep,name=paths,proto3" json:"paths,omitempty"`
+	// Only targets whose path starts with this prefix when set
+	PathPrefix string `protobuf:"bytes,2,opt,name=path_prefix,json=pathPrefix,proto3" json:"path_prefix,omitempty"`
+	// Only changes to one of these statuses when set
+	Statuses      []string `protobuf:"bytes,3,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTargetsRequest) Reset() {
+	*x = WatchTargetsRequest{}
+	mi := &file_server_proto_grpc_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTargetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTargetsRequest) ProtoMessage() {}
+
+func (x *WatchTargetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTargetsRequest.ProtoReflect.Descriptor instead.
+func (*WatchTargetsRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *WatchTargetsRequest) GetPaths() []string {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+func (x *WatchTargetsRequest) GetPathPrefix() string {
+	if x != nil {
+		return x.PathPrefix
+	}
+	return ""
+}
+
+func (x *WatchTargetsRequest) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+type TargetEvent struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Path           string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Status         string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	PreviousStatus string                 `protobuf:"bytes,4,opt,name=previous_status,json=previousStatus,proto3" json:"previous_status,omitempty"`
+	Hash           string                 `protobuf:"bytes,5,opt,name=hash,proto3" json:"hash,omitempty"`
+	Time           string                 `protobuf:"bytes,6,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TargetEvent) Reset() {
+	*x = TargetEvent{}
+	mi := &file_server_proto_grpc_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetEvent) ProtoMessage() {}
+
+func (x *TargetEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_grpc_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetEvent.ProtoReflect.Descriptor instead.
+func (*TargetEvent) Descriptor() ([]byte, []int) {
+	return file_server_proto_grpc_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *TargetEvent) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TargetEvent) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *TargetEvent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TargetEvent) GetPreviousStatus() string {
+	if x != nil {
+		return x.PreviousStatus
+	}
+	return ""
+}
+
+func (x *TargetEvent) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *TargetEvent) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
 var File_server_proto_grpc_proto protoreflect.FileDescriptor
 
 const file_server_proto_grpc_proto_rawDesc = "" +
@@ -1886,10 +4215,59 @@ const file_server_proto_grpc_proto_rawDesc = "" +
 	"\x0eHealthResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1c\n" +
 	"\ttimestamp\x18\x02 \x01(\tR\ttimestamp\"\x0f\n" +
-	"\rStatusRequest\"B\n" +
+	"\rStatusRequest\"\xaf\x05\n" +
 	"\x0eStatusResponse\x12\x18\n" +
 	"\aservice\x18\x01 \x01(\tR\aservice\x12\x16\n" +
-	"\x06uptime\x18\x02 \x01(\tR\x06uptime\"\xd7\x02\n" +
+	"\x06uptime\x18\x02 \x01(\tR\x06uptime\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12\x1d\n" +
+	"\n" +
+	"build_time\x18\x04 \x01(\tR\tbuildTime\x12\x1b\n" +
+	"\tcommit_id\x18\x05 \x01(\tR\bcommitId\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x06 \x01(\tR\tstartTime\x12\x1d\n" +
+	"\n" +
+	"store_path\x18\a \x01(\tR\tstorePath\x12#\n" +
+	"\rstore_backend\x18\b \x01(\tR\fstoreBackend\x12\x1d\n" +
+	"\n" +
+	"quad_count\x18\t \x01(\x03R\tquadCount\x12\x1d\n" +
+	"\n" +
+	"node_count\x18\n" +
+	" \x01(\x03R\tnodeCount\x12!\n" +
+	"\ftarget_count\x18\v \x01(\x03R\vtargetCount\x12\x1d\n" +
+	"\n" +
+	"rule_count\x18\f \x01(\x03R\truleCount\x12\x1f\n" +
+	"\vbuild_count\x18\r \x01(\x03R\n" +
+	"buildCount\x12\x1f\n" +
+	"\vactive_runs\x18\x0e \x01(\x05R\n" +
+	"activeRuns\x12+\n" +
+	"\x11connected_workers\x18\x0f \x01(\x05R\x10connectedWorkers\x12+\n" +
+	"\x11event_subscribers\x18\x10 \x01(\x05R\x10eventSubscribers\x12%\n" +
+	"\x0euptime_seconds\x18\x11 \x01(\x03R\ruptimeSeconds\x122\n" +
+	"\aprocess\x18\x12 \x01(\v2\x18.distninja.ProcessStatusR\aprocess\x12;\n" +
+	"\vevent_sinks\x18\x13 \x03(\v2\x1a.distninja.EventSinkStatusR\n" +
+	"eventSinks\"k\n" +
+	"\x0fEventSinkStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04sent\x18\x02 \x01(\x04R\x04sent\x12\x16\n" +
+	"\x06failed\x18\x03 \x01(\x04R\x06failed\x12\x18\n" +
+	"\adropped\x18\x04 \x01(\x04R\adropped\"\xd0\x02\n" +
+	"\rProcessStatus\x12\x10\n" +
+	"\x03pid\x18\x01 \x01(\x05R\x03pid\x12\x1e\n" +
+	"\n" +
+	"goroutines\x18\x02 \x01(\x05R\n" +
+	"goroutines\x12(\n" +
+	"\x10heap_alloc_bytes\x18\x03 \x01(\x04R\x0eheapAllocBytes\x12\x1b\n" +
+	"\tsys_bytes\x18\x04 \x01(\x04R\bsysBytes\x12\x1b\n" +
+	"\tgc_cycles\x18\x05 \x01(\rR\bgcCycles\x12\x1d\n" +
+	"\n" +
+	"open_files\x18\x06 \x01(\x05R\topenFiles\x12#\n" +
+	"\rhttp_requests\x18\a \x01(\x03R\fhttpRequests\x12\x1f\n" +
+	"\vhttp_errors\x18\b \x01(\x03R\n" +
+	"httpErrors\x12#\n" +
+	"\rgrpc_requests\x18\t \x01(\x03R\fgrpcRequests\x12\x1f\n" +
+	"\vgrpc_errors\x18\n" +
+	" \x01(\x03R\n" +
+	"grpcErrors\"\xeb\x03\n" +
 	"\x12CreateBuildRequest\x12\x19\n" +
 	"\bbuild_id\x18\x01 \x01(\tR\abuildId\x12\x12\n" +
 	"\x04rule\x18\x02 \x01(\tR\x04rule\x12J\n" +
@@ -1899,9 +4277,15 @@ const file_server_proto_grpc_proto_rawDesc = "" +
 	"\aoutputs\x18\x06 \x03(\tR\aoutputs\x12#\n" +
 	"\rimplicit_deps\x18\a \x03(\tR\fimplicitDeps\x12\x1d\n" +
 	"\n" +
-	"order_deps\x18\b \x03(\tR\torderDeps\x1a<\n" +
+	"order_deps\x18\b \x03(\tR\torderDeps\x12\x14\n" +
+	"\x05owner\x18\t \x01(\tR\x05owner\x12A\n" +
+	"\x06labels\x18\n" +
+	" \x03(\v2).distninja.CreateBuildRequest.LabelsEntryR\x06labels\x1a<\n" +
 	"\x0eVariablesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"H\n" +
 	"\x13CreateBuildResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x19\n" +
@@ -1918,7 +4302,12 @@ const file_server_proto_grpc_proto_rawDesc = "" +
 	"\x11BuildOrderRequest\"5\n" +
 	"\x12BuildOrderResponse\x12\x1f\n" +
 	"\vbuild_order\x18\x01 \x03(\tR\n" +
-	"buildOrder\"\xec\x01\n" +
+	"buildOrder\"$\n" +
+	"\x12DeleteBuildRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"H\n" +
+	"\x13DeleteBuildResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x19\n" +
+	"\bbuild_id\x18\x02 \x01(\tR\abuildId\"\xec\x01\n" +
 	"\x11CreateRuleRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
 	"\acommand\x18\x02 \x01(\tR\acommand\x12 \n" +
@@ -1931,12 +4320,35 @@ const file_server_proto_grpc_proto_rawDesc = "" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\"$\n" +
 	"\x0eGetRuleRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\"6\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"d\n" +
 	"\x17GetTargetsByRuleRequest\x12\x1b\n" +
-	"\trule_name\x18\x01 \x01(\tR\bruleName\"L\n" +
+	"\trule_name\x18\x01 \x01(\tR\bruleName\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x16\n" +
+	"\x06labels\x18\x03 \x03(\tR\x06labels\"L\n" +
 	"\x18GetTargetsByRuleResponse\x120\n" +
-	"\atargets\x18\x01 \x03(\v2\x16.distninja.NinjaTargetR\atargets\"\x16\n" +
-	"\x14GetAllTargetsRequest\"I\n" +
+	"\atargets\x18\x01 \x03(\v2\x16.distninja.NinjaTargetR\atargets\"\xec\x01\n" +
+	"\x11UpdateRuleRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\acommand\x18\x02 \x01(\tR\acommand\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12I\n" +
+	"\tvariables\x18\x04 \x03(\v2+.distninja.UpdateRuleRequest.VariablesEntryR\tvariables\x1a<\n" +
+	"\x0eVariablesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"t\n" +
+	"\x12UpdateRuleResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06builds\x18\x03 \x01(\x05R\x06builds\x12\x1a\n" +
+	"\bwarnings\x18\x04 \x03(\tR\bwarnings\"=\n" +
+	"\x11DeleteRuleRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05force\x18\x02 \x01(\bR\x05force\"@\n" +
+	"\x12DeleteRuleResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"D\n" +
+	"\x14GetAllTargetsRequest\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\x12\x16\n" +
+	"\x06labels\x18\x02 \x03(\tR\x06labels\"I\n" +
 	"\x15GetAllTargetsResponse\x120\n" +
 	"\atargets\x18\x01 \x03(\v2\x16.distninja.NinjaTargetR\atargets\"&\n" +
 	"\x10GetTargetRequest\x12\x12\n" +
@@ -1948,24 +4360,117 @@ const file_server_proto_grpc_proto_rawDesc = "" +
 	"#GetTargetReverseDependenciesRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\"q\n" +
 	"$GetTargetReverseDependenciesResponse\x12I\n" +
-	"\x14reverse_dependencies\x18\x01 \x03(\v2\x16.distninja.NinjaTargetR\x13reverseDependencies\"G\n" +
+	"\x14reverse_dependencies\x18\x01 \x03(\v2\x16.distninja.NinjaTargetR\x13reverseDependencies\"\xaf\x01\n" +
+	"\x1bTraverseDependenciesRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1c\n" +
+	"\tdirection\x18\x02 \x01(\tR\tdirection\x12\x14\n" +
+	"\x05depth\x18\x03 \x01(\x05R\x05depth\x12\x1d\n" +
+	"\n" +
+	"order_only\x18\x04 \x01(\bR\torderOnly\x12)\n" +
+	"\x10implicit_outputs\x18\x05 \x01(\bR\x0fimplicitOutputs\"\xbb\x01\n" +
+	"\x0eDependencyNode\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x12\n" +
+	"\x04rule\x18\x04 \x01(\tR\x04rule\x12\x1b\n" +
+	"\tfile_type\x18\x05 \x01(\tR\bfileType\x12\x14\n" +
+	"\x05depth\x18\x06 \x01(\x05R\x05depth\x12\x12\n" +
+	"\x04edge\x18\a \x01(\tR\x04edge\x12\x12\n" +
+	"\x04from\x18\b \x01(\tR\x04from\"O\n" +
+	"\x1cTraverseDependenciesResponse\x12/\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x19.distninja.DependencyNodeR\x05nodes\"\x90\x01\n" +
 	"\x19UpdateTargetStatusRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\tR\x06status\"4\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x15\n" +
+	"\x06run_id\x18\x03 \x01(\tR\x05runId\x12\x16\n" +
+	"\x06worker\x18\x04 \x01(\tR\x06worker\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\"4\n" +
 	"\x1aUpdateTargetStatusResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\"\x13\n" +
-	"\x11FindCyclesRequest\"_\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"_\n" +
+	"\x1dBulkUpdateTargetStatusRequest\x12>\n" +
+	"\aupdates\x18\x01 \x03(\v2$.distninja.UpdateTargetStatusRequestR\aupdates\"s\n" +
+	"\x1eBulkUpdateTargetStatusResponse\x12\x18\n" +
+	"\aupdated\x18\x01 \x01(\x05R\aupdated\x127\n" +
+	"\aresults\x18\x02 \x03(\v2\x1d.distninja.TargetStatusResultR\aresults\"V\n" +
+	"\x12TargetStatusResult\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"[\n" +
+	"\x17GetTargetHistoryRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"S\n" +
+	"\x18GetTargetHistoryResponse\x127\n" +
+	"\achanges\x18\x01 \x03(\v2\x1d.distninja.TargetStatusChangeR\achanges\"\xca\x01\n" +
+	"\x12TargetStatusChange\x12\x16\n" +
+	"\x06target\x18\x01 \x01(\tR\x06target\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12'\n" +
+	"\x0fprevious_status\x18\x03 \x01(\tR\x0epreviousStatus\x12\x12\n" +
+	"\x04time\x18\x04 \x01(\tR\x04time\x12\x15\n" +
+	"\x06run_id\x18\x05 \x01(\tR\x05runId\x12\x16\n" +
+	"\x06worker\x18\x06 \x01(\tR\x06worker\x12\x18\n" +
+	"\amessage\x18\a \x01(\tR\amessage\")\n" +
+	"\x13DeleteTargetRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"B\n" +
+	"\x14DeleteTargetResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"B\n" +
+	"\x11MoveTargetRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x19\n" +
+	"\bnew_path\x18\x02 \x01(\tR\anewPath\"\xba\x01\n" +
+	"\x12MoveTargetResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x19\n" +
+	"\bnew_path\x18\x03 \x01(\tR\anewPath\x12\x19\n" +
+	"\bbuild_id\x18\x04 \x01(\tR\abuildId\x12\x1d\n" +
+	"\n" +
+	"file_moved\x18\x05 \x01(\bR\tfileMoved\x12#\n" +
+	"\rhistory_moved\x18\x06 \x01(\x05R\fhistoryMoved\"\xc4\x01\n" +
+	"\x16SetTargetLabelsRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12E\n" +
+	"\x06labels\x18\x03 \x03(\v2-.distninja.SetTargetLabelsRequest.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xbe\x01\n" +
+	"\x15SetBuildLabelsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12D\n" +
+	"\x06labels\x18\x03 \x03(\v2,.distninja.SetBuildLabelsRequest.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"2\n" +
+	"\x11FindCyclesRequest\x12\x1d\n" +
+	"\n" +
+	"first_only\x18\x01 \x01(\bR\tfirstOnly\"_\n" +
 	"\x12FindCyclesResponse\x12(\n" +
 	"\x06cycles\x18\x01 \x03(\v2\x10.distninja.CycleR\x06cycles\x12\x1f\n" +
 	"\vcycle_count\x18\x02 \x01(\x05R\n" +
 	"cycleCount\"\x1d\n" +
 	"\x05Cycle\x12\x14\n" +
-	"\x05nodes\x18\x01 \x03(\tR\x05nodes\")\n" +
+	"\x05nodes\x18\x01 \x03(\tR\x05nodes\"h\n" +
+	"\x11GizmoQueryRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12'\n" +
+	"\x0ftimeout_seconds\x18\x03 \x01(\x05R\x0etimeoutSeconds\"L\n" +
+	"\x12GizmoQueryResponse\x12\x18\n" +
+	"\aresults\x18\x01 \x03(\tR\aresults\x12\x1c\n" +
+	"\ttruncated\x18\x02 \x01(\bR\ttruncated\"[\n" +
 	"\x11DebugQuadsRequest\x12\x14\n" +
-	"\x05limit\x18\x01 \x01(\x05R\x05limit\"D\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\x12\x18\n" +
+	"\asubject\x18\x03 \x01(\tR\asubject\"\x9e\x01\n" +
 	"\x12DebugQuadsResponse\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\x12\x14\n" +
-	"\x05limit\x18\x02 \x01(\x05R\x05limit\"M\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12%\n" +
+	"\x05quads\x18\x03 \x03(\v2\x0f.distninja.QuadR\x05quads\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\x12\x19\n" +
+	"\bhas_more\x18\x05 \x01(\bR\ahasMore\"l\n" +
+	"\x04Quad\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\x12\x1c\n" +
+	"\tpredicate\x18\x02 \x01(\tR\tpredicate\x12\x16\n" +
+	"\x06object\x18\x03 \x01(\tR\x06object\x12\x14\n" +
+	"\x05label\x18\x04 \x01(\tR\x05label\"M\n" +
 	"\x14LoadNinjaFileRequest\x12\x1b\n" +
 	"\tfile_path\x18\x01 \x01(\tR\bfilePath\x12\x18\n" +
 	"\acontent\x18\x02 \x01(\tR\acontent\"\xe5\x01\n" +
@@ -1978,7 +4483,15 @@ const file_server_proto_grpc_proto_rawDesc = "" +
 	"\n" +
 	"StatsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\x91\x01\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"K\n" +
+	"\x12LoadNinjaFileChunk\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\"\xb8\x01\n" +
+	"\x15LoadNinjaFileProgress\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12%\n" +
+	"\x0ebytes_received\x18\x02 \x01(\x03R\rbytesReceived\x12!\n" +
+	"\ffiles_loaded\x18\x03 \x01(\x03R\vfilesLoaded\x128\n" +
+	"\x06result\x18\x04 \x01(\v2 .distninja.LoadNinjaFileResponseR\x06result\"\xbf\x01\n" +
 	"\n" +
 	"NinjaBuild\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
@@ -1986,7 +4499,9 @@ const file_server_proto_grpc_proto_rawDesc = "" +
 	"\bbuild_id\x18\x03 \x01(\tR\abuildId\x12\x12\n" +
 	"\x04rule\x18\x04 \x01(\tR\x04rule\x12\x1c\n" +
 	"\tvariables\x18\x05 \x01(\tR\tvariables\x12\x12\n" +
-	"\x04pool\x18\x06 \x01(\tR\x04pool\"`\n" +
+	"\x04pool\x18\x06 \x01(\tR\x04pool\x12\x14\n" +
+	"\x05owner\x18\a \x01(\tR\x05owner\x12\x16\n" +
+	"\x06labels\x18\b \x01(\tR\x06labels\"`\n" +
 	"\tNinjaFile\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x12\x12\n" +
@@ -1998,36 +4513,75 @@ const file_server_proto_grpc_proto_rawDesc = "" +
 	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
 	"\acommand\x18\x04 \x01(\tR\acommand\x12 \n" +
 	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1c\n" +
-	"\tvariables\x18\x06 \x01(\tR\tvariables\"\x87\x01\n" +
+	"\tvariables\x18\x06 \x01(\tR\tvariables\"\xb5\x01\n" +
 	"\vNinjaTarget\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x12\x12\n" +
 	"\x04path\x18\x03 \x01(\tR\x04path\x12\x16\n" +
 	"\x06status\x18\x04 \x01(\tR\x06status\x12\x12\n" +
 	"\x04hash\x18\x05 \x01(\tR\x04hash\x12\x14\n" +
-	"\x05build\x18\x06 \x01(\tR\x05build2\xed\n" +
-	"\n" +
+	"\x05build\x18\x06 \x01(\tR\x05build\x12\x14\n" +
+	"\x05owner\x18\a \x01(\tR\x05owner\x12\x16\n" +
+	"\x06labels\x18\b \x01(\tR\x06labels\"y\n" +
+	"\x14StreamTargetsRequest\x12\x1b\n" +
+	"\trule_name\x18\x01 \x01(\tR\bruleName\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x14\n" +
+	"\x05owner\x18\x03 \x01(\tR\x05owner\x12\x16\n" +
+	"\x06labels\x18\x04 \x03(\tR\x06labels\".\n" +
+	"\x12StreamQuadsRequest\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\"h\n" +
+	"\x13WatchTargetsRequest\x12\x14\n" +
+	"\x05paths\x18\x01 \x03(\tR\x05paths\x12\x1f\n" +
+	"\vpath_prefix\x18\x02 \x01(\tR\n" +
+	"pathPrefix\x12\x1a\n" +
+	"\bstatuses\x18\x03 \x03(\tR\bstatuses\"\x9a\x01\n" +
+	"\vTargetEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12'\n" +
+	"\x0fprevious_status\x18\x04 \x01(\tR\x0epreviousStatus\x12\x12\n" +
+	"\x04hash\x18\x05 \x01(\tR\x04hash\x12\x12\n" +
+	"\x04time\x18\x06 \x01(\tR\x04time2\xb9\x14\n" +
 	"\x10DistNinjaService\x12=\n" +
 	"\x06Health\x12\x18.distninja.HealthRequest\x1a\x19.distninja.HealthResponse\x12=\n" +
 	"\x06Status\x12\x18.distninja.StatusRequest\x1a\x19.distninja.StatusResponse\x12L\n" +
 	"\vCreateBuild\x12\x1d.distninja.CreateBuildRequest\x1a\x1e.distninja.CreateBuildResponse\x12=\n" +
 	"\bGetBuild\x12\x1a.distninja.GetBuildRequest\x1a\x15.distninja.NinjaBuild\x12L\n" +
 	"\rGetBuildStats\x12\x1c.distninja.BuildStatsRequest\x1a\x1d.distninja.BuildStatsResponse\x12L\n" +
-	"\rGetBuildOrder\x12\x1c.distninja.BuildOrderRequest\x1a\x1d.distninja.BuildOrderResponse\x12I\n" +
+	"\rGetBuildOrder\x12\x1c.distninja.BuildOrderRequest\x1a\x1d.distninja.BuildOrderResponse\x12L\n" +
+	"\vDeleteBuild\x12\x1d.distninja.DeleteBuildRequest\x1a\x1e.distninja.DeleteBuildResponse\x12I\n" +
+	"\x0eSetBuildLabels\x12 .distninja.SetBuildLabelsRequest\x1a\x15.distninja.NinjaBuild\x12I\n" +
 	"\n" +
 	"CreateRule\x12\x1c.distninja.CreateRuleRequest\x1a\x1d.distninja.CreateRuleResponse\x12:\n" +
 	"\aGetRule\x12\x19.distninja.GetRuleRequest\x1a\x14.distninja.NinjaRule\x12[\n" +
-	"\x10GetTargetsByRule\x12\".distninja.GetTargetsByRuleRequest\x1a#.distninja.GetTargetsByRuleResponse\x12R\n" +
+	"\x10GetTargetsByRule\x12\".distninja.GetTargetsByRuleRequest\x1a#.distninja.GetTargetsByRuleResponse\x12I\n" +
+	"\n" +
+	"UpdateRule\x12\x1c.distninja.UpdateRuleRequest\x1a\x1d.distninja.UpdateRuleResponse\x12I\n" +
+	"\n" +
+	"DeleteRule\x12\x1c.distninja.DeleteRuleRequest\x1a\x1d.distninja.DeleteRuleResponse\x12R\n" +
 	"\rGetAllTargets\x12\x1f.distninja.GetAllTargetsRequest\x1a .distninja.GetAllTargetsResponse\x12@\n" +
 	"\tGetTarget\x12\x1b.distninja.GetTargetRequest\x1a\x16.distninja.NinjaTarget\x12j\n" +
 	"\x15GetTargetDependencies\x12'.distninja.GetTargetDependenciesRequest\x1a(.distninja.GetTargetDependenciesResponse\x12\x7f\n" +
-	"\x1cGetTargetReverseDependencies\x12..distninja.GetTargetReverseDependenciesRequest\x1a/.distninja.GetTargetReverseDependenciesResponse\x12a\n" +
-	"\x12UpdateTargetStatus\x12$.distninja.UpdateTargetStatusRequest\x1a%.distninja.UpdateTargetStatusResponse\x12I\n" +
+	"\x1cGetTargetReverseDependencies\x12..distninja.GetTargetReverseDependenciesRequest\x1a/.distninja.GetTargetReverseDependenciesResponse\x12g\n" +
+	"\x14TraverseDependencies\x12&.distninja.TraverseDependenciesRequest\x1a'.distninja.TraverseDependenciesResponse\x12a\n" +
+	"\x12UpdateTargetStatus\x12$.distninja.UpdateTargetStatusRequest\x1a%.distninja.UpdateTargetStatusResponse\x12m\n" +
+	"\x16BulkUpdateTargetStatus\x12(.distninja.BulkUpdateTargetStatusRequest\x1a).distninja.BulkUpdateTargetStatusResponse\x12O\n" +
+	"\fDeleteTarget\x12\x1e.distninja.DeleteTargetRequest\x1a\x1f.distninja.DeleteTargetResponse\x12I\n" +
+	"\n" +
+	"MoveTarget\x12\x1c.distninja.MoveTargetRequest\x1a\x1d.distninja.MoveTargetResponse\x12L\n" +
+	"\x0fSetTargetLabels\x12!.distninja.SetTargetLabelsRequest\x1a\x16.distninja.NinjaTarget\x12[\n" +
+	"\x10GetTargetHistory\x12\".distninja.GetTargetHistoryRequest\x1a#.distninja.GetTargetHistoryResponse\x12I\n" +
 	"\n" +
 	"FindCycles\x12\x1c.distninja.FindCyclesRequest\x1a\x1d.distninja.FindCyclesResponse\x12I\n" +
 	"\n" +
+	"GizmoQuery\x12\x1c.distninja.GizmoQueryRequest\x1a\x1d.distninja.GizmoQueryResponse\x12I\n" +
+	"\n" +
 	"DebugQuads\x12\x1c.distninja.DebugQuadsRequest\x1a\x1d.distninja.DebugQuadsResponse\x12R\n" +
-	"\rLoadNinjaFile\x12\x1f.distninja.LoadNinjaFileRequest\x1a .distninja.LoadNinjaFileResponseB3Z1github.com/distninja/distninja/server/proto;protob\x06proto3"
+	"\rLoadNinjaFile\x12\x1f.distninja.LoadNinjaFileRequest\x1a .distninja.LoadNinjaFileResponse\x12Z\n" +
+	"\x13LoadNinjaFileStream\x12\x1d.distninja.LoadNinjaFileChunk\x1a .distninja.LoadNinjaFileProgress(\x010\x01\x12J\n" +
+	"\rStreamTargets\x12\x1f.distninja.StreamTargetsRequest\x1a\x16.distninja.NinjaTarget0\x01\x12?\n" +
+	"\vStreamQuads\x12\x1d.distninja.StreamQuadsRequest\x1a\x0f.distninja.Quad0\x01\x12H\n" +
+	"\fWatchTargets\x12\x1e.distninja.WatchTargetsRequest\x1a\x16.distninja.TargetEvent0\x01B3Z1github.com/distninja/distninja/server/proto;protob\x06proto3"
 
 var (
 	file_server_proto_grpc_proto_rawDescOnce sync.Once
@@ -2041,98 +4595,176 @@ func file_server_proto_grpc_proto_rawDescGZIP() []byte {
 	return file_server_proto_grpc_proto_rawDescData
 }
 
-var file_server_proto_grpc_proto_msgTypes = make([]protoimpl.MessageInfo, 40)
+var file_server_proto_grpc_proto_msgTypes = make([]protoimpl.MessageInfo, 76)
 var file_server_proto_grpc_proto_goTypes = []any{
 	(*HealthRequest)(nil),                        // 0: distninja.HealthRequest
 	(*HealthResponse)(nil),                       // 1: distninja.HealthResponse
 	(*StatusRequest)(nil),                        // 2: distninja.StatusRequest
 	(*StatusResponse)(nil),                       // 3: distninja.StatusResponse
-	(*CreateBuildRequest)(nil),                   // 4: distninja.CreateBuildRequest
-	(*CreateBuildResponse)(nil),                  // 5: distninja.CreateBuildResponse
-	(*GetBuildRequest)(nil),                      // 6: distninja.GetBuildRequest
-	(*BuildStatsRequest)(nil),                    // 7: distninja.BuildStatsRequest
-	(*BuildStatsResponse)(nil),                   // 8: distninja.BuildStatsResponse
-	(*BuildOrderRequest)(nil),                    // 9: distninja.BuildOrderRequest
-	(*BuildOrderResponse)(nil),                   // 10: distninja.BuildOrderResponse
-	(*CreateRuleRequest)(nil),                    // 11: distninja.CreateRuleRequest
-	(*CreateRuleResponse)(nil),                   // 12: distninja.CreateRuleResponse
-	(*GetRuleRequest)(nil),                       // 13: distninja.GetRuleRequest
-	(*GetTargetsByRuleRequest)(nil),              // 14: distninja.GetTargetsByRuleRequest
-	(*GetTargetsByRuleResponse)(nil),             // 15: distninja.GetTargetsByRuleResponse
-	(*GetAllTargetsRequest)(nil),                 // 16: distninja.GetAllTargetsRequest
-	(*GetAllTargetsResponse)(nil),                // 17: distninja.GetAllTargetsResponse
-	(*GetTargetRequest)(nil),                     // 18: distninja.GetTargetRequest
-	(*GetTargetDependenciesRequest)(nil),         // 19: distninja.GetTargetDependenciesRequest
-	(*GetTargetDependenciesResponse)(nil),        // 20: distninja.GetTargetDependenciesResponse
-	(*GetTargetReverseDependenciesRequest)(nil),  // 21: distninja.GetTargetReverseDependenciesRequest
-	(*GetTargetReverseDependenciesResponse)(nil), // 22: distninja.GetTargetReverseDependenciesResponse
-	(*UpdateTargetStatusRequest)(nil),            // 23: distninja.UpdateTargetStatusRequest
-	(*UpdateTargetStatusResponse)(nil),           // 24: distninja.UpdateTargetStatusResponse
-	(*FindCyclesRequest)(nil),                    // 25: distninja.FindCyclesRequest
-	(*FindCyclesResponse)(nil),                   // 26: distninja.FindCyclesResponse
-	(*Cycle)(nil),                                // 27: distninja.Cycle
-	(*DebugQuadsRequest)(nil),                    // 28: distninja.DebugQuadsRequest
-	(*DebugQuadsResponse)(nil),                   // 29: distninja.DebugQuadsResponse
-	(*LoadNinjaFileRequest)(nil),                 // 30: distninja.LoadNinjaFileRequest
-	(*LoadNinjaFileResponse)(nil),                // 31: distninja.LoadNinjaFileResponse
-	(*NinjaBuild)(nil),                           // 32: distninja.NinjaBuild
-	(*NinjaFile)(nil),                            // 33: distninja.NinjaFile
-	(*NinjaRule)(nil),                            // 34: distninja.NinjaRule
-	(*NinjaTarget)(nil),                          // 35: distninja.NinjaTarget
-	nil,                                          // 36: distninja.CreateBuildRequest.VariablesEntry
-	nil,                                          // 37: distninja.BuildStatsResponse.StatsEntry
-	nil,                                          // 38: distninja.CreateRuleRequest.VariablesEntry
-	nil,                                          // 39: distninja.LoadNinjaFileResponse.StatsEntry
+	(*EventSinkStatus)(nil),                      // 4: distninja.EventSinkStatus
+	(*ProcessStatus)(nil),                        // 5: distninja.ProcessStatus
+	(*CreateBuildRequest)(nil),                   // 6: distninja.CreateBuildRequest
+	(*CreateBuildResponse)(nil),                  // 7: distninja.CreateBuildResponse
+	(*GetBuildRequest)(nil),                      // 8: distninja.GetBuildRequest
+	(*BuildStatsRequest)(nil),                    // 9: distninja.BuildStatsRequest
+	(*BuildStatsResponse)(nil),                   // 10: distninja.BuildStatsResponse
+	(*BuildOrderRequest)(nil),                    // 11: distninja.BuildOrderRequest
+	(*BuildOrderResponse)(nil),                   // 12: distninja.BuildOrderResponse
+	(*DeleteBuildRequest)(nil),                   // 13: distninja.DeleteBuildRequest
+	(*DeleteBuildResponse)(nil),                  // 14: distninja.DeleteBuildResponse
+	(*CreateRuleRequest)(nil),                    // 15: distninja.CreateRuleRequest
+	(*CreateRuleResponse)(nil),                   // 16: distninja.CreateRuleResponse
+	(*GetRuleRequest)(nil),                       // 17: distninja.GetRuleRequest
+	(*GetTargetsByRuleRequest)(nil),              // 18: distninja.GetTargetsByRuleRequest
+	(*GetTargetsByRuleResponse)(nil),             // 19: distninja.GetTargetsByRuleResponse
+	(*UpdateRuleRequest)(nil),                    // 20: distninja.UpdateRuleRequest
+	(*UpdateRuleResponse)(nil),                   // 21: distninja.UpdateRuleResponse
+	(*DeleteRuleRequest)(nil),                    // 22: distninja.DeleteRuleRequest
+	(*DeleteRuleResponse)(nil),                   // 23: distninja.DeleteRuleResponse
+	(*GetAllTargetsRequest)(nil),                 // 24: distninja.GetAllTargetsRequest
+	(*GetAllTargetsResponse)(nil),                // 25: distninja.GetAllTargetsResponse
+	(*GetTargetRequest)(nil),                     // 26: distninja.GetTargetRequest
+	(*GetTargetDependenciesRequest)(nil),         // 27: distninja.GetTargetDependenciesRequest
+	(*GetTargetDependenciesResponse)(nil),        // 28: distninja.GetTargetDependenciesResponse
+	(*GetTargetReverseDependenciesRequest)(nil),  // 29: distninja.GetTargetReverseDependenciesRequest
+	(*GetTargetReverseDependenciesResponse)(nil), // 30: distninja.GetTargetReverseDependenciesResponse
+	(*TraverseDependenciesRequest)(nil),          // 31: distninja.TraverseDependenciesRequest
+	(*DependencyNode)(nil),                       // 32: distninja.DependencyNode
+	(*TraverseDependenciesResponse)(nil),         // 33: distninja.TraverseDependenciesResponse
+	(*UpdateTargetStatusRequest)(nil),            // 34: distninja.UpdateTargetStatusRequest
+	(*UpdateTargetStatusResponse)(nil),           // 35: distninja.UpdateTargetStatusResponse
+	(*BulkUpdateTargetStatusRequest)(nil),        // 36: distninja.BulkUpdateTargetStatusRequest
+	(*BulkUpdateTargetStatusResponse)(nil),       // 37: distninja.BulkUpdateTargetStatusResponse
+	(*TargetStatusResult)(nil),                   // 38: distninja.TargetStatusResult
+	(*GetTargetHistoryRequest)(nil),              // 39: distninja.GetTargetHistoryRequest
+	(*GetTargetHistoryResponse)(nil),             // 40: distninja.GetTargetHistoryResponse
+	(*TargetStatusChange)(nil),                   // 41: distninja.TargetStatusChange
+	(*DeleteTargetRequest)(nil),                  // 42: distninja.DeleteTargetRequest
+	(*DeleteTargetResponse)(nil),                 // 43: distninja.DeleteTargetResponse
+	(*MoveTargetRequest)(nil),                    // 44: distninja.MoveTargetRequest
+	(*MoveTargetResponse)(nil),                   // 45: distninja.MoveTargetResponse
+	(*SetTargetLabelsRequest)(nil),               // 46: distninja.SetTargetLabelsRequest
+	(*SetBuildLabelsRequest)(nil),                // 47: distninja.SetBuildLabelsRequest
+	(*FindCyclesRequest)(nil),                    // 48: distninja.FindCyclesRequest
+	(*FindCyclesResponse)(nil),                   // 49: distninja.FindCyclesResponse
+	(*Cycle)(nil),                                // 50: distninja.Cycle
+	(*GizmoQueryRequest)(nil),                    // 51: distninja.GizmoQueryRequest
+	(*GizmoQueryResponse)(nil),                   // 52: distninja.GizmoQueryResponse
+	(*DebugQuadsRequest)(nil),                    // 53: distninja.DebugQuadsRequest
+	(*DebugQuadsResponse)(nil),                   // 54: distninja.DebugQuadsResponse
+	(*Quad)(nil),                                 // 55: distninja.Quad
+	(*LoadNinjaFileRequest)(nil),                 // 56: distninja.LoadNinjaFileRequest
+	(*LoadNinjaFileResponse)(nil),                // 57: distninja.LoadNinjaFileResponse
+	(*LoadNinjaFileChunk)(nil),                   // 58: distninja.LoadNinjaFileChunk
+	(*LoadNinjaFileProgress)(nil),                // 59: distninja.LoadNinjaFileProgress
+	(*NinjaBuild)(nil),                           // 60: distninja.NinjaBuild
+	(*NinjaFile)(nil),                            // 61: distninja.NinjaFile
+	(*NinjaRule)(nil),                            // 62: distninja.NinjaRule
+	(*NinjaTarget)(nil),                          // 63: distninja.NinjaTarget
+	(*StreamTargetsRequest)(nil),                 // 64: distninja.StreamTargetsRequest
+	(*StreamQuadsRequest)(nil),                   // 65: distninja.StreamQuadsRequest
+	(*WatchTargetsRequest)(nil),                  // 66: distninja.WatchTargetsRequest
+	(*TargetEvent)(nil),                          // 67: distninja.TargetEvent
+	nil,                                          // 68: distninja.CreateBuildRequest.VariablesEntry
+	nil,                                          // 69: distninja.CreateBuildRequest.LabelsEntry
+	nil,                                          // 70: distninja.BuildStatsResponse.StatsEntry
+	nil,                                          // 71: distninja.CreateRuleRequest.VariablesEntry
+	nil,                                          // 72: distninja.UpdateRuleRequest.VariablesEntry
+	nil,                                          // 73: distninja.SetTargetLabelsRequest.LabelsEntry
+	nil,                                          // 74: distninja.SetBuildLabelsRequest.LabelsEntry
+	nil,                                          // 75: distninja.LoadNinjaFileResponse.StatsEntry
 }
 var file_server_proto_grpc_proto_depIdxs = []int32{
-	36, // 0: distninja.CreateBuildRequest.variables:type_name -> distninja.CreateBuildRequest.VariablesEntry
-	37, // 1: distninja.BuildStatsResponse.stats:type_name -> distninja.BuildStatsResponse.StatsEntry
-	38, // 2: distninja.CreateRuleRequest.variables:type_name -> distninja.CreateRuleRequest.VariablesEntry
-	35, // 3: distninja.GetTargetsByRuleResponse.targets:type_name -> distninja.NinjaTarget
-	35, // 4: distninja.GetAllTargetsResponse.targets:type_name -> distninja.NinjaTarget
-	33, // 5: distninja.GetTargetDependenciesResponse.dependencies:type_name -> distninja.NinjaFile
-	35, // 6: distninja.GetTargetReverseDependenciesResponse.reverse_dependencies:type_name -> distninja.NinjaTarget
-	27, // 7: distninja.FindCyclesResponse.cycles:type_name -> distninja.Cycle
-	39, // 8: distninja.LoadNinjaFileResponse.stats:type_name -> distninja.LoadNinjaFileResponse.StatsEntry
-	0,  // 9: distninja.DistNinjaService.Health:input_type -> distninja.HealthRequest
-	2,  // 10: distninja.DistNinjaService.Status:input_type -> distninja.StatusRequest
-	4,  // 11: distninja.DistNinjaService.CreateBuild:input_type -> distninja.CreateBuildRequest
-	6,  // 12: distninja.DistNinjaService.GetBuild:input_type -> distninja.GetBuildRequest
-	7,  // 13: distninja.DistNinjaService.GetBuildStats:input_type -> distninja.BuildStatsRequest
-	9,  // 14: distninja.DistNinjaService.GetBuildOrder:input_type -> distninja.BuildOrderRequest
-	11, // 15: distninja.DistNinjaService.CreateRule:input_type -> distninja.CreateRuleRequest
-	13, // 16: distninja.DistNinjaService.GetRule:input_type -> distninja.GetRuleRequest
-	14, // 17: distninja.DistNinjaService.GetTargetsByRule:input_type -> distninja.GetTargetsByRuleRequest
-	16, // 18: distninja.DistNinjaService.GetAllTargets:input_type -> distninja.GetAllTargetsRequest
-	18, // 19: distninja.DistNinjaService.GetTarget:input_type -> distninja.GetTargetRequest
-	19, // 20: distninja.DistNinjaService.GetTargetDependencies:input_type -> distninja.GetTargetDependenciesRequest
-	21, // 21: distninja.DistNinjaService.GetTargetReverseDependencies:input_type -> distninja.GetTargetReverseDependenciesRequest
-	23, // 22: distninja.DistNinjaService.UpdateTargetStatus:input_type -> distninja.UpdateTargetStatusRequest
-	25, // 23: distninja.DistNinjaService.FindCycles:input_type -> distninja.FindCyclesRequest
-	28, // 24: distninja.DistNinjaService.DebugQuads:input_type -> distninja.DebugQuadsRequest
-	30, // 25: distninja.DistNinjaService.LoadNinjaFile:input_type -> distninja.LoadNinjaFileRequest
-	1,  // 26: distninja.DistNinjaService.Health:output_type -> distninja.HealthResponse
-	3,  // 27: distninja.DistNinjaService.Status:output_type -> distninja.StatusResponse
-	5,  // 28: distninja.DistNinjaService.CreateBuild:output_type -> distninja.CreateBuildResponse
-	32, // 29: distninja.DistNinjaService.GetBuild:output_type -> distninja.NinjaBuild
-	8,  // 30: distninja.DistNinjaService.GetBuildStats:output_type -> distninja.BuildStatsResponse
-	10, // 31: distninja.DistNinjaService.GetBuildOrder:output_type -> distninja.BuildOrderResponse
-	12, // 32: distninja.DistNinjaService.CreateRule:output_type -> distninja.CreateRuleResponse
-	34, // 33: distninja.DistNinjaService.GetRule:output_type -> distninja.NinjaRule
-	15, // 34: distninja.DistNinjaService.GetTargetsByRule:output_type -> distninja.GetTargetsByRuleResponse
-	17, // 35: distninja.DistNinjaService.GetAllTargets:output_type -> distninja.GetAllTargetsResponse
-	35, // 36: distninja.DistNinjaService.GetTarget:output_type -> distninja.NinjaTarget
-	20, // 37: distninja.DistNinjaService.GetTargetDependencies:output_type -> distninja.GetTargetDependenciesResponse
-	22, // 38: distninja.DistNinjaService.GetTargetReverseDependencies:output_type -> distninja.GetTargetReverseDependenciesResponse
-	24, // 39: distninja.DistNinjaService.UpdateTargetStatus:output_type -> distninja.UpdateTargetStatusResponse
-	26, // 40: distninja.DistNinjaService.FindCycles:output_type -> distninja.FindCyclesResponse
-	29, // 41: distninja.DistNinjaService.DebugQuads:output_type -> distninja.DebugQuadsResponse
-	31, // 42: distninja.DistNinjaService.LoadNinjaFile:output_type -> distninja.LoadNinjaFileResponse
-	26, // [26:43] is the sub-list for method output_type
-	9,  // [9:26] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	5,  // 0: distninja.StatusResponse.process:type_name -> distninja.ProcessStatus
+	4,  // 1: distninja.StatusResponse.event_sinks:type_name -> distninja.EventSinkStatus
+	68, // 2: distninja.CreateBuildRequest.variables:type_name -> distninja.CreateBuildRequest.VariablesEntry
+	69, // 3: distninja.CreateBuildRequest.labels:type_name -> distninja.CreateBuildRequest.LabelsEntry
+	70, // 4: distninja.BuildStatsResponse.stats:type_name -> distninja.BuildStatsResponse.StatsEntry
+	71, // 5: distninja.CreateRuleRequest.variables:type_name -> distninja.CreateRuleRequest.VariablesEntry
+	63, // 6: distninja.GetTargetsByRuleResponse.targets:type_name -> distninja.NinjaTarget
+	72, // 7: distninja.UpdateRuleRequest.variables:type_name -> distninja.UpdateRuleRequest.VariablesEntry
+	63, // 8: distninja.GetAllTargetsResponse.targets:type_name -> distninja.NinjaTarget
+	61, // 9: distninja.GetTargetDependenciesResponse.dependencies:type_name -> distninja.NinjaFile
+	63, // 10: distninja.GetTargetReverseDependenciesResponse.reverse_dependencies:type_name -> distninja.NinjaTarget
+	32, // 11: distninja.TraverseDependenciesResponse.nodes:type_name -> distninja.DependencyNode
+	34, // 12: distninja.BulkUpdateTargetStatusRequest.updates:type_name -> distninja.UpdateTargetStatusRequest
+	38, // 13: distninja.BulkUpdateTargetStatusResponse.results:type_name -> distninja.TargetStatusResult
+	41, // 14: distninja.GetTargetHistoryResponse.changes:type_name -> distninja.TargetStatusChange
+	73, // 15: distninja.SetTargetLabelsRequest.labels:type_name -> distninja.SetTargetLabelsRequest.LabelsEntry
+	74, // 16: distninja.SetBuildLabelsRequest.labels:type_name -> distninja.SetBuildLabelsRequest.LabelsEntry
+	50, // 17: distninja.FindCyclesResponse.cycles:type_name -> distninja.Cycle
+	55, // 18: distninja.DebugQuadsResponse.quads:type_name -> distninja.Quad
+	75, // 19: distninja.LoadNinjaFileResponse.stats:type_name -> distninja.LoadNinjaFileResponse.StatsEntry
+	57, // 20: distninja.LoadNinjaFileProgress.result:type_name -> distninja.LoadNinjaFileResponse
+	0,  // 21: distninja.DistNinjaService.Health:input_type -> distninja.HealthRequest
+	2,  // 22: distninja.DistNinjaService.Status:input_type -> distninja.StatusRequest
+	6,  // 23: distninja.DistNinjaService.CreateBuild:input_type -> distninja.CreateBuildRequest
+	8,  // 24: distninja.DistNinjaService.GetBuild:input_type -> distninja.GetBuildRequest
+	9,  // 25: distninja.DistNinjaService.GetBuildStats:input_type -> distninja.BuildStatsRequest
+	11, // 26: distninja.DistNinjaService.GetBuildOrder:input_type -> distninja.BuildOrderRequest
+	13, // 27: distninja.DistNinjaService.DeleteBuild:input_type -> distninja.DeleteBuildRequest
+	47, // 28: distninja.DistNinjaService.SetBuildLabels:input_type -> distninja.SetBuildLabelsRequest
+	15, // 29: distninja.DistNinjaService.CreateRule:input_type -> distninja.CreateRuleRequest
+	17, // 30: distninja.DistNinjaService.GetRule:input_type -> distninja.GetRuleRequest
+	18, // 31: distninja.DistNinjaService.GetTargetsByRule:input_type -> distninja.GetTargetsByRuleRequest
+	20, // 32: distninja.DistNinjaService.UpdateRule:input_type -> distninja.UpdateRuleRequest
+	22, // 33: distninja.DistNinjaService.DeleteRule:input_type -> distninja.DeleteRuleRequest
+	24, // 34: distninja.DistNinjaService.GetAllTargets:input_type -> distninja.GetAllTargetsRequest
+	26, // 35: distninja.DistNinjaService.GetTarget:input_type -> distninja.GetTargetRequest
+	27, // 36: distninja.DistNinjaService.GetTargetDependencies:input_type -> distninja.GetTargetDependenciesRequest
+	29, // 37: distninja.DistNinjaService.GetTargetReverseDependencies:input_type -> distninja.GetTargetReverseDependenciesRequest
+	31, // 38: distninja.DistNinjaService.TraverseDependencies:input_type -> distninja.TraverseDependenciesRequest
+	34, // 39: distninja.DistNinjaService.UpdateTargetStatus:input_type -> distninja.UpdateTargetStatusRequest
+	36, // 40: distninja.DistNinjaService.BulkUpdateTargetStatus:input_type -> distninja.BulkUpdateTargetStatusRequest
+	42, // 41: distninja.DistNinjaService.DeleteTarget:input_type -> distninja.DeleteTargetRequest
+	44, // 42: distninja.DistNinjaService.MoveTarget:input_type -> distninja.MoveTargetRequest
+	46, // 43: distninja.DistNinjaService.SetTargetLabels:input_type -> distninja.SetTargetLabelsRequest
+	39, // 44: distninja.DistNinjaService.GetTargetHistory:input_type -> distninja.GetTargetHistoryRequest
+	48, // 45: distninja.DistNinjaService.FindCycles:input_type -> distninja.FindCyclesRequest
+	51, // 46: distninja.DistNinjaService.GizmoQuery:input_type -> distninja.GizmoQueryRequest
+	53, // 47: distninja.DistNinjaService.DebugQuads:input_type -> distninja.DebugQuadsRequest
+	56, // 48: distninja.DistNinjaService.LoadNinjaFile:input_type -> distninja.LoadNinjaFileRequest
+	58, // 49: distninja.DistNinjaService.LoadNinjaFileStream:input_type -> distninja.LoadNinjaFileChunk
+	64, // 50: distninja.DistNinjaService.StreamTargets:input_type -> distninja.StreamTargetsRequest
+	65, // 51: distninja.DistNinjaService.StreamQuads:input_type -> distninja.StreamQuadsRequest
+	66, // 52: distninja.DistNinjaService.WatchTargets:input_type -> distninja.WatchTargetsRequest
+	1,  // 53: distninja.DistNinjaService.Health:output_type -> distninja.HealthResponse
+	3,  // 54: distninja.DistNinjaService.Status:output_type -> distninja.StatusResponse
+	7,  // 55: distninja.DistNinjaService.CreateBuild:output_type -> distninja.CreateBuildResponse
+	60, // 56: distninja.DistNinjaService.GetBuild:output_type -> distninja.NinjaBuild
+	10, // 57: distninja.DistNinjaService.GetBuildStats:output_type -> distninja.BuildStatsResponse
+	12, // 58: distninja.DistNinjaService.GetBuildOrder:output_type -> distninja.BuildOrderResponse
+	14, // 59: distninja.DistNinjaService.DeleteBuild:output_type -> distninja.DeleteBuildResponse
+	60, // 60: distninja.DistNinjaService.SetBuildLabels:output_type -> distninja.NinjaBuild
+	16, // 61: distninja.DistNinjaService.CreateRule:output_type -> distninja.CreateRuleResponse
+	62, // 62: distninja.DistNinjaService.GetRule:output_type -> distninja.NinjaRule
+	19, // 63: distninja.DistNinjaService.GetTargetsByRule:output_type -> distninja.GetTargetsByRuleResponse
+	21, // 64: distninja.DistNinjaService.UpdateRule:output_type -> distninja.UpdateRuleResponse
+	23, // 65: distninja.DistNinjaService.DeleteRule:output_type -> distninja.DeleteRuleResponse
+	25, // 66: distninja.DistNinjaService.GetAllTargets:output_type -> distninja.GetAllTargetsResponse
+	63, // 67: distninja.DistNinjaService.GetTarget:output_type -> distninja.NinjaTarget
+	28, // 68: distninja.DistNinjaService.GetTargetDependencies:output_type -> distninja.GetTargetDependenciesResponse
+	30, // 69: distninja.DistNinjaService.GetTargetReverseDependencies:output_type -> distninja.GetTargetReverseDependenciesResponse
+	33, // 70: distninja.DistNinjaService.TraverseDependencies:output_type -> distninja.TraverseDependenciesResponse
+	35, // 71: distninja.DistNinjaService.UpdateTargetStatus:output_type -> distninja.UpdateTargetStatusResponse
+	37, // 72: distninja.DistNinjaService.BulkUpdateTargetStatus:output_type -> distninja.BulkUpdateTargetStatusResponse
+	43, // 73: distninja.DistNinjaService.DeleteTarget:output_type -> distninja.DeleteTargetResponse
+	45, // 74: distninja.DistNinjaService.MoveTarget:output_type -> distninja.MoveTargetResponse
+	63, // 75: distninja.DistNinjaService.SetTargetLabels:output_type -> distninja.NinjaTarget
+	40, // 76: distninja.DistNinjaService.GetTargetHistory:output_type -> distninja.GetTargetHistoryResponse
+	49, // 77: distninja.DistNinjaService.FindCycles:output_type -> distninja.FindCyclesResponse
+	52, // 78: distninja.DistNinjaService.GizmoQuery:output_type -> distninja.GizmoQueryResponse
+	54, // 79: distninja.DistNinjaService.DebugQuads:output_type -> distninja.DebugQuadsResponse
+	57, // 80: distninja.DistNinjaService.LoadNinjaFile:output_type -> distninja.LoadNinjaFileResponse
+	59, // 81: distninja.DistNinjaService.LoadNinjaFileStream:output_type -> distninja.LoadNinjaFileProgress
+	63, // 82: distninja.DistNinjaService.StreamTargets:output_type -> distninja.NinjaTarget
+	55, // 83: distninja.DistNinjaService.StreamQuads:output_type -> distninja.Quad
+	67, // 84: distninja.DistNinjaService.WatchTargets:output_type -> distninja.TargetEvent
+	53, // [53:85] is the sub-list for method output_type
+	21, // [21:53] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_server_proto_grpc_proto_init() }
@@ -2146,7 +4778,7 @@ func file_server_proto_grpc_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_server_proto_grpc_proto_rawDesc), len(file_server_proto_grpc_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   40,
+			NumMessages:   76,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
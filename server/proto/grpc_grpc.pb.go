@@ -25,17 +25,32 @@ const (
 	DistNinjaService_GetBuild_FullMethodName                     = "/distninja.DistNinjaService/GetBuild"
 	DistNinjaService_GetBuildStats_FullMethodName                = "/distninja.DistNinjaService/GetBuildStats"
 	DistNinjaService_GetBuildOrder_FullMethodName                = "/distninja.DistNinjaService/GetBuildOrder"
+	DistNinjaService_DeleteBuild_FullMethodName                  = "/distninja.DistNinjaService/DeleteBuild"
+	DistNinjaService_SetBuildLabels_FullMethodName               = "/distninja.DistNinjaService/SetBuildLabels"
 	DistNinjaService_CreateRule_FullMethodName                   = "/distninja.DistNinjaService/CreateRule"
 	DistNinjaService_GetRule_FullMethodName                      = "/distninja.DistNinjaService/GetRule"
 	DistNinjaService_GetTargetsByRule_FullMethodName             = "/distninja.DistNinjaService/GetTargetsByRule"
+	DistNinjaService_UpdateRule_FullMethodName                   = "/distninja.DistNinjaService/UpdateRule"
+	DistNinjaService_DeleteRule_FullMethodName                   = "/distninja.DistNinjaService/DeleteRule"
 	DistNinjaService_GetAllTargets_FullMethodName                = "/distninja.DistNinjaService/GetAllTargets"
 	DistNinjaService_GetTarget_FullMethodName                    = "/distninja.DistNinjaService/GetTarget"
 	DistNinjaService_GetTargetDependencies_FullMethodName        = "/distninja.DistNinjaService/GetTargetDependencies"
 	DistNinjaService_GetTargetReverseDependencies_FullMethodName = "/distninja.DistNinjaService/GetTargetReverseDependencies"
+	DistNinjaService_TraverseDependencies_FullMethodName         = "/distninja.DistNinjaService/TraverseDependencies"
 	DistNinjaService_UpdateTargetStatus_FullMethodName           = "/distninja.DistNinjaService/UpdateTargetStatus"
+	DistNinjaService_BulkUpdateTargetStatus_FullMethodName       = "/distninja.DistNinjaService/BulkUpdateTargetStatus"
+	DistNinjaService_DeleteTarget_FullMethodName                 = "/distninja.DistNinjaService/DeleteTarget"
+	DistNinjaService_MoveTarget_FullMethodName                   = "/distninja.DistNinjaService/MoveTarget"
+	DistNinjaService_SetTargetLabels_FullMethodName              = "/distninja.DistNinjaService/SetTargetLabels"
+	DistNinjaService_GetTargetHistory_FullMethodName             = "/distninja.DistNinjaService/GetTargetHistory"
 	DistNinjaService_FindCycles_FullMethodName                   = "/distninja.DistNinjaService/FindCycles"
+	DistNinjaService_GizmoQuery_FullMethodName                   = "/distninja.DistNinjaService/GizmoQuery"
 	DistNinjaService_DebugQuads_FullMethodName                   = "/distninja.DistNinjaService/DebugQuads"
 	DistNinjaService_LoadNinjaFile_FullMethodName                = "/distninja.DistNinjaService/LoadNinjaFile"
+	DistNinjaService_LoadNinjaFileStream_FullMethodName          = "/distninja.DistNinjaService/LoadNinjaFileStream"
+	DistNinjaService_StreamTargets_FullMethodName                = "/distninja.DistNinjaService/StreamTargets"
+	DistNinjaService_StreamQuads_FullMethodName                  = "/distninja.DistNinjaService/StreamQuads"
+	DistNinjaService_WatchTargets_FullMethodName                 = "/distninja.DistNinjaService/WatchTargets"
 )
 
 // DistNinjaServiceClient is the client API for DistNinjaService service.
@@ -50,22 +65,39 @@ type DistNinjaServiceClient interface {
 	GetBuild(ctx context.Context, in *GetBuildRequest, opts ...grpc.CallOption) (*NinjaBuild, error)
 	GetBuildStats(ctx context.Context, in *BuildStatsRequest, opts ...grpc.CallOption) (*BuildStatsResponse, error)
 	GetBuildOrder(ctx context.Context, in *BuildOrderRequest, opts ...grpc.CallOption) (*BuildOrderResponse, error)
+	DeleteBuild(ctx context.Context, in *DeleteBuildRequest, opts ...grpc.CallOption) (*DeleteBuildResponse, error)
+	SetBuildLabels(ctx context.Context, in *SetBuildLabelsRequest, opts ...grpc.CallOption) (*NinjaBuild, error)
 	// Rule
 	CreateRule(ctx context.Context, in *CreateRuleRequest, opts ...grpc.CallOption) (*CreateRuleResponse, error)
 	GetRule(ctx context.Context, in *GetRuleRequest, opts ...grpc.CallOption) (*NinjaRule, error)
 	GetTargetsByRule(ctx context.Context, in *GetTargetsByRuleRequest, opts ...grpc.CallOption) (*GetTargetsByRuleResponse, error)
+	UpdateRule(ctx context.Context, in *UpdateRuleRequest, opts ...grpc.CallOption) (*UpdateRuleResponse, error)
+	DeleteRule(ctx context.Context, in *DeleteRuleRequest, opts ...grpc.CallOption) (*DeleteRuleResponse, error)
 	// Target
 	GetAllTargets(ctx context.Context, in *GetAllTargetsRequest, opts ...grpc.CallOption) (*GetAllTargetsResponse, error)
 	GetTarget(ctx context.Context, in *GetTargetRequest, opts ...grpc.CallOption) (*NinjaTarget, error)
 	GetTargetDependencies(ctx context.Context, in *GetTargetDependenciesRequest, opts ...grpc.CallOption) (*GetTargetDependenciesResponse, error)
 	GetTargetReverseDependencies(ctx context.Context, in *GetTargetReverseDependenciesRequest, opts ...grpc.CallOption) (*GetTargetReverseDependenciesResponse, error)
+	TraverseDependencies(ctx context.Context, in *TraverseDependenciesRequest, opts ...grpc.CallOption) (*TraverseDependenciesResponse, error)
 	UpdateTargetStatus(ctx context.Context, in *UpdateTargetStatusRequest, opts ...grpc.CallOption) (*UpdateTargetStatusResponse, error)
+	BulkUpdateTargetStatus(ctx context.Context, in *BulkUpdateTargetStatusRequest, opts ...grpc.CallOption) (*BulkUpdateTargetStatusResponse, error)
+	DeleteTarget(ctx context.Context, in *DeleteTargetRequest, opts ...grpc.CallOption) (*DeleteTargetResponse, error)
+	MoveTarget(ctx context.Context, in *MoveTargetRequest, opts ...grpc.CallOption) (*MoveTargetResponse, error)
+	SetTargetLabels(ctx context.Context, in *SetTargetLabelsRequest, opts ...grpc.CallOption) (*NinjaTarget, error)
+	GetTargetHistory(ctx context.Context, in *GetTargetHistoryRequest, opts ...grpc.CallOption) (*GetTargetHistoryResponse, error)
 	// Analysis
 	FindCycles(ctx context.Context, in *FindCyclesRequest, opts ...grpc.CallOption) (*FindCyclesResponse, error)
+	// Query
+	GizmoQuery(ctx context.Context, in *GizmoQueryRequest, opts ...grpc.CallOption) (*GizmoQueryResponse, error)
 	// Debug
 	DebugQuads(ctx context.Context, in *DebugQuadsRequest, opts ...grpc.CallOption) (*DebugQuadsResponse, error)
 	// Load
 	LoadNinjaFile(ctx context.Context, in *LoadNinjaFileRequest, opts ...grpc.CallOption) (*LoadNinjaFileResponse, error)
+	LoadNinjaFileStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LoadNinjaFileChunk, LoadNinjaFileProgress], error)
+	// Streaming
+	StreamTargets(ctx context.Context, in *StreamTargetsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NinjaTarget], error)
+	StreamQuads(ctx context.Context, in *StreamQuadsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Quad], error)
+	WatchTargets(ctx context.Context, in *WatchTargetsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TargetEvent], error)
 }
 
 type distNinjaServiceClient struct {
@@ -136,6 +168,26 @@ func (c *distNinjaServiceClient) GetBuildOrder(ctx context.Context, in *BuildOrd
 	return out, nil
 }
 
+func (c *distNinjaServiceClient) DeleteBuild(ctx context.Context, in *DeleteBuildRequest, opts ...grpc.CallOption) (*DeleteBuildResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteBuildResponse)
+	err := c.cc.Invoke(ctx, DistNinjaService_DeleteBuild_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distNinjaServiceClient) SetBuildLabels(ctx context.Context, in *SetBuildLabelsRequest, opts ...grpc.CallOption) (*NinjaBuild, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NinjaBuild)
+	err := c.cc.Invoke(ctx, DistNinjaService_SetBuildLabels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *distNinjaServiceClient) CreateRule(ctx context.Context, in *CreateRuleRequest, opts ...grpc.CallOption) (*CreateRuleResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CreateRuleResponse)
@@ -166,6 +218,26 @@ func (c *distNinjaServiceClient) GetTargetsByRule(ctx context.Context, in *GetTa
 	return out, nil
 }
 
+func (c *distNinjaServiceClient) UpdateRule(ctx context.Context, in *UpdateRuleRequest, opts ...grpc.CallOption) (*UpdateRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateRuleResponse)
+	err := c.cc.Invoke(ctx, DistNinjaService_UpdateRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distNinjaServiceClient) DeleteRule(ctx context.Context, in *DeleteRuleRequest, opts ...grpc.CallOption) (*DeleteRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteRuleResponse)
+	err := c.cc.Invoke(ctx, DistNinjaService_DeleteRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *distNinjaServiceClient) GetAllTargets(ctx context.Context, in *GetAllTargetsRequest, opts ...grpc.CallOption) (*GetAllTargetsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetAllTargetsResponse)
@@ -206,6 +278,16 @@ func (c *distNinjaServiceClient) GetTargetReverseDependencies(ctx context.Contex
 	return out, nil
 }
 
+func (c *distNinjaServiceClient) TraverseDependencies(ctx context.Context, in *TraverseDependenciesRequest, opts ...grpc.CallOption) (*TraverseDependenciesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TraverseDependenciesResponse)
+	err := c.cc.Invoke(ctx, DistNinjaService_TraverseDependencies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *distNinjaServiceClient) UpdateTargetStatus(ctx context.Context, in *UpdateTargetStatusRequest, opts ...grpc.CallOption) (*UpdateTargetStatusResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(UpdateTargetStatusResponse)
@@ -216,6 +298,56 @@ func (c *distNinjaServiceClient) UpdateTargetStatus(ctx context.Context, in *Upd
 	return out, nil
 }
 
+func (c *distNinjaServiceClient) BulkUpdateTargetStatus(ctx context.Context, in *BulkUpdateTargetStatusRequest, opts ...grpc.CallOption) (*BulkUpdateTargetStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BulkUpdateTargetStatusResponse)
+	err := c.cc.Invoke(ctx, DistNinjaService_BulkUpdateTargetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distNinjaServiceClient) DeleteTarget(ctx context.Context, in *DeleteTargetRequest, opts ...grpc.CallOption) (*DeleteTargetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTargetResponse)
+	err := c.cc.Invoke(ctx, DistNinjaService_DeleteTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distNinjaServiceClient) MoveTarget(ctx context.Context, in *MoveTargetRequest, opts ...grpc.CallOption) (*MoveTargetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MoveTargetResponse)
+	err := c.cc.Invoke(ctx, DistNinjaService_MoveTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distNinjaServiceClient) SetTargetLabels(ctx context.Context, in *SetTargetLabelsRequest, opts ...grpc.CallOption) (*NinjaTarget, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NinjaTarget)
+	err := c.cc.Invoke(ctx, DistNinjaService_SetTargetLabels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distNinjaServiceClient) GetTargetHistory(ctx context.Context, in *GetTargetHistoryRequest, opts ...grpc.CallOption) (*GetTargetHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTargetHistoryResponse)
+	err := c.cc.Invoke(ctx, DistNinjaService_GetTargetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *distNinjaServiceClient) FindCycles(ctx context.Context, in *FindCyclesRequest, opts ...grpc.CallOption) (*FindCyclesResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(FindCyclesResponse)
@@ -226,6 +358,16 @@ func (c *distNinjaServiceClient) FindCycles(ctx context.Context, in *FindCyclesR
 	return out, nil
 }
 
+func (c *distNinjaServiceClient) GizmoQuery(ctx context.Context, in *GizmoQueryRequest, opts ...grpc.CallOption) (*GizmoQueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GizmoQueryResponse)
+	err := c.cc.Invoke(ctx, DistNinjaService_GizmoQuery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *distNinjaServiceClient) DebugQuads(ctx context.Context, in *DebugQuadsRequest, opts ...grpc.CallOption) (*DebugQuadsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DebugQuadsResponse)
@@ -246,6 +388,76 @@ func (c *distNinjaServiceClient) LoadNinjaFile(ctx context.Context, in *LoadNinj
 	return out, nil
 }
 
+func (c *distNinjaServiceClient) LoadNinjaFileStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LoadNinjaFileChunk, LoadNinjaFileProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DistNinjaService_ServiceDesc.Streams[0], DistNinjaService_LoadNinjaFileStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LoadNinjaFileChunk, LoadNinjaFileProgress]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DistNinjaService_LoadNinjaFileStreamClient = grpc.BidiStreamingClient[LoadNinjaFileChunk, LoadNinjaFileProgress]
+
+func (c *distNinjaServiceClient) StreamTargets(ctx context.Context, in *StreamTargetsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NinjaTarget], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DistNinjaService_ServiceDesc.Streams[1], DistNinjaService_StreamTargets_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamTargetsRequest, NinjaTarget]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DistNinjaService_StreamTargetsClient = grpc.ServerStreamingClient[NinjaTarget]
+
+func (c *distNinjaServiceClient) StreamQuads(ctx context.Context, in *StreamQuadsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Quad], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DistNinjaService_ServiceDesc.Streams[2], DistNinjaService_StreamQuads_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamQuadsRequest, Quad]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DistNinjaService_StreamQuadsClient = grpc.ServerStreamingClient[Quad]
+
+func (c *distNinjaServiceClient) WatchTargets(ctx context.Context, in *WatchTargetsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TargetEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DistNinjaService_ServiceDesc.Streams[3], DistNinjaService_WatchTargets_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchTargetsRequest, TargetEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DistNinjaService_WatchTargetsClient = grpc.ServerStreamingClient[TargetEvent]
+
 // DistNinjaServiceServer is the server API for DistNinjaService service.
 // All implementations must embed UnimplementedDistNinjaServiceServer
 // for forward compatibility.
@@ -258,22 +470,39 @@ type DistNinjaServiceServer interface {
 	GetBuild(context.Context, *GetBuildRequest) (*NinjaBuild, error)
 	GetBuildStats(context.Context, *BuildStatsRequest) (*BuildStatsResponse, error)
 	GetBuildOrder(context.Context, *BuildOrderRequest) (*BuildOrderResponse, error)
+	DeleteBuild(context.Context, *DeleteBuildRequest) (*DeleteBuildResponse, error)
+	SetBuildLabels(context.Context, *SetBuildLabelsRequest) (*NinjaBuild, error)
 	// Rule
 	CreateRule(context.Context, *CreateRuleRequest) (*CreateRuleResponse, error)
 	GetRule(context.Context, *GetRuleRequest) (*NinjaRule, error)
 	GetTargetsByRule(context.Context, *GetTargetsByRuleRequest) (*GetTargetsByRuleResponse, error)
+	UpdateRule(context.Context, *UpdateRuleRequest) (*UpdateRuleResponse, error)
+	DeleteRule(context.Context, *DeleteRuleRequest) (*DeleteRuleResponse, error)
 	// Target
 	GetAllTargets(context.Context, *GetAllTargetsRequest) (*GetAllTargetsResponse, error)
 	GetTarget(context.Context, *GetTargetRequest) (*NinjaTarget, error)
 	GetTargetDependencies(context.Context, *GetTargetDependenciesRequest) (*GetTargetDependenciesResponse, error)
 	GetTargetReverseDependencies(context.Context, *GetTargetReverseDependenciesRequest) (*GetTargetReverseDependenciesResponse, error)
+	TraverseDependencies(context.Context, *TraverseDependenciesRequest) (*TraverseDependenciesResponse, error)
 	UpdateTargetStatus(context.Context, *UpdateTargetStatusRequest) (*UpdateTargetStatusResponse, error)
+	BulkUpdateTargetStatus(context.Context, *BulkUpdateTargetStatusRequest) (*BulkUpdateTargetStatusResponse, error)
+	DeleteTarget(context.Context, *DeleteTargetRequest) (*DeleteTargetResponse, error)
+	MoveTarget(context.Context, *MoveTargetRequest) (*MoveTargetResponse, error)
+	SetTargetLabels(context.Context, *SetTargetLabelsRequest) (*NinjaTarget, error)
+	GetTargetHistory(context.Context, *GetTargetHistoryRequest) (*GetTargetHistoryResponse, error)
 	// Analysis
 	FindCycles(context.Context, *FindCyclesRequest) (*FindCyclesResponse, error)
+	// Query
+	GizmoQuery(context.Context, *GizmoQueryRequest) (*GizmoQueryResponse, error)
 	// Debug
 	DebugQuads(context.Context, *DebugQuadsRequest) (*DebugQuadsResponse, error)
 	// Load
 	LoadNinjaFile(context.Context, *LoadNinjaFileRequest) (*LoadNinjaFileResponse, error)
+	LoadNinjaFileStream(grpc.BidiStreamingServer[LoadNinjaFileChunk, LoadNinjaFileProgress]) error
+	// Streaming
+	StreamTargets(*StreamTargetsRequest, grpc.ServerStreamingServer[NinjaTarget]) error
+	StreamQuads(*StreamQuadsRequest, grpc.ServerStreamingServer[Quad]) error
+	WatchTargets(*WatchTargetsRequest, grpc.ServerStreamingServer[TargetEvent]) error
 	mustEmbedUnimplementedDistNinjaServiceServer()
 }
 
@@ -302,6 +531,12 @@ func (UnimplementedDistNinjaServiceServer) GetBuildStats(context.Context, *Build
 func (UnimplementedDistNinjaServiceServer) GetBuildOrder(context.Context, *BuildOrderRequest) (*BuildOrderResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBuildOrder not implemented")
 }
+func (UnimplementedDistNinjaServiceServer) DeleteBuild(context.Context, *DeleteBuildRequest) (*DeleteBuildResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBuild not implemented")
+}
+func (UnimplementedDistNinjaServiceServer) SetBuildLabels(context.Context, *SetBuildLabelsRequest) (*NinjaBuild, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBuildLabels not implemented")
+}
 func (UnimplementedDistNinjaServiceServer) CreateRule(context.Context, *CreateRuleRequest) (*CreateRuleResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateRule not implemented")
 }
@@ -311,6 +546,12 @@ func (UnimplementedDistNinjaServiceServer) GetRule(context.Context, *GetRuleRequ
 func (UnimplementedDistNinjaServiceServer) GetTargetsByRule(context.Context, *GetTargetsByRuleRequest) (*GetTargetsByRuleResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTargetsByRule not implemented")
 }
+func (UnimplementedDistNinjaServiceServer) UpdateRule(context.Context, *UpdateRuleRequest) (*UpdateRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRule not implemented")
+}
+func (UnimplementedDistNinjaServiceServer) DeleteRule(context.Context, *DeleteRuleRequest) (*DeleteRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRule not implemented")
+}
 func (UnimplementedDistNinjaServiceServer) GetAllTargets(context.Context, *GetAllTargetsRequest) (*GetAllTargetsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllTargets not implemented")
 }
@@ -323,18 +564,51 @@ func (UnimplementedDistNinjaServiceServer) GetTargetDependencies(context.Context
 func (UnimplementedDistNinjaServiceServer) GetTargetReverseDependencies(context.Context, *GetTargetReverseDependenciesRequest) (*GetTargetReverseDependenciesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTargetReverseDependencies not implemented")
 }
+func (UnimplementedDistNinjaServiceServer) TraverseDependencies(context.Context, *TraverseDependenciesRequest) (*TraverseDependenciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TraverseDependencies not implemented")
+}
 func (UnimplementedDistNinjaServiceServer) UpdateTargetStatus(context.Context, *UpdateTargetStatusRequest) (*UpdateTargetStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateTargetStatus not implemented")
 }
+func (UnimplementedDistNinjaServiceServer) BulkUpdateTargetStatus(context.Context, *BulkUpdateTargetStatusRequest) (*BulkUpdateTargetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkUpdateTargetStatus not implemented")
+}
+func (UnimplementedDistNinjaServiceServer) DeleteTarget(context.Context, *DeleteTargetRequest) (*DeleteTargetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTarget not implemented")
+}
+func (UnimplementedDistNinjaServiceServer) MoveTarget(context.Context, *MoveTargetRequest) (*MoveTargetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveTarget not implemented")
+}
+func (UnimplementedDistNinjaServiceServer) SetTargetLabels(context.Context, *SetTargetLabelsRequest) (*NinjaTarget, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTargetLabels not implemented")
+}
+func (UnimplementedDistNinjaServiceServer) GetTargetHistory(context.Context, *GetTargetHistoryRequest) (*GetTargetHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTargetHistory not implemented")
+}
 func (UnimplementedDistNinjaServiceServer) FindCycles(context.Context, *FindCyclesRequest) (*FindCyclesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FindCycles not implemented")
 }
+func (UnimplementedDistNinjaServiceServer) GizmoQuery(context.Context, *GizmoQueryRequest) (*GizmoQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GizmoQuery not implemented")
+}
 func (UnimplementedDistNinjaServiceServer) DebugQuads(context.Context, *DebugQuadsRequest) (*DebugQuadsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DebugQuads not implemented")
 }
 func (UnimplementedDistNinjaServiceServer) LoadNinjaFile(context.Context, *LoadNinjaFileRequest) (*LoadNinjaFileResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LoadNinjaFile not implemented")
 }
+func (UnimplementedDistNinjaServiceServer) LoadNinjaFileStream(grpc.BidiStreamingServer[LoadNinjaFileChunk, LoadNinjaFileProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method LoadNinjaFileStream not implemented")
+}
+func (UnimplementedDistNinjaServiceServer) StreamTargets(*StreamTargetsRequest, grpc.ServerStreamingServer[NinjaTarget]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTargets not implemented")
+}
+func (UnimplementedDistNinjaServiceServer) StreamQuads(*StreamQuadsRequest, grpc.ServerStreamingServer[Quad]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamQuads not implemented")
+}
+func (UnimplementedDistNinjaServiceServer) WatchTargets(*WatchTargetsRequest, grpc.ServerStreamingServer[TargetEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTargets not implemented")
+}
 func (UnimplementedDistNinjaServiceServer) mustEmbedUnimplementedDistNinjaServiceServer() {}
 func (UnimplementedDistNinjaServiceServer) testEmbeddedByValue()                          {}
 
@@ -464,6 +738,42 @@ func _DistNinjaService_GetBuildOrder_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DistNinjaService_DeleteBuild_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBuildRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistNinjaServiceServer).DeleteBuild(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DistNinjaService_DeleteBuild_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistNinjaServiceServer).DeleteBuild(ctx, req.(*DeleteBuildRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistNinjaService_SetBuildLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBuildLabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistNinjaServiceServer).SetBuildLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DistNinjaService_SetBuildLabels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistNinjaServiceServer).SetBuildLabels(ctx, req.(*SetBuildLabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DistNinjaService_CreateRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateRuleRequest)
 	if err := dec(in); err != nil {
@@ -518,6 +828,42 @@ func _DistNinjaService_GetTargetsByRule_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DistNinjaService_UpdateRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistNinjaServiceServer).UpdateRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DistNinjaService_UpdateRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistNinjaServiceServer).UpdateRule(ctx, req.(*UpdateRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistNinjaService_DeleteRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistNinjaServiceServer).DeleteRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DistNinjaService_DeleteRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistNinjaServiceServer).DeleteRule(ctx, req.(*DeleteRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DistNinjaService_GetAllTargets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetAllTargetsRequest)
 	if err := dec(in); err != nil {
@@ -590,6 +936,24 @@ func _DistNinjaService_GetTargetReverseDependencies_Handler(srv interface{}, ctx
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DistNinjaService_TraverseDependencies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraverseDependenciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistNinjaServiceServer).TraverseDependencies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DistNinjaService_TraverseDependencies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistNinjaServiceServer).TraverseDependencies(ctx, req.(*TraverseDependenciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DistNinjaService_UpdateTargetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateTargetStatusRequest)
 	if err := dec(in); err != nil {
@@ -608,6 +972,96 @@ func _DistNinjaService_UpdateTargetStatus_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DistNinjaService_BulkUpdateTargetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkUpdateTargetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistNinjaServiceServer).BulkUpdateTargetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DistNinjaService_BulkUpdateTargetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistNinjaServiceServer).BulkUpdateTargetStatus(ctx, req.(*BulkUpdateTargetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistNinjaService_DeleteTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistNinjaServiceServer).DeleteTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DistNinjaService_DeleteTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistNinjaServiceServer).DeleteTarget(ctx, req.(*DeleteTargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistNinjaService_MoveTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveTargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistNinjaServiceServer).MoveTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DistNinjaService_MoveTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistNinjaServiceServer).MoveTarget(ctx, req.(*MoveTargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistNinjaService_SetTargetLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTargetLabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistNinjaServiceServer).SetTargetLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DistNinjaService_SetTargetLabels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistNinjaServiceServer).SetTargetLabels(ctx, req.(*SetTargetLabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DistNinjaService_GetTargetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTargetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistNinjaServiceServer).GetTargetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DistNinjaService_GetTargetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistNinjaServiceServer).GetTargetHistory(ctx, req.(*GetTargetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DistNinjaService_FindCycles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(FindCyclesRequest)
 	if err := dec(in); err != nil {
@@ -626,6 +1080,24 @@ func _DistNinjaService_FindCycles_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DistNinjaService_GizmoQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GizmoQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistNinjaServiceServer).GizmoQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DistNinjaService_GizmoQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistNinjaServiceServer).GizmoQuery(ctx, req.(*GizmoQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DistNinjaService_DebugQuads_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DebugQuadsRequest)
 	if err := dec(in); err != nil {
@@ -662,6 +1134,46 @@ func _DistNinjaService_LoadNinjaFile_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DistNinjaService_LoadNinjaFileStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DistNinjaServiceServer).LoadNinjaFileStream(&grpc.GenericServerStream[LoadNinjaFileChunk, LoadNinjaFileProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DistNinjaService_LoadNinjaFileStreamServer = grpc.BidiStreamingServer[LoadNinjaFileChunk, LoadNinjaFileProgress]
+
+func _DistNinjaService_StreamTargets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTargetsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DistNinjaServiceServer).StreamTargets(m, &grpc.GenericServerStream[StreamTargetsRequest, NinjaTarget]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DistNinjaService_StreamTargetsServer = grpc.ServerStreamingServer[NinjaTarget]
+
+func _DistNinjaService_StreamQuads_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamQuadsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DistNinjaServiceServer).StreamQuads(m, &grpc.GenericServerStream[StreamQuadsRequest, Quad]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DistNinjaService_StreamQuadsServer = grpc.ServerStreamingServer[Quad]
+
+func _DistNinjaService_WatchTargets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTargetsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DistNinjaServiceServer).WatchTargets(m, &grpc.GenericServerStream[WatchTargetsRequest, TargetEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DistNinjaService_WatchTargetsServer = grpc.ServerStreamingServer[TargetEvent]
+
 // DistNinjaService_ServiceDesc is the grpc.ServiceDesc for DistNinjaService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -693,6 +1205,14 @@ var DistNinjaService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetBuildOrder",
 			Handler:    _DistNinjaService_GetBuildOrder_Handler,
 		},
+		{
+			MethodName: "DeleteBuild",
+			Handler:    _DistNinjaService_DeleteBuild_Handler,
+		},
+		{
+			MethodName: "SetBuildLabels",
+			Handler:    _DistNinjaService_SetBuildLabels_Handler,
+		},
 		{
 			MethodName: "CreateRule",
 			Handler:    _DistNinjaService_CreateRule_Handler,
@@ -705,6 +1225,14 @@ var DistNinjaService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTargetsByRule",
 			Handler:    _DistNinjaService_GetTargetsByRule_Handler,
 		},
+		{
+			MethodName: "UpdateRule",
+			Handler:    _DistNinjaService_UpdateRule_Handler,
+		},
+		{
+			MethodName: "DeleteRule",
+			Handler:    _DistNinjaService_DeleteRule_Handler,
+		},
 		{
 			MethodName: "GetAllTargets",
 			Handler:    _DistNinjaService_GetAllTargets_Handler,
@@ -721,14 +1249,42 @@ var DistNinjaService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTargetReverseDependencies",
 			Handler:    _DistNinjaService_GetTargetReverseDependencies_Handler,
 		},
+		{
+			MethodName: "TraverseDependencies",
+			Handler:    _DistNinjaService_TraverseDependencies_Handler,
+		},
 		{
 			MethodName: "UpdateTargetStatus",
 			Handler:    _DistNinjaService_UpdateTargetStatus_Handler,
 		},
+		{
+			MethodName: "BulkUpdateTargetStatus",
+			Handler:    _DistNinjaService_BulkUpdateTargetStatus_Handler,
+		},
+		{
+			MethodName: "DeleteTarget",
+			Handler:    _DistNinjaService_DeleteTarget_Handler,
+		},
+		{
+			MethodName: "MoveTarget",
+			Handler:    _DistNinjaService_MoveTarget_Handler,
+		},
+		{
+			MethodName: "SetTargetLabels",
+			Handler:    _DistNinjaService_SetTargetLabels_Handler,
+		},
+		{
+			MethodName: "GetTargetHistory",
+			Handler:    _DistNinjaService_GetTargetHistory_Handler,
+		},
 		{
 			MethodName: "FindCycles",
 			Handler:    _DistNinjaService_FindCycles_Handler,
 		},
+		{
+			MethodName: "GizmoQuery",
+			Handler:    _DistNinjaService_GizmoQuery_Handler,
+		},
 		{
 			MethodName: "DebugQuads",
 			Handler:    _DistNinjaService_DebugQuads_Handler,
@@ -738,6 +1294,28 @@ var DistNinjaService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _DistNinjaService_LoadNinjaFile_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "LoadNinjaFileStream",
+			Handler:       _DistNinjaService_LoadNinjaFileStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamTargets",
+			Handler:       _DistNinjaService_StreamTargets_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamQuads",
+			Handler:       _DistNinjaService_StreamQuads_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchTargets",
+			Handler:       _DistNinjaService_WatchTargets_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "server/proto/grpc.proto",
 }
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/distninja/distninja/store"
+)
+
+// Permission groups, each one maps to the least privileged role allowed to use it
+const (
+	PermissionRead        = store.RoleViewer
+	PermissionLoad        = store.RoleLoader
+	PermissionRunControl  = store.RoleOperator
+	PermissionDestructive = store.RoleAdmin
+)
+
+// grpcPermissions maps DistNinjaService methods to permission groups, unlisted methods require admin
+var grpcPermissions = map[string]string{
+	"Health":                       PermissionRead,
+	"Status":                       PermissionRead,
+	"GetBuild":                     PermissionRead,
+	"GetBuildStats":                PermissionRead,
+	"GetBuildOrder":                PermissionRead,
+	"GetRule":                      PermissionRead,
+	"GetTargetsByRule":             PermissionRead,
+	"GetAllTargets":                PermissionRead,
+	"GetTarget":                    PermissionRead,
+	"GetTargetDependencies":        PermissionRead,
+	"GetTargetReverseDependencies": PermissionRead,
+	"TraverseDependencies":         PermissionRead,
+	"GetTargetHistory":             PermissionRead,
+	"FindCycles":                   PermissionRead,
+	"DebugQuads":                   PermissionRead,
+	"CreateBuild":                  PermissionLoad,
+	"CreateRule":                   PermissionLoad,
+	"LoadNinjaFile":                PermissionLoad,
+	"LoadNinjaFileStream":          PermissionLoad,
+	"UpdateTargetStatus":           PermissionRunControl,
+	"BulkUpdateTargetStatus":       PermissionRunControl,
+	"UpdateRule":                   PermissionLoad,
+	"DeleteRule":                   PermissionDestructive,
+	"DeleteBuild":                  PermissionDestructive,
+	"DeleteTarget":                 PermissionDestructive,
+	"MoveTarget":                   PermissionLoad,
+	"SetTargetLabels":              PermissionLoad,
+	"SetBuildLabels":               PermissionLoad,
+	"GizmoQuery":                   PermissionDestructive,
+	"StreamTargets":                PermissionRead,
+	"StreamQuads":                  PermissionRead,
+	"WatchTargets":                 PermissionRead,
+}
+
+const grpcServicePrefix = "/distninja.DistNinjaService/"
+
+// resolveRole returns the role of an identity, anonymous callers are viewers
+func resolveRole(ctx context.Context, config *AuthConfig, ninjaStore *store.NinjaStore, identity *Identity) string {
+	if identity == nil {
+		return store.RoleViewer
+	}
+
+	for _, admin := range config.Admins {
+		if identity.Subject == admin {
+			return store.RoleAdmin
+		}
+	}
+
+	if role, err := ninjaStore.GetRole(ctx, identity.Subject); err == nil {
+		return role
+	}
+
+	if role, ok := identity.Claims["role"].(string); ok && store.ValidRole(role) {
+		return role
+	}
+
+	if store.ValidRole(config.DefaultRole) {
+		return config.DefaultRole
+	}
+
+	return store.RoleViewer
+}
+
+// httpPermission returns the permission group required by the matched route
+func httpPermission(r *http.Request) string {
+	template := ""
+	if route := mux.CurrentRoute(r); route != nil {
+		template, _ = route.GetPathTemplate()
+	}
+
+	switch {
+	case strings.HasPrefix(template, "/api/v1/roles"), strings.HasPrefix(template, "/api/v1/query/"),
+		strings.HasPrefix(template, "/api/v1/webhooks"), strings.HasPrefix(template, "/api/v1/admin/"),
+		template == "/api/v1/audit":
+		return PermissionDestructive
+	case r.Method == http.MethodGet || r.Method == http.MethodHead || template == "/api/v1/graphql" ||
+		template == "/api/v1/analysis/changed":
+		return PermissionRead
+	case r.Method == http.MethodDelete:
+		return PermissionDestructive
+	case strings.HasSuffix(template, "/status"), template == "/api/v1/schedules":
+		return PermissionRunControl
+	default:
+		return PermissionLoad
+	}
+}
+
+// grpcPermission returns the permission group required by a gRPC method
+func grpcPermission(fullMethod string) string {
+	if !strings.HasPrefix(fullMethod, grpcServicePrefix) {
+		// Health checks and other auxiliary services
+		return PermissionRead
+	}
+
+	if permission, ok := grpcPermissions[strings.TrimPrefix(fullMethod, grpcServicePrefix)]; ok {
+		return permission
+	}
+
+	return PermissionDestructive
+}
+
+// authInterceptor authenticates gRPC callers and enforces method permissions
+func authInterceptor(config *AuthConfig, ninjaStore *store.NinjaStore) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorizeGRPC(ctx, config, ninjaStore, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// authStreamInterceptor is the streaming counterpart of authInterceptor
+func authStreamInterceptor(config *AuthConfig, ninjaStore *store.NinjaStore) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorizeGRPC(ss.Context(), config, ninjaStore, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorizeGRPC authenticates the caller of fullMethod and returns a context carrying its identity
+func authorizeGRPC(ctx context.Context, config *AuthConfig, ninjaStore *store.NinjaStore, fullMethod string) (context.Context, error) {
+	identity := peerIdentity(ctx)
+
+	if token := grpcToken(ctx); token != "" && config.Enabled() {
+		var err error
+		identity, err = config.Authenticate(token)
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "unauthorized: %v", err)
+		}
+	}
+
+	if identity != nil {
+		ctx = contextWithIdentity(ctx, identity)
+	}
+
+	if !config.Enabled() {
+		return ctx, nil
+	}
+
+	required := grpcPermission(fullMethod)
+	role := resolveRole(ctx, config, ninjaStore, identity)
+
+	if !store.RoleAllows(role, required) {
+		if identity == nil {
+			return nil, status.Error(codes.Unauthenticated, "unauthorized: missing credentials")
+		}
+		return nil, status.Errorf(codes.PermissionDenied, "role %s may not call %s, requires %s", role, fullMethod, required)
+	}
+
+	return ctx, nil
+}
+
+// contextStream overrides the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (cs *contextStream) Context() context.Context {
+	return cs.ctx
+}
+
+func grpcToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if keys := md.Get("x-api-key"); len(keys) > 0 && keys[0] != "" {
+		return keys[0]
+	}
+
+	if values := md.Get("authorization"); len(values) > 0 {
+		header := values[0]
+		if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+			return strings.TrimSpace(header[7:])
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/distninja/distninja/utils"
+)
+
+// requestID returns the valid request ID a client sent, or a new one
+func requestID(sent string) string {
+	if utils.ValidRequestID(sent) {
+		return sent
+	}
+
+	return utils.NewRequestID()
+}
+
+// requestIDMiddleware gives every HTTP request an ID, the X-Request-ID sent by the client or a new one,
+// returns it in the response and carries it in the request context for logs and audit entries
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := requestID(r.Header.Get(utils.RequestIDHeader))
+
+		w.Header().Set(utils.RequestIDHeader, id)
+
+		next.ServeHTTP(w, r.WithContext(utils.ContextWithRequestID(r.Context(), id)))
+	})
+}
+
+// grpcRequestID returns the context of a gRPC call with its request ID, the x-request-id metadata sent
+// by the client or a new one
+func grpcRequestID(ctx context.Context) (context.Context, string) {
+	sent := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(utils.RequestIDMetadata); len(ids) > 0 {
+			sent = ids[0]
+		}
+	}
+
+	id := requestID(sent)
+
+	return utils.ContextWithRequestID(ctx, id), id
+}
+
+// requestIDInterceptor gives every gRPC call a request ID and returns it in the response header
+func requestIDInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, id := grpcRequestID(ctx)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(utils.RequestIDMetadata, id))
+
+	return handler(ctx, req)
+}
+
+// requestIDStreamInterceptor is the streaming counterpart of requestIDInterceptor
+func requestIDStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, id := grpcRequestID(ss.Context())
+	_ = ss.SetHeader(metadata.Pairs(utils.RequestIDMetadata, id))
+
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
@@ -0,0 +1,448 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distninja/distninja/store"
+)
+
+// cronSearchLimit bounds how far ahead the next time of a cron expression is searched, expressions
+// without a time in it, e.g. 0 0 30 2 *, are rejected
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// cronMacros are the shorthands accepted instead of the five fields of a cron expression
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSpec is a parsed cron expression, each field a bit set of the values it matches
+type cronSpec struct {
+	minute, hour, day, month, weekday uint64
+	// anyDay and anyWeekday are set for a day of month or day of week starting with *, e.g. * or */2. A
+	// day matches when both fields match if either starts with *, or when one of them matches otherwise.
+	anyDay, anyWeekday bool
+}
+
+// parseCron parses a cron expression of minute, hour, day of month, month and day of week, each *, a
+// value, a range a-b or a list of them, optionally with a /step. Day of week 0 and 7 are Sunday.
+func parseCron(expression string) (*cronSpec, error) {
+	expression = strings.TrimSpace(expression)
+	if macro, ok := cronMacros[expression]; ok {
+		expression = macro
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q, expected minute, hour, day of month, month and day of week", expression)
+	}
+
+	spec := &cronSpec{anyDay: strings.HasPrefix(fields[2], "*"), anyWeekday: strings.HasPrefix(fields[4], "*")}
+
+	bounds := []struct {
+		name     string
+		min, max int
+		bits     *uint64
+	}{
+		{"minute", 0, 59, &spec.minute},
+		{"hour", 0, 23, &spec.hour},
+		{"day of month", 1, 31, &spec.day},
+		{"month", 1, 12, &spec.month},
+		{"day of week", 0, 7, &spec.weekday},
+	}
+
+	for i, field := range fields {
+		bits, err := parseCronField(field, bounds[i].min, bounds[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in cron expression %q: %w", bounds[i].name, expression, err)
+		}
+		*bounds[i].bits = bits
+	}
+
+	// Sunday is 0 for time.Weekday
+	if spec.weekday&(1<<7) != 0 {
+		spec.weekday |= 1
+	}
+
+	return spec, nil
+}
+
+// parseCronField returns the bit set of the values between min and max a cron field matches
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		low, high := min, max
+
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+			if low, err = strconv.Atoi(lowPart); err != nil {
+				return 0, fmt.Errorf("invalid value %q", lowPart)
+			}
+
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highPart); err != nil {
+					return 0, fmt.Errorf("invalid value %q", highPart)
+				}
+			} else if hasStep {
+				high = max
+			}
+		}
+
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// next returns the first time after t the spec matches, in UTC, and false when there is none within
+// cronSearchLimit
+func (s *cronSpec) next(t time.Time) (time.Time, bool) {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (s *cronSpec) matchesDay(t time.Time) bool {
+	day := s.day&(1<<uint(t.Day())) != 0
+	weekday := s.weekday&(1<<uint(t.Weekday())) != 0
+
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+
+	return day || weekday
+}
+
+// ScheduleRequest creates a schedule running targets, all targets when none are given, at the times
+// matching a cron expression in UTC. Options are passed on unchanged to whoever runs the builds.
+type ScheduleRequest struct {
+	Cron    string                 `json:"cron"`
+	Targets []string               `json:"targets,omitempty"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// ScheduleResponse describes a schedule, LastRun is the last time it fired since the server started
+type ScheduleResponse struct {
+	ID        string                 `json:"id"`
+	Cron      string                 `json:"cron"`
+	Targets   []string               `json:"targets"`
+	Options   map[string]interface{} `json:"options"`
+	CreatedAt string                 `json:"created_at"`
+	NextRun   *time.Time             `json:"next_run,omitempty"`
+	LastRun   *time.Time             `json:"last_run,omitempty"`
+}
+
+// Scheduler publishes a schedule.fired event each time a schedule in the store is due. The server
+// doesn't run builds itself, executors subscribed to the event run the targets of the schedule.
+type Scheduler struct {
+	store *store.NinjaStore
+	bus   *EventBus
+
+	mu      sync.Mutex
+	entries map[string]*scheduleEntry
+	// wake interrupts the wait for the next run after a schedule was added or removed
+	wake chan struct{}
+}
+
+type scheduleEntry struct {
+	schedule *store.NinjaSchedule
+	spec     *cronSpec
+	next     time.Time
+	last     time.Time
+}
+
+// NewScheduler creates a scheduler for the schedules already in the store, publishing to bus
+func NewScheduler(ctx context.Context, ninjaStore *store.NinjaStore, bus *EventBus) (*Scheduler, error) {
+	s := &Scheduler{
+		store:   ninjaStore,
+		bus:     bus,
+		entries: make(map[string]*scheduleEntry),
+		wake:    make(chan struct{}, 1),
+	}
+
+	schedules, err := ninjaStore.ListSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	for _, schedule := range schedules {
+		if err := s.add(schedule, now); err != nil {
+			slog.Warn("skipping schedule", "id", schedule.ScheduleID, "error", err)
+		}
+	}
+
+	return s, nil
+}
+
+// Start runs the scheduler until the returned function is called. Runs missed while the server was
+// stopped are not made up.
+func (s *Scheduler) Start() func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		s.run(stop)
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// Create validates and stores a schedule and starts firing it
+func (s *Scheduler) Create(ctx context.Context, req *ScheduleRequest) (*ScheduleResponse, error) {
+	spec, err := parseCron(req.Cron)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := spec.next(time.Now()); !ok {
+		return nil, fmt.Errorf("cron expression %q never matches", req.Cron)
+	}
+
+	for _, target := range req.Targets {
+		if strings.TrimSpace(target) == "" {
+			return nil, fmt.Errorf("target paths must not be empty")
+		}
+	}
+
+	schedule := &store.NinjaSchedule{
+		ScheduleID: randomHex(8),
+		Cron:       strings.TrimSpace(req.Cron),
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if err := schedule.SetTargets(req.Targets); err != nil {
+		return nil, err
+	}
+
+	if err := schedule.SetOptions(req.Options); err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+
+	if err := s.store.AddSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.add(schedule, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, schedule.ScheduleID)
+}
+
+// List returns all schedules ordered by id
+func (s *Scheduler) List(ctx context.Context) ([]*ScheduleResponse, error) {
+	schedules, err := s.store.ListSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].ScheduleID < schedules[j].ScheduleID })
+
+	responses := make([]*ScheduleResponse, 0, len(schedules))
+	for _, schedule := range schedules {
+		responses = append(responses, s.response(schedule))
+	}
+
+	return responses, nil
+}
+
+// Get returns the schedule with the given id
+func (s *Scheduler) Get(ctx context.Context, id string) (*ScheduleResponse, error) {
+	schedule, err := s.store.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.response(schedule), nil
+}
+
+// Delete removes a schedule, it doesn't fire again
+func (s *Scheduler) Delete(ctx context.Context, id string) error {
+	if err := s.store.DeleteSchedule(ctx, id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.entries, id)
+	s.mu.Unlock()
+
+	s.notify()
+
+	return nil
+}
+
+func (s *Scheduler) add(schedule *store.NinjaSchedule, now time.Time) error {
+	spec, err := parseCron(schedule.Cron)
+	if err != nil {
+		return err
+	}
+
+	next, ok := spec.next(now)
+	if !ok {
+		return fmt.Errorf("cron expression %q never matches", schedule.Cron)
+	}
+
+	s.mu.Lock()
+	s.entries[schedule.ScheduleID] = &scheduleEntry{schedule: schedule, spec: spec, next: next}
+	s.mu.Unlock()
+
+	s.notify()
+
+	return nil
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run fires the schedules that are due and waits for the next one
+func (s *Scheduler) run(stop <-chan struct{}) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		wait := s.fire(time.Now())
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-stop:
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// fire publishes the schedules due at now and returns how long to wait for the next one
+func (s *Scheduler) fire(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wait := time.Hour
+
+	for id, entry := range s.entries {
+		if !entry.next.After(now) {
+			targets, _ := entry.schedule.GetTargets()
+			options, _ := entry.schedule.GetOptions()
+
+			s.bus.Publish(EventScheduleFired, map[string]interface{}{
+				"id":           id,
+				"cron":         entry.schedule.Cron,
+				"targets":      targets,
+				"options":      options,
+				"scheduled_at": entry.next,
+			})
+
+			entry.last = entry.next
+
+			next, ok := entry.spec.next(now)
+			if !ok {
+				slog.Warn("schedule no longer matches, stopping it", "id", id, "cron", entry.schedule.Cron)
+				delete(s.entries, id)
+				continue
+			}
+			entry.next = next
+		}
+
+		if until := entry.next.Sub(now); until < wait {
+			wait = until
+		}
+	}
+
+	return wait
+}
+
+func (s *Scheduler) response(schedule *store.NinjaSchedule) *ScheduleResponse {
+	targets, _ := schedule.GetTargets()
+	if targets == nil {
+		targets = []string{}
+	}
+
+	options, _ := schedule.GetOptions()
+
+	response := &ScheduleResponse{
+		ID:        schedule.ScheduleID,
+		Cron:      schedule.Cron,
+		Targets:   targets,
+		Options:   options,
+		CreatedAt: schedule.CreatedAt,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[schedule.ScheduleID]; ok {
+		next := entry.next
+		response.NextRun = &next
+		if !entry.last.IsZero() {
+			last := entry.last
+			response.LastRun = &last
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,352 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/distninja/distninja/parser"
+	"github.com/distninja/distninja/store"
+)
+
+const (
+	serviceName = "distninja"
+
+	// shutdownTimeout bounds how long in flight HTTP requests may take to finish on shutdown
+	shutdownTimeout = 30 * time.Second
+
+	// eventSinkBuffer is the number of events buffered for each sink of Options.EventSinks
+	eventSinkBuffer = 1024
+
+	// maxLoggedProblems bounds the integrity problems logged one by one on startup
+	maxLoggedProblems = 20
+)
+
+// Options holds settings shared by the HTTP and gRPC servers
+type Options struct {
+	Auth   AuthConfig
+	TLS    TLSConfig
+	Limits LimitsConfig
+	GRPC   GRPCConfig
+	// History bounds the status history kept per target
+	History store.HistoryRetention
+	// Load sets the store writers of ninja file loads
+	Load parser.LoadOptions
+	// Repair removes what the startup integrity check finds broken in the store
+	Repair bool
+	// ForceUnlock removes the lock of the store before opening it, for locks left by a process that
+	// hangs or runs on another host
+	ForceUnlock bool
+	// Compact rewrites the store file without its free pages when it is opened
+	Compact bool
+	// CacheSize is the number of rules, builds and targets the store keeps in memory, zero disables
+	// the cache
+	CacheSize int
+	// SnapshotDir is where /admin/snapshot writes snapshots, next to the store when empty
+	SnapshotDir string
+	// SnapshotKey encrypts snapshots with AES-GCM, nil writes them in the clear
+	SnapshotKey []byte
+	// EncryptStore encrypts the store file and its journal at rest with SnapshotKey
+	EncryptStore bool
+	// Workspace is the git checkout diffed by /analysis/changed?since= and for pull request triggers
+	Workspace string
+	// WatchInterval is how often Workspace is polled for changed sources, whose dependent targets are
+	// marked dirty, zero disables watching
+	WatchInterval time.Duration
+	// TriggerSecret verifies GitHub and GitLab webhooks sent to /triggers/git, which is only served
+	// when it is set
+	TriggerSecret string
+	// EventSinks receive the events of the server besides its webhooks, e.g. to forward them to a
+	// message bus
+	EventSinks []EventSink
+	// EventSinkTypes are the event types sent to EventSinks, all types when empty
+	EventSinkTypes []string
+	// CORSOrigins are the origins browsers may call the API and open WebSockets from, "*" allows any.
+	// When empty any origin may call the API but WebSockets are only accepted from the server's own.
+	CORSOrigins []string
+	// BuildTime and CommitID identify the running binary
+	BuildTime string
+	CommitID  string
+}
+
+// Serve runs the gRPC server, the HTTP server or both against one shared store until ctx is done, the
+// process is interrupted or a server fails
+func Serve(ctx context.Context, grpcAddress, httpAddress, storePath string, opts Options) error {
+	if grpcAddress == "" && httpAddress == "" {
+		return fmt.Errorf("a grpc or http address is required")
+	}
+
+	if opts.WatchInterval > 0 && opts.Workspace == "" {
+		return fmt.Errorf("watching needs a workspace, start the server with --workspace")
+	}
+
+	var listener net.Listener
+	if grpcAddress != "" {
+		var err error
+		listener, err = net.Listen("tcp", grpcAddress)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", grpcAddress, err)
+		}
+	}
+
+	var err error
+
+	if opts.ForceUnlock {
+		pid, err := store.ForceUnlock(storePath)
+		if err != nil {
+			return err
+		}
+		if pid != 0 {
+			slog.Warn("removed store lock", "store", storePath, "pid", pid)
+		}
+	}
+
+	// Handlers of both servers share the package level store
+	if opts.EncryptStore {
+		ninjaStore, err = store.NewEncryptedNinjaStore(storePath, opts.SnapshotKey)
+	} else {
+		ninjaStore, err = store.NewNinjaStore(storePath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open ninja store: %w", err)
+	}
+
+	defer func(ninjaStore *store.NinjaStore) {
+		_ = ninjaStore.Close()
+	}(ninjaStore)
+
+	if opts.Compact {
+		if err := compactStore(ctx, ninjaStore); err != nil {
+			return err
+		}
+	}
+
+	if err := checkStore(ctx, ninjaStore, opts.Repair); err != nil {
+		return err
+	}
+
+	ninjaStore.SetHistoryRetention(opts.History)
+	ninjaStore.SetCacheSize(opts.CacheSize)
+	ninjaStore.SetSnapshotKey(opts.SnapshotKey)
+	loadOptions = opts.Load
+
+	markStarted(&opts)
+
+	webhooks, err = NewWebhookDispatcher(ctx, ninjaStore)
+	if err != nil {
+		return fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	stopWebhooks := webhooks.Start(eventBus)
+	defer stopWebhooks()
+
+	scheduler, err = NewScheduler(ctx, ninjaStore, eventBus)
+	if err != nil {
+		return fmt.Errorf("failed to load schedules: %w", err)
+	}
+
+	stopScheduler := scheduler.Start()
+	defer stopScheduler()
+
+	if opts.WatchInterval > 0 {
+		stopWatcher := newWorkspaceWatcher(opts.Workspace, opts.WatchInterval, storePath).Start()
+		defer stopWatcher()
+	}
+
+	for _, sink := range opts.EventSinks {
+		defer eventBus.StartSink(sink, eventSinkBuffer, opts.EventSinkTypes...)()
+	}
+
+	// Clients share one rate limit across protocols
+	addressLimiter := newAddressRateLimiter(&opts.Limits)
+	limiter := newRateLimiter(&opts.Limits)
+
+	serverErr := make(chan error, 2)
+
+	var grpcServer *grpc.Server
+	if listener != nil {
+		grpcServer, err = newGRPCServer(ninjaStore, &opts, addressLimiter, limiter)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				serverErr <- fmt.Errorf("gRPC server error: %w", err)
+			}
+		}()
+	}
+
+	var httpServer *http.Server
+	if httpAddress != "" {
+		httpServer, err = newHTTPServer(httpAddress, &opts, addressLimiter, limiter)
+		if err != nil {
+			if grpcServer != nil {
+				grpcServer.Stop()
+			}
+			return err
+		}
+
+		go func() {
+			var err error
+			if httpServer.TLSConfig != nil {
+				err = httpServer.ListenAndServeTLS("", "")
+			} else {
+				err = httpServer.ListenAndServe()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErr <- fmt.Errorf("http server error: %w", err)
+			}
+		}()
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	var runErr error
+
+	select {
+	case <-ctx.Done():
+	case <-quit:
+	case runErr = <-serverErr:
+		slog.Error("server failed, shutting down", "error", runErr)
+	case runErr = <-storeFailure:
+		slog.Error("store failed, shutting down", "error", runErr)
+	}
+
+	if httpServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		_ = httpServer.Shutdown(shutdownCtx)
+		cancel()
+	}
+
+	// Watch streams and WebSockets only end when their subscription is closed
+	eventBus.CloseSubscribers()
+
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+
+	return runErr
+}
+
+// StatusResponse describes the running server and its store
+type StatusResponse struct {
+	Service          string             `json:"service"`
+	Version          string             `json:"version"`
+	BuildTime        string             `json:"build_time"`
+	CommitID         string             `json:"commit_id"`
+	StartTime        time.Time          `json:"start_time"`
+	Uptime           string             `json:"uptime"`
+	UptimeSeconds    int64              `json:"uptime_seconds"`
+	Store            *store.StoreInfo   `json:"store"`
+	ActiveRuns       int                `json:"active_runs"`
+	ConnectedWorkers int                `json:"connected_workers"`
+	EventSubscribers int                `json:"event_subscribers"`
+	EventSinks       []*EventSinkStatus `json:"event_sinks"`
+	Process          *ProcessStatus     `json:"process"`
+}
+
+// serverStart records when serving started and which binary is serving
+var serverStart struct {
+	time      time.Time
+	buildTime string
+	commitID  string
+}
+
+func markStarted(opts *Options) {
+	serverStart.time = time.Now()
+	serverStart.buildTime = opts.BuildTime
+	serverStart.commitID = opts.CommitID
+}
+
+// compactStore compacts the store file before the servers start
+func compactStore(ctx context.Context, ninjaStore *store.NinjaStore) error {
+	stats, err := ninjaStore.Compact(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to compact store: %w", err)
+	}
+
+	slog.Info("compacted store", "bytes_before", stats.BytesBefore, "bytes_after", stats.BytesAfter,
+		"reclaimed_bytes", stats.BytesBefore-stats.BytesAfter)
+
+	return nil
+}
+
+// checkStore logs the writes replayed from the journal of the store and the integrity problems of its
+// graph, removing them with repair
+func checkStore(ctx context.Context, ninjaStore *store.NinjaStore, repair bool) error {
+	report, err := ninjaStore.CheckIntegrity(ctx, repair)
+	if err != nil {
+		return fmt.Errorf("failed to check store integrity: %w", err)
+	}
+
+	if report.ReplayedWrites > 0 {
+		slog.Warn("replayed writes interrupted by a crash", "writes", report.ReplayedWrites)
+	}
+
+	for i, problem := range report.Problems {
+		if i == maxLoggedProblems {
+			slog.Warn("more integrity problems", "count", len(report.Problems)-i)
+			break
+		}
+		slog.Warn("integrity problem", "kind", problem.Kind, "node", problem.Node, "detail", problem.Detail)
+	}
+
+	switch {
+	case report.Repaired:
+		slog.Info("repaired store", "removed_nodes", report.RemovedNodes, "removed_links", report.RemovedLinks)
+	case len(report.Problems) > 0:
+		slog.Warn("store has integrity problems, serve with --repair to remove them", "problems", len(report.Problems))
+	}
+
+	return nil
+}
+
+// loadOptions are the load settings of the running server
+var loadOptions parser.LoadOptions
+
+// newNinjaParser returns a parser loading into ninjaStore with the load settings of the server
+func newNinjaParser(ninjaStore *store.NinjaStore) *parser.NinjaParser {
+	ninjaParser := parser.NewNinjaParser(ninjaStore)
+	ninjaParser.SetLoadOptions(loadOptions)
+
+	return ninjaParser
+}
+
+// collectStatus gathers the status shared by the HTTP and gRPC status endpoints
+func collectStatus(ctx context.Context, ninjaStore *store.NinjaStore) (*StatusResponse, error) {
+	info, err := ninjaStore.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	uptime := time.Since(serverStart.time).Round(time.Second)
+
+	return &StatusResponse{
+		Service:       serviceName,
+		Version:       fmt.Sprintf("%s-%s", serverStart.buildTime, serverStart.commitID),
+		BuildTime:     serverStart.buildTime,
+		CommitID:      serverStart.commitID,
+		StartTime:     serverStart.time.UTC(),
+		Uptime:        uptime.String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+		Store:         info,
+		// The server does not execute builds itself, runs and workers are reported once it does
+		ActiveRuns:       0,
+		ConnectedWorkers: 0,
+		EventSubscribers: eventBus.Subscribers(),
+		EventSinks:       eventBus.Sinks(),
+		Process:          collectProcessStatus(),
+	}, nil
+}
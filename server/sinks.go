@@ -0,0 +1,398 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	natsDefaultPort  = "4222"
+	natsDialTimeout  = 5 * time.Second
+	natsWriteTimeout = 5 * time.Second
+	// natsMaxLine bounds the INFO and error lines read from a NATS server
+	natsMaxLine = 64 << 10
+
+	kafkaTimeout     = 10 * time.Second
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+	kafkaAccept      = "application/vnd.kafka.v2+json"
+)
+
+// NATSSink publishes events to a NATS server, each to the subject <prefix>.<event type>, e.g.
+// distninja.target.status_changed. It speaks the NATS client protocol itself and connects again after
+// the connection fails. User and password or a token are taken from the url, tls:// connects with TLS.
+type NATSSink struct {
+	address string
+	tls     bool
+	user    *url.Userinfo
+	prefix  string
+
+	mu         sync.Mutex
+	conn       net.Conn
+	writer     *bufio.Writer
+	maxPayload int
+	// serverErr is the last error the server sent on the current connection
+	serverErr error
+}
+
+// natsInfo is the part of the INFO message of a NATS server the sink uses
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+	MaxPayload  int  `json:"max_payload"`
+}
+
+// NewNATSSink creates a sink for the server at rawURL, nats://host:port or tls://host:port, connecting
+// on the first event
+func NewNATSSink(rawURL, prefix string) (*NATSSink, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "nats" && target.Scheme != "tls") || target.Hostname() == "" {
+		return nil, fmt.Errorf("invalid nats url %q, expected nats://host:port or tls://host:port", rawURL)
+	}
+
+	if prefix == "" || strings.ContainsAny(prefix, " \t\r\n*>") {
+		return nil, fmt.Errorf("invalid nats subject prefix %q", prefix)
+	}
+
+	port := target.Port()
+	if port == "" {
+		port = natsDefaultPort
+	}
+
+	return &NATSSink{
+		address: net.JoinHostPort(target.Hostname(), port),
+		tls:     target.Scheme == "tls",
+		user:    target.User,
+		prefix:  strings.TrimSuffix(prefix, "."),
+	}, nil
+}
+
+// Name identifies the sink among the event sinks
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+// Send publishes event, after a failed write the connection is opened again and the event sent once more
+func (s *NATSSink) Send(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	subject := s.prefix + "." + event.Type
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		if err := s.publish(subject, payload); err == nil {
+			return nil
+		}
+		s.disconnect()
+	}
+
+	if err := s.connect(); err != nil {
+		return err
+	}
+
+	if err := s.publish(subject, payload); err != nil {
+		s.disconnect()
+		return err
+	}
+
+	return nil
+}
+
+// Close closes the connection to the server
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.disconnect()
+
+	return nil
+}
+
+// publish writes a PUB message, s.mu must be held
+func (s *NATSSink) publish(subject string, payload []byte) error {
+	if s.serverErr != nil {
+		return s.serverErr
+	}
+
+	if s.maxPayload > 0 && len(payload) > s.maxPayload {
+		return fmt.Errorf("event of %d bytes exceeds the nats max payload of %d bytes", len(payload), s.maxPayload)
+	}
+
+	if err := s.conn.SetWriteDeadline(time.Now().Add(natsWriteTimeout)); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(s.writer, "PUB %s %d\r\n", subject, len(payload)); err != nil {
+		return err
+	}
+	if _, err := s.writer.Write(payload); err != nil {
+		return err
+	}
+	if _, err := s.writer.WriteString("\r\n"); err != nil {
+		return err
+	}
+
+	return s.writer.Flush()
+}
+
+// connect opens a connection, sends CONNECT and waits for the PONG of a PING so authentication errors
+// are reported before events are published. s.mu must be held.
+func (s *NATSSink) connect() error {
+	conn, err := net.DialTimeout("tcp", s.address, natsDialTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to nats %s: %w", s.address, err)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(natsDialTimeout))
+
+	reader := bufio.NewReaderSize(conn, 4096)
+
+	line, err := readNATSLine(reader)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to read nats info: %w", err)
+	}
+
+	infoJSON, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		_ = conn.Close()
+		return fmt.Errorf("unexpected nats greeting %q", line)
+	}
+
+	var info natsInfo
+	if err := json.Unmarshal([]byte(infoJSON), &info); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("invalid nats info: %w", err)
+	}
+
+	if s.tls || info.TLSRequired {
+		host, _, _ := net.SplitHostPort(s.address)
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+		if err := tlsConn.Handshake(); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("nats tls handshake failed: %w", err)
+		}
+		conn = tlsConn
+		reader = bufio.NewReaderSize(conn, 4096)
+	}
+
+	options := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     serviceName,
+		"lang":     "go",
+		"version":  "1",
+		"protocol": 1,
+	}
+
+	if s.user != nil {
+		if password, ok := s.user.Password(); ok {
+			options["user"], options["pass"] = s.user.Username(), password
+		} else {
+			options["auth_token"] = s.user.Username()
+		}
+	}
+
+	connect, err := json.Marshal(options)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to send nats connect: %w", err)
+	}
+
+	for {
+		line, err := readNATSLine(reader)
+		if err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("failed to connect to nats: %w", err)
+		}
+
+		if line == "PONG" {
+			break
+		}
+		if strings.HasPrefix(line, "-ERR") {
+			_ = conn.Close()
+			return fmt.Errorf("nats refused connection: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	s.conn = conn
+	s.writer = bufio.NewWriter(conn)
+	s.maxPayload = info.MaxPayload
+	s.serverErr = nil
+
+	go s.readLoop(conn, reader)
+
+	return nil
+}
+
+// readLoop answers the keepalive pings of the server and keeps the errors it reports, it ends when conn
+// is closed
+func (s *NATSSink) readLoop(conn net.Conn, reader *bufio.Reader) {
+	for {
+		line, err := readNATSLine(reader)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.conn == conn {
+			switch {
+			case line == "PING":
+				_ = s.conn.SetWriteDeadline(time.Now().Add(natsWriteTimeout))
+				if _, err := s.writer.WriteString("PONG\r\n"); err == nil {
+					_ = s.writer.Flush()
+				}
+			case strings.HasPrefix(line, "-ERR"):
+				s.serverErr = fmt.Errorf("nats error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// disconnect closes the connection, s.mu must be held
+func (s *NATSSink) disconnect() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+	}
+	s.conn, s.writer, s.serverErr = nil, nil, nil
+}
+
+func readNATSLine(reader *bufio.Reader) (string, error) {
+	var line []byte
+
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		if line = append(line, chunk...); len(line) > natsMaxLine {
+			return "", fmt.Errorf("nats line longer than %d bytes", natsMaxLine)
+		}
+		if !isPrefix {
+			return string(line), nil
+		}
+	}
+}
+
+// KafkaRESTSink produces events to a Kafka topic through a Kafka REST proxy speaking the v2 API. Events
+// about a target are keyed by its path, so they land in one partition and keep their order, other events
+// by their type.
+type KafkaRESTSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+// kafkaRecord is a record of a v2 produce request
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value Event  `json:"value"`
+}
+
+// kafkaProduceResponse is the part of a v2 produce response the sink checks
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// NewKafkaRESTSink creates a sink producing to topic through the REST proxy at proxyURL
+func NewKafkaRESTSink(proxyURL, topic string) (*KafkaRESTSink, error) {
+	target, err := url.Parse(proxyURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("invalid kafka rest proxy url %q, expected an absolute http or https url", proxyURL)
+	}
+
+	if topic == "" || strings.ContainsAny(topic, "/ ") {
+		return nil, fmt.Errorf("invalid kafka topic %q", topic)
+	}
+
+	return &KafkaRESTSink{
+		endpoint: strings.TrimSuffix(proxyURL, "/") + "/topics/" + url.PathEscape(topic),
+		client:   &http.Client{Timeout: kafkaTimeout},
+	}, nil
+}
+
+// Name identifies the sink among the event sinks
+func (s *KafkaRESTSink) Name() string {
+	return "kafka"
+}
+
+// Send produces event as a single record
+func (s *KafkaRESTSink) Send(event Event) error {
+	body, err := json.Marshal(map[string][]kafkaRecord{
+		"records": {{Key: eventKey(event), Value: event}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", kafkaAccept)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to produce to kafka: %w", err)
+	}
+
+	defer func(body io.ReadCloser) {
+		_ = body.Close()
+	}(resp.Body)
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("kafka rest proxy returned %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var produced kafkaProduceResponse
+	if err := json.Unmarshal(respBody, &produced); err == nil {
+		for _, offset := range produced.Offsets {
+			if offset.ErrorCode != nil {
+				return fmt.Errorf("kafka refused record: %s (error code %d)", offset.Error, *offset.ErrorCode)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Close releases idle connections to the proxy
+func (s *KafkaRESTSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// eventKey returns the path of the target an event is about, its type for other events
+func eventKey(event Event) string {
+	if data, ok := event.Data.(map[string]string); ok && data["path"] != "" {
+		return data["path"]
+	}
+
+	return event.Type
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	authMethodMTLS     = "mtls"
+	certReloadInterval = 30 * time.Second
+)
+
+// TLSConfig configures TLS, it is disabled when no certificate is set
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables mutual TLS, clients must present a certificate signed by this CA
+	ClientCAFile string
+}
+
+// Enabled reports whether a certificate is configured
+func (c *TLSConfig) Enabled() bool {
+	return c != nil && c.CertFile != ""
+}
+
+// certReloader serves a key pair and reloads it when the files change on disk
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("both tls certificate and key are required")
+	}
+
+	reloader := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if err := reloader.reload(); err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+func (cr *certReloader) reload() error {
+	info, err := os.Stat(cr.certFile)
+	if err != nil {
+		return fmt.Errorf("failed to stat tls certificate %s: %w", cr.certFile, err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls key pair: %w", err)
+	}
+
+	cr.mu.Lock()
+	cr.cert = &cert
+	cr.modTime = info.ModTime()
+	cr.checkedAt = time.Now()
+	cr.mu.Unlock()
+
+	return nil
+}
+
+// GetCertificate returns the current certificate, picking up rotated files at most every certReloadInterval
+func (cr *certReloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mu.RLock()
+	cert, modTime, checkedAt := cr.cert, cr.modTime, cr.checkedAt
+	cr.mu.RUnlock()
+
+	if time.Since(checkedAt) < certReloadInterval {
+		return cert, nil
+	}
+
+	cr.mu.Lock()
+	cr.checkedAt = time.Now()
+	cr.mu.Unlock()
+
+	if info, err := os.Stat(cr.certFile); err == nil && info.ModTime().After(modTime) {
+		if err := cr.reload(); err != nil {
+			// Keep serving the previous certificate until the rotated files are consistent
+			slog.Warn("failed to reload tls certificate", "cert", cr.certFile, "error", err)
+			return cert, nil
+		}
+
+		cr.mu.RLock()
+		cert = cr.cert
+		cr.mu.RUnlock()
+	}
+
+	return cert, nil
+}
+
+// serverTLSConfig builds a tls.Config serving the configured certificate
+func serverTLSConfig(config *TLSConfig) (*tls.Config, error) {
+	reloader, err := newCertReloader(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if config.ClientCAFile != "" {
+		caBytes, err := os.ReadFile(config.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client ca %s: %w", config.ClientCAFile, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBytes) {
+			return nil, fmt.Errorf("no certificates found in client ca %s", config.ClientCAFile)
+		}
+
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// certIdentity returns the identity of a verified client certificate chain
+func certIdentity(state *tls.ConnectionState) *Identity {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	cert := state.VerifiedChains[0][0]
+
+	return &Identity{
+		Subject: cert.Subject.CommonName,
+		Method:  authMethodMTLS,
+		Claims: map[string]interface{}{
+			"dns_names":     cert.DNSNames,
+			"serial_number": cert.SerialNumber.String(),
+			"issuer":        cert.Issuer.CommonName,
+		},
+	}
+}
+
+// peerIdentity returns the client certificate identity of a gRPC peer
+func peerIdentity(ctx context.Context) *Identity {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+
+	return certIdentity(&info.State)
+}
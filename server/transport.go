@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+)
+
+// GRPCConfig tunes the gRPC transport, zero values keep the gRPC defaults
+type GRPCConfig struct {
+	// KeepaliveTime is how long a connection may be idle before the server pings it
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout is how long the server waits for a ping ack before closing the connection
+	KeepaliveTimeout time.Duration
+	// KeepaliveMinTime is the shortest ping interval allowed from clients, faster clients are disconnected
+	KeepaliveMinTime time.Duration
+	// MaxConcurrentStreams limits the concurrent streams of one connection
+	MaxConcurrentStreams uint32
+	// MaxRecvMsgBytes and MaxSendMsgBytes cap the size of one message
+	MaxRecvMsgBytes int
+	MaxSendMsgBytes int
+	// Compression compresses responses to clients that accept it, "gzip" or empty for none
+	Compression string
+}
+
+// validate rejects compressors the server doesn't know
+func (c *GRPCConfig) validate() error {
+	if c.Compression != "" && c.Compression != gzip.Name {
+		return fmt.Errorf("unsupported grpc compression %s, expected %s", c.Compression, gzip.Name)
+	}
+
+	return nil
+}
+
+// serverOptions returns the transport options of config, gzip compressed requests are always accepted
+func (c *GRPCConfig) serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+
+	if c.KeepaliveTime > 0 || c.KeepaliveTimeout > 0 {
+		opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    c.KeepaliveTime,
+			Timeout: c.KeepaliveTimeout,
+		}))
+	}
+
+	if c.KeepaliveMinTime > 0 {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime: c.KeepaliveMinTime,
+			// Idle workers keep their connection warm between builds
+			PermitWithoutStream: true,
+		}))
+	}
+
+	if c.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(c.MaxConcurrentStreams))
+	}
+
+	if c.MaxRecvMsgBytes > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(c.MaxRecvMsgBytes))
+	}
+
+	if c.MaxSendMsgBytes > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(c.MaxSendMsgBytes))
+	}
+
+	return opts
+}
+
+// compressionInterceptor compresses responses with compressor when the client accepts it
+func compressionInterceptor(compressor string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		setSendCompressor(ctx, compressor)
+		return handler(ctx, req)
+	}
+}
+
+// compressionStreamInterceptor is the streaming counterpart of compressionInterceptor
+func compressionStreamInterceptor(compressor string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		setSendCompressor(ss.Context(), compressor)
+		return handler(srv, ss)
+	}
+}
+
+func setSendCompressor(ctx context.Context, compressor string) {
+	if compressor == "" {
+		return
+	}
+
+	accepted, err := grpc.ClientSupportedCompressors(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, name := range accepted {
+		if name == compressor {
+			_ = grpc.SetSendCompressor(ctx, compressor)
+			return
+		}
+	}
+}
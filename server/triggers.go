@@ -0,0 +1,192 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/distninja/distninja/store"
+)
+
+// triggerPath receives push and pull request webhooks of GitHub and GitLab. They can't send bearer
+// tokens, so the handler checks their signature instead of the auth middleware.
+const triggerPath = "/api/v1/triggers/git"
+
+// TriggerResult is the impact of the change announced by a git webhook, also published as a
+// trigger.received event
+type TriggerResult struct {
+	Provider string `json:"provider"`
+	Event    string `json:"event"`
+	Ref      string `json:"ref,omitempty"`
+	Commit   string `json:"commit,omitempty"`
+	*store.ChangedResult
+}
+
+// gitPushPayload holds the fields of GitHub and GitLab push payloads used by the trigger
+type gitPushPayload struct {
+	Ref     string `json:"ref"`
+	After   string `json:"after"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Removed  []string `json:"removed"`
+		Modified []string `json:"modified"`
+	} `json:"commits"`
+}
+
+type githubPullRequestPayload struct {
+	PullRequest struct {
+		Base struct {
+			SHA string `json:"sha"`
+		} `json:"base"`
+		Head struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+}
+
+type gitlabMergeRequestPayload struct {
+	ObjectAttributes struct {
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+		LastCommit   struct {
+			ID string `json:"id"`
+		} `json:"last_commit"`
+	} `json:"object_attributes"`
+}
+
+// triggerHandler maps the files changed by a GitHub or GitLab push or pull request to the targets
+// they affect and publishes them as a trigger.received event. Webhooks are verified with secret, pull
+// and merge requests list no files and are diffed in workspace.
+func triggerHandler(secret, workspace string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		result := &TriggerResult{}
+		var since string
+		var paths []string
+
+		switch {
+		case r.Header.Get("X-GitHub-Event") != "":
+			result.Provider, result.Event = "github", r.Header.Get("X-GitHub-Event")
+			if !validGitHubSignature(secret, body, r.Header.Get("X-Hub-Signature-256")) {
+				writeError(w, "Invalid webhook signature", http.StatusUnauthorized)
+				return
+			}
+		case r.Header.Get("X-Gitlab-Event") != "":
+			result.Provider, result.Event = "gitlab", r.Header.Get("X-Gitlab-Event")
+			if !hmac.Equal([]byte(r.Header.Get("X-Gitlab-Token")), []byte(secret)) {
+				writeError(w, "Invalid webhook token", http.StatusUnauthorized)
+				return
+			}
+		default:
+			writeError(w, "Expected a GitHub or GitLab webhook", http.StatusBadRequest)
+			return
+		}
+
+		switch result.Event {
+		case "ping":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]string{"status": "pong"})
+			return
+		case "push", "Push Hook":
+			var payload gitPushPayload
+			if err := json.Unmarshal(body, &payload); err != nil {
+				writeError(w, fmt.Sprintf("Invalid push payload: %v", err), http.StatusBadRequest)
+				return
+			}
+			result.Ref, result.Commit = payload.Ref, payload.After
+			paths = payload.changedPaths()
+		case "pull_request":
+			var payload githubPullRequestPayload
+			if err := json.Unmarshal(body, &payload); err != nil {
+				writeError(w, fmt.Sprintf("Invalid pull request payload: %v", err), http.StatusBadRequest)
+				return
+			}
+			pr := payload.PullRequest
+			result.Ref, result.Commit = pr.Head.Ref, pr.Head.SHA
+			since = pr.Base.SHA + "..." + pr.Head.SHA
+		case "Merge Request Hook":
+			var payload gitlabMergeRequestPayload
+			if err := json.Unmarshal(body, &payload); err != nil {
+				writeError(w, fmt.Sprintf("Invalid merge request payload: %v", err), http.StatusBadRequest)
+				return
+			}
+			mr := payload.ObjectAttributes
+			result.Ref, result.Commit = mr.SourceBranch, mr.LastCommit.ID
+			since = "origin/" + mr.TargetBranch + "..." + mr.LastCommit.ID
+		default:
+			writeError(w, fmt.Sprintf("Unsupported %s event %s", result.Provider, result.Event), http.StatusBadRequest)
+			return
+		}
+
+		if since != "" {
+			if workspace == "" {
+				writeError(w, "Pull requests need a git workspace, start the server with --workspace", http.StatusUnprocessableEntity)
+				return
+			}
+			if paths, err = gitChangedPaths(r.Context(), workspace, since); err != nil {
+				writeError(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+		}
+
+		if result.ChangedResult, err = ninjaStore.GetChangedImpact(r.Context(), paths); err != nil {
+			writeError(w, fmt.Sprintf("Failed to analyze changes: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		eventBus.Publish(EventTriggerReceived, result)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	}
+}
+
+// validGitHubSignature checks an X-Hub-Signature-256 header, the HMAC-SHA256 of the body
+func validGitHubSignature(secret string, body []byte, signature string) bool {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// changedPaths returns the files added, removed or modified by the commits of a push
+func (p *gitPushPayload) changedPaths() []string {
+	seen := make(map[string]bool)
+	for _, commit := range p.Commits {
+		for _, files := range [][]string{commit.Added, commit.Removed, commit.Modified} {
+			for _, file := range files {
+				seen[file] = true
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
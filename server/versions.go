@@ -0,0 +1,244 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/distninja/distninja/store"
+)
+
+const (
+	apiV1Prefix = "/api/v1"
+	apiV2Prefix = "/api/v2"
+
+	problemContentType = "application/problem+json"
+)
+
+// DataEnvelope wraps every successful JSON response of the v2 API, Data is the v1 response body. The v2
+// API has no response types of its own, its payloads change with those of v1.
+type DataEnvelope struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// ProblemDetails is the RFC 7807 error body of the v2 API, Code is the machine readable reason of
+// the error and stays stable across releases while Detail may change
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+// statusReasons are the reasons of errors without a more specific one, by status code
+var statusReasons = map[int]string{
+	http.StatusBadRequest:            "invalid_argument",
+	http.StatusUnauthorized:          "unauthenticated",
+	http.StatusForbidden:             "permission_denied",
+	http.StatusNotFound:              "not_found",
+	http.StatusMethodNotAllowed:      "method_not_allowed",
+	http.StatusConflict:              "conflict",
+	http.StatusRequestEntityTooLarge: "payload_too_large",
+	http.StatusUnsupportedMediaType:  "unsupported_media_type",
+	http.StatusUnprocessableEntity:   "unprocessable_entity",
+	http.StatusTooManyRequests:       "rate_limited",
+	http.StatusInternalServerError:   "internal",
+	http.StatusNotImplemented:        "not_implemented",
+	http.StatusServiceUnavailable:    "unavailable",
+	http.StatusGatewayTimeout:        "timeout",
+}
+
+// statusReason returns the reason of an error response with code
+func statusReason(code int) string {
+	if reason, ok := statusReasons[code]; ok {
+		return reason
+	}
+	if code >= http.StatusInternalServerError {
+		return "internal"
+	}
+
+	return "invalid_argument"
+}
+
+// errorReason returns the reason of a store error, and the reason of code for other errors
+func errorReason(err error, code int) string {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, store.ErrExists):
+		return "already_exists"
+	case errors.Is(err, store.ErrRuleInUse):
+		return "rule_in_use"
+	case errors.Is(err, store.ErrInvalidPattern):
+		return "invalid_pattern"
+	case errors.Is(err, store.ErrInvalidLabel):
+		return "invalid_label"
+	case errors.Is(err, store.ErrInvalidResources):
+		return "invalid_resources"
+	case errors.Is(err, store.ErrStoreLocked):
+		return "store_locked"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return statusReason(code)
+	}
+}
+
+// apiVersionMiddleware serves /api/v2 with the handlers of /api/v1, wrapping successful JSON responses
+// in a DataEnvelope and turning errors into ProblemDetails. Routes, permissions, idempotency and audit
+// are matched on the v1 path, so both versions behave the same. Responses of v1 carry the Deprecation
+// header and a link to their v2 successor.
+func apiVersionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+
+		if rest, ok := apiPath(path, apiV1Prefix); ok {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", apiV2Prefix, rest))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rest, ok := apiPath(path, apiV2Prefix)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		v1 := new(http.Request)
+		*v1 = *r
+		url := *r.URL
+		url.Path = apiV1Prefix + rest
+		if url.RawPath != "" {
+			url.RawPath = apiV1Prefix + strings.TrimPrefix(url.RawPath, apiV2Prefix)
+		}
+		v1.URL = &url
+
+		writer := &envelopeWriter{ResponseWriter: w, wrap: !unwrappedV2Routes[rest]}
+		next.ServeHTTP(writer, v1)
+		writer.finish(path)
+	})
+}
+
+// unwrappedV2Routes keep their v1 response body on success, they follow their own formats
+var unwrappedV2Routes = map[string]bool{
+	"/graphql":      true,
+	"/openapi.json": true,
+	"/docs":         true,
+	"/ws":           true,
+}
+
+// apiPath returns the path below prefix
+func apiPath(path, prefix string) (string, bool) {
+	if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+		return "", false
+	}
+
+	return strings.TrimPrefix(path, prefix), true
+}
+
+// envelopeWriter holds back error responses and, when wrap is set, successful JSON responses until
+// finish rewrites them, other responses such as exports and WebSocket upgrades pass through
+type envelopeWriter struct {
+	http.ResponseWriter
+	wrap bool
+
+	status   int
+	buffered bool
+	body     bytes.Buffer
+}
+
+func (ew *envelopeWriter) WriteHeader(code int) {
+	if ew.status != 0 {
+		return
+	}
+	ew.status = code
+
+	contentType := ew.Header().Get("Content-Type")
+	if code >= http.StatusBadRequest ||
+		(ew.wrap && code != http.StatusNoContent && strings.HasPrefix(contentType, "application/json")) {
+		ew.buffered = true
+		return
+	}
+
+	ew.ResponseWriter.WriteHeader(code)
+}
+
+func (ew *envelopeWriter) Write(b []byte) (int, error) {
+	if ew.status == 0 {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if ew.buffered {
+		return ew.body.Write(b)
+	}
+
+	return ew.ResponseWriter.Write(b)
+}
+
+func (ew *envelopeWriter) Unwrap() http.ResponseWriter {
+	return ew.ResponseWriter
+}
+
+// Hijack lets WebSocket upgrades take over the connection
+func (ew *envelopeWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := ew.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+
+	return hijacker.Hijack()
+}
+
+// finish writes the held back response, instance is the path of the v2 request
+func (ew *envelopeWriter) finish(instance string) {
+	if !ew.buffered {
+		return
+	}
+
+	header := ew.Header()
+	header.Del("Content-Length")
+
+	if ew.status >= http.StatusBadRequest {
+		problem := ProblemDetails{
+			Type:     "about:blank",
+			Title:    http.StatusText(ew.status),
+			Status:   ew.status,
+			Instance: instance,
+			Code:     statusReason(ew.status),
+		}
+
+		// Errors of handlers are ErrorResponses, those of the router plain text
+		var response ErrorResponse
+		if err := json.Unmarshal(ew.body.Bytes(), &response); err == nil && response.Error != "" {
+			problem.Detail = response.Error
+			if response.Reason != "" {
+				problem.Code = response.Reason
+			}
+		} else {
+			problem.Detail = strings.TrimSpace(ew.body.String())
+		}
+
+		header.Set("Content-Type", problemContentType)
+		ew.ResponseWriter.WriteHeader(ew.status)
+		_ = json.NewEncoder(ew.ResponseWriter).Encode(problem)
+		return
+	}
+
+	data := bytes.TrimSpace(ew.body.Bytes())
+	if len(data) == 0 {
+		data = []byte("null")
+	}
+
+	ew.ResponseWriter.WriteHeader(ew.status)
+	if err := json.NewEncoder(ew.ResponseWriter).Encode(DataEnvelope{Data: data}); err != nil {
+		_, _ = ew.ResponseWriter.Write(ew.body.Bytes())
+	}
+}
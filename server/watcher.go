@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/distninja/distninja/store"
+)
+
+const (
+	// watchDirtyStatus is the status of targets whose sources changed since they were built
+	watchDirtyStatus = "dirty"
+	// watchWorker is the worker recorded in the status history of targets marked dirty by the watcher
+	watchWorker = "watch"
+	// watchMaxMessagePaths bounds the changed sources named in the status history message
+	watchMaxMessagePaths = 5
+)
+
+// fileStamp is what the watcher compares to tell a file changed
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// workspaceWatcher polls a workspace for changed source files, marks the targets depending on them dirty
+// and publishes a workspace.changed event, which executors act on to start incremental runs. Changes are
+// collected until a poll finds no more, so a save of several files or a checkout is reported once.
+type workspaceWatcher struct {
+	root     string
+	interval time.Duration
+	// skip is a directory below root that isn't scanned, e.g. the store
+	skip string
+
+	stamps  map[string]fileStamp
+	pending map[string]bool
+}
+
+func newWorkspaceWatcher(root string, interval time.Duration, skip string) *workspaceWatcher {
+	if abs, err := filepath.Abs(skip); err == nil {
+		skip = abs
+	}
+
+	return &workspaceWatcher{
+		root:     root,
+		interval: interval,
+		skip:     skip,
+		pending:  make(map[string]bool),
+	}
+}
+
+// Start polls the workspace until the returned function is called
+func (w *workspaceWatcher) Start() func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			w.poll()
+
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// poll scans the workspace once and reports the pending changes when the scan found no new ones
+func (w *workspaceWatcher) poll() {
+	stamps, err := w.scan()
+	if err != nil {
+		slog.Warn("failed to scan workspace", "workspace", w.root, "error", err)
+		return
+	}
+
+	// The first scan is the baseline
+	if w.stamps == nil {
+		w.stamps = stamps
+		return
+	}
+
+	changed := false
+	for path, stamp := range stamps {
+		if old, ok := w.stamps[path]; !ok || old != stamp {
+			w.pending[path] = true
+			changed = true
+		}
+	}
+	for path := range w.stamps {
+		if _, ok := stamps[path]; !ok {
+			w.pending[path] = true
+			changed = true
+		}
+	}
+	w.stamps = stamps
+
+	if changed || len(w.pending) == 0 {
+		return
+	}
+
+	paths := make([]string, 0, len(w.pending))
+	for path := range w.pending {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	w.pending = make(map[string]bool)
+
+	if err := w.report(context.Background(), paths); err != nil {
+		slog.Error("failed to report workspace changes", "workspace", w.root, "error", err)
+	}
+}
+
+// scan returns the stamps of the files of the workspace by their slash separated path relative to it,
+// hidden directories such as .git are skipped
+func (w *workspaceWatcher) scan() (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+
+	err := filepath.WalkDir(w.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Files removed while walking are picked up by the next scan
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if entry.IsDir() {
+			if path != w.root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if abs, err := filepath.Abs(path); err == nil && abs == w.skip {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		rel, err := filepath.Rel(w.root, path)
+		if err != nil {
+			return err
+		}
+
+		stamps[filepath.ToSlash(rel)] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+
+		return nil
+	})
+
+	return stamps, err
+}
+
+// report marks the targets depending on the changed source files dirty and publishes the change.
+// Changed outputs of builds are ignored, the run that wrote them already built their dependents.
+func (w *workspaceWatcher) report(ctx context.Context, paths []string) error {
+	if _, err := storeGate.enter(ctx); err != nil {
+		return err
+	}
+	defer storeGate.leave()
+
+	result, err := ninjaStore.GetChangedImpact(ctx, paths)
+	if err != nil {
+		return err
+	}
+
+	sources := make([]string, 0, len(result.Files))
+	for _, file := range result.Files {
+		if _, err := ninjaStore.GetTarget(ctx, file); err != nil {
+			sources = append(sources, file)
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil
+	}
+
+	if len(sources) != len(result.Files) {
+		if result.ImpactResult, err = ninjaStore.GetImpact(ctx, sources); err != nil {
+			return err
+		}
+	}
+
+	named := sources
+	if len(named) > watchMaxMessagePaths {
+		named = append(named[:watchMaxMessagePaths:watchMaxMessagePaths], fmt.Sprintf("%d more", len(sources)-watchMaxMessagePaths))
+	}
+	origin := &store.StatusOrigin{Worker: watchWorker, Message: "sources changed: " + strings.Join(named, ", ")}
+
+	for _, path := range result.Targets {
+		target, err := ninjaStore.GetTarget(ctx, path)
+		if err != nil || target.Status == watchDirtyStatus {
+			continue
+		}
+
+		if err := ninjaStore.UpdateTargetStatus(ctx, path, watchDirtyStatus, origin); err != nil {
+			return fmt.Errorf("failed to mark %s dirty: %w", path, err)
+		}
+
+		publishStatusChange(eventBus, target, watchDirtyStatus)
+	}
+
+	slog.Info("workspace changed", "files", len(sources), "targets", result.TargetCount)
+
+	eventBus.Publish(EventWorkspaceChanged, result)
+
+	return nil
+}
@@ -0,0 +1,445 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/distninja/distninja/store"
+)
+
+const (
+	webhookBusBuffer   = 1024
+	webhookQueueSize   = 256
+	webhookHistorySize = 50
+	webhookMaxAttempts = 5
+	webhookRetryDelay  = time.Second
+	webhookTimeout     = 10 * time.Second
+
+	webhookEventHeader     = "X-Distninja-Event"
+	webhookDeliveryHeader  = "X-Distninja-Delivery"
+	webhookSignatureHeader = "X-Distninja-Signature"
+)
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+	DeliveryDropped   = "dropped"
+)
+
+// WebhookRequest registers a webhook, a secret is generated when none is given
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// WebhookResponse describes a webhook, the secret is only returned on registration
+type WebhookResponse struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	CreatedAt string   `json:"created_at"`
+	Secret    string   `json:"secret,omitempty"`
+}
+
+// WebhookDelivery tracks the delivery of one event to one webhook
+type WebhookDelivery struct {
+	ID           string    `json:"id"`
+	WebhookID    string    `json:"webhook_id"`
+	EventID      uint64    `json:"event_id"`
+	EventType    string    `json:"event_type"`
+	Status       string    `json:"status"`
+	Attempts     int       `json:"attempts"`
+	ResponseCode int       `json:"response_code,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// WebhookDispatcher posts bus events to registered webhooks, each webhook receives its events in order
+type WebhookDispatcher struct {
+	store  *store.NinjaStore
+	client *http.Client
+	seq    atomic.Uint64
+
+	mu      sync.Mutex
+	workers map[string]*webhookWorker
+}
+
+// webhookWorker delivers the queued events of a single webhook
+type webhookWorker struct {
+	webhook *store.NinjaWebhook
+	events  map[string]bool
+	queue   chan *webhookJob
+	done    chan struct{}
+	history []*WebhookDelivery
+}
+
+type webhookJob struct {
+	event    Event
+	delivery *WebhookDelivery
+}
+
+// NewWebhookDispatcher creates a dispatcher for the webhooks already in the store
+func NewWebhookDispatcher(ctx context.Context, ninjaStore *store.NinjaStore) (*WebhookDispatcher, error) {
+	d := &WebhookDispatcher{
+		store:   ninjaStore,
+		client:  &http.Client{Timeout: webhookTimeout},
+		workers: make(map[string]*webhookWorker),
+	}
+
+	webhooks, err := ninjaStore.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, webhook := range webhooks {
+		if err := d.startWorker(webhook); err != nil {
+			slog.Warn("skipping webhook", "id", webhook.WebhookID, "error", err)
+		}
+	}
+
+	return d, nil
+}
+
+// Start runs the dispatcher as a sink of bus, the returned function stops all deliveries
+func (d *WebhookDispatcher) Start(bus *EventBus) func() {
+	return bus.StartSink(d, webhookBusBuffer)
+}
+
+// Name identifies the dispatcher among the event sinks
+func (d *WebhookDispatcher) Name() string {
+	return "webhooks"
+}
+
+// Send queues event for the webhooks subscribed to its type, deliveries that fail are retried and
+// recorded by their webhook
+func (d *WebhookDispatcher) Send(event Event) error {
+	d.dispatch(event)
+	return nil
+}
+
+// Close stops the deliveries of all webhooks
+func (d *WebhookDispatcher) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for id, worker := range d.workers {
+		worker.stop()
+		delete(d.workers, id)
+	}
+
+	return nil
+}
+
+// Register validates and stores a webhook and starts delivering events to it
+func (d *WebhookDispatcher) Register(ctx context.Context, req *WebhookRequest) (*WebhookResponse, error) {
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("invalid webhook url %q, expected an absolute http or https url", req.URL)
+	}
+
+	if err := ValidateEventTypes(req.Events); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = randomHex(32)
+	}
+
+	webhook := &store.NinjaWebhook{
+		WebhookID: randomHex(8),
+		URL:       req.URL,
+		Secret:    secret,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if err := webhook.SetEvents(req.Events); err != nil {
+		return nil, err
+	}
+
+	if err := d.store.AddWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	if err := d.startWorker(webhook); err != nil {
+		return nil, err
+	}
+
+	response := webhookResponse(webhook)
+	response.Secret = secret
+
+	return response, nil
+}
+
+// List returns all registered webhooks
+func (d *WebhookDispatcher) List(ctx context.Context) ([]*WebhookResponse, error) {
+	webhooks, err := d.store.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		responses = append(responses, webhookResponse(webhook))
+	}
+
+	return responses, nil
+}
+
+// Get returns the webhook with the given id
+func (d *WebhookDispatcher) Get(ctx context.Context, id string) (*WebhookResponse, error) {
+	webhook, err := d.store.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhookResponse(webhook), nil
+}
+
+// Delete removes a webhook, pending deliveries are abandoned
+func (d *WebhookDispatcher) Delete(ctx context.Context, id string) error {
+	if err := d.store.DeleteWebhook(ctx, id); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if worker, ok := d.workers[id]; ok {
+		worker.stop()
+		delete(d.workers, id)
+	}
+
+	return nil
+}
+
+// Deliveries returns the recent deliveries of a webhook, newest first
+func (d *WebhookDispatcher) Deliveries(id string) ([]*WebhookDelivery, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	worker, ok := d.workers[id]
+	if !ok {
+		return nil, fmt.Errorf("webhook %s: %w", id, store.ErrNotFound)
+	}
+
+	deliveries := make([]*WebhookDelivery, 0, len(worker.history))
+	for i := len(worker.history) - 1; i >= 0; i-- {
+		delivery := *worker.history[i]
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, nil
+}
+
+func (d *WebhookDispatcher) startWorker(webhook *store.NinjaWebhook) error {
+	events, err := webhook.GetEvents()
+	if err != nil {
+		return fmt.Errorf("invalid events of webhook %s: %w", webhook.WebhookID, err)
+	}
+
+	worker := &webhookWorker{
+		webhook: webhook,
+		events:  make(map[string]bool, len(events)),
+		queue:   make(chan *webhookJob, webhookQueueSize),
+		done:    make(chan struct{}),
+	}
+
+	for _, eventType := range events {
+		worker.events[eventType] = true
+	}
+
+	d.mu.Lock()
+	d.workers[webhook.WebhookID] = worker
+	d.mu.Unlock()
+
+	go d.work(worker)
+
+	return nil
+}
+
+// dispatch queues event for every webhook subscribed to its type
+func (d *WebhookDispatcher) dispatch(event Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, worker := range d.workers {
+		if len(worker.events) > 0 && !worker.events[event.Type] {
+			continue
+		}
+
+		now := time.Now().UTC()
+		delivery := &WebhookDelivery{
+			ID:        strconv.FormatUint(d.seq.Add(1), 10),
+			WebhookID: worker.webhook.WebhookID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Status:    DeliveryPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		worker.record(delivery)
+
+		select {
+		case worker.queue <- &webhookJob{event: event, delivery: delivery}:
+		default:
+			delivery.Status = DeliveryDropped
+			delivery.Error = "delivery queue full"
+		}
+	}
+}
+
+func (d *WebhookDispatcher) work(worker *webhookWorker) {
+	for {
+		select {
+		case <-worker.done:
+			return
+		case job := <-worker.queue:
+			d.deliver(worker, job)
+		}
+	}
+}
+
+// deliver posts a job with exponential backoff between attempts, client errors other than 408 and 429 are not retried
+func (d *WebhookDispatcher) deliver(worker *webhookWorker, job *webhookJob) {
+	body, err := json.Marshal(job.event)
+	if err != nil {
+		d.update(job.delivery, DeliveryFailed, 0, err)
+		return
+	}
+
+	delay := webhookRetryDelay
+
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		d.mu.Lock()
+		job.delivery.Attempts = attempt
+		d.mu.Unlock()
+
+		code, err := d.post(worker.webhook, job, body)
+		if err == nil {
+			d.update(job.delivery, DeliverySucceeded, code, nil)
+			return
+		}
+
+		retryable := code == 0 || code >= http.StatusInternalServerError ||
+			code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+		if !retryable || attempt == webhookMaxAttempts {
+			d.update(job.delivery, DeliveryFailed, code, err)
+			slog.Warn("webhook delivery failed", "webhook", worker.webhook.WebhookID, "event", job.event.Type, "attempts", attempt, "error", err)
+			return
+		}
+
+		d.update(job.delivery, DeliveryPending, code, err)
+
+		select {
+		case <-worker.done:
+			return
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+func (d *WebhookDispatcher) post(webhook *store.NinjaWebhook, job *webhookJob, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", serviceName+"-webhook")
+	req.Header.Set(webhookEventHeader, job.event.Type)
+	req.Header.Set(webhookDeliveryHeader, job.delivery.ID)
+	req.Header.Set(webhookSignatureHeader, signWebhook(webhook.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func(body io.ReadCloser) {
+		_ = body.Close()
+	}(resp.Body)
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (d *WebhookDispatcher) update(delivery *WebhookDelivery, status string, code int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery.Status = status
+	delivery.ResponseCode = code
+	delivery.Error = ""
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	delivery.UpdatedAt = time.Now().UTC()
+}
+
+// record appends a delivery to the bounded history, callers hold the dispatcher lock
+func (w *webhookWorker) record(delivery *WebhookDelivery) {
+	w.history = append(w.history, delivery)
+	if len(w.history) > webhookHistorySize {
+		w.history = w.history[len(w.history)-webhookHistorySize:]
+	}
+}
+
+func (w *webhookWorker) stop() {
+	close(w.done)
+}
+
+// signWebhook returns the signature header value, the hex HMAC-SHA256 of the body keyed by the webhook secret
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookResponse(webhook *store.NinjaWebhook) *WebhookResponse {
+	events, _ := webhook.GetEvents()
+	if events == nil {
+		events = []string{}
+	}
+
+	return &WebhookResponse{
+		ID:        webhook.WebhookID,
+		URL:       webhook.URL,
+		Events:    events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
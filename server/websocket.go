@@ -0,0 +1,115 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsBufferSize = 256
+	wsPingPeriod = 30 * time.Second
+	wsPongWait   = 2 * wsPingPeriod
+	wsWriteWait  = 10 * time.Second
+)
+
+// newWSUpgrader returns an upgrader accepting connections from origins, or from the server's own origin
+// when there are none. Browsers don't apply CORS to WebSockets, so the origin is checked here, a page of
+// any site could read the events otherwise. Clients that aren't browsers send no Origin.
+func newWSUpgrader(origins []string) *websocket.Upgrader {
+	return &websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" || allowedOrigin(origins, origin) {
+				return true
+			}
+
+			u, err := url.Parse(origin)
+			return err == nil && strings.EqualFold(u.Host, r.Host)
+		},
+	}
+}
+
+// eventsWebSocketHandler streams bus events to a WebSocket client, ?events= restricts the event types
+func eventsWebSocketHandler(origins []string) http.HandlerFunc {
+	upgrader := newWSUpgrader(origins)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var types []string
+		if filter := r.URL.Query().Get("events"); filter != "" {
+			for _, t := range strings.Split(filter, ",") {
+				if t = strings.TrimSpace(t); t != "" {
+					types = append(types, t)
+				}
+			}
+		}
+
+		if err := ValidateEventTypes(types); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade has already replied to the client
+			slog.Warn("websocket upgrade failed", "remote", r.RemoteAddr, "error", err)
+			return
+		}
+
+		defer func(conn *websocket.Conn) {
+			_ = conn.Close()
+		}(conn)
+
+		// Clear the deadlines inherited from the HTTP server timeouts
+		_ = conn.NetConn().SetDeadline(time.Time{})
+
+		events, unsubscribe := eventBus.Subscribe(wsBufferSize, types...)
+		defer unsubscribe()
+
+		// Drain client frames to process pongs and notice disconnects
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+			conn.SetPongHandler(func(string) error {
+				return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+			})
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+
+		ticker := time.NewTicker(wsPingPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-closed:
+				return
+			case <-r.Context().Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+				if err := conn.WriteJSON(event); err != nil {
+					return
+				}
+			case <-ticker.C:
+				_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
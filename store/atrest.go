@@ -0,0 +1,345 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cayleygraph/cayley/graph"
+)
+
+const (
+	// boltFile is the file the bolt backend keeps in the store directory, encryptedBoltFile holds it in
+	// stores encrypted at rest
+	boltFile          = "indexes.bolt"
+	encryptedBoltFile = boltFile + encryptedSuffix
+
+	// checkpointWrites bounds the writes kept in the journal of a store encrypted at rest
+	checkpointWrites = 1000
+
+	// checkpointFile is the copy of the decrypted store that a background checkpoint encrypts
+	checkpointFile = "checkpoint.bolt"
+
+	// sharedMemoryDir holds the decrypted store when it exists, so it stays in memory
+	sharedMemoryDir = "/dev/shm"
+)
+
+// ErrStoreEncrypted is returned when a store encrypted at rest is opened without a key
+var ErrStoreEncrypted = errors.New("store is encrypted, a key is required")
+
+// atRest keeps the bolt file of a store encrypted. While the store is open the file is decrypted to a
+// private directory, in memory when /dev/shm exists. Every write is logged to the encrypted journal
+// before it is applied and the file is encrypted again at checkpoints, every checkpointWrites writes,
+// after compacting and on close, clearing the journal. Opening the store decrypts the last checkpoint
+// and replays the journal.
+//
+// The checkpoints every checkpointWrites writes run in the background: writes wait while the file is
+// copied within the private directory, the copy is encrypted meanwhile and the journal entries it holds
+// are removed after. The private directory holds the store twice while it runs.
+type atRest struct {
+	key []byte
+	// workDir is the private directory, workPath the store in it
+	workDir  string
+	workPath string
+	// writes counts the writes logged since the last checkpoint
+	writes atomic.Int64
+	// running is set while a background checkpoint runs, checkpoints tracks it for those that wait
+	running     atomic.Bool
+	checkpoints sync.WaitGroup
+}
+
+// IsEncryptedStore returns whether the store at dbPath is encrypted at rest
+func IsEncryptedStore(dbPath string) bool {
+	_, err := os.Stat(filepath.Join(dbPath, encryptedBoltFile))
+	return err == nil
+}
+
+// openAtRest decrypts the store at dbPath to a new private directory. A store written in the clear is
+// copied, the first checkpoint encrypts it. The caller holds the store lock.
+func openAtRest(dbPath string, key []byte) (*atRest, error) {
+	rest, err := newAtRest(dbPath, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rest.decrypt(dbPath); err != nil {
+		rest.discard()
+		return nil, err
+	}
+
+	return rest, nil
+}
+
+// newAtRest creates an empty private directory for the store at dbPath, removing those left by a
+// crash. The caller holds the store lock.
+func newAtRest(dbPath string, key []byte) (*atRest, error) {
+	if _, err := newAEAD(key); err != nil {
+		return nil, fmt.Errorf("invalid store key: %w", err)
+	}
+
+	base := os.TempDir()
+	if stat, err := os.Stat(sharedMemoryDir); err == nil && stat.IsDir() {
+		base = sharedMemoryDir
+	}
+
+	// Directories left by a crash hold the store in the clear, nothing uses them while the lock is held
+	prefix, err := workDirPrefix(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stale, _ := filepath.Glob(filepath.Join(base, prefix+"*"))
+	for _, dir := range stale {
+		_ = os.RemoveAll(dir)
+	}
+
+	workDir, err := os.MkdirTemp(base, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory for the decrypted store: %w", err)
+	}
+
+	return &atRest{key: key, workDir: workDir, workPath: filepath.Join(workDir, "store")}, nil
+}
+
+// workDirPrefix names the private directories of the store at dbPath
+func workDirPrefix(dbPath string) (string, error) {
+	abs, err := filepath.Abs(dbPath)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(abs))
+
+	return "distninja-" + hex.EncodeToString(sum[:8]) + "-", nil
+}
+
+// decrypt writes the bolt file of the store at dbPath to workPath, a store without one is created
+// when it is opened
+func (r *atRest) decrypt(dbPath string) error {
+	src := filepath.Join(dbPath, encryptedBoltFile)
+	key := r.key
+
+	if !IsEncryptedStore(dbPath) {
+		src, key = filepath.Join(dbPath, boltFile), nil
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			return nil
+		}
+	}
+
+	if err := os.Mkdir(r.workPath, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", r.workPath, err)
+	}
+
+	file, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	var reader io.Reader = file
+	if key != nil {
+		if reader, err = openEncrypted(file, key); err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", src, err)
+		}
+	}
+
+	dst, err := os.OpenFile(filepath.Join(r.workPath, boltFile), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create decrypted store: %w", err)
+	}
+
+	_, err = io.Copy(dst, reader)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", src, err)
+	}
+
+	return nil
+}
+
+// encrypt writes the decrypted bolt file to the store at dbPath, replacing the last checkpoint once the
+// copy is complete, and removes the file a store written in the clear had
+func (r *atRest) encrypt(dbPath string) error {
+	return r.encryptFile(filepath.Join(r.workPath, boltFile), dbPath)
+}
+
+// encryptFile is encrypt for the decrypted bolt file at path
+func (r *atRest) encryptFile(path string, dbPath string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open decrypted store: %w", err)
+	}
+
+	defer func(src *os.File) {
+		_ = src.Close()
+	}(src)
+
+	if err := os.MkdirAll(dbPath, 0700); err != nil {
+		return fmt.Errorf("failed to create store directory %s: %w", dbPath, err)
+	}
+
+	dst := filepath.Join(dbPath, encryptedBoltFile)
+	tmpPath := dst + ".tmp"
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tmpPath, err)
+	}
+
+	encrypted, err := newEncryptWriter(file, r.key)
+	if err == nil {
+		_, err = io.Copy(encrypted, src)
+	}
+	if err == nil {
+		err = encrypted.Close()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, dst)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to encrypt store: %w", err)
+	}
+
+	if err := os.Remove(filepath.Join(dbPath, boltFile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove unencrypted store: %w", err)
+	}
+
+	return nil
+}
+
+// discard removes the decrypted store once a background checkpoint ended
+func (r *atRest) discard() {
+	r.checkpoints.Wait()
+	_ = os.RemoveAll(r.workDir)
+}
+
+// checkpoint encrypts the store file and clears the journal once a background checkpoint ended, the
+// countingWriter lock must be held. Bolt has written every committed write to the file, so its copy
+// holds them all.
+func (ncs *NinjaStore) checkpoint() error {
+	ncs.atRest.checkpoints.Wait()
+
+	if err := ncs.atRest.encrypt(ncs.dbPath); err != nil {
+		return err
+	}
+
+	if err := ncs.journal.clear(); err != nil {
+		return fmt.Errorf("failed to clear journal: %w", err)
+	}
+
+	ncs.atRest.writes.Store(0)
+
+	return nil
+}
+
+// startCheckpoint copies the store file and encrypts the copy in the background, removing the journal
+// entries it holds after, the countingWriter lock must be held. A failed checkpoint keeps them, the
+// writes that follow start the next one.
+func (ncs *NinjaStore) startCheckpoint() error {
+	rest := ncs.atRest
+
+	// Entries logged from now on aren't in the copy
+	entries, err := ncs.journal.entries()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(rest.workDir, checkpointFile)
+	if err := copyFile(filepath.Join(rest.workPath, boltFile), path); err != nil {
+		return fmt.Errorf("failed to copy decrypted store: %w", err)
+	}
+
+	covered := rest.writes.Load()
+	rest.running.Store(true)
+	rest.checkpoints.Add(1)
+
+	go func() {
+		defer rest.checkpoints.Done()
+		defer rest.running.Store(false)
+		defer func() {
+			_ = os.Remove(path)
+		}()
+
+		if err := rest.encryptFile(path, ncs.dbPath); err != nil {
+			return
+		}
+
+		for _, entry := range entries {
+			ncs.journal.complete(entry)
+		}
+
+		rest.writes.Add(-covered)
+	}()
+
+	return nil
+}
+
+// copyFile copies the file at src to a new file at dst, readable by the owner alone
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer func(in *os.File) {
+		_ = in.Close()
+	}(in)
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// logWrite logs tx to the journal of a store encrypted at rest before it is applied, the returned
+// function ends the write with its error. The countingWriter lock must be held.
+func (ncs *NinjaStore) logWrite(tx *graph.Transaction) (func(error), error) {
+	if ncs.atRest == nil || len(tx.Deltas) == 0 {
+		return func(error) {}, nil
+	}
+
+	entry, err := ncs.journal.log(tx.Deltas)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(err error) {
+		// Failed writes leave the store as it was
+		if err != nil {
+			ncs.journal.complete(entry)
+			return
+		}
+
+		// A failed checkpoint keeps the journal, the next write tries again
+		if ncs.atRest.writes.Add(1) >= checkpointWrites && !ncs.atRest.running.Load() {
+			_ = ncs.startCheckpoint()
+		}
+	}, nil
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// NinjaAuditEntry records who changed what and when
+type NinjaAuditEntry struct {
+	ID       quad.IRI `json:"-" quad:"@id"`
+	Type     quad.IRI `json:"-" quad:"@type"`
+	Time     string   `json:"time" quad:"time"`
+	Subject  string   `json:"subject" quad:"subject"`
+	Protocol string   `json:"protocol" quad:"protocol"`
+	Action   string   `json:"action" quad:"action"`
+	Resource string   `json:"resource" quad:"resource"`
+	Result   string   `json:"result" quad:"result"`
+	Details  string   `json:"details,omitempty" quad:"details"`
+	// RequestID correlates the entry with the logs of the request that made the change
+	RequestID string `json:"request_id,omitempty" quad:"request_id,optional"`
+}
+
+var auditSeq atomic.Uint64
+
+// AddAuditEntry stores an audit entry, Time defaults to now
+func (ncs *NinjaStore) AddAuditEntry(ctx context.Context, entry *NinjaAuditEntry) error {
+	now := time.Now().UTC()
+
+	if entry.Time == "" {
+		entry.Time = now.Format(time.RFC3339Nano)
+	}
+
+	if entry.Details == "" {
+		entry.Details = "{}"
+	}
+
+	entry.ID = quad.IRI(fmt.Sprintf("audit:%d-%d", now.UnixNano(), auditSeq.Add(1)))
+	entry.Type = "NinjaAuditEntry"
+
+	tx := graph.NewTransaction()
+	if _, err := ncs.schema.WriteAsQuads(graph.NewTxWriter(tx, graph.Add), entry); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+
+	return ncs.store.ApplyTransaction(tx)
+}
+
+// GetAuditEntries returns up to limit audit entries recorded at or after since, oldest first
+func (ncs *NinjaStore) GetAuditEntries(ctx context.Context, since time.Time, limit int) ([]*NinjaAuditEntry, error) {
+	subjects, err := ncs.typeSubjects(ctx, "NinjaAuditEntry")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	type timedEntry struct {
+		time  time.Time
+		entry *NinjaAuditEntry
+	}
+
+	var entries []timedEntry
+
+	for _, subject := range subjects {
+		var entry NinjaAuditEntry
+		if err := ncs.loadTo(ctx, &entry, subject); err != nil {
+			continue // Skip entries we can't load
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, entry.Time)
+		if err != nil || t.Before(since) {
+			continue
+		}
+
+		entries = append(entries, timedEntry{time: t, entry: &entry})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].time.Before(entries[j].time) })
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	result := make([]*NinjaAuditEntry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.entry)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
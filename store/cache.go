@@ -0,0 +1,185 @@
+package store
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// defaultCacheSize is the number of rules, builds and targets kept in memory by default
+const defaultCacheSize = 4096
+
+// nodeCache keeps the most recently loaded rules, builds and targets, least recently used ones are
+// evicted first. Writes drop the nodes they touch, and a generation bumped by every write keeps loads
+// that raced with a write from caching what they read before it.
+type nodeCache struct {
+	mu         sync.Mutex
+	size       int
+	entries    map[quad.IRI]*list.Element
+	order      *list.List
+	generation uint64
+}
+
+type cacheEntry struct {
+	id    quad.IRI
+	value interface{}
+}
+
+func newNodeCache(size int) *nodeCache {
+	return &nodeCache{
+		size:    size,
+		entries: make(map[quad.IRI]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns the cached copy of id, and the generation to put a loaded value with on a miss
+func (c *nodeCache) get(id quad.IRI) (interface{}, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[id]
+	if !ok {
+		return nil, c.generation, false
+	}
+	c.order.MoveToFront(elem)
+
+	return elem.Value.(*cacheEntry).value, c.generation, true
+}
+
+// put caches value for id unless a write happened since generation
+func (c *nodeCache) put(id quad.IRI, value interface{}, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size <= 0 || generation != c.generation {
+		return
+	}
+
+	if elem, ok := c.entries[id]; ok {
+		elem.Value.(*cacheEntry).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[id] = c.order.PushFront(&cacheEntry{id: id, value: value})
+	c.evict()
+}
+
+// invalidate drops the nodes whose quads tx writes
+func (c *nodeCache) invalidate(tx *graph.Transaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	for _, delta := range tx.Deltas {
+		if id, ok := delta.Quad.Subject.(quad.IRI); ok {
+			c.remove(id)
+		}
+	}
+}
+
+// purge drops every node
+func (c *nodeCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = make(map[quad.IRI]*list.Element)
+	c.order.Init()
+}
+
+func (c *nodeCache) resize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.size = size
+	c.evict()
+}
+
+func (c *nodeCache) evict() {
+	for c.order.Len() > c.size && c.order.Len() > 0 {
+		c.remove(c.order.Back().Value.(*cacheEntry).id)
+	}
+}
+
+func (c *nodeCache) remove(id quad.IRI) {
+	if elem, ok := c.entries[id]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, id)
+	}
+}
+
+// SetCacheSize sets the number of rules, builds and targets kept in memory, zero disables the cache
+func (ncs *NinjaStore) SetCacheSize(size int) {
+	ncs.cache.resize(size)
+}
+
+// loadTo loads the node id into dst with schema.LoadTo, it fails once ctx is done so loops loading many
+// nodes stop when the request gives up
+func (ncs *NinjaStore) loadTo(ctx context.Context, dst interface{}, id quad.Value) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return ncs.schema.LoadTo(ctx, ncs.store, dst, id)
+}
+
+// loadNode loads the node id into dst like schema.LoadTo, rules, builds and targets are served from the
+// cache when they were loaded before and not written since
+func (ncs *NinjaStore) loadNode(ctx context.Context, dst interface{}, id quad.Value) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	iri, ok := id.(quad.IRI)
+	if !ok {
+		return ncs.loadTo(ctx, dst, id)
+	}
+
+	cached, generation, hit := ncs.cache.get(iri)
+
+	switch dst := dst.(type) {
+	case *NinjaRule:
+		if hit {
+			if rule, ok := cached.(NinjaRule); ok {
+				*dst = rule
+				return nil
+			}
+		}
+		if err := ncs.loadTo(ctx, dst, iri); err != nil {
+			return err
+		}
+		ncs.cache.put(iri, *dst, generation)
+	case *NinjaBuild:
+		if hit {
+			if build, ok := cached.(NinjaBuild); ok {
+				*dst = build
+				return nil
+			}
+		}
+		if err := ncs.loadTo(ctx, dst, iri); err != nil {
+			return err
+		}
+		ncs.cache.put(iri, *dst, generation)
+	case *NinjaTarget:
+		if hit {
+			if target, ok := cached.(NinjaTarget); ok {
+				*dst = target
+				return nil
+			}
+		}
+		if err := ncs.loadTo(ctx, dst, iri); err != nil {
+			return err
+		}
+		ncs.cache.put(iri, *dst, generation)
+	default:
+		return ncs.loadTo(ctx, dst, iri)
+	}
+
+	return nil
+}
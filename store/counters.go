@@ -0,0 +1,297 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// countedTypes maps the rdf:type objects counted by GetBuildStats to their stats keys
+var countedTypes = map[string]string{
+	`<NinjaRule>`:   "rules",
+	`<NinjaBuild>`:  "builds",
+	`<NinjaTarget>`: "targets",
+	`<NinjaFile>`:   "files",
+}
+
+// relationshipPredicates are the edges counted as relationships by GetBuildStats
+var relationshipPredicates = map[quad.Value]bool{
+	quad.String(PredicateHasInput):       true,
+	quad.String(PredicateHasOutput):      true,
+	quad.String(PredicateHasImplicitDep): true,
+	quad.String(PredicateHasOrderDep):    true,
+	quad.String(PredicateDependsOn):      true,
+}
+
+// graphCounters holds the counts reported by GetBuildStats. They are counted from the quads on first
+// use and kept up to date by countingWriter afterwards, so stats don't scan the store.
+type graphCounters struct {
+	mu      sync.Mutex
+	counted bool
+	counts  map[string]int64
+}
+
+// countingWriter passes the writes of the store to the cayley writer and updates the counters with the
+// counted quads each write actually adds or removes. Writes are serialized so the quads they find
+// present are still present when they are applied.
+type countingWriter struct {
+	graph.QuadWriter
+	ncs *NinjaStore
+	mu  sync.Mutex
+}
+
+// counterKey returns the stats key q is counted under, or "" if it isn't counted
+func counterKey(q quad.Quad) string {
+	if q.Predicate == nil || q.Object == nil {
+		return ""
+	}
+
+	if q.Predicate.String() == `<rdf:type>` {
+		return countedTypes[q.Object.String()]
+	}
+
+	if relationshipPredicates[q.Predicate] {
+		return "relationships"
+	}
+
+	return ""
+}
+
+func (w *countingWriter) AddQuad(q quad.Quad) error {
+	return w.AddQuadSet([]quad.Quad{q})
+}
+
+func (w *countingWriter) AddQuadSet(quads []quad.Quad) error {
+	tx := graph.NewTransactionN(len(quads))
+	for _, q := range quads {
+		tx.AddQuad(q)
+	}
+
+	return w.ApplyTransaction(tx)
+}
+
+func (w *countingWriter) RemoveQuad(q quad.Quad) error {
+	tx := graph.NewTransaction()
+	tx.RemoveQuad(q)
+
+	return w.ApplyTransaction(tx)
+}
+
+// RemoveNode isn't used by the store, the counters are recounted and the cache and path index emptied
+// after it
+func (w *countingWriter) RemoveNode(v quad.Value) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	defer w.ncs.revision.count.Add(1)
+	defer w.ncs.invalidateCounters()
+	defer w.ncs.cache.purge()
+	defer w.ncs.paths.reset()
+
+	return w.QuadWriter.RemoveNode(v)
+}
+
+func (w *countingWriter) ApplyTransaction(tx *graph.Transaction) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.applyLocked(tx)
+}
+
+// applyLocked applies tx for writers that hold w.mu, e.g. to apply what they read without other writes
+// in between
+func (w *countingWriter) applyLocked(tx *graph.Transaction) (err error) {
+	// Loads that started before the write must not cache what they read, nor versions taken before it
+	// match what follows it
+	defer w.ncs.revision.count.Add(1)
+	defer w.ncs.cache.invalidate(tx)
+	defer func() { w.ncs.paths.update(tx, err != nil) }()
+
+	end, err := w.ncs.logWrite(tx)
+	if err != nil {
+		return err
+	}
+	defer func() { end(err) }()
+
+	counters := &w.ncs.counters
+	counters.mu.Lock()
+	counted := counters.counted
+	counters.mu.Unlock()
+
+	if !counted {
+		return w.QuadWriter.ApplyTransaction(tx)
+	}
+
+	// The last delta of a quad decides whether it is present afterwards
+	present := make(map[quad.Quad]bool)
+	var order []quad.Quad
+
+	for _, delta := range tx.Deltas {
+		if counterKey(delta.Quad) == "" {
+			continue
+		}
+		if _, ok := present[delta.Quad]; !ok {
+			order = append(order, delta.Quad)
+		}
+		present[delta.Quad] = delta.Action == graph.Add
+	}
+
+	bySubject := make(map[quad.Value][]quad.Quad)
+	for _, q := range order {
+		bySubject[q.Subject] = append(bySubject[q.Subject], q)
+	}
+
+	diff := make(map[string]int64)
+	for subject, quads := range bySubject {
+		// Writes have no request context, a started write isn't given up
+		existing, err := w.ncs.existingQuads(context.Background(), subject, quads)
+		if err != nil {
+			return err
+		}
+
+		for _, q := range quads {
+			switch {
+			case present[q] && !existing[q]:
+				diff[counterKey(q)]++
+			case !present[q] && existing[q]:
+				diff[counterKey(q)]--
+			}
+		}
+	}
+
+	if err := w.QuadWriter.ApplyTransaction(tx); err != nil {
+		return err
+	}
+
+	counters.mu.Lock()
+	defer counters.mu.Unlock()
+
+	if counters.counted {
+		for key, n := range diff {
+			counters.counts[key] += n
+		}
+	}
+
+	return nil
+}
+
+// existingQuads returns which of quads, all of subject, are in the store. The subjects of counted quads
+// are rules, builds, targets and files, which have few quads, so they are read in one pass and compared
+// by the refs of their values without loading them.
+func (ncs *NinjaStore) existingQuads(ctx context.Context, subject quad.Value, quads []quad.Quad) (map[quad.Quad]bool, error) {
+	existing := make(map[quad.Quad]bool, len(quads))
+
+	ref := ncs.store.ValueOf(subject)
+	if ref == nil {
+		return existing, nil
+	}
+
+	type edge struct{ predicate, object interface{} }
+	wanted := make(map[edge]quad.Quad, len(quads))
+
+	for _, q := range quads {
+		predicate, object := ncs.store.ValueOf(q.Predicate), ncs.store.ValueOf(q.Object)
+		if predicate != nil && object != nil && q.Label == nil {
+			wanted[edge{graph.ToKey(predicate), graph.ToKey(object)}] = q
+		}
+	}
+
+	if len(wanted) == 0 {
+		return existing, nil
+	}
+
+	it := ncs.quadIterator(quad.Subject, ref)
+	defer func(it graph.Iterator) {
+		_ = it.Close()
+	}(it)
+
+	for it.Next(ctx) {
+		result := it.Result()
+		if result == nil || ncs.store.QuadDirection(result, quad.Label) != nil {
+			continue
+		}
+
+		key := edge{
+			graph.ToKey(ncs.store.QuadDirection(result, quad.Predicate)),
+			graph.ToKey(ncs.store.QuadDirection(result, quad.Object)),
+		}
+		if q, ok := wanted[key]; ok {
+			existing[q] = true
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate quads: %w", err)
+	}
+
+	return existing, nil
+}
+
+func (ncs *NinjaStore) invalidateCounters() {
+	ncs.counters.mu.Lock()
+	defer ncs.counters.mu.Unlock()
+
+	ncs.counters.counted = false
+}
+
+// graphCounts returns the counters, counting the quads first if they haven't been counted
+func (ncs *NinjaStore) graphCounts(ctx context.Context) (map[string]int64, error) {
+	ncs.counters.mu.Lock()
+	if ncs.counters.counted {
+		counts := make(map[string]int64, len(ncs.counters.counts))
+		for key, n := range ncs.counters.counts {
+			counts[key] = n
+		}
+		ncs.counters.mu.Unlock()
+		return counts, nil
+	}
+	ncs.counters.mu.Unlock()
+
+	return ncs.Recount(ctx)
+}
+
+// Recount counts the rules, builds, targets, files and relationships of the graph from its quads and
+// resets the counters kept by writes to the result. It repairs counters that drifted, e.g. after the
+// store was written by an older version.
+func (ncs *NinjaStore) Recount(ctx context.Context) (map[string]int64, error) {
+	// Writes wait until the count is in place, so none is missed
+	writer := ncs.store.QuadWriter.(*countingWriter)
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	it := ncs.allQuadsIterator()
+	defer func(it graph.Iterator) {
+		_ = it.Close()
+	}(it)
+
+	counts := map[string]int64{"rules": 0, "builds": 0, "targets": 0, "files": 0, "relationships": 0}
+
+	for it.Next(ctx) {
+		result := it.Result()
+		if result == nil {
+			continue
+		}
+
+		if key := counterKey(ncs.store.Quad(result)); key != "" {
+			counts[key]++
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate quads: %w", err)
+	}
+
+	ncs.counters.mu.Lock()
+	defer ncs.counters.mu.Unlock()
+
+	ncs.counters.counts = make(map[string]int64, len(counts))
+	for key, n := range counts {
+		ncs.counters.counts[key] = n
+	}
+	ncs.counters.counted = true
+
+	return counts, nil
+}
@@ -0,0 +1,305 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// SnapshotKeyEnv is the environment variable EnvKeyProvider reads by default
+	SnapshotKeyEnv = "DISTNINJA_SNAPSHOT_KEY"
+
+	// encryptionMagic starts every encrypted file, followed by the random nonce prefix of the file
+	encryptionMagic = "DNENC1\x00\x00"
+	// encryptionChunkSize is the plaintext sealed per chunk, chunks are read and written one at a time
+	encryptionChunkSize = 64 << 10
+	// encryptionPrefixSize is the random part of the nonces, the rest counts chunks and flags the last
+	encryptionPrefixSize = 7
+
+	keyCommandTimeout = 30 * time.Second
+)
+
+// errEncrypted is returned for encrypted files read without a key
+var errEncrypted = errors.New("snapshot is encrypted, a key is required")
+
+// KeyProvider returns the AES key encrypting snapshots, 16, 24 or 32 bytes. Providers are called once
+// on startup, a KMS is supported through CommandKeyProvider running its client.
+type KeyProvider interface {
+	Key() ([]byte, error)
+}
+
+// EnvKeyProvider reads a base64 encoded key from an environment variable, SnapshotKeyEnv when Variable
+// is empty
+type EnvKeyProvider struct {
+	Variable string
+}
+
+// Key returns the decoded key, nil when the variable isn't set
+func (p EnvKeyProvider) Key() ([]byte, error) {
+	name := p.Variable
+	if name == "" {
+		name = SnapshotKeyEnv
+	}
+
+	value := os.Getenv(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	key, err := decodeKey(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key in %s: %w", name, err)
+	}
+
+	return key, nil
+}
+
+// CommandKeyProvider runs a command printing a base64 encoded key, e.g. a KMS client decrypting a data
+// key or a secret manager reading one
+type CommandKeyProvider struct {
+	Command string
+	Args    []string
+}
+
+// Key runs the command and returns the key it printed
+func (p CommandKeyProvider) Key() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), keyCommandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+
+	command := exec.CommandContext(ctx, p.Command, p.Args...)
+	command.Stderr = &stderr
+
+	output, err := command.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("key command %s failed: %w: %s", p.Command, err, message)
+		}
+		return nil, fmt.Errorf("key command %s failed: %w", p.Command, err)
+	}
+
+	key, err := decodeKey(string(output))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key printed by %s: %w", p.Command, err)
+	}
+
+	return key, nil
+}
+
+func decodeKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("expected base64: %w", err)
+	}
+
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("expected a 16, 24 or 32 byte key, got %d bytes", len(key))
+	}
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of a chunk: the prefix of the file, the chunk number and whether it is
+// the last chunk, so chunks can't be reordered, dropped or the file cut after a chunk unnoticed
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, encryptionPrefixSize+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+
+	if last {
+		return append(nonce, 1)
+	}
+
+	return append(nonce, 0)
+}
+
+// encryptWriter seals what is written to it with AES-GCM in chunks, Close seals the last chunk
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	counter uint32
+	buf     []byte
+}
+
+func newEncryptWriter(w io.Writer, key []byte) (*encryptWriter, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, len(encryptionMagic)+encryptionPrefixSize)
+	copy(header, encryptionMagic)
+	if _, err := rand.Read(header[len(encryptionMagic):]); err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{w: w, aead: aead, header: header, buf: make([]byte, 0, encryptionChunkSize)}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	written := 0
+
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data follows, the last one is sealed by Close
+		if len(e.buf) == encryptionChunkSize {
+			if err := e.seal(false); err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(e.buf[len(e.buf):encryptionChunkSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+// Close seals the last chunk, it doesn't close the underlying writer
+func (e *encryptWriter) Close() error {
+	return e.seal(true)
+}
+
+func (e *encryptWriter) seal(last bool) error {
+	if e.counter == ^uint32(0) {
+		return errors.New("encrypted file too large")
+	}
+
+	nonce := chunkNonce(e.header[len(encryptionMagic):], e.counter, last)
+	sealed := e.aead.Seal(nil, nonce, e.buf, e.header)
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(sealed)))
+
+	if _, err := e.w.Write(size[:]); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+
+	e.counter++
+	e.buf = e.buf[:0]
+
+	return nil
+}
+
+// decryptReader opens the chunks written by encryptWriter, the magic must have been read already
+type decryptReader struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	header  []byte
+	counter uint32
+	plain   []byte
+	done    bool
+}
+
+func newDecryptReader(r io.Reader, key []byte) (*decryptReader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, len(encryptionMagic)+encryptionPrefixSize)
+	copy(header, encryptionMagic)
+	if _, err := io.ReadFull(r, header[len(encryptionMagic):]); err != nil {
+		return nil, fmt.Errorf("failed to read encryption header: %w", err)
+	}
+
+	return &decryptReader{r: r, aead: aead, header: header}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+
+	return n, nil
+}
+
+func (d *decryptReader) open() error {
+	var size [4]byte
+	if _, err := io.ReadFull(d.r, size[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("encrypted file is truncated: %w", io.ErrUnexpectedEOF)
+		}
+		return err
+	}
+
+	length := binary.BigEndian.Uint32(size[:])
+	if length > encryptionChunkSize+uint32(d.aead.Overhead()) {
+		return fmt.Errorf("encrypted chunk of %d bytes is too large", length)
+	}
+
+	sealed := make([]byte, length)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return fmt.Errorf("encrypted file is truncated: %w", io.ErrUnexpectedEOF)
+	}
+
+	prefix := d.header[len(encryptionMagic):]
+
+	plain, err := d.aead.Open(nil, chunkNonce(prefix, d.counter, false), sealed, d.header)
+	if err != nil {
+		if plain, err = d.aead.Open(nil, chunkNonce(prefix, d.counter, true), sealed, d.header); err != nil {
+			return errors.New("failed to decrypt, the key is wrong or the file is corrupted")
+		}
+
+		var extra [1]byte
+		if n, _ := d.r.Read(extra[:]); n > 0 {
+			return errors.New("encrypted file has data after its last chunk")
+		}
+		d.done = true
+	}
+
+	d.counter++
+	d.plain = plain
+
+	return nil
+}
+
+// openEncrypted returns the plaintext of the encrypted file read by r
+func openEncrypted(r io.Reader, key []byte) (io.Reader, error) {
+	magic := make([]byte, len(encryptionMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != encryptionMagic {
+		return nil, errors.New("file is not encrypted")
+	}
+
+	return newDecryptReader(r, key)
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// GCStats counts what CollectGarbage removed
+type GCStats struct {
+	// SupersededQuads are the status and last_modified quads of targets that have newer ones
+	SupersededQuads int `json:"superseded_quads"`
+	// UnreferencedFiles are the files no build or target uses any more
+	UnreferencedFiles int `json:"unreferenced_files"`
+	// RemovedQuads counts all quads removed, including those of the unreferenced files
+	RemovedQuads int `json:"removed_quads"`
+}
+
+// fileLinks are the edges pointing to the files a build or target uses
+var fileLinks = map[quad.Value]bool{
+	quad.String(PredicateHasInput):       true,
+	quad.String(PredicateHasImplicitDep): true,
+	quad.String(PredicateHasOrderDep):    true,
+	quad.String(PredicateDependsOn):      true,
+}
+
+// CollectGarbage removes what loads and status updates left behind: the status and last_modified quads
+// of targets that have more than one, keeping the status of their latest status change and the latest
+// time, and the files no build or target uses, e.g. after builds were deleted. Writes wait until the
+// quads read are removed in a single transaction. The bolt file keeps its size, freed pages are reused.
+func (ncs *NinjaStore) CollectGarbage(ctx context.Context) (*GCStats, error) {
+	writer := ncs.store.QuadWriter.(*countingWriter)
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	statuses := make(map[quad.IRI][]quad.Quad)
+	modified := make(map[quad.IRI][]quad.Quad)
+	files := make(map[quad.IRI][]quad.Quad)
+	referenced := make(map[quad.IRI]bool)
+
+	it := ncs.allQuadsIterator()
+	defer func(it graph.Iterator) {
+		_ = it.Close()
+	}(it)
+
+	for it.Next(ctx) {
+		result := it.Result()
+		if result == nil {
+			continue
+		}
+
+		q := ncs.store.Quad(result)
+		subject, ok := q.Subject.(quad.IRI)
+		if !ok || q.Label != nil {
+			continue
+		}
+
+		switch {
+		case fileLinks[q.Predicate]:
+			if object, ok := q.Object.(quad.IRI); ok {
+				referenced[object] = true
+			}
+		case strings.HasPrefix(string(subject), "file:"):
+			files[subject] = append(files[subject], q)
+		case strings.HasPrefix(string(subject), "target:") && q.Predicate == quad.IRI("status"):
+			statuses[subject] = append(statuses[subject], q)
+		case strings.HasPrefix(string(subject), "target:") && q.Predicate == quad.IRI("last_modified"):
+			modified[subject] = append(modified[subject], q)
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate quads: %w", err)
+	}
+
+	stats := &GCStats{}
+	tx := graph.NewTransaction()
+
+	remove := func(quads []quad.Quad, keep int) {
+		for i, q := range quads {
+			if i != keep {
+				tx.RemoveQuad(q)
+				stats.SupersededQuads++
+			}
+		}
+	}
+
+	for subject, quads := range statuses {
+		if len(quads) > 1 {
+			remove(quads, ncs.latestStatus(ctx, strings.TrimPrefix(string(subject), "target:"), quads))
+		}
+	}
+
+	for _, quads := range modified {
+		if len(quads) > 1 {
+			remove(quads, latestTime(quads))
+		}
+	}
+
+	for subject, quads := range files {
+		if referenced[subject] {
+			continue
+		}
+		for _, q := range quads {
+			tx.RemoveQuad(q)
+		}
+		stats.UnreferencedFiles++
+		stats.RemovedQuads += len(quads)
+	}
+
+	stats.RemovedQuads += stats.SupersededQuads
+
+	if len(tx.Deltas) == 0 {
+		return stats, nil
+	}
+
+	if err := writer.applyLocked(tx); err != nil {
+		return nil, fmt.Errorf("failed to collect garbage: %w", err)
+	}
+
+	return stats, nil
+}
+
+// latestStatus returns the index of the status quad set by the latest status change of the target at
+// path, the first status in order when its history doesn't tell
+func (ncs *NinjaStore) latestStatus(ctx context.Context, path string, quads []quad.Quad) int {
+	sort.Slice(quads, func(i, j int) bool { return quad.ToString(quads[i].Object) < quad.ToString(quads[j].Object) })
+
+	history, err := ncs.targetHistory(ctx, path)
+	if err != nil || len(history) == 0 {
+		return 0
+	}
+
+	for i, q := range quads {
+		if quad.ToString(q.Object) == history[0].change.Status {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// latestTime returns the index of the quad with the latest time
+func latestTime(quads []quad.Quad) int {
+	latest := 0
+	var newest time.Time
+
+	for i, q := range quads {
+		t, ok := q.Object.Native().(time.Time)
+		if ok && t.After(newest) {
+			latest, newest = i, t
+		}
+	}
+
+	return latest
+}
@@ -0,0 +1,502 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cayleygraph/quad"
+)
+
+// Graph node kinds
+const (
+	NodeKindTarget = "target"
+	NodeKindFile   = "file"
+)
+
+// Graph edge kinds, named after the build statement section the dependency comes from
+const (
+	EdgeKindInput    = "input"
+	EdgeKindImplicit = "implicit"
+	EdgeKindOrder    = "order"
+	// EdgeKindOutput links a target to another output of its build
+	EdgeKindOutput = "output"
+)
+
+// Traversal directions of TraverseDependencies
+const (
+	// DirectionDependencies follows the edges from targets to the files they are built from
+	DirectionDependencies = "dependencies"
+	// DirectionDependents follows the edges from files to the targets built from them
+	DirectionDependents = "dependents"
+)
+
+// GraphNode is a target or source file in an exported dependency graph
+type GraphNode struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"`
+	Status   string `json:"status,omitempty"`
+	Rule     string `json:"rule,omitempty"`
+	FileType string `json:"file_type,omitempty"`
+}
+
+// GraphEdge points from a target to one of its dependencies
+type GraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Kind   string `json:"kind"`
+}
+
+// DependencyGraph is a visualization friendly view of the build graph
+type DependencyGraph struct {
+	Nodes []*GraphNode `json:"nodes"`
+	Edges []*GraphEdge `json:"edges"`
+}
+
+// TraversalOptions selects the closure returned by TraverseDependencies
+type TraversalOptions struct {
+	// Direction is DirectionDependencies, the default, or DirectionDependents
+	Direction string
+	// Depth is the number of levels followed, zero follows all of them
+	Depth int
+	// OrderOnly also follows order-only dependencies
+	OrderOnly bool
+	// ImplicitOutputs adds the other outputs of the builds of the targets reached, such as the implicit
+	// outputs declared after | in a build statement
+	ImplicitOutputs bool
+}
+
+// DependencyNode is a target or file reached by TraverseDependencies
+type DependencyNode struct {
+	GraphNode
+	// Depth is the number of edges followed to reach the node
+	Depth int `json:"depth"`
+	// Edge is the kind of the edge the node was reached by, From the node at its other end
+	Edge string `json:"edge"`
+	From string `json:"from"`
+}
+
+// buildLinks holds the outputs and dependencies of a build statement
+type buildLinks struct {
+	outputs []string
+	deps    []*GraphEdge
+}
+
+var edgeKinds = map[string]string{
+	PredicateHasInput:       EdgeKindInput,
+	PredicateHasImplicitDep: EdgeKindImplicit,
+	PredicateHasOrderDep:    EdgeKindOrder,
+}
+
+// GetDependencyGraph returns the dependency graph, restricted to the subgraph reachable from root
+// within depth levels when root is set, depth <= 0 means unlimited
+func (ncs *NinjaStore) GetDependencyGraph(ctx context.Context, root string, depth int) (*DependencyGraph, error) {
+	builder := &graphBuilder{
+		ctx:   ctx,
+		ncs:   ncs,
+		nodes: make(map[string]*GraphNode),
+		edges: make(map[GraphEdge]bool),
+	}
+
+	if root == "" {
+		builds, err := ncs.typeSubjects(ctx, "NinjaBuild")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list builds: %w", err)
+		}
+
+		for _, build := range builds {
+			links, err := ncs.buildLinks(ctx, build)
+			if err != nil {
+				return nil, err
+			}
+			for _, output := range links.outputs {
+				builder.addTarget(output, links)
+			}
+		}
+
+		return builder.graph(), nil
+	}
+
+	if _, err := ncs.GetTarget(ctx, root); err != nil {
+		return nil, fmt.Errorf("target %s: %w", root, ErrNotFound)
+	}
+
+	type item struct {
+		path  string
+		level int
+	}
+
+	visited := map[string]bool{root: true}
+	queue := []item{{path: root}}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		target, err := ncs.GetTarget(ctx, current.path)
+		if err != nil {
+			continue // Source files have no dependencies
+		}
+
+		links, err := ncs.buildLinks(ctx, target.Build)
+		if err != nil {
+			return nil, err
+		}
+
+		// Only the edges of the visited output, sibling outputs may be outside the subgraph
+		builder.addTarget(current.path, links)
+
+		if depth > 0 && current.level+1 >= depth {
+			continue
+		}
+
+		for _, dep := range links.deps {
+			if !visited[dep.Target] {
+				visited[dep.Target] = true
+				queue = append(queue, item{path: dep.Target, level: current.level + 1})
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return builder.graph(), nil
+}
+
+// GetTransitiveDependencies returns the files target depends on directly or through the builds of
+// its dependencies, including order-only ones, up to depth levels, depth <= 0 means unlimited
+func (ncs *NinjaStore) GetTransitiveDependencies(ctx context.Context, target string, depth int) ([]*NinjaFile, error) {
+	graph, err := ncs.GetDependencyGraph(ctx, target, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*NinjaFile, 0, len(graph.Nodes))
+
+	for _, node := range graph.Nodes {
+		if node.ID == target {
+			continue
+		}
+
+		file, err := ncs.GetFile(ctx, node.ID)
+		if err != nil {
+			// Order-only dependencies are not stored as files
+			file = &NinjaFile{
+				ID:       quad.IRI(fmt.Sprintf("file:%s", node.ID)),
+				Type:     "NinjaFile",
+				Path:     node.ID,
+				FileType: node.FileType,
+			}
+		}
+		files = append(files, file)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// GetTransitiveReverseDependencies returns the targets depending on file directly or through other
+// targets, up to depth levels, depth <= 0 means unlimited
+func (ncs *NinjaStore) GetTransitiveReverseDependencies(ctx context.Context, file string, depth int) ([]*NinjaTarget, error) {
+	visited := map[string]bool{file: true}
+	current := []string{file}
+
+	var dependents []*NinjaTarget
+
+	for level := 0; len(current) > 0 && (depth <= 0 || level < depth); level++ {
+		var next []string
+
+		for _, path := range current {
+			targets, err := ncs.GetReverseDependencies(ctx, path)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, target := range targets {
+				if visited[target.Path] {
+					continue
+				}
+				visited[target.Path] = true
+				dependents = append(dependents, target)
+				next = append(next, target.Path)
+			}
+		}
+
+		current = next
+	}
+
+	sort.Slice(dependents, func(i, j int) bool { return dependents[i].Path < dependents[j].Path })
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return dependents, nil
+}
+
+// TraverseDependencies returns the targets and files reached from path in one direction, each with the
+// level it was first reached at, ordered by level and path. Following dependencies path must be a
+// target, following dependents it may be a target or a file.
+func (ncs *NinjaStore) TraverseDependencies(ctx context.Context, path string, opts TraversalOptions) ([]*DependencyNode, error) {
+	var next func(context.Context, string) ([]*GraphEdge, error)
+
+	switch opts.Direction {
+	case "", DirectionDependencies:
+		if _, err := ncs.GetTarget(ctx, path); err != nil {
+			return nil, fmt.Errorf("target %s: %w", path, ErrNotFound)
+		}
+		next = ncs.dependencyEdges
+	case DirectionDependents:
+		_, targetErr := ncs.GetTarget(ctx, path)
+		_, fileErr := ncs.GetFile(ctx, path)
+		if targetErr != nil && fileErr != nil {
+			return nil, fmt.Errorf("target or file %s: %w", path, ErrNotFound)
+		}
+		next = ncs.dependentEdges
+	default:
+		return nil, fmt.Errorf("unknown direction %s, expected %s or %s", opts.Direction, DirectionDependencies, DirectionDependents)
+	}
+
+	builder := &graphBuilder{
+		ctx:   ctx,
+		ncs:   ncs,
+		nodes: make(map[string]*GraphNode),
+		edges: make(map[GraphEdge]bool),
+	}
+
+	depths := map[string]int{path: 0}
+	nodes := []*DependencyNode{}
+
+	reach := func(edge *GraphEdge, from string, depth int) bool {
+		if _, ok := depths[edge.Target]; ok {
+			return false
+		}
+		depths[edge.Target] = depth
+		nodes = append(nodes, &DependencyNode{GraphNode: *builder.node(edge.Target), Depth: depth, Edge: edge.Kind, From: from})
+		return true
+	}
+
+	current := []string{path}
+
+	for level := 1; len(current) > 0 && (opts.Depth <= 0 || level <= opts.Depth); level++ {
+		var following []string
+
+		for _, from := range current {
+			edges, err := next(ctx, from)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, edge := range edges {
+				if edge.Kind == EdgeKindOrder && !opts.OrderOnly {
+					continue
+				}
+				if reach(edge, from, level) {
+					following = append(following, edge.Target)
+				}
+			}
+		}
+
+		current = following
+	}
+
+	if opts.ImplicitOutputs {
+		// Other outputs share the dependencies of the target they are found by, they aren't followed
+		reached := []string{path}
+		for _, node := range nodes {
+			if node.Kind == NodeKindTarget {
+				reached = append(reached, node.ID)
+			}
+		}
+
+		for _, from := range reached {
+			target, err := ncs.GetTarget(ctx, from)
+			if err != nil {
+				continue // Source files have no build
+			}
+
+			links, err := ncs.buildLinks(ctx, target.Build)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, output := range links.outputs {
+				reach(&GraphEdge{Target: output, Kind: EdgeKindOutput}, from, depths[from])
+			}
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Depth != nodes[j].Depth {
+			return nodes[i].Depth < nodes[j].Depth
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// dependencyEdges returns the edges from the target at path to its dependencies, none for source files
+func (ncs *NinjaStore) dependencyEdges(ctx context.Context, path string) ([]*GraphEdge, error) {
+	target, err := ncs.GetTarget(ctx, path)
+	if err != nil {
+		return nil, nil
+	}
+
+	links, err := ncs.buildLinks(ctx, target.Build)
+	if err != nil {
+		return nil, err
+	}
+
+	return links.deps, nil
+}
+
+// dependentEdges returns the edges from the file at path to the outputs of the builds using it
+func (ncs *NinjaStore) dependentEdges(ctx context.Context, path string) ([]*GraphEdge, error) {
+	refs, err := ncs.objectQuads(ctx, quad.IRI(fmt.Sprintf("file:%s", path)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dependents of %s: %w", path, err)
+	}
+
+	var edges []*GraphEdge
+
+	for _, q := range refs {
+		predicate, ok := q.Predicate.(quad.String)
+		if !ok {
+			continue
+		}
+
+		kind, ok := edgeKinds[string(predicate)]
+		if !ok {
+			continue
+		}
+
+		links, err := ncs.buildLinks(ctx, q.Subject)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, output := range links.outputs {
+			edges = append(edges, &GraphEdge{Target: output, Kind: kind})
+		}
+	}
+
+	return edges, nil
+}
+
+// buildLinks returns the outputs and dependencies of a build
+func (ncs *NinjaStore) buildLinks(ctx context.Context, build quad.Value) (*buildLinks, error) {
+	quads, err := ncs.subjectQuads(ctx, build)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load build %s: %w", build, err)
+	}
+
+	links := &buildLinks{}
+
+	for _, q := range quads {
+		object, _ := q.Object.(quad.IRI)
+
+		switch {
+		case q.Predicate == quad.String(PredicateHasOutput):
+			links.outputs = append(links.outputs, strings.TrimPrefix(string(object), "target:"))
+		default:
+			predicate, ok := q.Predicate.(quad.String)
+			if !ok {
+				continue
+			}
+			if kind, ok := edgeKinds[string(predicate)]; ok {
+				links.deps = append(links.deps, &GraphEdge{Target: strings.TrimPrefix(string(object), "file:"), Kind: kind})
+			}
+		}
+	}
+
+	return links, nil
+}
+
+// targetRule returns the name of the rule building target, empty when its build can't be loaded
+func (ncs *NinjaStore) targetRule(ctx context.Context, target *NinjaTarget) string {
+	var build NinjaBuild
+
+	if err := ncs.loadNode(ctx, &build, target.Build); err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(string(build.Rule), "rule:")
+}
+
+// graphBuilder accumulates nodes and de-duplicated edges
+type graphBuilder struct {
+	ctx   context.Context
+	ncs   *NinjaStore
+	nodes map[string]*GraphNode
+	edges map[GraphEdge]bool
+}
+
+func (gb *graphBuilder) addTarget(path string, links *buildLinks) {
+	gb.node(path)
+
+	for _, dep := range links.deps {
+		gb.node(dep.Target)
+		gb.edges[GraphEdge{Source: path, Target: dep.Target, Kind: dep.Kind}] = true
+	}
+}
+
+// node returns the node for path, loading it as a target or else as a file on first use
+func (gb *graphBuilder) node(path string) *GraphNode {
+	if node, ok := gb.nodes[path]; ok {
+		return node
+	}
+
+	node := &GraphNode{ID: path, Kind: NodeKindFile}
+
+	if target, err := gb.ncs.GetTarget(gb.ctx, path); err == nil {
+		node.Kind = NodeKindTarget
+		node.Status = target.Status
+		node.Rule = gb.ncs.targetRule(gb.ctx, target)
+	} else if file, err := gb.ncs.GetFile(gb.ctx, path); err == nil {
+		node.FileType = file.FileType
+	} else {
+		node.FileType = gb.ncs.inferFileType(path)
+	}
+
+	gb.nodes[path] = node
+
+	return node
+}
+
+func (gb *graphBuilder) graph() *DependencyGraph {
+	graph := &DependencyGraph{
+		Nodes: make([]*GraphNode, 0, len(gb.nodes)),
+		Edges: make([]*GraphEdge, 0, len(gb.edges)),
+	}
+
+	for _, node := range gb.nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	for edge := range gb.edges {
+		edge := edge
+		graph.Edges = append(graph.Edges, &edge)
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Target < b.Target
+	})
+
+	return graph
+}
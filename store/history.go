@@ -0,0 +1,245 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// NinjaStatusChange is an entry of the append-only status history of a target. Entries are linked to
+// the target by path, so they outlive deleting or reloading the target.
+type NinjaStatusChange struct {
+	ID             quad.IRI `json:"-" quad:"@id"`
+	Type           quad.IRI `json:"-" quad:"@type"`
+	Target         string   `json:"target" quad:"target_path"`
+	Status         string   `json:"status" quad:"status"`
+	PreviousStatus string   `json:"previous_status,omitempty" quad:"previous_status,optional"`
+	Time           string   `json:"time" quad:"time"`
+	RunID          string   `json:"run_id,omitempty" quad:"run_id,optional"`
+	Worker         string   `json:"worker,omitempty" quad:"worker,optional"`
+	Message        string   `json:"message,omitempty" quad:"message,optional"`
+}
+
+// StatusOrigin tells the history where a status change came from, all fields are optional
+type StatusOrigin struct {
+	RunID   string
+	Worker  string
+	Message string
+}
+
+// HistoryRetention bounds the status history kept per target, older entries are dropped when a new
+// one is added
+type HistoryRetention struct {
+	// MaxEntries is the number of entries kept per target, zero keeps all
+	MaxEntries int
+	// MaxAge is how long entries are kept, zero keeps them regardless of age
+	MaxAge time.Duration
+}
+
+var historySeq atomic.Uint64
+
+// SetHistoryRetention sets the limits applied to the status history of each target
+func (ncs *NinjaStore) SetHistoryRetention(retention HistoryRetention) {
+	ncs.history = retention
+}
+
+// addStatusChange adds change to tx
+func (ncs *NinjaStore) addStatusChange(tx *graph.Transaction, change *NinjaStatusChange) error {
+	now := time.Now().UTC()
+
+	change.Time = now.Format(time.RFC3339Nano)
+	change.ID = quad.IRI(fmt.Sprintf("history:%s:%d-%d", change.Target, now.UnixNano(), historySeq.Add(1)))
+	change.Type = "NinjaStatusChange"
+
+	if _, err := ncs.schema.WriteAsQuads(graph.NewTxWriter(tx, graph.Add), change); err != nil {
+		return fmt.Errorf("failed to write status change: %w", err)
+	}
+
+	return nil
+}
+
+// pruneHistory removes the entries of the target at path falling outside the retention limits. It runs
+// in a transaction of its own, the bolt backend loses values shared by quads removed and added together.
+func (ncs *NinjaStore) pruneHistory(ctx context.Context, path string) error {
+	if ncs.history.MaxEntries <= 0 && ncs.history.MaxAge <= 0 {
+		return nil
+	}
+
+	entries, err := ncs.targetHistory(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	tx := graph.NewTransaction()
+
+	for i, entry := range entries {
+		expired := ncs.history.MaxAge > 0 && now.Sub(entry.time) > ncs.history.MaxAge
+		if !expired && (ncs.history.MaxEntries <= 0 || i < ncs.history.MaxEntries) {
+			continue
+		}
+
+		if err := ncs.removeNode(ctx, tx, entry.change.ID); err != nil {
+			return err
+		}
+	}
+
+	if len(tx.Deltas) == 0 {
+		return nil
+	}
+
+	if err := ncs.store.ApplyTransaction(tx); err != nil {
+		return fmt.Errorf("failed to prune status history of %s: %w", path, err)
+	}
+
+	return nil
+}
+
+type timedStatusChange struct {
+	time   time.Time
+	change *NinjaStatusChange
+}
+
+// targetHistory returns the status history of the target at path, newest first
+func (ncs *NinjaStore) targetHistory(ctx context.Context, path string) ([]timedStatusChange, error) {
+	refs, err := ncs.objectQuads(ctx, quad.String(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load status history of %s: %w", path, err)
+	}
+
+	var entries []timedStatusChange
+
+	for _, q := range refs {
+		if q.Predicate != quad.IRI("target_path") {
+			continue
+		}
+
+		var change NinjaStatusChange
+		if err := ncs.loadTo(ctx, &change, q.Subject); err != nil {
+			continue // Skip entries we can't load
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, change.Time)
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, timedStatusChange{time: t, change: &change})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].time.After(entries[j].time) })
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetTargetHistory returns up to limit status changes of the target at path, newest first, only those
+// to status when it is set. Limit <= 0 returns all of them.
+func (ncs *NinjaStore) GetTargetHistory(ctx context.Context, path, status string, limit int) ([]*NinjaStatusChange, error) {
+	entries, err := ncs.targetHistory(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*NinjaStatusChange, 0, len(entries))
+	for _, entry := range entries {
+		if status != "" && entry.change.Status != status {
+			continue
+		}
+		if limit > 0 && len(result) == limit {
+			break
+		}
+		result = append(result, entry.change)
+	}
+
+	return result, nil
+}
+
+// statusBuilding is the status executors report when a target starts building
+const statusBuilding = "building"
+
+// GetTargetDurations returns how long the last build of each target took, from its change to building
+// to the status that followed in the status history. Targets never seen building are left out.
+func (ncs *NinjaStore) GetTargetDurations(ctx context.Context) (map[string]time.Duration, error) {
+	subjects, err := ncs.typeSubjects(ctx, "NinjaStatusChange")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status history: %w", err)
+	}
+
+	byTarget := make(map[string][]timedStatusChange)
+
+	for _, subject := range subjects {
+		var change NinjaStatusChange
+		if err := ncs.loadTo(ctx, &change, subject); err != nil {
+			continue // Skip entries we can't load
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, change.Time)
+		if err != nil {
+			continue
+		}
+
+		byTarget[change.Target] = append(byTarget[change.Target], timedStatusChange{time: t, change: &change})
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	durations := make(map[string]time.Duration)
+
+	for path, entries := range byTarget {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].time.Before(entries[j].time) })
+
+		var started time.Time
+		for _, entry := range entries {
+			switch {
+			case entry.change.Status == statusBuilding:
+				started = entry.time
+			case !started.IsZero():
+				durations[path] = entry.time.Sub(started)
+				started = time.Time{}
+			}
+		}
+	}
+
+	return durations, nil
+}
+
+// GetBuildDurations returns how long the last run of each build took by build id, the longest of the
+// durations of its outputs
+func (ncs *NinjaStore) GetBuildDurations(ctx context.Context) (map[string]time.Duration, error) {
+	targets, err := ncs.GetTargetDurations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	durations := make(map[string]time.Duration, len(targets))
+
+	for path, duration := range targets {
+		target, err := ncs.GetTarget(ctx, path)
+		if err != nil {
+			continue // The target was removed since it was built
+		}
+
+		id := strings.TrimPrefix(string(target.Build), "build:")
+		if duration > durations[id] {
+			durations[id] = duration
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return durations, nil
+}
@@ -0,0 +1,293 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cayleygraph/quad"
+)
+
+// ImpactResult lists the targets that need rebuilding when files change
+type ImpactResult struct {
+	Files        []string       `json:"files"`
+	UnknownFiles []string       `json:"unknown_files"`
+	Targets      []string       `json:"targets"`
+	TargetCount  int            `json:"target_count"`
+	ByRule       map[string]int `json:"by_rule"`
+}
+
+// GetImpact returns the transitive set of targets depending on any of files, order-only
+// dependencies do not trigger rebuilds and are not followed
+func (ncs *NinjaStore) GetImpact(ctx context.Context, files []string) (*ImpactResult, error) {
+	result := &ImpactResult{
+		Files:        files,
+		UnknownFiles: []string{},
+		Targets:      []string{},
+		ByRule:       make(map[string]int),
+	}
+
+	affected := make(map[string]bool)
+	var queue []string
+
+	for _, file := range files {
+		if _, err := ncs.GetFile(ctx, file); err != nil {
+			if _, err := ncs.GetTarget(ctx, file); err != nil {
+				result.UnknownFiles = append(result.UnknownFiles, file)
+				continue
+			}
+		}
+		queue = append(queue, file)
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		refs, err := ncs.objectQuads(ctx, quad.IRI(fmt.Sprintf("file:%s", current)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dependents of %s: %w", current, err)
+		}
+
+		for _, q := range refs {
+			if q.Predicate != quad.String(PredicateDependsOn) {
+				continue
+			}
+
+			subject, ok := q.Subject.(quad.IRI)
+			if !ok {
+				continue
+			}
+
+			path := strings.TrimPrefix(string(subject), "target:")
+			if affected[path] {
+				continue
+			}
+
+			affected[path] = true
+			queue = append(queue, path)
+		}
+	}
+
+	for path := range affected {
+		result.Targets = append(result.Targets, path)
+
+		target, err := ncs.GetTarget(ctx, path)
+		if err != nil {
+			continue
+		}
+		result.ByRule[ncs.targetRule(ctx, target)]++
+	}
+
+	sort.Strings(result.Targets)
+	result.TargetCount = len(result.Targets)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ChangedResult is the impact of the files changed in a repository, matched to files of the graph
+type ChangedResult struct {
+	ChangedPaths   []string `json:"changed_paths"`
+	UnmatchedPaths []string `json:"unmatched_paths"`
+	*ImpactResult
+}
+
+// GetChangedImpact maps paths relative to the root of a repository to the files and targets of the graph
+// and returns the targets depending on them. Graph paths are relative to the build directory or
+// absolute, so a path matches graph paths equal to it or ending in / followed by it.
+func (ncs *NinjaStore) GetChangedImpact(ctx context.Context, paths []string) (*ChangedResult, error) {
+	var graphPaths []string
+
+	for _, typeName := range []string{"NinjaFile", "NinjaTarget"} {
+		subjects, err := ncs.typeSubjects(ctx, typeName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s nodes: %w", typeName, err)
+		}
+
+		for _, subject := range subjects {
+			if iri, ok := subject.(quad.IRI); ok {
+				_, path, _ := strings.Cut(string(iri), ":")
+				graphPaths = append(graphPaths, path)
+			}
+		}
+	}
+
+	result := &ChangedResult{ChangedPaths: paths, UnmatchedPaths: []string{}}
+
+	matched := make(map[string]bool)
+	for _, changed := range paths {
+		changed = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(changed)), "./")
+
+		found := false
+		for _, path := range graphPaths {
+			cleaned := filepath.ToSlash(filepath.Clean(path))
+			if cleaned == changed || strings.HasSuffix(cleaned, "/"+changed) {
+				matched[path] = true
+				found = true
+			}
+		}
+
+		if !found {
+			result.UnmatchedPaths = append(result.UnmatchedPaths, changed)
+		}
+	}
+
+	files := make([]string, 0, len(matched))
+	for path := range matched {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+
+	impact, err := ncs.GetImpact(ctx, files)
+	if err != nil {
+		return nil, err
+	}
+	result.ImpactResult = impact
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// maxDependencyChains bounds the shortest chains returned by GetDependencyChains, their number can grow
+// exponentially with the length of the chains
+const maxDependencyChains = 100
+
+// DependencyChain is a chain of dependencies from a target down to one of the files it depends on
+type DependencyChain struct {
+	// Nodes starts at the target and ends at the file, each node depends on the next one
+	Nodes []string `json:"nodes"`
+	// Edges holds the kind of the dependency of each node on the next one
+	Edges []string `json:"edges"`
+}
+
+// PathResult explains why a target depends on a file
+type PathResult struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Length is the number of dependencies in the shortest chains, -1 when from doesn't depend on to
+	Length int                `json:"length"`
+	Chains []*DependencyChain `json:"chains"`
+	// Truncated is set when there are more shortest chains than returned
+	Truncated bool `json:"truncated"`
+}
+
+// GetDependencyChains returns a shortest chain of dependencies from the target from to the file or
+// target to, or with all every shortest chain up to maxDependencyChains of them. Order-only
+// dependencies do not trigger rebuilds and are not followed.
+func (ncs *NinjaStore) GetDependencyChains(ctx context.Context, from, to string, all bool) (*PathResult, error) {
+	if _, err := ncs.GetTarget(ctx, from); err != nil {
+		return nil, fmt.Errorf("target %s: %w", from, ErrNotFound)
+	}
+
+	if _, err := ncs.GetFile(ctx, to); err != nil {
+		if _, err := ncs.GetTarget(ctx, to); err != nil {
+			return nil, fmt.Errorf("file %s: %w", to, ErrNotFound)
+		}
+	}
+
+	result := &PathResult{From: from, To: to, Length: -1, Chains: []*DependencyChain{}}
+
+	type parent struct {
+		path string
+		kind string
+	}
+
+	// parents holds the nodes each node is reached from at its distance from the target
+	distance := map[string]int{from: 0}
+	parents := make(map[string][]parent)
+	current := []string{from}
+
+	for level := 1; len(current) > 0; level++ {
+		if _, ok := distance[to]; ok {
+			break
+		}
+
+		var following []string
+
+		for _, path := range current {
+			edges, err := ncs.dependencyEdges(ctx, path)
+			if err != nil {
+				return nil, err
+			}
+
+			linked := make(map[string]bool)
+			for _, edge := range edges {
+				if edge.Kind == EdgeKindOrder || linked[edge.Target] {
+					continue
+				}
+				linked[edge.Target] = true
+
+				d, ok := distance[edge.Target]
+				if !ok {
+					distance[edge.Target] = level
+					following = append(following, edge.Target)
+				} else if d != level {
+					continue
+				}
+				parents[edge.Target] = append(parents[edge.Target], parent{path: path, kind: edge.Kind})
+			}
+		}
+
+		current = following
+	}
+
+	length, ok := distance[to]
+	if !ok {
+		return result, nil
+	}
+	result.Length = length
+
+	// Walk the parents back from the file, the chains are built in reverse
+	var walk func(path string, nodes, edges []string)
+	walk = func(path string, nodes, edges []string) {
+		if result.Truncated || !all && len(result.Chains) == 1 {
+			return
+		}
+
+		nodes = append(nodes, path)
+		if path == from {
+			if len(result.Chains) == maxDependencyChains {
+				result.Truncated = true
+				return
+			}
+			result.Chains = append(result.Chains, reverseChain(nodes, edges))
+			return
+		}
+
+		for _, p := range parents[path] {
+			walk(p.path, nodes, append(edges, p.kind))
+		}
+	}
+	walk(to, nil, nil)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func reverseChain(nodes, edges []string) *DependencyChain {
+	chain := &DependencyChain{
+		Nodes: make([]string, len(nodes)),
+		Edges: make([]string, len(edges)),
+	}
+
+	for i, node := range nodes {
+		chain.Nodes[len(nodes)-1-i] = node
+	}
+	for i, edge := range edges {
+		chain.Edges[len(edges)-1-i] = edge
+	}
+
+	return chain
+}
@@ -0,0 +1,276 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// Kinds of integrity problems
+const (
+	// ProblemIncomplete is a rule, build, target or file without its type or a required field
+	ProblemIncomplete = "incomplete"
+	// ProblemDanglingRule is a build whose rule is missing
+	ProblemDanglingRule = "dangling_rule"
+	// ProblemOrphanTarget is a target whose build is missing
+	ProblemOrphanTarget = "orphan_target"
+	// ProblemDanglingLink is an edge of a build or target to a node that doesn't exist
+	ProblemDanglingLink = "dangling_link"
+)
+
+// IntegrityProblem is an inconsistency of the graph found by CheckIntegrity
+type IntegrityProblem struct {
+	Kind   string `json:"kind"`
+	Node   string `json:"node"`
+	Detail string `json:"detail"`
+}
+
+// IntegrityReport lists the problems of the graph, with repair the nodes and edges removed to fix them
+type IntegrityReport struct {
+	// ReplayedWrites counts the build writes a crash interrupted, applied again from the journal when the
+	// store was opened
+	ReplayedWrites int                 `json:"replayed_writes"`
+	Problems       []*IntegrityProblem `json:"problems"`
+	Repaired       bool                `json:"repaired"`
+	RemovedNodes   int                 `json:"removed_nodes"`
+	RemovedLinks   int                 `json:"removed_links"`
+}
+
+// nodePrefixes maps the IRI prefix of each graph node type to the type
+var nodePrefixes = map[string]string{
+	"rule:":   "NinjaRule",
+	"build:":  "NinjaBuild",
+	"target:": "NinjaTarget",
+	"file:":   "NinjaFile",
+}
+
+// requiredFields lists the predicates each graph node type can't be loaded without
+var requiredFields = map[string][]string{
+	"NinjaRule":   schemaFields(NinjaRule{}),
+	"NinjaBuild":  schemaFields(NinjaBuild{}),
+	"NinjaTarget": schemaFields(NinjaTarget{}),
+	"NinjaFile":   schemaFields(NinjaFile{}),
+}
+
+// phonyRule is built into ninja, builds use it without a rule node
+const phonyRule = quad.IRI("rule:phony")
+
+// linkTypes maps the edges of builds and targets to the type of node they point to
+var linkTypes = map[quad.Value]string{
+	quad.String(PredicateHasInput):       "NinjaFile",
+	quad.String(PredicateHasOutput):      "NinjaTarget",
+	quad.String(PredicateHasImplicitDep): "NinjaFile",
+	quad.String(PredicateHasOrderDep):    "NinjaFile",
+	quad.String(PredicateDependsOn):      "NinjaFile",
+}
+
+// schemaFields returns the quad predicates of the fields of v that are not optional
+func schemaFields(v interface{}) []string {
+	var fields []string
+
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		name, options, _ := strings.Cut(t.Field(i).Tag.Get("quad"), ",")
+		if name == "" || strings.HasPrefix(name, "@") || options == "optional" {
+			continue
+		}
+		fields = append(fields, name)
+	}
+
+	return fields
+}
+
+// graphNode collects the quads of a rule, build, target or file seen by CheckIntegrity
+type graphNode struct {
+	typeName string
+	fields   map[string]quad.Value
+}
+
+// CheckIntegrity looks for what a crash or an older version may have left half written: nodes without
+// their type or required fields, builds whose rule is missing, targets whose build is missing and edges
+// to nodes that don't exist. With repair the broken nodes are removed with all their edges, together
+// with the targets of removed builds, and dangling edges are removed, in a single transaction.
+func (ncs *NinjaStore) CheckIntegrity(ctx context.Context, repair bool) (*IntegrityReport, error) {
+	nodes := make(map[quad.IRI]*graphNode)
+	var links []quad.Quad
+
+	it := ncs.allQuadsIterator()
+	defer func(it graph.Iterator) {
+		_ = it.Close()
+	}(it)
+
+	for it.Next(ctx) {
+		result := it.Result()
+		if result == nil {
+			continue
+		}
+
+		q := ncs.store.Quad(result)
+		subject, ok := q.Subject.(quad.IRI)
+		if !ok || nodeType(subject) == "" {
+			continue
+		}
+
+		node := nodes[subject]
+		if node == nil {
+			node = &graphNode{fields: make(map[string]quad.Value)}
+			nodes[subject] = node
+		}
+
+		switch predicate := q.Predicate.(type) {
+		case quad.IRI:
+			if predicate == `rdf:type` {
+				if object, ok := q.Object.(quad.IRI); ok {
+					node.typeName = string(object)
+				}
+				continue
+			}
+			node.fields[string(predicate)] = q.Object
+		case quad.String:
+			if _, ok := linkTypes[predicate]; ok {
+				links = append(links, q)
+			}
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate quads: %w", err)
+	}
+
+	report := &IntegrityReport{ReplayedWrites: ncs.replayed, Problems: []*IntegrityProblem{}}
+	broken := make(map[quad.IRI]bool)
+
+	problem := func(kind string, node quad.IRI, detail string) {
+		report.Problems = append(report.Problems, &IntegrityProblem{Kind: kind, Node: string(node), Detail: detail})
+	}
+
+	iris := sortedNodes(nodes)
+
+	for _, iri := range iris {
+		node := nodes[iri]
+
+		if want := nodeType(iri); node.typeName != want {
+			problem(ProblemIncomplete, iri, fmt.Sprintf("has no %s type", want))
+			broken[iri] = true
+			continue
+		}
+
+		var missing []string
+		for _, field := range requiredFields[node.typeName] {
+			if _, ok := node.fields[field]; !ok {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			problem(ProblemIncomplete, iri, "missing "+strings.Join(missing, ", "))
+			broken[iri] = true
+		}
+	}
+
+	// valid reports whether value is a complete node of typeName that is kept
+	valid := func(value quad.Value, typeName string) bool {
+		iri, ok := value.(quad.IRI)
+		if !ok || broken[iri] {
+			return false
+		}
+		node := nodes[iri]
+		return node != nil && node.typeName == typeName
+	}
+
+	for _, iri := range iris {
+		node := nodes[iri]
+		if broken[iri] || node.typeName != "NinjaBuild" {
+			continue
+		}
+		if rule := node.fields["rule"]; rule != phonyRule && !valid(rule, "NinjaRule") {
+			problem(ProblemDanglingRule, iri, fmt.Sprintf("rule %s %s", rule, missingOrBroken(rule, broken)))
+			broken[iri] = true
+		}
+	}
+
+	for _, iri := range iris {
+		node := nodes[iri]
+		if broken[iri] || node.typeName != "NinjaTarget" {
+			continue
+		}
+		if build := node.fields["build"]; !valid(build, "NinjaBuild") {
+			problem(ProblemOrphanTarget, iri, fmt.Sprintf("build %s %s", build, missingOrBroken(build, broken)))
+			broken[iri] = true
+		}
+	}
+
+	// Edges of broken nodes go with them, edges to nodes that were never written are removed alone
+	var dangling []quad.Quad
+	for _, q := range links {
+		subject := q.Subject.(quad.IRI)
+		if broken[subject] {
+			continue
+		}
+
+		object, ok := q.Object.(quad.IRI)
+		if ok && (broken[object] || nodes[object] != nil && nodes[object].typeName == linkTypes[q.Predicate]) {
+			continue
+		}
+
+		problem(ProblemDanglingLink, subject, fmt.Sprintf("%s %s is missing", q.Predicate.Native(), q.Object))
+		dangling = append(dangling, q)
+	}
+
+	if !repair || len(report.Problems) == 0 {
+		return report, nil
+	}
+
+	tx := graph.NewTransaction()
+	for iri := range broken {
+		if err := ncs.removeNode(ctx, tx, iri); err != nil {
+			return nil, err
+		}
+	}
+	for _, q := range dangling {
+		tx.RemoveQuad(q)
+	}
+
+	if err := ncs.store.ApplyTransaction(tx); err != nil {
+		return nil, fmt.Errorf("failed to repair graph: %w", err)
+	}
+
+	report.Repaired = true
+	report.RemovedNodes = len(broken)
+	report.RemovedLinks = len(dangling)
+
+	return report, nil
+}
+
+// nodeType returns the graph node type named by the prefix of iri, "" for other nodes
+func nodeType(iri quad.IRI) string {
+	prefix, _, ok := strings.Cut(string(iri), ":")
+	if !ok {
+		return ""
+	}
+
+	return nodePrefixes[prefix+":"]
+}
+
+// missingOrBroken describes a node that value points to and that is not kept
+func missingOrBroken(value quad.Value, broken map[quad.IRI]bool) string {
+	if iri, ok := value.(quad.IRI); ok && broken[iri] {
+		return "is broken"
+	}
+
+	return "is missing"
+}
+
+func sortedNodes(nodes map[quad.IRI]*graphNode) []quad.IRI {
+	iris := make([]quad.IRI, 0, len(nodes))
+	for iri := range nodes {
+		iris = append(iris, iri)
+	}
+	sort.Slice(iris, func(i, j int) bool { return iris[i] < iris[j] })
+
+	return iris
+}
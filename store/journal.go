@@ -0,0 +1,289 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/nquads"
+)
+
+// journalSuffix names the directory next to the store that holds the journal
+const journalSuffix = ".journal"
+
+// writeJournal keeps the quads of a multi-quad write on disk until the store has applied them. Entries
+// are synced and renamed into place before the write starts and removed after it ends, so an entry found
+// when the store is opened belongs to a write cut short by a crash and is applied again. Replaying only
+// adds quads, which the store ignores when they are present already.
+//
+// The journal of a store encrypted at rest logs every write instead, encrypted with key, and keeps the
+// entries until the next checkpoint, see atRest.
+type writeJournal struct {
+	dir string
+	key []byte
+	seq atomic.Uint64
+}
+
+func newWriteJournal(dbPath string, key []byte) (*writeJournal, error) {
+	dir := dbPath + journalSuffix
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory %s: %w", dir, err)
+	}
+
+	return &writeJournal{dir: dir, key: key}, nil
+}
+
+// record writes quads to a new entry and returns its path
+func (j *writeJournal) record(quads []quad.Quad) (string, error) {
+	return j.write(".nq", func(w io.Writer) error {
+		writer := nquads.NewWriter(w)
+		if _, err := writer.WriteQuads(quads); err != nil {
+			return err
+		}
+		return writer.Close()
+	})
+}
+
+// log writes the deltas of a write to a new encrypted entry and returns its path. The entry holds the
+// number of removed quads, the removed quads and the added ones, a write never adds and removes the
+// same quad.
+func (j *writeJournal) log(deltas []graph.Delta) (string, error) {
+	return j.write(".nq"+encryptedSuffix, func(w io.Writer) error {
+		encrypted, err := newEncryptWriter(w, j.key)
+		if err != nil {
+			return err
+		}
+
+		var removed, added []quad.Quad
+		for _, delta := range deltas {
+			if delta.Action == graph.Delete {
+				removed = append(removed, delta.Quad)
+			} else {
+				added = append(added, delta.Quad)
+			}
+		}
+
+		if _, err := fmt.Fprintf(encrypted, "%d\n", len(removed)); err != nil {
+			return err
+		}
+
+		writer := nquads.NewWriter(encrypted)
+		if _, err := writer.WriteQuads(append(removed, added...)); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+
+		return encrypted.Close()
+	})
+}
+
+// write creates a new entry ending in ext with the content written by fill and returns its path
+func (j *writeJournal) write(ext string, fill func(w io.Writer) error) (string, error) {
+	path := filepath.Join(j.dir, fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), j.seq.Add(1), ext))
+	tmpPath := path + ".tmp"
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create journal entry: %w", err)
+	}
+
+	err = fill(file)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to write journal entry: %w", err)
+	}
+
+	return path, nil
+}
+
+// complete removes the entry of a write that ended
+func (j *writeJournal) complete(path string) {
+	_ = os.Remove(path)
+}
+
+// entries returns the paths of the entries left in the journal, oldest first. Entries a crash left
+// unfinished belong to writes that never started and are dropped.
+func (j *writeJournal) entries() ([]string, error) {
+	unfinished, err := filepath.Glob(filepath.Join(j.dir, "*.tmp"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range unfinished {
+		_ = os.Remove(path)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(j.dir, "*.nq"))
+	if err != nil {
+		return nil, err
+	}
+
+	logged, err := filepath.Glob(filepath.Join(j.dir, "*.nq"+encryptedSuffix))
+	if err != nil {
+		return nil, err
+	}
+
+	paths = append(paths, logged...)
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// clear removes every entry, the writes they hold are in the store file
+func (j *writeJournal) clear() error {
+	paths, err := j.entries()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyJournaled adds quads to the store in a single transaction, recorded in the journal until it is
+// applied.
+func (ncs *NinjaStore) applyJournaled(ctx context.Context, quads []quad.Quad) error {
+	if len(quads) == 0 {
+		return nil
+	}
+
+	tx := graph.NewTransactionN(len(quads))
+	for _, q := range quads {
+		tx.AddQuad(q)
+	}
+
+	// The writer logs the writes of stores encrypted at rest
+	if ncs.atRest != nil {
+		return ncs.store.ApplyTransaction(tx)
+	}
+
+	entry, err := ncs.journal.record(quads)
+	if err != nil {
+		return err
+	}
+
+	// The entry is removed before other writers run, a removal applied after the write must not be
+	// undone by replaying it
+	writer := ncs.store.QuadWriter.(*countingWriter)
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+	defer ncs.journal.complete(entry)
+
+	return writer.applyLocked(tx)
+}
+
+// replayJournal applies the writes left in the journal by a crash, or logged since the last checkpoint
+// of a store encrypted at rest, and returns their number. They go to the quad store directly, they are
+// in the journal already. Quads added twice or removed when missing are ignored, a crash between a
+// checkpoint and clearing the journal leaves writes the checkpoint holds.
+func (ncs *NinjaStore) replayJournal(ctx context.Context) (int, error) {
+	entries, err := ncs.journal.entries()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list journal entries: %w", err)
+	}
+
+	for _, entry := range entries {
+		deltas, err := ncs.journal.read(entry)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read journal entry %s: %w", entry, err)
+		}
+
+		if err := ncs.store.QuadStore.ApplyDeltas(deltas, graph.IgnoreOpts{IgnoreDup: true, IgnoreMissing: true}); err != nil {
+			return 0, fmt.Errorf("failed to replay journal entry %s: %w", entry, err)
+		}
+
+		// Logged writes stay until the next checkpoint
+		if ncs.journal.key == nil {
+			ncs.journal.complete(entry)
+		}
+	}
+
+	return len(entries), nil
+}
+
+// read returns the deltas of the entry at path, decrypting the entries written by log
+func (j *writeJournal) read(path string) ([]graph.Delta, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	if !strings.HasSuffix(path, encryptedSuffix) {
+		return readDeltas(file, 0)
+	}
+
+	if j.key == nil {
+		return nil, ErrStoreEncrypted
+	}
+
+	decrypted, err := openEncrypted(file, j.key)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(decrypted)
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		return nil, fmt.Errorf("invalid count of removed quads: %w", err)
+	}
+
+	return readDeltas(reader, removed)
+}
+
+// readDeltas reads the N-Quads of r, the first removed of them are removed and the rest added
+func readDeltas(r io.Reader, removed int) ([]graph.Delta, error) {
+	reader := nquads.NewReader(r, false)
+
+	var deltas []graph.Delta
+	for {
+		q, err := reader.ReadQuad()
+		if errors.Is(err, io.EOF) {
+			return deltas, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		action := graph.Add
+		if len(deltas) < removed {
+			action = graph.Delete
+		}
+		deltas = append(deltas, graph.Delta{Quad: q, Action: action})
+	}
+}
@@ -0,0 +1,283 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// Build variables the parser reads the owner and labels of a build and its outputs from, labels are
+// comma separated key=value pairs
+const (
+	OwnerVariable  = "distninja_owner"
+	LabelsVariable = "distninja_labels"
+)
+
+// NodeLabels is the owner and labels of a build or target
+type NodeLabels struct {
+	Owner  string            `json:"owner"`
+	Labels map[string]string `json:"labels"`
+}
+
+// LabelMatch requires a label, with Value unless AnyValue is set
+type LabelMatch struct {
+	Key      string
+	Value    string
+	AnyValue bool
+}
+
+// LabelSelector selects builds and targets by owner and labels, empty fields match every node
+type LabelSelector struct {
+	Owner  string
+	Labels []LabelMatch
+}
+
+// SetLabels converts map to JSON string, no labels leave the field empty so none are stored
+func (nb *NinjaBuild) SetLabels(labels map[string]string) error {
+	encoded, err := encodeLabels(labels)
+	if err != nil {
+		return err
+	}
+
+	nb.Labels = encoded
+
+	return nil
+}
+
+// GetLabels converts JSON string back to map
+func (nb *NinjaBuild) GetLabels() (map[string]string, error) {
+	return decodeLabels(nb.Labels)
+}
+
+// SetLabels converts map to JSON string, no labels leave the field empty so none are stored
+func (nt *NinjaTarget) SetLabels(labels map[string]string) error {
+	encoded, err := encodeLabels(labels)
+	if err != nil {
+		return err
+	}
+
+	nt.Labels = encoded
+
+	return nil
+}
+
+// GetLabels converts JSON string back to map
+func (nt *NinjaTarget) GetLabels() (map[string]string, error) {
+	return decodeLabels(nt.Labels)
+}
+
+func encodeLabels(labels map[string]string) (string, error) {
+	if len(labels) == 0 {
+		return "", nil
+	}
+
+	if err := ValidateLabels(labels); err != nil {
+		return "", err
+	}
+
+	jsonBytes, err := json.Marshal(labels)
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonBytes), nil
+}
+
+func decodeLabels(encoded string) (map[string]string, error) {
+	if encoded == "" || encoded == "{}" {
+		return make(map[string]string), nil
+	}
+
+	var labels map[string]string
+	err := json.Unmarshal([]byte(encoded), &labels)
+
+	return labels, err
+}
+
+// ValidateLabels checks that label keys are set and can be written in LabelsVariable and selectors
+func ValidateLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if key == "" || strings.ContainsAny(key, "=, ") {
+			return fmt.Errorf("%w: key %q", ErrInvalidLabel, key)
+		}
+		if strings.Contains(value, ",") {
+			return fmt.Errorf("%w: value of %s contains a comma", ErrInvalidLabel, key)
+		}
+	}
+
+	return nil
+}
+
+// ParseLabels parses the comma separated key=value pairs of LabelsVariable
+func ParseLabels(value string) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q, expected key=value", ErrInvalidLabel, pair)
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+
+	if err := ValidateLabels(labels); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// ParseLabelSelector builds a selector from an owner and label expressions, key=value requires the
+// label to have the value and key alone requires the label with any value
+func ParseLabelSelector(owner string, exprs []string) (*LabelSelector, error) {
+	selector := &LabelSelector{Owner: owner}
+
+	for _, expr := range exprs {
+		key, value, ok := strings.Cut(expr, "=")
+		if key == "" {
+			return nil, fmt.Errorf("%w: selector %q has no key", ErrInvalidLabel, expr)
+		}
+		selector.Labels = append(selector.Labels, LabelMatch{Key: key, Value: value, AnyValue: !ok})
+	}
+
+	return selector, nil
+}
+
+// Empty reports whether the selector matches every node
+func (s *LabelSelector) Empty() bool {
+	return s == nil || s.Owner == "" && len(s.Labels) == 0
+}
+
+// MatchesBuild reports whether build has the owner and labels of the selector
+func (s *LabelSelector) MatchesBuild(build *NinjaBuild) bool {
+	return s.matches(build.Owner, build.Labels)
+}
+
+// MatchesTarget reports whether target has the owner and labels of the selector
+func (s *LabelSelector) MatchesTarget(target *NinjaTarget) bool {
+	return s.matches(target.Owner, target.Labels)
+}
+
+func (s *LabelSelector) matches(owner, encoded string) bool {
+	if s.Empty() {
+		return true
+	}
+
+	if s.Owner != "" && owner != s.Owner {
+		return false
+	}
+
+	if len(s.Labels) == 0 {
+		return true
+	}
+
+	labels, err := decodeLabels(encoded)
+	if err != nil {
+		return false
+	}
+
+	for _, match := range s.Labels {
+		value, ok := labels[match.Key]
+		if !ok || !match.AnyValue && value != match.Value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FilterTargets returns the targets matching selector
+func FilterTargets(targets []*NinjaTarget, selector *LabelSelector) []*NinjaTarget {
+	if selector.Empty() {
+		return targets
+	}
+
+	filtered := make([]*NinjaTarget, 0, len(targets))
+	for _, target := range targets {
+		if selector.MatchesTarget(target) {
+			filtered = append(filtered, target)
+		}
+	}
+
+	return filtered
+}
+
+// SetTargetLabels replaces the owner and labels of a target, an empty owner or no labels remove them
+func (ncs *NinjaStore) SetTargetLabels(ctx context.Context, path string, labels *NodeLabels) (*NinjaTarget, error) {
+	if err := ncs.setLabels(ctx, quad.IRI(fmt.Sprintf("target:%s", path)), "target "+path, labels); err != nil {
+		return nil, err
+	}
+
+	return ncs.GetTarget(ctx, path)
+}
+
+// SetBuildLabels replaces the owner and labels of a build, an empty owner or no labels remove them.
+// Its targets keep theirs, builds only pass theirs on to their outputs when they are written.
+func (ncs *NinjaStore) SetBuildLabels(ctx context.Context, id string, labels *NodeLabels) (*NinjaBuild, error) {
+	if err := ncs.setLabels(ctx, quad.IRI(fmt.Sprintf("build:%s", id)), "build "+id, labels); err != nil {
+		return nil, err
+	}
+
+	return ncs.GetBuild(ctx, id)
+}
+
+// setLabels replaces the owner and labels quads of node in a single transaction
+func (ncs *NinjaStore) setLabels(ctx context.Context, node quad.IRI, name string, labels *NodeLabels) error {
+	encoded, err := encodeLabels(labels.Labels)
+	if err != nil {
+		return err
+	}
+
+	quads, err := ncs.subjectQuads(ctx, node)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", name, err)
+	}
+
+	if len(quads) == 0 {
+		return fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+
+	wanted := make(map[quad.Quad]bool)
+	if labels.Owner != "" {
+		wanted[quad.Make(node, quad.IRI("owner"), quad.String(labels.Owner), nil)] = true
+	}
+	if encoded != "" {
+		wanted[quad.Make(node, quad.IRI("labels"), quad.String(encoded), nil)] = true
+	}
+
+	tx := graph.NewTransaction()
+
+	for _, q := range quads {
+		if q.Predicate != quad.IRI("owner") && q.Predicate != quad.IRI("labels") {
+			continue
+		}
+		if wanted[q] {
+			delete(wanted, q) // Already set
+			continue
+		}
+		tx.RemoveQuad(q)
+	}
+
+	for q := range wanted {
+		tx.AddQuad(q)
+	}
+
+	if len(tx.Deltas) == 0 {
+		return nil
+	}
+
+	if err := ncs.store.ApplyTransaction(tx); err != nil {
+		return fmt.Errorf("failed to set labels of %s: %w", name, err)
+	}
+
+	return nil
+}
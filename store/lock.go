@@ -0,0 +1,131 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// lockSuffix names the file next to the store that the process holding the store keeps locked
+const lockSuffix = ".lock"
+
+// errLockHeld is returned by lockFile when another process holds the lock
+var errLockHeld = errors.New("lock held")
+
+// storeLock is an exclusive advisory lock on the lock file of a store, which holds the PID of its owner.
+// The lock goes away with the process holding it, so a crash never leaves the store locked.
+type storeLock struct {
+	file *os.File
+}
+
+// lockStore locks the store at dbPath, failing with ErrStoreLocked naming the owner when another
+// process holds it
+func lockStore(dbPath string) (*storeLock, error) {
+	path := dbPath + lockSuffix
+
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open lock file %s: %w", path, err)
+		}
+
+		if err := lockFile(file); err != nil {
+			pid := readLockPID(file)
+			_ = file.Close()
+			if errors.Is(err, errLockHeld) {
+				return nil, fmt.Errorf("%w: %s is held by process %d", ErrStoreLocked, dbPath, pid)
+			}
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		// ForceUnlock may have removed the file before it was locked, lock the one in place instead
+		if !lockedFileInPlace(file, path) {
+			_ = unlockFile(file)
+			_ = file.Close()
+			continue
+		}
+
+		lock := &storeLock{file: file}
+		if err := lock.writePID(); err != nil {
+			lock.release()
+			return nil, fmt.Errorf("failed to write lock file %s: %w", path, err)
+		}
+
+		return lock, nil
+	}
+}
+
+func lockedFileInPlace(file *os.File, path string) bool {
+	opened, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(opened, current)
+}
+
+func (l *storeLock) writePID() error {
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+
+	_, err := l.file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+
+	return err
+}
+
+// release clears the PID and unlocks the store, the file stays so that later processes lock the same one
+func (l *storeLock) release() {
+	_ = l.file.Truncate(0)
+	_ = unlockFile(l.file)
+	_ = l.file.Close()
+}
+
+// readLockPID returns the PID in a lock file, zero when it has none
+func readLockPID(file io.ReaderAt) int {
+	buf := make([]byte, 32)
+
+	n, err := file.ReadAt(buf, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+	if err != nil {
+		return 0
+	}
+
+	return pid
+}
+
+// ForceUnlock removes the lock file of the store at dbPath and returns the PID it named, zero when there
+// was none. A process still running keeps a lock on the removed file and is no longer kept out of the
+// store, so this is only for locks left by a process that hangs or runs on another host.
+func ForceUnlock(dbPath string) (int, error) {
+	path := dbPath + lockSuffix
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open lock file %s: %w", path, err)
+	}
+
+	pid := readLockPID(file)
+	_ = file.Close()
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to remove lock file %s: %w", path, err)
+	}
+
+	return pid, nil
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+package store
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockOffsetHigh places the locked byte at 4 GiB, past the PID, because windows locks keep other
+// processes from reading the locked range
+const lockOffsetHigh = 1
+
+// lockFile takes an exclusive lock on file without waiting for it
+func lockFile(file *os.File) error {
+	ol := &windows.Overlapped{OffsetHigh: lockOffsetHigh}
+
+	err := windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, ol)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLockHeld
+	}
+
+	return err
+}
+
+func unlockFile(file *os.File) error {
+	ol := &windows.Overlapped{OffsetHigh: lockOffsetHigh}
+
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, ol)
+}
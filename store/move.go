@@ -0,0 +1,226 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// MoveResult reports what MoveTarget renamed along with the target
+type MoveResult struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Build is the new id of the build of the target when it was named after its outputs
+	Build string `json:"build,omitempty"`
+	// File is set when builds consuming the target as an input were pointed at the new path
+	File bool `json:"file"`
+	// History counts the status changes moved to the new path
+	History int `json:"history"`
+}
+
+// MoveTarget renames the target at oldPath to newPath in a single transaction. Every quad of the target
+// and every quad pointing at it is rewritten, so its status, hash, build and dependencies are kept and
+// its status history follows it. The file node builds read the output through is renamed too, and so is
+// the build when its id is made of its outputs as the parser names builds. It fails with ErrNotFound
+// when there is no target at oldPath and with ErrExists when newPath or the renamed build is taken.
+func (ncs *NinjaStore) MoveTarget(ctx context.Context, oldPath, newPath string) (*MoveResult, error) {
+	if oldPath == "" || newPath == "" {
+		return nil, fmt.Errorf("old and new target paths are required")
+	}
+
+	result := &MoveResult{From: oldPath, To: newPath}
+
+	oldTarget := quad.IRI(fmt.Sprintf("target:%s", oldPath))
+	newTarget := quad.IRI(fmt.Sprintf("target:%s", newPath))
+
+	targetQuads, err := ncs.subjectQuads(ctx, oldTarget)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load target %s: %w", oldPath, err)
+	}
+
+	if len(targetQuads) == 0 {
+		return nil, fmt.Errorf("target %s: %w", oldPath, ErrNotFound)
+	}
+
+	if oldPath == newPath {
+		return result, nil
+	}
+
+	taken, err := ncs.subjectQuads(ctx, newTarget)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load target %s: %w", newPath, err)
+	}
+
+	if len(taken) > 0 {
+		return nil, fmt.Errorf("target %s: %w", newPath, ErrExists)
+	}
+
+	// renames maps the nodes moving along with the target to their new IRIs
+	renames := map[quad.Value]quad.Value{oldTarget: newTarget}
+	// fields maps the quads holding the old path, file type or build id to their new values
+	fields := make(map[quad.Quad]quad.Value)
+
+	oldFile := quad.IRI(fmt.Sprintf("file:%s", oldPath))
+	fileQuads, err := ncs.subjectQuads(ctx, oldFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load file %s: %w", oldPath, err)
+	}
+
+	if len(fileQuads) > 0 {
+		renames[oldFile] = quad.IRI(fmt.Sprintf("file:%s", newPath))
+		result.File = true
+
+		for _, q := range fileQuads {
+			if q.Predicate == quad.IRI("file_type") {
+				fields[q] = quad.String(ncs.inferFileType(newPath))
+			}
+		}
+	}
+
+	build, err := ncs.targetBuild(ctx, targetQuads)
+	if err != nil {
+		return nil, err
+	}
+
+	if build != nil {
+		if buildID, ok := movedBuildID(build, oldPath, newPath); ok {
+			newBuild := quad.IRI(fmt.Sprintf("build:%s", buildID))
+
+			taken, err := ncs.subjectQuads(ctx, newBuild)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load build %s: %w", buildID, err)
+			}
+
+			if len(taken) > 0 {
+				return nil, fmt.Errorf("build %s: %w", buildID, ErrExists)
+			}
+
+			buildQuads, err := ncs.subjectQuads(ctx, build.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load build %s: %w", build.BuildID, err)
+			}
+
+			for _, q := range buildQuads {
+				if q.Predicate == quad.IRI("build_id") {
+					fields[q] = quad.String(buildID)
+				}
+			}
+
+			renames[build.ID] = newBuild
+			result.Build = buildID
+		}
+	}
+
+	// The target and file paths and the status history are found by the old path
+	pathRefs, err := ncs.objectQuads(ctx, quad.String(oldPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load quads of %s: %w", oldPath, err)
+	}
+
+	for _, q := range pathRefs {
+		switch {
+		case q.Predicate == quad.IRI("target_path"):
+			result.History++
+		case q.Predicate == quad.IRI("path") && renames[q.Subject] != nil:
+		default:
+			continue
+		}
+		fields[q] = quad.String(newPath)
+	}
+
+	// Collect every quad of the renamed nodes once
+	seen := make(map[quad.Quad]bool)
+	var quads []quad.Quad
+
+	collect := func(qs []quad.Quad) {
+		for _, q := range qs {
+			if !seen[q] {
+				seen[q] = true
+				quads = append(quads, q)
+			}
+		}
+	}
+
+	for node := range renames {
+		for _, d := range []quad.Direction{quad.Subject, quad.Object} {
+			qs, err := ncs.directionQuads(ctx, d, node)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load quads of %s: %w", node, err)
+			}
+			collect(qs)
+		}
+	}
+
+	for q := range fields {
+		collect([]quad.Quad{q})
+	}
+
+	tx := graph.NewTransaction()
+
+	for _, q := range quads {
+		moved := q
+		if to, ok := renames[q.Subject]; ok {
+			moved.Subject = to
+		}
+		if to, ok := renames[q.Object]; ok {
+			moved.Object = to
+		}
+		if to, ok := fields[q]; ok {
+			moved.Object = to
+		}
+
+		if moved == q {
+			continue
+		}
+
+		tx.RemoveQuad(q)
+		tx.AddQuad(moved)
+	}
+
+	if err := ncs.store.ApplyTransaction(tx); err != nil {
+		return nil, fmt.Errorf("failed to move target %s to %s: %w", oldPath, newPath, err)
+	}
+
+	return result, nil
+}
+
+// targetBuild loads the build named by the quads of a target, nil when it has none
+func (ncs *NinjaStore) targetBuild(ctx context.Context, targetQuads []quad.Quad) (*NinjaBuild, error) {
+	for _, q := range targetQuads {
+		if q.Predicate != quad.IRI("build") {
+			continue
+		}
+
+		var build NinjaBuild
+		if err := ncs.loadNode(ctx, &build, q.Object); err != nil {
+			return nil, fmt.Errorf("failed to load build %s: %w", q.Object, err)
+		}
+
+		return &build, nil
+	}
+
+	return nil, nil
+}
+
+// movedBuildID returns the id of build after renaming its output oldPath to newPath, ok is false when
+// oldPath isn't part of the id
+func movedBuildID(build *NinjaBuild, oldPath, newPath string) (string, bool) {
+	outputs := strings.Split(build.BuildID, ",")
+
+	found := false
+	for i, output := range outputs {
+		if output == oldPath {
+			outputs[i] = newPath
+			found = true
+		}
+	}
+
+	if !found {
+		return "", false
+	}
+
+	return strings.Join(outputs, ","), true
+}
@@ -0,0 +1,228 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BuildPlan packs the builds of the graph onto workers by the resources they declare. Each step starts
+// once the builds of the previous step are done, builds of one step run at the same time.
+type BuildPlan struct {
+	Policy   string      `json:"policy"`
+	Workers  int         `json:"workers"`
+	Capacity Resources   `json:"capacity"`
+	Steps    []*PlanStep `json:"steps"`
+	// Oversized are the builds needing more than a worker offers, each runs alone on a worker
+	Oversized []string `json:"oversized"`
+}
+
+// PlanStep is the builds each worker runs in one step, workers without builds are left out
+type PlanStep struct {
+	Workers []*WorkerAssignment `json:"workers"`
+}
+
+// WorkerAssignment is the builds a worker runs in a step and the resources they use together
+type WorkerAssignment struct {
+	Worker int       `json:"worker"`
+	Builds []string  `json:"builds"`
+	Used   Resources `json:"used"`
+}
+
+// planBuild is a build with its declared resources and the builds depending on its outputs
+type planBuild struct {
+	resources  Resources
+	dependents []string
+	waiting    int
+}
+
+// GetBuildPlan packs the builds of the graph onto workers with capacity each. Ready builds are taken in
+// the order policy picks them and placed on the first worker with room, builds that don't fit wait for
+// the next step. Builds declare their resources with CPUVariable, MemoryVariable and DiskVariable.
+func (ncs *NinjaStore) GetBuildPlan(ctx context.Context, workers int, capacity Resources, policy SchedulingPolicy) (*BuildPlan, error) {
+	if workers < 1 || !(capacity.CPU > 0) || capacity.MemoryBytes < 0 || capacity.DiskBytes < 0 {
+		return nil, fmt.Errorf("%w: at least one worker with a positive cpu is required", ErrInvalidResources)
+	}
+
+	builds, err := ncs.planBuilds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dependents := make(map[string][]string, len(builds))
+	for id, build := range builds {
+		dependents[id] = build.dependents
+	}
+	policy.Init(dependents)
+
+	plan := &BuildPlan{
+		Policy:    policy.Name(),
+		Workers:   workers,
+		Capacity:  capacity,
+		Steps:     []*PlanStep{},
+		Oversized: []string{},
+	}
+
+	var ready []string
+	for id, build := range builds {
+		if build.waiting == 0 {
+			ready = append(ready, id)
+		}
+		if !build.resources.fits(capacity, capacity) {
+			plan.Oversized = append(plan.Oversized, id)
+		}
+	}
+	sort.Strings(ready)
+	sort.Strings(plan.Oversized)
+
+	done := 0
+
+	for len(ready) > 0 {
+		free := make([]Resources, workers)
+		assignments := make([]*WorkerAssignment, workers)
+		for i := range free {
+			free[i] = capacity
+		}
+
+		for _, id := range ready {
+			policy.Push(id)
+		}
+		var waiting, placed []string
+
+		for policy.Len() > 0 {
+			id := policy.Pop()
+
+			worker := placeBuild(builds[id].resources, free, capacity, assignments)
+			if worker < 0 {
+				waiting = append(waiting, id)
+				continue
+			}
+
+			if assignments[worker] == nil {
+				assignments[worker] = &WorkerAssignment{Worker: worker}
+			}
+			assignment := assignments[worker]
+			assignment.Builds = append(assignment.Builds, id)
+			assignment.Used = assignment.Used.add(builds[id].resources)
+			free[worker] = free[worker].sub(builds[id].resources)
+			placed = append(placed, id)
+		}
+
+		step := &PlanStep{}
+		for _, assignment := range assignments {
+			if assignment != nil {
+				step.Workers = append(step.Workers, assignment)
+			}
+		}
+		plan.Steps = append(plan.Steps, step)
+
+		// Builds become ready in the order their dependencies were placed
+		ready = waiting
+		for _, id := range placed {
+			done++
+			for _, dependent := range builds[id].dependents {
+				builds[dependent].waiting--
+				if builds[dependent].waiting == 0 {
+					ready = append(ready, dependent)
+				}
+			}
+		}
+	}
+
+	if done != len(builds) {
+		return nil, fmt.Errorf("circular dependency detected in build graph")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// placeBuild returns the first worker with room for resources, reserving it, and -1 when none has room.
+// A build larger than a worker takes one that is still idle to itself.
+func placeBuild(resources Resources, free []Resources, capacity Resources, assignments []*WorkerAssignment) int {
+	oversized := !resources.fits(capacity, capacity)
+
+	for i := range free {
+		if oversized {
+			if assignments[i] == nil {
+				free[i] = resources
+				return i
+			}
+			continue
+		}
+
+		if resources.fits(free[i], capacity) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// planBuilds loads every build with its resolved resources and the builds depending on its outputs
+func (ncs *NinjaStore) planBuilds(ctx context.Context) (map[string]*planBuild, error) {
+	all, err := ncs.GetAllBuilds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make(map[string]map[string]string)
+	builds := make(map[string]*planBuild, len(all))
+	producers := make(map[string]string)
+	deps := make(map[string][]string, len(all))
+
+	for _, build := range all {
+		ruleVariables, ok := rules[string(build.Rule)]
+		if !ok {
+			var rule NinjaRule
+			if err := ncs.loadNode(ctx, &rule, build.Rule); err == nil {
+				ruleVariables, _ = rule.GetVariables()
+			}
+			rules[string(build.Rule)] = ruleVariables
+		}
+
+		buildVariables, _ := build.GetVariables()
+
+		resources, err := ResolveResources(ruleVariables, buildVariables)
+		if err != nil {
+			return nil, fmt.Errorf("build %s with rule %s: %w", build.BuildID, strings.TrimPrefix(string(build.Rule), "rule:"), err)
+		}
+
+		links, err := ncs.buildLinks(ctx, build.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		builds[build.BuildID] = &planBuild{resources: resources}
+		for _, output := range links.outputs {
+			producers[output] = build.BuildID
+		}
+		for _, dep := range links.deps {
+			deps[build.BuildID] = append(deps[build.BuildID], dep.Target)
+		}
+	}
+
+	for id, paths := range deps {
+		seen := make(map[string]bool)
+		for _, path := range paths {
+			producer, ok := producers[path]
+			if !ok || producer == id || seen[producer] {
+				continue
+			}
+			seen[producer] = true
+
+			builds[producer].dependents = append(builds[producer].dependents, id)
+			builds[id].waiting++
+		}
+	}
+
+	for _, build := range builds {
+		sort.Strings(build.dependents)
+	}
+
+	return builds, nil
+}
@@ -0,0 +1,206 @@
+package store
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Scheduling policy names
+const (
+	PolicyFIFO         = "fifo"
+	PolicyCriticalPath = "critical_path"
+)
+
+// ErrUnknownPolicy is returned for scheduling policy names that aren't registered
+var ErrUnknownPolicy = errors.New("unknown scheduling policy")
+
+// SchedulingPolicy holds the ready targets while the build graph is ordered and decides which one is
+// emitted next
+type SchedulingPolicy interface {
+	// Name returns the policy name
+	Name() string
+	// Init is called once with the dependents of every target (dep -> targets depending on it), it
+	// empties the ready targets
+	Init(dependents map[string][]string)
+	// Push adds a target that became ready
+	Push(target string)
+	// Pop removes and returns the next ready target
+	Pop() string
+	// Len returns the number of ready targets
+	Len() int
+}
+
+// NewSchedulingPolicy returns the policy registered under name
+func NewSchedulingPolicy(name string, durations map[string]time.Duration) (SchedulingPolicy, error) {
+	switch name {
+	case "", PolicyFIFO:
+		return &FIFOPolicy{}, nil
+	case PolicyCriticalPath:
+		return NewCriticalPathPolicy(durations), nil
+	default:
+		return nil, fmt.Errorf("%w %s", ErrUnknownPolicy, name)
+	}
+}
+
+// FIFOPolicy emits ready targets in the order they became ready
+type FIFOPolicy struct {
+	ready []string
+}
+
+func (p *FIFOPolicy) Name() string {
+	return PolicyFIFO
+}
+
+func (p *FIFOPolicy) Init(_ map[string][]string) {
+	p.ready = nil
+}
+
+func (p *FIFOPolicy) Push(target string) {
+	p.ready = append(p.ready, target)
+}
+
+func (p *FIFOPolicy) Pop() string {
+	target := p.ready[0]
+	p.ready = p.ready[1:]
+
+	return target
+}
+
+func (p *FIFOPolicy) Len() int {
+	return len(p.ready)
+}
+
+// CriticalPathPolicy prefers targets on the longest remaining dependency chain
+type CriticalPathPolicy struct {
+	durations map[string]time.Duration
+	weights   map[string]time.Duration
+	ready     weightHeap
+}
+
+// NewCriticalPathPolicy creates a critical path policy weighing targets by their durations, e.g. those of
+// GetTargetDurations. Targets without a known duration weigh one second, without durations the policy
+// prefers the targets with the most levels of dependents.
+func NewCriticalPathPolicy(durations map[string]time.Duration) *CriticalPathPolicy {
+	return &CriticalPathPolicy{
+		durations: durations,
+		weights:   make(map[string]time.Duration),
+	}
+}
+
+func (p *CriticalPathPolicy) Name() string {
+	return PolicyCriticalPath
+}
+
+// Init computes the remaining chain weight of every target
+func (p *CriticalPathPolicy) Init(dependents map[string][]string) {
+	p.weights = make(map[string]time.Duration, len(dependents))
+	p.ready = weightHeap{}
+
+	// 0: unvisited, 1: visiting, 2: visited
+	state := make(map[string]int, len(dependents))
+
+	for root := range dependents {
+		if state[root] != 0 {
+			continue
+		}
+
+		stack := []string{root}
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+
+			if state[current] == 0 {
+				state[current] = 1
+				for _, next := range dependents[current] {
+					if state[next] == 0 {
+						stack = append(stack, next)
+					}
+				}
+				continue
+			}
+
+			stack = stack[:len(stack)-1]
+			if state[current] == 2 {
+				continue
+			}
+
+			var longest time.Duration
+			for _, next := range dependents[current] {
+				if p.weights[next] > longest {
+					longest = p.weights[next]
+				}
+			}
+
+			p.weights[current] = p.duration(current) + longest
+			state[current] = 2
+		}
+	}
+}
+
+// Push adds a ready target, targets of the same weight are popped in the order they were pushed
+func (p *CriticalPathPolicy) Push(target string) {
+	heap.Push(&p.ready, weightedTarget{target: target, weight: p.weights[target], seq: p.ready.seq})
+	p.ready.seq++
+}
+
+// Pop returns the ready target with the heaviest remaining chain
+func (p *CriticalPathPolicy) Pop() string {
+	return heap.Pop(&p.ready).(weightedTarget).target
+}
+
+func (p *CriticalPathPolicy) Len() int {
+	return len(p.ready.targets)
+}
+
+// Weight returns the remaining chain weight computed for a target
+func (p *CriticalPathPolicy) Weight(target string) time.Duration {
+	return p.weights[target]
+}
+
+func (p *CriticalPathPolicy) duration(target string) time.Duration {
+	if d, ok := p.durations[target]; ok && d > 0 {
+		return d
+	}
+
+	return time.Second
+}
+
+type weightedTarget struct {
+	target string
+	weight time.Duration
+	seq    uint64
+}
+
+// weightHeap is a max-heap of ready targets by weight, then by push order
+type weightHeap struct {
+	targets []weightedTarget
+	seq     uint64
+}
+
+func (h *weightHeap) Len() int {
+	return len(h.targets)
+}
+
+func (h *weightHeap) Less(i, j int) bool {
+	if h.targets[i].weight != h.targets[j].weight {
+		return h.targets[i].weight > h.targets[j].weight
+	}
+
+	return h.targets[i].seq < h.targets[j].seq
+}
+
+func (h *weightHeap) Swap(i, j int) {
+	h.targets[i], h.targets[j] = h.targets[j], h.targets[i]
+}
+
+func (h *weightHeap) Push(x interface{}) {
+	h.targets = append(h.targets, x.(weightedTarget))
+}
+
+func (h *weightHeap) Pop() interface{} {
+	last := h.targets[len(h.targets)-1]
+	h.targets = h.targets[:len(h.targets)-1]
+
+	return last
+}
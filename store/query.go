@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/query/gizmo"
+)
+
+// QueryResult holds the results of an ad-hoc graph query
+type QueryResult struct {
+	Results   []interface{} `json:"results"`
+	Truncated bool          `json:"truncated"`
+}
+
+// RunGizmo runs a Gizmo query against the graph, returning at most limit results, the query is
+// interrupted when ctx is done
+func (ncs *NinjaStore) RunGizmo(ctx context.Context, text string, limit int) (*QueryResult, error) {
+	if text == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	session := gizmo.NewSession(ncs.store)
+
+	// Ask for one extra result to detect truncation
+	it, err := session.Execute(ctx, text, query.Options{Limit: limit + 1, Collation: query.JSON})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile query: %w", err)
+	}
+
+	defer func(it query.Iterator) {
+		_ = it.Close()
+	}(it)
+
+	result := &QueryResult{Results: []interface{}{}}
+
+	for it.Next(ctx) {
+		value := it.Result()
+		if value == nil {
+			continue
+		}
+
+		if len(result.Results) == limit {
+			result.Truncated = true
+			break
+		}
+
+		result.Results = append(result.Results, value)
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+
+	return result, nil
+}
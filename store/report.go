@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// RuleReport counts the targets of a rule by status
+type RuleReport struct {
+	Rule     string         `json:"rule"`
+	Targets  int            `json:"targets"`
+	Statuses map[string]int `json:"statuses"`
+}
+
+// GraphReport summarizes the build graph per rule with its critical path, the longest chain of targets
+// each depending on the next
+type GraphReport struct {
+	Rules              []*RuleReport `json:"rules"`
+	CriticalPath       []string      `json:"critical_path"`
+	CriticalPathLength int           `json:"critical_path_length"`
+}
+
+// GetGraphReport returns the target counts of each rule, busiest rule first, and a critical path of
+// the whole graph. Order-only dependencies don't delay a target and are left out of the path.
+func (ncs *NinjaStore) GetGraphReport(ctx context.Context) (*GraphReport, error) {
+	graph, err := ncs.GetDependencyGraph(ctx, "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependency graph: %w", err)
+	}
+
+	rules := make(map[string]*RuleReport)
+	targets := make(map[string]bool)
+
+	for _, node := range graph.Nodes {
+		if node.Kind != NodeKindTarget {
+			continue
+		}
+		targets[node.ID] = true
+
+		rule := rules[node.Rule]
+		if rule == nil {
+			rule = &RuleReport{Rule: node.Rule, Statuses: make(map[string]int)}
+			rules[node.Rule] = rule
+		}
+		rule.Targets++
+		rule.Statuses[node.Status]++
+	}
+
+	deps := make(map[string][]string)
+	for _, edge := range graph.Edges {
+		if edge.Kind != EdgeKindOrder && targets[edge.Target] {
+			deps[edge.Source] = append(deps[edge.Source], edge.Target)
+		}
+	}
+
+	report := &GraphReport{
+		Rules:        make([]*RuleReport, 0, len(rules)),
+		CriticalPath: criticalPath(targets, deps),
+	}
+	report.CriticalPathLength = len(report.CriticalPath)
+
+	for _, rule := range rules {
+		report.Rules = append(report.Rules, rule)
+	}
+
+	sort.Slice(report.Rules, func(i, j int) bool {
+		if report.Rules[i].Targets != report.Rules[j].Targets {
+			return report.Rules[i].Targets > report.Rules[j].Targets
+		}
+		return report.Rules[i].Rule < report.Rules[j].Rule
+	})
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// criticalPath returns the longest chain of targets through deps, starting with the target built last.
+// Ties go to the first path in name order, dependencies closing a cycle are ignored.
+func criticalPath(targets map[string]bool, deps map[string][]string) []string {
+	names, lengths, next := chainLengths(targets, deps)
+
+	start := ""
+	longest := 0
+	for _, name := range names {
+		if length := lengths[name]; length > longest {
+			start, longest = name, length
+		}
+	}
+
+	path := make([]string, 0, longest)
+	for name := start; name != ""; name = next[name] {
+		path = append(path, name)
+	}
+
+	return path
+}
+
+// chainLengths returns the targets in name order with the number of targets in the longest chain
+// through deps starting at each of them, and the next target of that chain. Ties go to the first
+// dependency in name order, dependencies closing a cycle are ignored.
+func chainLengths(targets map[string]bool, deps map[string][]string) ([]string, map[string]int, map[string]string) {
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lengths := make(map[string]int)
+	next := make(map[string]string)
+	visiting := make(map[string]bool)
+
+	var visit func(name string) int
+	visit = func(name string) int {
+		if length, ok := lengths[name]; ok {
+			return length
+		}
+		if visiting[name] {
+			return 0
+		}
+		visiting[name] = true
+
+		children := deps[name]
+		sort.Strings(children)
+
+		best := ""
+		length := 0
+		for _, child := range children {
+			if childLength := visit(child); childLength > length {
+				best, length = child, childLength
+			}
+		}
+
+		visiting[name] = false
+		lengths[name] = length + 1
+		next[name] = best
+
+		return length + 1
+	}
+
+	for _, name := range names {
+		visit(name)
+	}
+
+	return names, lengths, next
+}
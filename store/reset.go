@@ -0,0 +1,254 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/cayleygraph/cayley"
+	"github.com/cayleygraph/cayley/graph"
+)
+
+// compactTxBytes bounds the data copied per write transaction while compacting
+const compactTxBytes = 64 << 20
+
+// graphTypes are the node types making up the build graph, roles, webhooks and audit entries are not
+// part of it
+var graphTypes = []string{"NinjaRule", "NinjaBuild", "NinjaTarget", "NinjaFile"}
+
+// ResetStats counts the nodes removed by Reset
+type ResetStats struct {
+	Rules   int64 `json:"rules"`
+	Builds  int64 `json:"builds"`
+	Targets int64 `json:"targets"`
+	Files   int64 `json:"files"`
+}
+
+// CompactStats reports the size of a bolt file before and after Compact
+type CompactStats struct {
+	BytesBefore int64 `json:"bytes_before"`
+	BytesAfter  int64 `json:"bytes_after"`
+}
+
+// Reset removes all rules, builds, targets and files in a single transaction, role bindings, webhooks,
+// schedules and audit entries are kept. Writes wait until the nodes listed are removed. The bolt file
+// keeps its size, pages freed here are reused by later writes.
+func (ncs *NinjaStore) Reset(ctx context.Context) (*ResetStats, error) {
+	writer := ncs.store.QuadWriter.(*countingWriter)
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	stats := &ResetStats{}
+	counts := map[string]*int64{
+		"NinjaRule":   &stats.Rules,
+		"NinjaBuild":  &stats.Builds,
+		"NinjaTarget": &stats.Targets,
+		"NinjaFile":   &stats.Files,
+	}
+
+	tx := graph.NewTransaction()
+
+	for _, typeName := range graphTypes {
+		subjects, err := ncs.typeSubjects(ctx, typeName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", typeName, err)
+		}
+
+		for _, subject := range subjects {
+			if err := ncs.removeNode(ctx, tx, subject); err != nil {
+				return nil, err
+			}
+		}
+
+		*counts[typeName] = int64(len(subjects))
+	}
+
+	if err := writer.applyLocked(tx); err != nil {
+		return nil, fmt.Errorf("failed to reset store: %w", err)
+	}
+
+	return stats, nil
+}
+
+// Compact rewrites the bolt file of the open store without its free pages, closing and reopening it.
+// Writes wait until it is done. Nothing may read the store meanwhile, callers hold reads back, e.g. the
+// server keeps requests out while it compacts. A failed compaction leaves the file as it was and reopens
+// it; if it can't be reopened the store stays closed and ErrStoreClosed is returned, the process has to
+// open it again.
+func (ncs *NinjaStore) Compact(ctx context.Context) (*CompactStats, error) {
+	writer := ncs.store.QuadWriter.(*countingWriter)
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := ncs.store.Close(); err != nil {
+		return nil, fmt.Errorf("%w: failed to close %s: %v", ErrStoreClosed, ncs.dbPath, err)
+	}
+
+	openPath := ncs.dbPath
+	if ncs.atRest != nil {
+		openPath = ncs.atRest.workPath
+	}
+
+	// The store is reopened even if compacting failed, the file is only replaced by a complete copy
+	stats, err := compactFile(openPath)
+
+	handle, openErr := cayley.NewGraph(storeBackend, openPath, nil)
+	if openErr != nil {
+		return nil, fmt.Errorf("%w: failed to reopen %s: %v", ErrStoreClosed, ncs.dbPath, openErr)
+	}
+
+	// Writes waiting for the lock go to the reopened handle
+	writer.QuadWriter = handle.QuadWriter
+	handle.QuadWriter = writer
+	ncs.store = handle
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The encrypted file shrinks with it
+	if ncs.atRest != nil {
+		if err := ncs.checkpoint(); err != nil {
+			return nil, err
+		}
+	}
+
+	return stats, nil
+}
+
+// compactFile rewrites the bolt file of the store at dbPath without its free pages, replacing it once the
+// copy is complete. The store is closed and the caller holds its lock.
+func compactFile(dbPath string) (*CompactStats, error) {
+	path := filepath.Join(dbPath, boltFile)
+
+	before, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	src, err := bolt.Open(path, 0, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	defer func(src *bolt.DB) {
+		_ = src.Close()
+	}(src)
+
+	tmpPath := path + ".compact"
+
+	dst, err := bolt.Open(tmpPath, before.Mode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", tmpPath, err)
+	}
+
+	if err := compactBolt(dst, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to compact %s: %w", path, err)
+	}
+
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to close %s: %w", tmpPath, err)
+	}
+
+	after, err := os.Stat(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", tmpPath, err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return nil, fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	return &CompactStats{BytesBefore: before.Size(), BytesAfter: after.Size()}, nil
+}
+
+// compactBolt copies every bucket and key of src to dst, committing every compactTxBytes so memory
+// use does not grow with the file size
+func compactBolt(dst, src *bolt.DB) error {
+	tx, err := dst.Begin(true)
+	if err != nil {
+		return err
+	}
+
+	size := 0
+
+	err = src.View(func(srcTx *bolt.Tx) error {
+		return srcTx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return walkBucket(b, nil, name, nil, b.Sequence(), func(parents [][]byte, key, value []byte, seq uint64) error {
+				if size += len(key) + len(value); size > compactTxBytes {
+					if err := tx.Commit(); err != nil {
+						return err
+					}
+					if tx, err = dst.Begin(true); err != nil {
+						return err
+					}
+					size = len(key) + len(value)
+				}
+
+				if len(parents) == 0 {
+					bucket, err := tx.CreateBucket(key)
+					if err != nil {
+						return err
+					}
+					return bucket.SetSequence(seq)
+				}
+
+				bucket := tx.Bucket(parents[0])
+				for _, parent := range parents[1:] {
+					bucket = bucket.Bucket(parent)
+				}
+				// Keys arrive in order, full pages keep the copy small
+				bucket.FillPercent = 1
+
+				if value == nil {
+					nested, err := bucket.CreateBucket(key)
+					if err != nil {
+						return err
+					}
+					return nested.SetSequence(seq)
+				}
+
+				return bucket.Put(key, value)
+			})
+		})
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// walkBucket calls fn for the bucket b named key below parents and then for each of its keys and
+// nested buckets, value is nil for buckets
+func walkBucket(b *bolt.Bucket, parents [][]byte, key, value []byte, seq uint64,
+	fn func(parents [][]byte, key, value []byte, seq uint64) error) error {
+	if err := fn(parents, key, value, seq); err != nil {
+		return err
+	}
+
+	if value != nil {
+		return nil
+	}
+
+	path := append(append([][]byte{}, parents...), key)
+
+	return b.ForEach(func(k, v []byte) error {
+		if v == nil {
+			nested := b.Bucket(k)
+			return walkBucket(nested, path, k, nil, nested.Sequence(), fn)
+		}
+		return walkBucket(b, path, k, v, 0, fn)
+	})
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Rule and build variables declaring the resources an action needs, a build's own variables override
+// those of its rule. Memory and disk are sizes like 512Mi, 8G or 2GiB.
+const (
+	CPUVariable    = "distninja_cpu"
+	MemoryVariable = "distninja_memory"
+	DiskVariable   = "distninja_disk"
+)
+
+// ErrInvalidResources is returned for resource hints that can't be parsed
+var ErrInvalidResources = errors.New("invalid resources")
+
+// defaultCPU is the CPU of actions without a hint, so a worker's CPU works like a slot count for them
+const defaultCPU = 1
+
+// Resources are the CPU, memory and disk an action needs or a worker offers, zero memory and disk are
+// not accounted for
+type Resources struct {
+	CPU         float64 `json:"cpu"`
+	MemoryBytes int64   `json:"memory_bytes"`
+	DiskBytes   int64   `json:"disk_bytes"`
+}
+
+// fits reports whether r fits into free
+func (r Resources) fits(free Resources, capacity Resources) bool {
+	return r.CPU <= free.CPU &&
+		(capacity.MemoryBytes == 0 || r.MemoryBytes <= free.MemoryBytes) &&
+		(capacity.DiskBytes == 0 || r.DiskBytes <= free.DiskBytes)
+}
+
+func (r Resources) add(other Resources) Resources {
+	return Resources{CPU: r.CPU + other.CPU, MemoryBytes: r.MemoryBytes + other.MemoryBytes, DiskBytes: r.DiskBytes + other.DiskBytes}
+}
+
+func (r Resources) sub(other Resources) Resources {
+	return Resources{CPU: r.CPU - other.CPU, MemoryBytes: r.MemoryBytes - other.MemoryBytes, DiskBytes: r.DiskBytes - other.DiskBytes}
+}
+
+// ResolveResources returns the resources of an action from the variables of its rule and build, one
+// CPU and no memory or disk when neither declares them
+func ResolveResources(ruleVariables, buildVariables map[string]string) (Resources, error) {
+	resources := Resources{CPU: defaultCPU}
+
+	for _, variables := range []map[string]string{ruleVariables, buildVariables} {
+		if err := applyResources(&resources, variables); err != nil {
+			return Resources{}, err
+		}
+	}
+
+	return resources, nil
+}
+
+// ValidateResources checks the resource hints among variables
+func ValidateResources(variables map[string]string) error {
+	var resources Resources
+
+	return applyResources(&resources, variables)
+}
+
+func applyResources(resources *Resources, variables map[string]string) error {
+	if value, ok := variables[CPUVariable]; ok {
+		cpu, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || !(cpu > 0) || math.IsInf(cpu, 0) {
+			return fmt.Errorf("%w: %s = %q, expected a positive number", ErrInvalidResources, CPUVariable, value)
+		}
+		resources.CPU = cpu
+	}
+
+	sizes := []struct {
+		name  string
+		field *int64
+	}{
+		{MemoryVariable, &resources.MemoryBytes},
+		{DiskVariable, &resources.DiskBytes},
+	}
+
+	for _, size := range sizes {
+		value, ok := variables[size.name]
+		if !ok {
+			continue
+		}
+
+		bytes, err := ParseSize(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s = %q: %v", ErrInvalidResources, size.name, value, err)
+		}
+		*size.field = bytes
+	}
+
+	return nil
+}
+
+// sizeUnits maps size suffixes to bytes, K, M, G and T are decimal, Ki, Mi, Gi and Ti binary
+var sizeUnits = map[string]float64{
+	"":   1,
+	"k":  1e3,
+	"m":  1e6,
+	"g":  1e9,
+	"t":  1e12,
+	"ki": 1 << 10,
+	"mi": 1 << 20,
+	"gi": 1 << 30,
+	"ti": 1 << 40,
+}
+
+// ParseSize parses a byte size like 512, 512Mi, 8G or 2GiB
+func ParseSize(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+
+	end := len(value)
+	for end > 0 && (value[end-1] < '0' || value[end-1] > '9') && value[end-1] != '.' {
+		end--
+	}
+
+	unit := strings.TrimSuffix(strings.ToLower(value[end:]), "b")
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", value[end:])
+	}
+
+	number, err := strconv.ParseFloat(value[:end], 64)
+	if err != nil || !(number >= 0) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("expected a size like 512Mi or 8G")
+	}
+
+	size := number * multiplier
+	if size > math.MaxInt64 {
+		return 0, fmt.Errorf("size too large")
+	}
+
+	return int64(size), nil
+}
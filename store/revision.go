@@ -0,0 +1,24 @@
+package store
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// storeRevision counts the writes applied since the store was opened. The epoch is the time it was
+// opened, so revisions taken before a restart never equal those taken after it.
+type storeRevision struct {
+	epoch int64
+	count atomic.Uint64
+}
+
+func newStoreRevision() *storeRevision {
+	return &storeRevision{epoch: time.Now().UnixNano()}
+}
+
+// Revision returns an opaque version of the store content that changes with every write, e.g. for ETags.
+// Read it before the data it versions, a write in between then only makes the version look older.
+func (ncs *NinjaStore) Revision() string {
+	return strconv.FormatInt(ncs.revision.epoch, 36) + "-" + strconv.FormatUint(ncs.revision.count.Load(), 10)
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// NinjaSchedule is a recurring run of targets at the times matching a cron expression
+type NinjaSchedule struct {
+	ID         quad.IRI `json:"-" quad:"@id"`
+	Type       quad.IRI `json:"-" quad:"@type"`
+	ScheduleID string   `json:"id" quad:"schedule_id"`
+	Cron       string   `json:"cron" quad:"cron"`
+	Targets    string   `json:"-" quad:"targets"`
+	Options    string   `json:"-" quad:"options"`
+	CreatedAt  string   `json:"created_at" quad:"created_at"`
+}
+
+// GetTargets returns the targets the schedule runs, empty means all targets
+func (s *NinjaSchedule) GetTargets() ([]string, error) {
+	var targets []string
+	if s.Targets == "" {
+		return targets, nil
+	}
+
+	err := json.Unmarshal([]byte(s.Targets), &targets)
+
+	return targets, err
+}
+
+// SetTargets sets the targets the schedule runs
+func (s *NinjaSchedule) SetTargets(targets []string) error {
+	if targets == nil {
+		targets = []string{}
+	}
+
+	data, err := json.Marshal(targets)
+	if err != nil {
+		return err
+	}
+
+	s.Targets = string(data)
+
+	return nil
+}
+
+// GetOptions returns the run options passed on with every run of the schedule
+func (s *NinjaSchedule) GetOptions() (map[string]interface{}, error) {
+	options := map[string]interface{}{}
+	if s.Options == "" {
+		return options, nil
+	}
+
+	err := json.Unmarshal([]byte(s.Options), &options)
+
+	return options, err
+}
+
+// SetOptions sets the run options passed on with every run of the schedule
+func (s *NinjaSchedule) SetOptions(options map[string]interface{}) error {
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	s.Options = string(data)
+
+	return nil
+}
+
+// AddSchedule stores a schedule under its ScheduleID
+func (ncs *NinjaStore) AddSchedule(ctx context.Context, schedule *NinjaSchedule) error {
+	if schedule.ScheduleID == "" || schedule.Cron == "" {
+		return fmt.Errorf("schedule id and cron expression are required")
+	}
+
+	if schedule.Targets == "" {
+		schedule.Targets = "[]"
+	}
+
+	if schedule.Options == "" {
+		schedule.Options = "{}"
+	}
+
+	schedule.ID = quad.IRI(fmt.Sprintf("schedule:%s", schedule.ScheduleID))
+	schedule.Type = "NinjaSchedule"
+
+	tx := graph.NewTransaction()
+	if _, err := ncs.schema.WriteAsQuads(graph.NewTxWriter(tx, graph.Add), schedule); err != nil {
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+
+	return ncs.store.ApplyTransaction(tx)
+}
+
+// GetSchedule returns the schedule with the given id
+func (ncs *NinjaStore) GetSchedule(ctx context.Context, id string) (*NinjaSchedule, error) {
+	var schedule NinjaSchedule
+
+	err := ncs.loadTo(ctx, &schedule, quad.IRI(fmt.Sprintf("schedule:%s", id)))
+	if err != nil {
+		return nil, fmt.Errorf("schedule %s: %w", id, ErrNotFound)
+	}
+
+	return &schedule, nil
+}
+
+// ListSchedules returns all schedules
+func (ncs *NinjaStore) ListSchedules(ctx context.Context) ([]*NinjaSchedule, error) {
+	subjects, err := ncs.typeSubjects(ctx, "NinjaSchedule")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	var schedules []*NinjaSchedule
+
+	for _, subject := range subjects {
+		var schedule NinjaSchedule
+		if err := ncs.loadTo(ctx, &schedule, subject); err != nil {
+			continue // Skip schedules we can't load
+		}
+		schedules = append(schedules, &schedule)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// DeleteSchedule removes the schedule with the given id
+func (ncs *NinjaStore) DeleteSchedule(ctx context.Context, id string) error {
+	old, err := ncs.subjectQuads(ctx, quad.IRI(fmt.Sprintf("schedule:%s", id)))
+	if err != nil {
+		return fmt.Errorf("failed to load schedule %s: %w", id, err)
+	}
+
+	if len(old) == 0 {
+		return fmt.Errorf("schedule %s: %w", id, ErrNotFound)
+	}
+
+	tx := graph.NewTransaction()
+	for _, q := range old {
+		tx.RemoveQuad(q)
+	}
+
+	return ncs.store.ApplyTransaction(tx)
+}
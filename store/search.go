@@ -0,0 +1,323 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// TargetQuery selects the targets returned by SearchTargets, empty fields match every target
+type TargetQuery struct {
+	// Glob matches whole paths, * and ? don't match a slash, ** matches any number of directories
+	Glob string
+	// Regex matches anywhere in paths unless anchored
+	Regex  string
+	Status string
+	// Labels selects targets by owner and labels when set
+	Labels *LabelSelector
+	// Limit is the maximum number of targets returned, zero returns all of them
+	Limit int
+}
+
+// pathIndex keeps the paths of all targets sorted for searches. It is built from the type quads on
+// first use and kept up to date by countingWriter afterwards, a generation bumped by every write keeps
+// builds that raced with a write from being kept.
+type pathIndex struct {
+	mu         sync.Mutex
+	indexed    bool
+	generation uint64
+	paths      map[string]bool
+	// sorted is nil when paths changed since it was sorted, a sorted slice is never modified
+	sorted []string
+}
+
+// update applies the targets tx added or removed, a failed write drops the index
+func (ix *pathIndex) update(tx *graph.Transaction, failed bool) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.generation++
+
+	if failed {
+		ix.indexed = false
+		ix.paths, ix.sorted = nil, nil
+		return
+	}
+
+	if !ix.indexed {
+		return
+	}
+
+	for _, delta := range tx.Deltas {
+		path, ok := targetTypePath(delta.Quad)
+		if !ok {
+			continue
+		}
+		if delta.Action == graph.Add {
+			ix.paths[path] = true
+		} else {
+			delete(ix.paths, path)
+		}
+		ix.sorted = nil
+	}
+}
+
+// reset drops the index, it is built again on next use
+func (ix *pathIndex) reset() {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.generation++
+	ix.indexed = false
+	ix.paths, ix.sorted = nil, nil
+}
+
+// targetTypePath returns the path of the target q declares the type of
+func targetTypePath(q quad.Quad) (string, bool) {
+	if q.Predicate == nil || q.Object == nil || q.Predicate.String() != `<rdf:type>` || q.Object.String() != `<NinjaTarget>` {
+		return "", false
+	}
+
+	subject, ok := q.Subject.(quad.IRI)
+	if !ok || !strings.HasPrefix(string(subject), "target:") {
+		return "", false
+	}
+
+	return strings.TrimPrefix(string(subject), "target:"), true
+}
+
+// targetPaths returns the sorted paths of all targets, the slice must not be modified
+func (ncs *NinjaStore) targetPaths(ctx context.Context) ([]string, error) {
+	ix := &ncs.paths
+
+	ix.mu.Lock()
+	if ix.indexed {
+		defer ix.mu.Unlock()
+		return ix.sortedPaths(), nil
+	}
+	generation := ix.generation
+	ix.mu.Unlock()
+
+	subjects, err := ncs.typeSubjects(ctx, "NinjaTarget")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list targets: %w", err)
+	}
+
+	paths := make(map[string]bool, len(subjects))
+	for _, subject := range subjects {
+		if iri, ok := subject.(quad.IRI); ok && strings.HasPrefix(string(iri), "target:") {
+			paths[strings.TrimPrefix(string(iri), "target:")] = true
+		}
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if ix.indexed {
+		return ix.sortedPaths(), nil
+	}
+
+	if ix.generation != generation {
+		// A write raced with the scan, use what was read without keeping it
+		return sortedKeys(paths), nil
+	}
+
+	ix.indexed = true
+	ix.paths, ix.sorted = paths, nil
+
+	return ix.sortedPaths(), nil
+}
+
+// sortedPaths sorts the paths again if they changed, ix.mu must be held
+func (ix *pathIndex) sortedPaths() []string {
+	if ix.sorted == nil {
+		ix.sorted = sortedKeys(ix.paths)
+	}
+
+	return ix.sorted
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// SearchTargets returns the targets matching query in path order. Globs are looked up in the sorted
+// path index by their literal prefix and statuses by the status quads, so only the matching targets
+// are loaded.
+func (ncs *NinjaStore) SearchTargets(ctx context.Context, query TargetQuery) ([]*NinjaTarget, error) {
+	var matchers []*regexp.Regexp
+	prefix := ""
+
+	if query.Glob != "" {
+		re, literal, err := globRegexp(query.Glob)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, re)
+		prefix = literal
+	}
+
+	if query.Regex != "" {
+		re, err := regexp.Compile(query.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("regex %q: %w: %v", query.Regex, ErrInvalidPattern, err)
+		}
+		matchers = append(matchers, re)
+	}
+
+	paths, err := ncs.targetPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := sort.SearchStrings(paths, prefix)
+
+	var statuses map[string]bool
+	if query.Status != "" {
+		statuses, err = ncs.statusTargets(ctx, query.Status)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	targets := []*NinjaTarget{}
+
+	for _, path := range paths[start:] {
+		if !strings.HasPrefix(path, prefix) {
+			break
+		}
+		if statuses != nil && !statuses[path] {
+			continue
+		}
+		if !matchesAll(matchers, path) {
+			continue
+		}
+
+		target, err := ncs.GetTarget(ctx, path)
+		if err != nil {
+			continue // Removed since it was indexed
+		}
+		if query.Status != "" && target.Status != query.Status || !query.Labels.MatchesTarget(target) {
+			continue
+		}
+
+		targets = append(targets, target)
+		if query.Limit > 0 && len(targets) >= query.Limit {
+			break
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// statusTargets returns the paths of the targets with status
+func (ncs *NinjaStore) statusTargets(ctx context.Context, status string) (map[string]bool, error) {
+	quads, err := ncs.objectQuads(ctx, quad.String(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load targets with status %s: %w", status, err)
+	}
+
+	paths := make(map[string]bool)
+	for _, q := range quads {
+		subject, ok := q.Subject.(quad.IRI)
+		if ok && q.Predicate == quad.IRI("status") && strings.HasPrefix(string(subject), "target:") {
+			paths[strings.TrimPrefix(string(subject), "target:")] = true
+		}
+	}
+
+	return paths, nil
+}
+
+func matchesAll(matchers []*regexp.Regexp, path string) bool {
+	for _, re := range matchers {
+		if !re.MatchString(path) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// globRegexp compiles glob to an anchored regular expression and returns the literal prefix every
+// matching path starts with. A ** segment matches any number of directories, * and ? match within a
+// path segment, [...] matches a character class negated by a leading ! or ^, and \ escapes a character.
+func globRegexp(glob string) (*regexp.Regexp, string, error) {
+	var expr, prefix strings.Builder
+	literal := true
+
+	expr.WriteString("^")
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+
+		switch c {
+		case '*':
+			literal = false
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' && (i == 1 || glob[i-2] == '/') {
+					i++
+					expr.WriteString("(?:.*/)?")
+				} else {
+					expr.WriteString(".*")
+				}
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			literal = false
+			expr.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return nil, "", fmt.Errorf("glob %q: %w: unterminated character class", glob, ErrInvalidPattern)
+			}
+			class := glob[i+1 : i+1+end]
+			if end == 0 || class == "!" || class == "^" {
+				return nil, "", fmt.Errorf("glob %q: %w: empty character class", glob, ErrInvalidPattern)
+			}
+			literal = false
+			if class[0] == '!' {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 == len(glob) {
+				return nil, "", fmt.Errorf("glob %q: %w: trailing backslash", glob, ErrInvalidPattern)
+			}
+			i++
+			fallthrough
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(glob[i])))
+			if literal {
+				prefix.WriteByte(glob[i])
+			}
+		}
+	}
+
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, "", fmt.Errorf("glob %q: %w: %v", glob, ErrInvalidPattern, err)
+	}
+
+	return re, prefix.String(), nil
+}
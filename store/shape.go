@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cayleygraph/quad"
+)
+
+// DefaultShapeTop is the number of most depended on files GetGraphShape returns by default
+const DefaultShapeTop = 10
+
+// countBuckets are the lower bounds of the ranges counts are grouped in by a CountDistribution
+var countBuckets = []int{0, 1, 2, 5, 10, 20, 50, 100}
+
+// CountBucket is the number of nodes whose count falls in a range, Max is -1 for the last, open range
+type CountBucket struct {
+	Min   int `json:"min"`
+	Max   int `json:"max"`
+	Nodes int `json:"nodes"`
+}
+
+// CountDistribution summarizes a count of every node of a kind
+type CountDistribution struct {
+	Min     int            `json:"min"`
+	Max     int            `json:"max"`
+	Mean    float64        `json:"mean"`
+	Median  int            `json:"median"`
+	P90     int            `json:"p90"`
+	Buckets []*CountBucket `json:"buckets"`
+}
+
+// FileFanIn is a file or target with the number of targets depending on it directly
+type FileFanIn struct {
+	Path       string `json:"path"`
+	Kind       string `json:"kind"`
+	Dependents int    `json:"dependents"`
+}
+
+// RuleUsage counts the builds and targets of a rule
+type RuleUsage struct {
+	Rule    string `json:"rule"`
+	Builds  int    `json:"builds"`
+	Targets int    `json:"targets"`
+}
+
+// GraphShape describes how dependencies spread through the build graph
+type GraphShape struct {
+	Targets int `json:"targets"`
+	Files   int `json:"files"`
+	// FanOut is the distribution of the direct dependencies of each target
+	FanOut *CountDistribution `json:"fan_out"`
+	// FanIn is the distribution of the targets depending directly on each file or target they use
+	FanIn *CountDistribution `json:"fan_in"`
+	// MostDependedOn lists the files and targets with the most direct dependents, most first
+	MostDependedOn []*FileFanIn `json:"most_depended_on"`
+	// AverageDepth and MaxDepth measure the longest chain of targets starting at each target, a target
+	// depending on no other target has a depth of 1
+	AverageDepth float64      `json:"average_depth"`
+	MaxDepth     int          `json:"max_depth"`
+	Rules        []*RuleUsage `json:"rules"`
+}
+
+// GetGraphShape returns the fan-out and fan-in distributions of the graph, the top files with the most
+// dependents, the depth of its target chains and the usage of every rule, busiest rule first. Order-only
+// dependencies are left out like in GetGraphReport.
+func (ncs *NinjaStore) GetGraphShape(ctx context.Context, top int) (*GraphShape, error) {
+	graph, err := ncs.GetDependencyGraph(ctx, "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependency graph: %w", err)
+	}
+
+	shape := &GraphShape{MostDependedOn: []*FileFanIn{}}
+
+	kinds := make(map[string]string)
+	targets := make(map[string]bool)
+	rules := make(map[string]*RuleUsage)
+
+	rule := func(name string) *RuleUsage {
+		usage := rules[name]
+		if usage == nil {
+			usage = &RuleUsage{Rule: name}
+			rules[name] = usage
+		}
+		return usage
+	}
+
+	for _, node := range graph.Nodes {
+		kinds[node.ID] = node.Kind
+		if node.Kind != NodeKindTarget {
+			shape.Files++
+			continue
+		}
+		shape.Targets++
+		targets[node.ID] = true
+		rule(node.Rule).Targets++
+	}
+
+	fanOut := make(map[string]int, len(targets))
+	fanIn := make(map[string]int)
+	deps := make(map[string][]string)
+
+	for _, edge := range graph.Edges {
+		if edge.Kind == EdgeKindOrder {
+			continue
+		}
+		fanOut[edge.Source]++
+		fanIn[edge.Target]++
+		if targets[edge.Target] {
+			deps[edge.Source] = append(deps[edge.Source], edge.Target)
+		}
+	}
+
+	outCounts := make([]int, 0, len(targets))
+	for name := range targets {
+		outCounts = append(outCounts, fanOut[name])
+	}
+	shape.FanOut = countDistribution(outCounts)
+
+	inCounts := make([]int, 0, len(fanIn))
+	for path, dependents := range fanIn {
+		inCounts = append(inCounts, dependents)
+		shape.MostDependedOn = append(shape.MostDependedOn, &FileFanIn{Path: path, Kind: kinds[path], Dependents: dependents})
+	}
+	shape.FanIn = countDistribution(inCounts)
+
+	sort.Slice(shape.MostDependedOn, func(i, j int) bool {
+		if shape.MostDependedOn[i].Dependents != shape.MostDependedOn[j].Dependents {
+			return shape.MostDependedOn[i].Dependents > shape.MostDependedOn[j].Dependents
+		}
+		return shape.MostDependedOn[i].Path < shape.MostDependedOn[j].Path
+	})
+	if top >= 0 && len(shape.MostDependedOn) > top {
+		shape.MostDependedOn = shape.MostDependedOn[:top]
+	}
+
+	names, lengths, _ := chainLengths(targets, deps)
+	total := 0
+	for _, name := range names {
+		total += lengths[name]
+		if lengths[name] > shape.MaxDepth {
+			shape.MaxDepth = lengths[name]
+		}
+	}
+	if len(names) > 0 {
+		shape.AverageDepth = float64(total) / float64(len(names))
+	}
+
+	ruleNodes, err := ncs.typeSubjects(ctx, "NinjaRule")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+	for _, node := range ruleNodes {
+		if iri, ok := node.(quad.IRI); ok {
+			rule(strings.TrimPrefix(string(iri), "rule:"))
+		}
+	}
+
+	buildRules, err := ncs.directionQuads(ctx, quad.Predicate, quad.IRI("rule"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load build rules: %w", err)
+	}
+	for _, q := range buildRules {
+		if object, ok := q.Object.(quad.IRI); ok {
+			rule(strings.TrimPrefix(string(object), "rule:")).Builds++
+		}
+	}
+
+	shape.Rules = make([]*RuleUsage, 0, len(rules))
+	for _, usage := range rules {
+		shape.Rules = append(shape.Rules, usage)
+	}
+
+	sort.Slice(shape.Rules, func(i, j int) bool {
+		if shape.Rules[i].Builds != shape.Rules[j].Builds {
+			return shape.Rules[i].Builds > shape.Rules[j].Builds
+		}
+		return shape.Rules[i].Rule < shape.Rules[j].Rule
+	})
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return shape, nil
+}
+
+// countDistribution summarizes counts, the median and 90th percentile are nearest rank values
+func countDistribution(counts []int) *CountDistribution {
+	dist := &CountDistribution{Buckets: make([]*CountBucket, len(countBuckets))}
+
+	for i, lower := range countBuckets {
+		upper := -1
+		if i+1 < len(countBuckets) {
+			upper = countBuckets[i+1] - 1
+		}
+		dist.Buckets[i] = &CountBucket{Min: lower, Max: upper}
+	}
+
+	if len(counts) == 0 {
+		return dist
+	}
+
+	sort.Ints(counts)
+
+	total := 0
+	for _, count := range counts {
+		total += count
+
+		i := sort.Search(len(countBuckets), func(i int) bool { return countBuckets[i] > count }) - 1
+		dist.Buckets[i].Nodes++
+	}
+
+	dist.Min = counts[0]
+	dist.Max = counts[len(counts)-1]
+	dist.Mean = float64(total) / float64(len(counts))
+	dist.Median = counts[(len(counts)-1)/2]
+	dist.P90 = counts[(len(counts)*9+9)/10-1]
+
+	return dist
+}
@@ -0,0 +1,313 @@
+package store
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cayleygraph/cayley"
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/nquads"
+)
+
+const (
+	// snapshotSuffix names the directory next to the store that snapshots are written to by default
+	snapshotSuffix = ".snapshots"
+
+	// restoreSuffix names the store being restored and previousSuffix the store it replaced
+	restoreSuffix  = ".restore"
+	previousSuffix = ".previous"
+
+	// restoreBatchSize bounds the quads written per transaction by RestoreSnapshot
+	restoreBatchSize = 10000
+
+	// encryptedSuffix is appended to the names of encrypted snapshots
+	encryptedSuffix = ".enc"
+)
+
+// SnapshotInfo describes a snapshot written by Snapshot
+type SnapshotInfo struct {
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	Quads     int64     `json:"quads"`
+	Bytes     int64     `json:"bytes"`
+}
+
+// RestoreStats reports the quads RestoreSnapshot wrote and where the store it replaced was moved
+type RestoreStats struct {
+	Quads    int64  `json:"quads"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// DefaultSnapshotDir returns the directory snapshots of the store at dbPath are written to by default
+func DefaultSnapshotDir(dbPath string) string {
+	return dbPath + snapshotSuffix
+}
+
+// SetSnapshotKey sets the AES key snapshots are encrypted with, nil writes them in the clear
+func (ncs *NinjaStore) SetSnapshotKey(key []byte) {
+	ncs.snapshotKey = key
+}
+
+// Snapshot writes every quad of the store to a gzipped N-Quads file in dir named after the time it was
+// taken, encrypted with AES-GCM when a snapshot key is set. Writes wait while the quads are read so the
+// snapshot holds the store at one point in time, reads go on.
+func (ncs *NinjaStore) Snapshot(ctx context.Context, dir string) (*SnapshotInfo, error) {
+	if dir == "" {
+		dir = DefaultSnapshotDir(ncs.dbPath)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory %s: %w", dir, err)
+	}
+
+	writer := ncs.store.QuadWriter.(*countingWriter)
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	info := &SnapshotInfo{CreatedAt: time.Now().UTC()}
+	info.Path = filepath.Join(dir, fmt.Sprintf("snapshot-%s.nq.gz", info.CreatedAt.Format("20060102T150405.000Z")))
+	if ncs.snapshotKey != nil {
+		info.Path += encryptedSuffix
+	}
+	tmpPath := info.Path + ".tmp"
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	info.Quads, err = ncs.writeSnapshotFile(ctx, file)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, info.Path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	if stat, err := os.Stat(info.Path); err == nil {
+		info.Bytes = stat.Size()
+	}
+
+	return info, nil
+}
+
+// writeSnapshotFile writes the quads of the store to file, encrypted when a snapshot key is set. The
+// countingWriter lock must be held.
+func (ncs *NinjaStore) writeSnapshotFile(ctx context.Context, file io.Writer) (int64, error) {
+	if ncs.snapshotKey == nil {
+		return ncs.writeSnapshot(ctx, file)
+	}
+
+	encrypted, err := newEncryptWriter(file, ncs.snapshotKey)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := ncs.writeSnapshot(ctx, encrypted)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, encrypted.Close()
+}
+
+// writeSnapshot writes the quads of the store to w, the countingWriter lock must be held
+func (ncs *NinjaStore) writeSnapshot(ctx context.Context, w io.Writer) (int64, error) {
+	compressed := gzip.NewWriter(w)
+	writer := nquads.NewWriter(compressed)
+
+	it := ncs.allQuadsIterator()
+	defer func(it graph.Iterator) {
+		_ = it.Close()
+	}(it)
+
+	var count int64
+
+	for it.Next(ctx) {
+		result := it.Result()
+		if result == nil {
+			continue
+		}
+
+		if err := writer.WriteQuad(ncs.store.Quad(result)); err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	if err := it.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate quads: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+
+	return count, compressed.Close()
+}
+
+// RestoreSnapshot replaces the store at dbPath, which must not be open, with the quads of the snapshot
+// at path, decrypted with key when it is encrypted. The quads are written to a new store first, so a
+// failed restore leaves the store as it was. The replaced store is kept next to it until the next
+// restore, writes left in its journal are dropped. A store encrypted at rest is restored encrypted with
+// key.
+func RestoreSnapshot(dbPath, path string, key []byte) (*RestoreStats, error) {
+	lock, err := lockStore(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	// The quads of encrypted stores are written to a private directory, only the encrypted file is kept
+	var rest *atRest
+	if IsEncryptedStore(dbPath) {
+		if key == nil {
+			return nil, fmt.Errorf("%w: %s", ErrStoreEncrypted, dbPath)
+		}
+		if rest, err = newAtRest(dbPath, key); err != nil {
+			return nil, err
+		}
+		defer rest.discard()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	reader, err := snapshotReader(file, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", path, err)
+	}
+
+	restorePath := dbPath + restoreSuffix
+	if err := os.RemoveAll(restorePath); err != nil {
+		return nil, fmt.Errorf("failed to remove %s: %w", restorePath, err)
+	}
+
+	stats := &RestoreStats{}
+
+	if rest == nil {
+		stats.Quads, err = restoreQuads(restorePath, nquads.NewReader(reader, false))
+	} else if stats.Quads, err = restoreQuads(rest.workPath, nquads.NewReader(reader, false)); err == nil {
+		if err = os.Mkdir(restorePath, 0700); err == nil {
+			err = rest.encrypt(restorePath)
+		}
+	}
+
+	if err != nil {
+		_ = os.RemoveAll(restorePath)
+		return nil, fmt.Errorf("failed to restore %s: %w", path, err)
+	}
+
+	if _, err := os.Stat(dbPath); err == nil {
+		stats.Previous = dbPath + previousSuffix
+		if err := os.RemoveAll(stats.Previous); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", stats.Previous, err)
+		}
+		if err := os.Rename(dbPath, stats.Previous); err != nil {
+			return nil, fmt.Errorf("failed to move %s aside: %w", dbPath, err)
+		}
+	}
+
+	if err := os.Rename(restorePath, dbPath); err != nil {
+		return nil, fmt.Errorf("failed to move restored store to %s: %w", dbPath, err)
+	}
+
+	// Journal entries are writes to the replaced store, replaying them would mix them into the snapshot
+	if err := os.RemoveAll(dbPath + journalSuffix); err != nil {
+		return nil, fmt.Errorf("failed to remove journal: %w", err)
+	}
+
+	return stats, nil
+}
+
+// snapshotReader returns the N-Quads of a snapshot, gzipped as written by Snapshot or plain, decrypted
+// with key when the snapshot is encrypted
+func snapshotReader(file io.Reader, key []byte) (io.Reader, error) {
+	buffered := bufio.NewReader(file)
+
+	if magic, err := buffered.Peek(len(encryptionMagic)); err == nil && string(magic) == encryptionMagic {
+		if key == nil {
+			return nil, errEncrypted
+		}
+
+		_, _ = buffered.Discard(len(encryptionMagic))
+
+		decrypted, err := newDecryptReader(buffered, key)
+		if err != nil {
+			return nil, err
+		}
+
+		buffered = bufio.NewReader(decrypted)
+	}
+
+	magic, err := buffered.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(buffered)
+	}
+
+	return buffered, nil
+}
+
+// restoreQuads writes the quads of reader to a new store at dbPath in batches
+func restoreQuads(dbPath string, reader *nquads.Reader) (int64, error) {
+	if err := graph.InitQuadStore(storeBackend, dbPath, nil); err != nil {
+		return 0, fmt.Errorf("failed to initialize store at %s: %w", dbPath, err)
+	}
+
+	handle, err := cayley.NewGraph(storeBackend, dbPath, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open store at %s: %w", dbPath, err)
+	}
+
+	defer func(handle *cayley.Handle) {
+		_ = handle.Close()
+	}(handle)
+
+	var count int64
+	batch := make([]quad.Quad, 0, restoreBatchSize)
+
+	for {
+		q, err := reader.ReadQuad()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+
+		if q.IsValid() {
+			batch = append(batch, q)
+		}
+
+		if len(batch) == restoreBatchSize || errors.Is(err, io.EOF) && len(batch) > 0 {
+			if err := handle.AddQuadSet(batch); err != nil {
+				return 0, err
+			}
+			count += int64(len(batch))
+			batch = batch[:0]
+		}
+
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+	}
+}
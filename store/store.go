@@ -3,14 +3,18 @@ package store
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cayleygraph/cayley"
 	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/kv"
 	_ "github.com/cayleygraph/cayley/graph/kv/bolt"
 	"github.com/cayleygraph/cayley/schema"
 	"github.com/cayleygraph/quad"
@@ -25,6 +29,22 @@ const (
 	PredicateDependsOn      = "depends_on"
 )
 
+const storeBackend = "bolt"
+
+// Store errors
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrRuleInUse      = errors.New("rule in use")
+	ErrExists         = errors.New("already exists")
+	ErrInvalidPattern = errors.New("invalid pattern")
+	ErrInvalidLabel   = errors.New("invalid label")
+	ErrStoreLocked    = errors.New("store is locked by another process")
+	ErrStoreClosed    = errors.New("store is closed")
+
+	// errStopIteration ends an Each* iteration early without an error
+	errStopIteration = errors.New("stop iteration")
+)
+
 // NinjaBuild represents a build statement
 type NinjaBuild struct {
 	ID        quad.IRI `json:"@id" quad:"@id"`
@@ -33,6 +53,8 @@ type NinjaBuild struct {
 	Rule      quad.IRI `json:"rule" quad:"rule"`
 	Variables string   `json:"variables,omitempty" quad:"variables"`
 	Pool      string   `json:"pool,omitempty" quad:"pool"`
+	Owner     string   `json:"owner,omitempty" quad:"owner,optional"`
+	Labels    string   `json:"labels,omitempty" quad:"labels,optional"`
 }
 
 // NinjaFile represents source files and dependencies
@@ -49,7 +71,7 @@ type NinjaRule struct {
 	Type        quad.IRI `json:"@type" quad:"@type"`
 	Name        string   `json:"name" quad:"name"`
 	Command     string   `json:"command" quad:"command"`
-	Description string   `json:"description,omitempty" quad:"description"`
+	Description string   `json:"description,omitempty" quad:"description,optional"`
 	Variables   string   `json:"variables,omitempty" quad:"variables"`
 }
 
@@ -61,14 +83,70 @@ type NinjaTarget struct {
 	Status string   `json:"status" quad:"status"`
 	Hash   string   `json:"hash,omitempty" quad:"hash"`
 	Build  quad.IRI `json:"build" quad:"build"`
+	Owner  string   `json:"owner,omitempty" quad:"owner,optional"`
+	Labels string   `json:"labels,omitempty" quad:"labels,optional"`
+}
+
+// BuildSpec describes a build statement together with its edges
+type BuildSpec struct {
+	Build        *NinjaBuild
+	Inputs       []string
+	Outputs      []string
+	ImplicitDeps []string
+	OrderDeps    []string
+}
+
+// StoreInfo describes the backing quad store and the size of the graph
+type StoreInfo struct {
+	Path    string `json:"path"`
+	Backend string `json:"backend"`
+	Quads   int64  `json:"quads"`
+	Nodes   int64  `json:"nodes"`
+	Targets int64  `json:"targets"`
+	Rules   int64  `json:"rules"`
+	Builds  int64  `json:"builds"`
+}
+
+// QuadRecord is a quad with its values in N-Quads notation, so IRIs and strings stay distinguishable
+type QuadRecord struct {
+	Subject   string `json:"subject"`
+	Predicate string `json:"predicate"`
+	Object    string `json:"object"`
+	Label     string `json:"label,omitempty"`
+}
+
+// QuadPage is a page of quads in store order
+type QuadPage struct {
+	Quads   []*QuadRecord `json:"quads"`
+	Offset  int           `json:"offset"`
+	Limit   int           `json:"limit"`
+	HasMore bool          `json:"has_more"`
 }
 
 // NinjaStore implements Ninja build graph using Cayley
 type NinjaStore struct {
 	store  *cayley.Handle
 	schema *schema.Config
-	ctx    context.Context
 	dbPath string
+	// history bounds the status history of each target
+	history HistoryRetention
+	// counters back GetBuildStats
+	counters graphCounters
+	// cache holds recently loaded rules, builds and targets
+	cache *nodeCache
+	// paths indexes target paths for SearchTargets
+	paths pathIndex
+	// journal holds build writes until they are applied, replayed counts those a crash interrupted
+	journal  *writeJournal
+	replayed int
+	// lock keeps other processes from opening the store
+	lock *storeLock
+	// snapshotKey encrypts the snapshots written by Snapshot, nil writes them in the clear
+	snapshotKey []byte
+	// atRest keeps the store file encrypted, nil for stores in the clear
+	atRest *atRest
+	// revision is bumped by every write, see Revision
+	revision *storeRevision
 }
 
 // SetVariables converts map to JSON string
@@ -129,8 +207,82 @@ func (nr *NinjaRule) GetVariables() (map[string]string, error) {
 	return variables, err
 }
 
+// UndefinedVariables returns variables referenced by command that are neither ninja built-ins nor defined in scopes
+func UndefinedVariables(command string, scopes ...map[string]string) []string {
+	builtins := map[string]bool{"in": true, "in_newline": true, "out": true}
+
+	var undefined []string
+	seen := make(map[string]bool)
+
+	for i := 0; i < len(command); i++ {
+		if command[i] != '$' || i+1 >= len(command) {
+			continue
+		}
+
+		var name string
+		switch next := command[i+1]; {
+		case next == '{':
+			end := strings.IndexByte(command[i+2:], '}')
+			if end < 0 {
+				continue
+			}
+			name = command[i+2 : i+2+end]
+			i += end + 2
+		case next == '_' || next == '-' || next >= 'a' && next <= 'z' || next >= 'A' && next <= 'Z' || next >= '0' && next <= '9':
+			j := i + 1
+			for j < len(command) {
+				c := command[j]
+				if c != '_' && c != '-' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+					break
+				}
+				j++
+			}
+			name = command[i+1 : j]
+			i = j - 1
+		default:
+			// Escapes such as $$, "$ " and "$:"
+			i++
+			continue
+		}
+
+		if builtins[name] || seen[name] {
+			continue
+		}
+
+		defined := false
+		for _, scope := range scopes {
+			if _, ok := scope[name]; ok {
+				defined = true
+				break
+			}
+		}
+
+		if !defined {
+			seen[name] = true
+			undefined = append(undefined, name)
+		}
+	}
+
+	return undefined
+}
+
 // NewNinjaStore creates a new Cayley-based Ninja graph store
 func NewNinjaStore(dbPath string) (*NinjaStore, error) {
+	return openNinjaStore(dbPath, nil)
+}
+
+// NewEncryptedNinjaStore opens the store at dbPath encrypted at rest with key, an AES key of 16, 24 or
+// 32 bytes. A store written in the clear is encrypted when it is opened.
+func NewEncryptedNinjaStore(dbPath string, key []byte) (*NinjaStore, error) {
+	if key == nil {
+		return nil, errors.New("a key is required to encrypt the store")
+	}
+
+	return openNinjaStore(dbPath, key)
+}
+
+// openNinjaStore opens the store at dbPath, encrypted at rest with key unless it is nil
+func openNinjaStore(dbPath string, key []byte) (*NinjaStore, error) {
 	// Ensure the directory exists
 	dbDir := filepath.Dir(dbPath)
 	err := os.MkdirAll(dbDir, 0755)
@@ -138,44 +290,153 @@ func NewNinjaStore(dbPath string) (*NinjaStore, error) {
 		return nil, fmt.Errorf("failed to create database directory %s: %w", dbDir, err)
 	}
 
+	// Bolt files opened by two processes get corrupted, hold the lock before creating or opening it
+	lock, err := lockStore(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypted stores are opened decrypted in a private directory
+	var rest *atRest
+	openPath := dbPath
+
+	if key != nil {
+		rest, err = openAtRest(dbPath, key)
+		if err != nil {
+			lock.release()
+			return nil, err
+		}
+		openPath = rest.workPath
+	} else if IsEncryptedStore(dbPath) {
+		lock.release()
+		return nil, fmt.Errorf("%w: %s", ErrStoreEncrypted, dbPath)
+	}
+
 	// Check if database exists, if not initialize it
 	var store *cayley.Handle
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+	if _, err := os.Stat(openPath); os.IsNotExist(err) {
 		// Initialize new database
-		err = graph.InitQuadStore("bolt", dbPath, nil)
+		err = graph.InitQuadStore(storeBackend, openPath, nil)
 		if err != nil {
+			discardAtRest(rest)
+			lock.release()
 			return nil, fmt.Errorf("failed to initialize store at %s: %w", dbPath, err)
 		}
 	}
 
 	// Open the database
-	store, err = cayley.NewGraph("bolt", dbPath, nil)
+	store, err = cayley.NewGraph(storeBackend, openPath, nil)
 	if err != nil {
+		discardAtRest(rest)
+		lock.release()
 		return nil, fmt.Errorf("failed to open store at %s: %w", dbPath, err)
 	}
 
-	// Register types
-	schema.RegisterType("NinjaRule", NinjaRule{})
-	schema.RegisterType("NinjaBuild", NinjaBuild{})
-	schema.RegisterType("NinjaTarget", NinjaTarget{})
-	schema.RegisterType("NinjaFile", NinjaFile{})
+	// Register types, registering them again panics
+	registerTypes.Do(func() {
+		schema.RegisterType("NinjaRule", NinjaRule{})
+		schema.RegisterType("NinjaBuild", NinjaBuild{})
+		schema.RegisterType("NinjaTarget", NinjaTarget{})
+		schema.RegisterType("NinjaFile", NinjaFile{})
+		schema.RegisterType("NinjaRoleBinding", NinjaRoleBinding{})
+	})
 
 	// Configure schema
 	schemaConfig := schema.NewConfig()
 
-	ctx := context.Background()
+	ncs := &NinjaStore{
+		store:    store,
+		schema:   schemaConfig,
+		dbPath:   dbPath,
+		cache:    newNodeCache(defaultCacheSize),
+		lock:     lock,
+		revision: newStoreRevision(),
+	}
+
+	// Every write goes through the handle's writer, which keeps the stats counters, the cache and the path
+	// index
+	store.QuadWriter = &countingWriter{QuadWriter: store.QuadWriter, ncs: ncs}
+
+	ncs.journal, err = newWriteJournal(dbPath, key)
+	if err != nil {
+		_ = ncs.Close()
+		discardAtRest(rest)
+		return nil, err
+	}
+
+	ncs.replayed, err = ncs.replayJournal(context.Background())
+	if err != nil {
+		_ = ncs.Close()
+		discardAtRest(rest)
+		return nil, err
+	}
+
+	// The replayed writes and a store written in the clear are encrypted right away
+	if rest != nil {
+		ncs.atRest = rest
+		if err := ncs.checkpoint(); err != nil {
+			_ = ncs.Close()
+			return nil, err
+		}
+	}
 
-	return &NinjaStore{
-		store:  store,
-		schema: schemaConfig,
-		ctx:    ctx,
-		dbPath: dbPath,
-	}, nil
+	return ncs, nil
 }
 
-// Close closes the Cayley store
+// Close closes the Cayley store and releases its lock. Stores encrypted at rest are encrypted once the
+// file is closed, the decrypted copy is removed even if that fails, the journal still holds the writes.
 func (ncs *NinjaStore) Close() error {
-	return ncs.store.Close()
+	err := ncs.store.Close()
+
+	if ncs.atRest != nil {
+		if err == nil {
+			err = ncs.checkpoint()
+		}
+		ncs.atRest.discard()
+	}
+
+	ncs.lock.release()
+
+	return err
+}
+
+// registerTypes registers the node types with the schema package once per process
+var registerTypes sync.Once
+
+func discardAtRest(rest *atRest) {
+	if rest != nil {
+		rest.discard()
+	}
+}
+
+// Info returns the store location and graph counts, quad and node counts may be estimates
+func (ncs *NinjaStore) Info(ctx context.Context) (*StoreInfo, error) {
+	// Stores without writes have no size yet
+	stats, err := ncs.store.Stats(ctx, false)
+	if err != nil && !errors.Is(err, kv.ErrNoBucket) {
+		return nil, fmt.Errorf("failed to get store stats: %w", err)
+	}
+
+	info := &StoreInfo{
+		Path:    ncs.dbPath,
+		Backend: storeBackend,
+		Quads:   stats.Quads.Size,
+		Nodes:   stats.Nodes.Size,
+	}
+
+	for typeName, count := range map[string]*int64{
+		"NinjaTarget": &info.Targets,
+		"NinjaRule":   &info.Rules,
+		"NinjaBuild":  &info.Builds,
+	} {
+		subjects, err := ncs.typeSubjects(ctx, typeName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", typeName, err)
+		}
+		*count = int64(len(subjects))
+	}
+
+	return info, nil
 }
 
 func (ncs *NinjaStore) Cleanup() error {
@@ -184,11 +445,48 @@ func (ncs *NinjaStore) Cleanup() error {
 }
 
 // AddRule adds a build rule to the graph
-func (ncs *NinjaStore) AddRule(rule *NinjaRule) (quad.Value, error) {
-	qw := graph.NewWriter(ncs.store)
-	defer func(qw graph.BatchWriter) {
-		_ = qw.Close()
-	}(qw)
+func (ncs *NinjaStore) AddRule(ctx context.Context, rule *NinjaRule) (quad.Value, error) {
+	buf := &quadBuffer{}
+	id, err := ncs.writeRule(buf, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ncs.applyJournaled(ctx, buf.quads); err != nil {
+		return nil, fmt.Errorf("failed to apply rule: %w", err)
+	}
+
+	return id, nil
+}
+
+// AddRules adds rules in a single transaction, returning one error slot per rule
+func (ncs *NinjaStore) AddRules(ctx context.Context, rules []*NinjaRule) ([]error, error) {
+	tx := graph.NewTransaction()
+	results := make([]error, len(rules))
+
+	for i, rule := range rules {
+		buf := &quadBuffer{}
+		if _, err := ncs.writeRule(buf, rule); err != nil {
+			results[i] = err
+			continue
+		}
+		for _, q := range buf.quads {
+			tx.AddQuad(q)
+		}
+	}
+
+	if err := ncs.store.ApplyTransaction(tx); err != nil {
+		return nil, fmt.Errorf("failed to apply rules: %w", err)
+	}
+
+	return results, nil
+}
+
+// writeRule writes rule quads to qw
+func (ncs *NinjaStore) writeRule(qw quad.Writer, rule *NinjaRule) (quad.Value, error) {
+	if rule.Name == "" {
+		return nil, fmt.Errorf("rule name is required")
+	}
 
 	rule.ID = quad.IRI(fmt.Sprintf("rule:%s", rule.Name))
 	rule.Type = "NinjaRule"
@@ -202,10 +500,10 @@ func (ncs *NinjaStore) AddRule(rule *NinjaRule) (quad.Value, error) {
 }
 
 // GetRule retrieves a rule by name
-func (ncs *NinjaStore) GetRule(name string) (*NinjaRule, error) {
+func (ncs *NinjaStore) GetRule(ctx context.Context, name string) (*NinjaRule, error) {
 	var rule NinjaRule
 
-	err := ncs.schema.LoadTo(ncs.ctx, ncs.store, &rule, quad.IRI(fmt.Sprintf("rule:%s", name)))
+	err := ncs.loadNode(ctx, &rule, quad.IRI(fmt.Sprintf("rule:%s", name)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load rule %s: %w", name, err)
 	}
@@ -213,12 +511,130 @@ func (ncs *NinjaStore) GetRule(name string) (*NinjaRule, error) {
 	return &rule, nil
 }
 
+// UpdateRule replaces command, description and variables of an existing rule
+func (ncs *NinjaStore) UpdateRule(ctx context.Context, rule *NinjaRule) error {
+	ruleIRI := quad.IRI(fmt.Sprintf("rule:%s", rule.Name))
+
+	old, err := ncs.subjectQuads(ctx, ruleIRI)
+	if err != nil {
+		return fmt.Errorf("failed to load rule %s: %w", rule.Name, err)
+	}
+
+	if len(old) == 0 {
+		return fmt.Errorf("rule %s: %w", rule.Name, ErrNotFound)
+	}
+
+	tx := graph.NewTransaction()
+	for _, q := range old {
+		tx.RemoveQuad(q)
+	}
+
+	rule.ID = ruleIRI
+	rule.Type = "NinjaRule"
+
+	id, err := ncs.schema.WriteAsQuads(graph.NewTxWriter(tx, graph.Add), rule)
+	if err != nil || id != rule.ID {
+		return fmt.Errorf("failed to write rule: %w", err)
+	}
+
+	return ncs.store.ApplyTransaction(tx)
+}
+
+// DeleteRule removes a rule, refusing to do so while builds reference it unless force is set
+func (ncs *NinjaStore) DeleteRule(ctx context.Context, name string, force bool) error {
+	ruleIRI := quad.IRI(fmt.Sprintf("rule:%s", name))
+
+	old, err := ncs.subjectQuads(ctx, ruleIRI)
+	if err != nil {
+		return fmt.Errorf("failed to load rule %s: %w", name, err)
+	}
+
+	if len(old) == 0 {
+		return fmt.Errorf("rule %s: %w", name, ErrNotFound)
+	}
+
+	if !force {
+		builds, err := ncs.GetBuildsByRule(ctx, name)
+		if err != nil {
+			return err
+		}
+		if len(builds) > 0 {
+			return fmt.Errorf("rule %s is referenced by %d builds: %w", name, len(builds), ErrRuleInUse)
+		}
+	}
+
+	tx := graph.NewTransaction()
+	for _, q := range old {
+		tx.RemoveQuad(q)
+	}
+
+	return ncs.store.ApplyTransaction(tx)
+}
+
+// GetBuildsByRule returns all builds referencing a rule
+func (ncs *NinjaStore) GetBuildsByRule(ctx context.Context, ruleName string) ([]*NinjaBuild, error) {
+	refs, err := ncs.objectQuads(ctx, quad.IRI(fmt.Sprintf("rule:%s", ruleName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get builds for rule %s: %w", ruleName, err)
+	}
+
+	var builds []*NinjaBuild
+
+	for _, q := range refs {
+		if q.Predicate != quad.IRI("rule") {
+			continue
+		}
+
+		var build NinjaBuild
+		if err := ncs.loadTo(ctx, &build, q.Subject); err != nil {
+			continue // Skip builds we can't load
+		}
+		builds = append(builds, &build)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return builds, nil
+}
+
 // AddBuild adds a build statement to the graph
-func (ncs *NinjaStore) AddBuild(build *NinjaBuild, inputs, outputs, implicitDeps, orderDeps []string) error {
-	qw := graph.NewWriter(ncs.store)
-	defer func(qw graph.BatchWriter) {
-		_ = qw.Close()
-	}(qw)
+func (ncs *NinjaStore) AddBuild(ctx context.Context, build *NinjaBuild, inputs, outputs, implicitDeps, orderDeps []string) error {
+	buf := &quadBuffer{}
+	if err := ncs.writeBuild(buf, build, inputs, outputs, implicitDeps, orderDeps); err != nil {
+		return err
+	}
+
+	return ncs.applyJournaled(ctx, buf.quads)
+}
+
+// AddBuilds adds builds in a single transaction, returning one error slot per build
+func (ncs *NinjaStore) AddBuilds(ctx context.Context, specs []*BuildSpec) ([]error, error) {
+	buf := &quadBuffer{}
+	results := make([]error, len(specs))
+
+	for i, spec := range specs {
+		written := len(buf.quads)
+		if err := ncs.writeBuild(buf, spec.Build, spec.Inputs, spec.Outputs, spec.ImplicitDeps, spec.OrderDeps); err != nil {
+			// Drop what the failed build wrote before its error
+			buf.quads = buf.quads[:written]
+			results[i] = err
+		}
+	}
+
+	if err := ncs.applyJournaled(ctx, buf.quads); err != nil {
+		return nil, fmt.Errorf("failed to apply builds: %w", err)
+	}
+
+	return results, nil
+}
+
+// writeBuild writes build, target and file quads to qw, the outputs get the owner and labels of build
+func (ncs *NinjaStore) writeBuild(qw quad.Writer, build *NinjaBuild, inputs, outputs, implicitDeps, orderDeps []string) error {
+	if build == nil || build.BuildID == "" {
+		return fmt.Errorf("build id is required")
+	}
 
 	// Set build metadata
 	build.ID = quad.IRI(fmt.Sprintf("build:%s", build.BuildID))
@@ -241,6 +657,8 @@ func (ncs *NinjaStore) AddBuild(build *NinjaBuild, inputs, outputs, implicitDeps
 			Status: "clean",
 			Hash:   "none",
 			Build:  build.ID,
+			Owner:  build.Owner,
+			Labels: build.Labels,
 		}
 
 		id, err := ncs.schema.WriteAsQuads(qw, target)
@@ -323,10 +741,10 @@ func (ncs *NinjaStore) AddBuild(build *NinjaBuild, inputs, outputs, implicitDeps
 }
 
 // GetBuild retrieves a build by name
-func (ncs *NinjaStore) GetBuild(id string) (*NinjaBuild, error) {
+func (ncs *NinjaStore) GetBuild(ctx context.Context, id string) (*NinjaBuild, error) {
 	var build NinjaBuild
 
-	err := ncs.schema.LoadTo(ncs.ctx, ncs.store, &build, quad.IRI(fmt.Sprintf("build:%s", id)))
+	err := ncs.loadNode(ctx, &build, quad.IRI(fmt.Sprintf("build:%s", id)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load build %s: %w", id, err)
 	}
@@ -334,10 +752,40 @@ func (ncs *NinjaStore) GetBuild(id string) (*NinjaBuild, error) {
 	return &build, nil
 }
 
+// DeleteBuild removes a build and the targets it outputs, its input files are kept
+func (ncs *NinjaStore) DeleteBuild(ctx context.Context, id string) error {
+	buildIRI := quad.IRI(fmt.Sprintf("build:%s", id))
+
+	old, err := ncs.subjectQuads(ctx, buildIRI)
+	if err != nil {
+		return fmt.Errorf("failed to load build %s: %w", id, err)
+	}
+
+	if len(old) == 0 {
+		return fmt.Errorf("build %s: %w", id, ErrNotFound)
+	}
+
+	tx := graph.NewTransaction()
+
+	for _, q := range old {
+		if q.Predicate == quad.String(PredicateHasOutput) {
+			if err := ncs.removeNode(ctx, tx, q.Object); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := ncs.removeNode(ctx, tx, buildIRI); err != nil {
+		return err
+	}
+
+	return ncs.store.ApplyTransaction(tx)
+}
+
 // GetTarget retrieves a target by path
-func (ncs *NinjaStore) GetTarget(path string) (*NinjaTarget, error) {
+func (ncs *NinjaStore) GetTarget(ctx context.Context, path string) (*NinjaTarget, error) {
 	var target NinjaTarget
-	err := ncs.schema.LoadTo(ncs.ctx, ncs.store, &target, quad.IRI(fmt.Sprintf("target:%s", path)))
+	err := ncs.loadNode(ctx, &target, quad.IRI(fmt.Sprintf("target:%s", path)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load target %s: %w", path, err)
 	}
@@ -346,12 +794,12 @@ func (ncs *NinjaStore) GetTarget(path string) (*NinjaTarget, error) {
 }
 
 // GetBuildDependencies returns all dependencies of a target
-func (ncs *NinjaStore) GetBuildDependencies(targetPath string) ([]*NinjaFile, error) {
+func (ncs *NinjaStore) GetBuildDependencies(ctx context.Context, targetPath string) ([]*NinjaFile, error) {
 	targetIRI := quad.IRI(fmt.Sprintf("target:%s", targetPath))
 
 	// Debug: First check if the target exists
 	var target NinjaTarget
-	err := ncs.schema.LoadTo(ncs.ctx, ncs.store, &target, targetIRI)
+	err := ncs.loadNode(ctx, &target, targetIRI)
 	if err != nil {
 		return nil, fmt.Errorf("target %s not found: %w", targetPath, err)
 	}
@@ -361,7 +809,7 @@ func (ncs *NinjaStore) GetBuildDependencies(targetPath string) ([]*NinjaFile, er
 
 	// Load the build object
 	var build NinjaBuild
-	err = ncs.schema.LoadTo(ncs.ctx, ncs.store, &build, buildIRI)
+	err = ncs.loadNode(ctx, &build, buildIRI)
 	if err != nil {
 		return nil, fmt.Errorf("build %s not found: %w", buildIRI, err)
 	}
@@ -370,12 +818,12 @@ func (ncs *NinjaStore) GetBuildDependencies(targetPath string) ([]*NinjaFile, er
 	var dependencies []*NinjaFile
 
 	// Query for input files
-	inputsIt := ncs.store.QuadsAllIterator()
+	inputsIt := ncs.allQuadsIterator()
 	defer func(inputsIt graph.Iterator) {
 		_ = inputsIt.Close()
 	}(inputsIt)
 
-	for inputsIt.Next(ncs.ctx) {
+	for inputsIt.Next(ctx) {
 		result := inputsIt.Result()
 		if result == nil {
 			continue
@@ -391,7 +839,7 @@ func (ncs *NinjaStore) GetBuildDependencies(targetPath string) ([]*NinjaFile, er
 		if q.Subject == buildIRI && q.Predicate == quad.String(PredicateHasInput) {
 			// Load the file object
 			var file NinjaFile
-			err := ncs.schema.LoadTo(ncs.ctx, ncs.store, &file, q.Object)
+			err := ncs.loadTo(ctx, &file, q.Object)
 			if err != nil {
 				continue // Skip if we can't load the file
 			}
@@ -402,7 +850,7 @@ func (ncs *NinjaStore) GetBuildDependencies(targetPath string) ([]*NinjaFile, er
 		if q.Subject == buildIRI && q.Predicate == quad.String(PredicateHasImplicitDep) {
 			// Load the file object
 			var file NinjaFile
-			err := ncs.schema.LoadTo(ncs.ctx, ncs.store, &file, q.Object)
+			err := ncs.loadTo(ctx, &file, q.Object)
 			if err != nil {
 				continue // Skip if we can't load the file
 			}
@@ -418,14 +866,14 @@ func (ncs *NinjaStore) GetBuildDependencies(targetPath string) ([]*NinjaFile, er
 }
 
 // GetReverseDependencies returns all targets that depend on a file
-func (ncs *NinjaStore) GetReverseDependencies(filePath string) ([]*NinjaTarget, error) {
+func (ncs *NinjaStore) GetReverseDependencies(ctx context.Context, filePath string) ([]*NinjaTarget, error) {
 	// Query for all targets that depend on this file
 	// Use quad.String instead of quad.IRI for the predicate
 	p := cayley.StartPath(ncs.store, quad.IRI(fmt.Sprintf("file:%s", filePath))).
 		In(quad.String(PredicateDependsOn))
 
 	var dependents []NinjaTarget
-	err := ncs.schema.LoadPathTo(ncs.ctx, ncs.store, &dependents, p)
+	err := ncs.schema.LoadPathTo(ctx, ncs.store, &dependents, p)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reverse dependencies for %s: %w", filePath, err)
 	}
@@ -435,103 +883,51 @@ func (ncs *NinjaStore) GetReverseDependencies(filePath string) ([]*NinjaTarget,
 		result = append(result, &dependents[i])
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
-// GetBuildStats returns statistics about the build graph
-func (ncs *NinjaStore) GetBuildStats() (map[string]interface{}, error) {
-	if ncs == nil || ncs.store == nil || ncs.ctx == nil {
+// GetBuildStats returns statistics about the build graph from the counters kept by writes, the first
+// call counts the quads of the store
+func (ncs *NinjaStore) GetBuildStats(ctx context.Context) (map[string]interface{}, error) {
+	if ncs == nil || ncs.store == nil {
 		return nil, fmt.Errorf("invalid store or context")
 	}
 
-	stats := make(map[string]interface{})
-
-	// Count by iterating through all quads and checking types manually
-	it := ncs.store.QuadsAllIterator()
-	if it == nil {
-		return nil, fmt.Errorf("failed to create iterator")
+	counts, err := ncs.graphCounts(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	defer func(it graph.Iterator) {
-		_ = it.Close()
-	}(it)
-
-	rulesCount := 0
-	buildsCount := 0
-	targetsCount := 0
-	filesCount := 0
-	quadCount := 0
-	relationshipCount := 0
-
-	seenObjects := make(map[string]bool) // Track unique objects by type
-
-	for it.Next(ncs.ctx) {
-		result := it.Result()
-		if result == nil {
-			continue
-		}
-
-		q := ncs.store.Quad(result)
-		if q.Subject == nil || q.Predicate == nil || q.Object == nil {
-			continue
-		}
-
-		quadCount++
-
-		// Check for type declarations
-		if q.Predicate.String() == `<rdf:type>` {
-			objectType := q.Object.String()
-			subject := q.Subject.String()
-
-			// Only count each object once
-			key := subject + ":" + objectType
-			if !seenObjects[key] {
-				seenObjects[key] = true
-
-				switch objectType {
-				case `<NinjaRule>`:
-					rulesCount++
-				case `<NinjaBuild>`:
-					buildsCount++
-				case `<NinjaTarget>`:
-					targetsCount++
-				case `<NinjaFile>`:
-					filesCount++
-				}
-			}
-		}
-
-		// Count relationship predicates
-		predicate := q.Predicate.String()
-		if predicate == `"`+PredicateHasInput+`"` ||
-			predicate == `"`+PredicateHasOutput+`"` ||
-			predicate == `"`+PredicateHasImplicitDep+`"` ||
-			predicate == `"`+PredicateHasOrderDep+`"` ||
-			predicate == `"`+PredicateDependsOn+`"` {
-			relationshipCount++
-		}
+	// Stores without writes have no size yet
+	storeStats, err := ncs.store.Stats(ctx, false)
+	if err != nil && !errors.Is(err, kv.ErrNoBucket) {
+		return nil, fmt.Errorf("failed to get store stats: %w", err)
 	}
 
-	if err := it.Err(); err != nil {
-		return nil, fmt.Errorf("failed to iterate quads: %w", err)
+	stats := make(map[string]interface{}, len(counts)+1)
+	for key, n := range counts {
+		stats[key] = n
 	}
-
-	stats["rules"] = rulesCount
-	stats["builds"] = buildsCount
-	stats["targets"] = targetsCount
-	stats["files"] = filesCount
-	stats["total_quads"] = quadCount
-	stats["relationships"] = relationshipCount
+	stats["total_quads"] = storeStats.Quads.Size
 
 	return stats, nil
 }
 
 // GetBuildOrder returns targets in topological order
-func (ncs *NinjaStore) GetBuildOrder() ([]string, error) {
+func (ncs *NinjaStore) GetBuildOrder(ctx context.Context) ([]string, error) {
+	return ncs.GetBuildOrderWithPolicy(ctx, &FIFOPolicy{})
+}
+
+// GetBuildOrderWithPolicy returns targets in topological order, breaking ties between ready targets with policy
+func (ncs *NinjaStore) GetBuildOrderWithPolicy(ctx context.Context, policy SchedulingPolicy) ([]string, error) {
 	// Get all targets
 	var allTargets []*NinjaTarget
 
-	allTargets, err := ncs.GetAllTargets()
+	allTargets, err := ncs.GetAllTargets(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all targets: %w", err)
 	}
@@ -552,7 +948,7 @@ func (ncs *NinjaStore) GetBuildOrder() ([]string, error) {
 
 	// Populate dependencies
 	for _, target := range allTargets {
-		deps, err := ncs.GetBuildDependencies(target.Path)
+		deps, err := ncs.GetBuildDependencies(ctx, target.Path)
 		if err != nil {
 			continue // Skip targets we can't get dependencies for
 		}
@@ -567,29 +963,34 @@ func (ncs *NinjaStore) GetBuildOrder() ([]string, error) {
 		}
 	}
 
-	// Topological sort using Kahn's algorithm
-	var queue []string
+	// Dependencies skipped because the request gave up would look like missing edges
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	policy.Init(g)
+
+	// Topological sort using Kahn's algorithm, the policy holds the ready targets
 	var result []string
 
 	// Find all nodes with no incoming edges
 	for target, degree := range inDegree {
 		if degree == 0 {
-			queue = append(queue, target)
+			policy.Push(target)
 		}
 	}
 
 	// Process queue
-	for len(queue) > 0 {
-		// Remove first element from queue
-		current := queue[0]
-		queue = queue[1:]
+	for policy.Len() > 0 {
+		// Take the element chosen by the policy
+		current := policy.Pop()
 		result = append(result, current)
 
 		// For each neighbor of current
 		for _, neighbor := range g[current] {
 			inDegree[neighbor]--
 			if inDegree[neighbor] == 0 {
-				queue = append(queue, neighbor)
+				policy.Push(neighbor)
 			}
 		}
 	}
@@ -603,19 +1004,19 @@ func (ncs *NinjaStore) GetBuildOrder() ([]string, error) {
 }
 
 // GetTargetsByRule returns all targets built by a specific rule
-func (ncs *NinjaStore) GetTargetsByRule(ruleName string) ([]*NinjaTarget, error) {
+func (ncs *NinjaStore) GetTargetsByRule(ctx context.Context, ruleName string) ([]*NinjaTarget, error) {
 	ruleIRI := quad.IRI(fmt.Sprintf("rule:%s", ruleName))
 	var targets []*NinjaTarget
 
 	// Find all builds that use this rule
-	it := ncs.store.QuadsAllIterator()
+	it := ncs.allQuadsIterator()
 	defer func(it graph.Iterator) {
 		_ = it.Close()
 	}(it)
 
 	var buildIRIs []quad.Value
 
-	for it.Next(ncs.ctx) {
+	for it.Next(ctx) {
 		result := it.Result()
 		if result == nil {
 			continue
@@ -639,9 +1040,9 @@ func (ncs *NinjaStore) GetTargetsByRule(ruleName string) ([]*NinjaTarget, error)
 	// For each build, find its output targets
 	for _, buildIRI := range buildIRIs {
 		// Find targets that are outputs of this build
-		it := ncs.store.QuadsAllIterator()
+		it := ncs.allQuadsIterator()
 
-		for it.Next(ncs.ctx) {
+		for it.Next(ctx) {
 			result := it.Result()
 			if result == nil {
 				continue
@@ -656,7 +1057,7 @@ func (ncs *NinjaStore) GetTargetsByRule(ruleName string) ([]*NinjaTarget, error)
 			if q.Subject == buildIRI && q.Predicate.String() == `"`+PredicateHasOutput+`"` {
 				// Load the target
 				var target NinjaTarget
-				err := ncs.schema.LoadTo(ncs.ctx, ncs.store, &target, q.Object)
+				err := ncs.loadNode(ctx, &target, q.Object)
 				if err != nil {
 					continue // Skip targets we can't load
 				}
@@ -672,23 +1073,35 @@ func (ncs *NinjaStore) GetTargetsByRule(ruleName string) ([]*NinjaTarget, error)
 		_ = it.Close()
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return targets, nil
 }
 
-// UpdateTargetStatus updates the status of a target
-func (ncs *NinjaStore) UpdateTargetStatus(targetPath, status string) error {
+// UpdateTargetStatus sets the status of a target and appends the change to its history, origin may
+// be nil
+func (ncs *NinjaStore) UpdateTargetStatus(ctx context.Context, targetPath, status string, origin *StatusOrigin) error {
 	tx := graph.NewTransaction()
 
 	targetIRI := quad.IRI(fmt.Sprintf("target:%s", targetPath))
 
+	change := &NinjaStatusChange{Target: targetPath, Status: status}
+	if origin != nil {
+		change.RunID = origin.RunID
+		change.Worker = origin.Worker
+		change.Message = origin.Message
+	}
+
 	// Remove old status - iterate through quads to find status ones
-	it := ncs.store.QuadsAllIterator()
+	it := ncs.allQuadsIterator()
 
 	defer func(it graph.Iterator) {
 		_ = it.Close()
 	}(it)
 
-	for it.Next(ncs.ctx) {
+	for it.Next(ctx) {
 		ref := it.Result()
 		if ref == nil {
 			continue
@@ -701,6 +1114,11 @@ func (ncs *NinjaStore) UpdateTargetStatus(targetPath, status string) error {
 
 		if q.Subject == targetIRI && q.Predicate == quad.IRI("status") {
 			tx.RemoveQuad(q)
+			change.PreviousStatus = quad.ToString(q.Object)
+		}
+
+		if q.Subject == targetIRI && q.Predicate == quad.IRI("last_modified") {
+			tx.RemoveQuad(q)
 		}
 	}
 
@@ -712,92 +1130,168 @@ func (ncs *NinjaStore) UpdateTargetStatus(targetPath, status string) error {
 	tx.AddQuad(quad.Make(targetIRI, quad.IRI("status"), quad.String(status), nil))
 	tx.AddQuad(quad.Make(targetIRI, quad.IRI("last_modified"), quad.Time(time.Now()), nil))
 
+	if err := ncs.addStatusChange(tx, change); err != nil {
+		return err
+	}
+
+	if err := ncs.store.ApplyTransaction(tx); err != nil {
+		return err
+	}
+
+	return ncs.pruneHistory(ctx, targetPath)
+}
+
+// DeleteTarget removes a target and the links of its build to it
+func (ncs *NinjaStore) DeleteTarget(ctx context.Context, path string) error {
+	targetIRI := quad.IRI(fmt.Sprintf("target:%s", path))
+
+	old, err := ncs.subjectQuads(ctx, targetIRI)
+	if err != nil {
+		return fmt.Errorf("failed to load target %s: %w", path, err)
+	}
+
+	if len(old) == 0 {
+		return fmt.Errorf("target %s: %w", path, ErrNotFound)
+	}
+
+	tx := graph.NewTransaction()
+	if err := ncs.removeNode(ctx, tx, targetIRI); err != nil {
+		return err
+	}
+
 	return ncs.store.ApplyTransaction(tx)
 }
 
-// FindCycles detects circular dependencies in the build graph
-func (ncs *NinjaStore) FindCycles() ([][]string, error) {
-	targets, err := ncs.GetAllTargets()
+// FindCycles detects circular dependencies in the build graph. The targets are walked depth first
+// without recursion over the depends_on edges read in a single pass, so deep graphs don't exhaust the
+// stack. With firstOnly it stops at the first cycle, enough to tell whether the graph is acyclic.
+func (ncs *NinjaStore) FindCycles(ctx context.Context, firstOnly bool) ([][]string, error) {
+	deps, err := ncs.targetDependencies(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get targets: %w", err)
+		return nil, err
+	}
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type frame struct {
+		target string
+		next   int
 	}
 
-	visited := make(map[string]int) // 0: unvisited, 1: visiting, 2: visited
+	// depth holds the stack index of the targets being visited, done the targets fully explored
+	depth := make(map[string]int)
+	done := make(map[string]bool)
 	var cycles [][]string
-	var currentPath []string
-
-	var dfs func(string) error
-	dfs = func(target string) error {
-		if visited[target] == 1 {
-			// Found cycle
-			cycleStart := -1
-			for i, path := range currentPath {
-				if path == target {
-					cycleStart = i
-					break
-				}
-			}
-			if cycleStart >= 0 {
-				cycle := make([]string, len(currentPath[cycleStart:]))
-				copy(cycle, currentPath[cycleStart:])
-				cycles = append(cycles, cycle)
-			}
-			return nil
-		}
 
-		if visited[target] == 2 {
-			return nil
+	for _, name := range names {
+		if done[name] {
+			continue
 		}
 
-		visited[target] = 1
-		currentPath = append(currentPath, target)
+		stack := []frame{{target: name}}
+		depth[name] = 0
 
-		deps, err := ncs.GetBuildDependencies(target)
-		if err != nil {
-			return err
-		}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			children := deps[top.target]
 
-		for _, dep := range deps {
-			// Only follow dependencies that are also targets
-			if _, exists := visited[dep.Path]; exists {
-				err = dfs(dep.Path)
-				if err != nil {
-					return err
+			if top.next == len(children) {
+				delete(depth, top.target)
+				done[top.target] = true
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			dep := children[top.next]
+			top.next++
+
+			if done[dep] {
+				continue
+			}
+
+			if start, ok := depth[dep]; ok {
+				cycle := make([]string, 0, len(stack)-start)
+				for _, f := range stack[start:] {
+					cycle = append(cycle, f.target)
+				}
+				cycles = append(cycles, cycle)
+
+				if firstOnly {
+					return cycles, nil
 				}
+				continue
 			}
+
+			depth[dep] = len(stack)
+			stack = append(stack, frame{target: dep})
 		}
+	}
 
-		visited[target] = 2
-		currentPath = currentPath[:len(currentPath)-1]
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-		return nil
+	return cycles, nil
+}
+
+// targetDependencies maps each target to the targets among its explicit inputs, in name order
+func (ncs *NinjaStore) targetDependencies(ctx context.Context) (map[string][]string, error) {
+	targets, err := ncs.typeSubjects(ctx, "NinjaTarget")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get targets: %w", err)
 	}
 
+	deps := make(map[string][]string, len(targets))
 	for _, target := range targets {
-		if visited[target.Path] == 0 {
-			err = dfs(target.Path)
-			if err != nil {
-				return nil, err
-			}
+		if iri, ok := target.(quad.IRI); ok {
+			deps[strings.TrimPrefix(string(iri), "target:")] = nil
 		}
 	}
 
-	return cycles, nil
+	quads, err := ncs.directionQuads(ctx, quad.Predicate, quad.String(PredicateDependsOn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependencies: %w", err)
+	}
+
+	for _, q := range quads {
+		subject, _ := q.Subject.(quad.IRI)
+		object, _ := q.Object.(quad.IRI)
+
+		source := strings.TrimPrefix(string(subject), "target:")
+		dep := strings.TrimPrefix(string(object), "file:")
+
+		if _, ok := deps[source]; !ok {
+			continue
+		}
+		if _, ok := deps[dep]; ok {
+			deps[source] = append(deps[source], dep)
+		}
+	}
+
+	for _, children := range deps {
+		sort.Strings(children)
+	}
+
+	return deps, nil
 }
 
 // GetAllTargets returns all targets in the graph
-func (ncs *NinjaStore) GetAllTargets() ([]*NinjaTarget, error) {
+func (ncs *NinjaStore) GetAllTargets(ctx context.Context) ([]*NinjaTarget, error) {
 	var targets []*NinjaTarget
 
 	// Iterate through all quads to find targets
-	it := ncs.store.QuadsAllIterator()
+	it := ncs.allQuadsIterator()
 	defer func(it graph.Iterator) {
 		_ = it.Close()
 	}(it)
 
 	targetIRIs := make(map[quad.Value]bool)
 
-	for it.Next(ncs.ctx) {
+	for it.Next(ctx) {
 		result := it.Result()
 		if result == nil {
 			continue
@@ -821,19 +1315,145 @@ func (ncs *NinjaStore) GetAllTargets() ([]*NinjaTarget, error) {
 	// Load each target
 	for targetIRI := range targetIRIs {
 		var target NinjaTarget
-		err := ncs.schema.LoadTo(ncs.ctx, ncs.store, &target, targetIRI)
+		err := ncs.loadTo(ctx, &target, targetIRI)
 		if err != nil {
 			continue // Skip targets we can't load
 		}
 		targets = append(targets, &target)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// GetAllBuilds returns all build statements
+func (ncs *NinjaStore) GetAllBuilds(ctx context.Context) ([]*NinjaBuild, error) {
+	ids, err := ncs.typeSubjects(ctx, "NinjaBuild")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list builds: %w", err)
+	}
+
+	var builds []*NinjaBuild
+
+	for _, id := range ids {
+		var build NinjaBuild
+		if err := ncs.loadTo(ctx, &build, id); err != nil {
+			continue // Skip builds we can't load
+		}
+		builds = append(builds, &build)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return builds, nil
+}
+
+// GetAllRules returns all rules
+func (ncs *NinjaStore) GetAllRules(ctx context.Context) ([]*NinjaRule, error) {
+	ids, err := ncs.typeSubjects(ctx, "NinjaRule")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+
+	var rules []*NinjaRule
+
+	for _, id := range ids {
+		var rule NinjaRule
+		if err := ncs.loadTo(ctx, &rule, id); err != nil {
+			continue // Skip rules we can't load
+		}
+		rules = append(rules, &rule)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// GetFile retrieves a file by path
+func (ncs *NinjaStore) GetFile(ctx context.Context, path string) (*NinjaFile, error) {
+	var file NinjaFile
+
+	err := ncs.loadTo(ctx, &file, quad.IRI(fmt.Sprintf("file:%s", path)))
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %w", path, ErrNotFound)
+	}
+
+	return &file, nil
+}
+
+// GetBuildFiles returns the files linked to a build by predicate, one of the has_* predicates
+func (ncs *NinjaStore) GetBuildFiles(ctx context.Context, buildID, predicate string) ([]*NinjaFile, error) {
+	links, err := ncs.subjectQuads(ctx, quad.IRI(fmt.Sprintf("build:%s", buildID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get files of build %s: %w", buildID, err)
+	}
+
+	var files []*NinjaFile
+
+	for _, q := range links {
+		if q.Predicate != quad.String(predicate) {
+			continue
+		}
+
+		var file NinjaFile
+		if err := ncs.loadTo(ctx, &file, q.Object); err != nil {
+			// Order-only dependencies are linked without a file node
+			iri, ok := q.Object.(quad.IRI)
+			if !ok {
+				continue
+			}
+			path := strings.TrimPrefix(string(iri), "file:")
+			file = NinjaFile{ID: iri, Type: "NinjaFile", Path: path, FileType: ncs.inferFileType(path)}
+		}
+		files = append(files, &file)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// GetBuildOutputs returns the targets produced by a build
+func (ncs *NinjaStore) GetBuildOutputs(ctx context.Context, buildID string) ([]*NinjaTarget, error) {
+	links, err := ncs.subjectQuads(ctx, quad.IRI(fmt.Sprintf("build:%s", buildID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outputs of build %s: %w", buildID, err)
+	}
+
+	var targets []*NinjaTarget
+
+	for _, q := range links {
+		if q.Predicate != quad.String(PredicateHasOutput) {
+			continue
+		}
+
+		var target NinjaTarget
+		if err := ncs.loadNode(ctx, &target, q.Object); err != nil {
+			continue // Skip targets we can't load
+		}
+		targets = append(targets, &target)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return targets, nil
 }
 
 // DebugQuads prints all quads in the database for debugging
-func (ncs *NinjaStore) DebugQuads() error {
-	it := ncs.store.QuadsAllIterator()
+func (ncs *NinjaStore) DebugQuads(ctx context.Context) error {
+	it := ncs.allQuadsIterator()
 	defer func(it graph.Iterator) {
 		_ = it.Close()
 	}(it)
@@ -841,7 +1461,7 @@ func (ncs *NinjaStore) DebugQuads() error {
 	fmt.Println("\nDEBUG: All quads in database")
 
 	count := 0
-	for it.Next(ncs.ctx) {
+	for it.Next(ctx) {
 		result := it.Result()
 		if result == nil {
 			continue
@@ -860,8 +1480,147 @@ func (ncs *NinjaStore) DebugQuads() error {
 	return it.Err()
 }
 
+// GetQuads returns up to limit quads after skipping offset, restricted to quads of subject when it is set
+func (ncs *NinjaStore) GetQuads(ctx context.Context, subject string, limit, offset int) (*QuadPage, error) {
+	page := &QuadPage{
+		Quads:  []*QuadRecord{},
+		Offset: offset,
+		Limit:  limit,
+	}
+
+	skipped := 0
+	err := ncs.EachQuad(ctx, subject, func(record *QuadRecord) error {
+		if skipped < offset {
+			skipped++
+			return nil
+		}
+
+		if len(page.Quads) == limit {
+			page.HasMore = true
+			return errStopIteration
+		}
+
+		page.Quads = append(page.Quads, record)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// EachQuad calls fn for every quad in store order, restricted to quads of subject when it is set, it stops
+// early when ctx is done or fn returns an error
+func (ncs *NinjaStore) EachQuad(ctx context.Context, subject string, fn func(*QuadRecord) error) error {
+	var it graph.Iterator
+	if subject != "" {
+		ref := ncs.store.ValueOf(quad.IRI(strings.TrimSuffix(strings.TrimPrefix(subject, "<"), ">")))
+		if ref == nil {
+			return nil
+		}
+		it = ncs.quadIterator(quad.Subject, ref)
+	} else {
+		it = ncs.allQuadsIterator()
+	}
+	defer func(it graph.Iterator) {
+		_ = it.Close()
+	}(it)
+
+	for it.Next(ctx) {
+		result := it.Result()
+		if result == nil {
+			continue
+		}
+
+		q := ncs.store.Quad(result)
+		if q.Subject == nil || q.Predicate == nil || q.Object == nil {
+			continue
+		}
+
+		record := &QuadRecord{
+			Subject:   q.Subject.String(),
+			Predicate: q.Predicate.String(),
+			Object:    q.Object.String(),
+		}
+		if q.Label != nil {
+			record.Label = q.Label.String()
+		}
+
+		if err := fn(record); err != nil {
+			if errors.Is(err, errStopIteration) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("failed to iterate quads: %w", err)
+	}
+
+	return nil
+}
+
+// EachTarget calls fn for every target, restricted to targets built by rule and targets with status when
+// they are set, it stops early when ctx is done or fn returns an error
+func (ncs *NinjaStore) EachTarget(ctx context.Context, rule, status string, fn func(*NinjaTarget) error) error {
+	var builds map[quad.Value]bool
+	if rule != "" {
+		refs, err := ncs.objectQuads(ctx, quad.IRI(fmt.Sprintf("rule:%s", rule)))
+		if err != nil {
+			return fmt.Errorf("failed to find builds of rule %s: %w", rule, err)
+		}
+
+		builds = make(map[quad.Value]bool, len(refs))
+		for _, q := range refs {
+			if q.Predicate.String() == `<rule>` {
+				builds[q.Subject] = true
+			}
+		}
+	}
+
+	subjects, err := ncs.typeSubjects(ctx, "NinjaTarget")
+	if err != nil {
+		return fmt.Errorf("failed to list targets: %w", err)
+	}
+
+	for _, subject := range subjects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var target NinjaTarget
+		if err := ncs.loadTo(ctx, &target, subject); err != nil {
+			continue // Skip targets we can't load
+		}
+
+		if builds != nil && !builds[target.Build] {
+			continue
+		}
+
+		if status != "" && target.Status != status {
+			continue
+		}
+
+		if err := fn(&target); err != nil {
+			if errors.Is(err, errStopIteration) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 // DebugDependencyGraph Add this debug function to understand the graph structure
-func (ncs *NinjaStore) DebugDependencyGraph(filePath string) {
+func (ncs *NinjaStore) DebugDependencyGraph(ctx context.Context, filePath string) {
 	fileIRI := quad.IRI(fmt.Sprintf("file:%s", filePath))
 
 	fmt.Printf("\nDebugging dependency graph for %s\n", filePath)
@@ -870,29 +1629,29 @@ func (ncs *NinjaStore) DebugDependencyGraph(filePath string) {
 	fmt.Println("\nTrying In() traversal")
 	p1 := cayley.StartPath(ncs.store, fileIRI).In(quad.IRI(PredicateDependsOn))
 	var deps1 []NinjaTarget
-	err1 := ncs.schema.LoadPathTo(ncs.ctx, ncs.store, &deps1, p1)
+	err1 := ncs.schema.LoadPathTo(ctx, ncs.store, &deps1, p1)
 	fmt.Printf("In() result: %d items, error: %v\n", len(deps1), err1)
 
 	fmt.Println("\nTrying Out() traversal")
 	p2 := cayley.StartPath(ncs.store, fileIRI).Out(quad.IRI(PredicateDependsOn))
 	var deps2 []NinjaTarget
-	err2 := ncs.schema.LoadPathTo(ncs.ctx, ncs.store, &deps2, p2)
+	err2 := ncs.schema.LoadPathTo(ctx, ncs.store, &deps2, p2)
 	fmt.Printf("Out() result: %d items, error: %v\n", len(deps2), err2)
 
 	fmt.Println("\nChecking Has() approach")
 	p3 := cayley.StartPath(ncs.store).Has(quad.IRI(PredicateDependsOn), fileIRI)
 	var deps3 []NinjaTarget
-	err3 := ncs.schema.LoadPathTo(ncs.ctx, ncs.store, &deps3, p3)
+	err3 := ncs.schema.LoadPathTo(ctx, ncs.store, &deps3, p3)
 	fmt.Printf("Has() result: %d items, error: %v\n", len(deps3), err3)
 
 	fmt.Println("\nRaw quad inspection (first 20 quads)")
-	it := ncs.store.QuadsAllIterator()
+	it := ncs.allQuadsIterator()
 	defer func(it graph.Iterator) {
 		_ = it.Close()
 	}(it)
 
 	count := 0
-	for it.Next(ncs.ctx) && count < 20 {
+	for it.Next(ctx) && count < 20 {
 		result := it.Result()
 		if result == nil {
 			continue
@@ -915,6 +1674,133 @@ func (ncs *NinjaStore) DebugDependencyGraph(filePath string) {
 	}
 }
 
+// quadBuffer collects quads in memory so that a failed write leaves nothing behind
+type quadBuffer struct {
+	quads []quad.Quad
+}
+
+func (b *quadBuffer) WriteQuad(q quad.Quad) error {
+	b.quads = append(b.quads, q)
+	return nil
+}
+
+func (b *quadBuffer) WriteQuads(buf []quad.Quad) (int, error) {
+	b.quads = append(b.quads, buf...)
+	return len(buf), nil
+}
+
+// subjectQuads returns all quads having value as subject
+func (ncs *NinjaStore) subjectQuads(ctx context.Context, value quad.Value) ([]quad.Quad, error) {
+	return ncs.directionQuads(ctx, quad.Subject, value)
+}
+
+// removeNode adds the removal of every quad having value as subject or object to tx
+func (ncs *NinjaStore) removeNode(ctx context.Context, tx *graph.Transaction, value quad.Value) error {
+	for _, d := range []quad.Direction{quad.Subject, quad.Object} {
+		quads, err := ncs.directionQuads(ctx, d, value)
+		if err != nil {
+			return fmt.Errorf("failed to load quads of %s: %w", value, err)
+		}
+
+		for _, q := range quads {
+			tx.RemoveQuad(q)
+		}
+	}
+
+	return nil
+}
+
+// objectQuads returns all quads having value as object
+func (ncs *NinjaStore) objectQuads(ctx context.Context, value quad.Value) ([]quad.Quad, error) {
+	return ncs.directionQuads(ctx, quad.Object, value)
+}
+
+func (ncs *NinjaStore) directionQuads(ctx context.Context, d quad.Direction, value quad.Value) ([]quad.Quad, error) {
+	ref := ncs.store.ValueOf(value)
+	if ref == nil {
+		return nil, nil
+	}
+
+	it := ncs.quadIterator(d, ref)
+	defer func(it graph.Iterator) {
+		_ = it.Close()
+	}(it)
+
+	var quads []quad.Quad
+
+	for it.Next(ctx) {
+		result := it.Result()
+		if result == nil {
+			continue
+		}
+
+		q := ncs.store.Quad(result)
+		if q.Subject == nil || q.Predicate == nil || q.Object == nil {
+			continue
+		}
+
+		quads = append(quads, q)
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate quads: %w", err)
+	}
+
+	return quads, nil
+}
+
+// cancelableIterator stops once the context passed to Next is done, Err then returns the context's error.
+// The bolt iterators don't watch the context themselves, scans of a large graph would run on after the
+// request gave up.
+type cancelableIterator struct {
+	graph.Iterator
+	err error
+}
+
+func (it *cancelableIterator) Next(ctx context.Context) bool {
+	if it.err = ctx.Err(); it.err != nil {
+		return false
+	}
+
+	return it.Iterator.Next(ctx)
+}
+
+func (it *cancelableIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+
+	return it.Iterator.Err()
+}
+
+// quadIterator iterates the quads having ref in direction d
+func (ncs *NinjaStore) quadIterator(d quad.Direction, ref graph.Ref) graph.Iterator {
+	return &cancelableIterator{Iterator: ncs.store.QuadIterator(d, ref)}
+}
+
+// allQuadsIterator iterates every quad of the store
+func (ncs *NinjaStore) allQuadsIterator() graph.Iterator {
+	return &cancelableIterator{Iterator: ncs.store.QuadsAllIterator()}
+}
+
+// typeSubjects returns the subjects declared with the given rdf:type
+func (ncs *NinjaStore) typeSubjects(ctx context.Context, typeName string) ([]quad.Value, error) {
+	refs, err := ncs.objectQuads(ctx, quad.IRI(typeName))
+	if err != nil {
+		return nil, err
+	}
+
+	var subjects []quad.Value
+
+	for _, q := range refs {
+		if q.Predicate.String() == `<rdf:type>` {
+			subjects = append(subjects, q.Subject)
+		}
+	}
+
+	return subjects, nil
+}
+
 // inferFileType infers file type from extension
 func (ncs *NinjaStore) inferFileType(path string) string {
 	ext := strings.ToLower(path[strings.LastIndex(path, ".")+1:])
@@ -1 +1,78 @@
 package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// crash leaves the store as a killed process would, its private directory is lost with /dev/shm
+func crash(t *testing.T, ncs *NinjaStore) {
+	t.Helper()
+
+	ncs.atRest.checkpoints.Wait()
+
+	if err := ncs.store.Close(); err != nil {
+		t.Fatalf("failed to close store: %v", err)
+	}
+
+	ncs.lock.release()
+	_ = os.RemoveAll(ncs.atRest.workDir)
+}
+
+func TestEncryptedStoreRecoversJournalAfterCrash(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "ninja.db")
+	key := make([]byte, 32)
+
+	ncs, err := NewEncryptedNinjaStore(dbPath, key)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+
+	// Past a background checkpoint, the writes after it are only in the journal
+	rules := checkpointWrites + 10
+	for i := 0; i < rules; i++ {
+		rule := &NinjaRule{Name: fmt.Sprintf("rule%d", i), Command: "cc $in", Variables: "{}"}
+		if _, err := ncs.AddRule(ctx, rule); err != nil {
+			t.Fatalf("failed to add rule %d: %v", i, err)
+		}
+	}
+
+	crash(t, ncs)
+
+	if _, err := os.Stat(filepath.Join(dbPath, boltFile)); !os.IsNotExist(err) {
+		t.Fatalf("store left in the clear: %v", err)
+	}
+
+	entries, err := filepath.Glob(filepath.Join(dbPath+journalSuffix, "*.nq"+encryptedSuffix))
+	if err != nil || len(entries) == 0 || len(entries) >= rules {
+		t.Fatalf("journal has %d entries after %d writes and a checkpoint: %v", len(entries), rules, err)
+	}
+
+	if _, err := NewNinjaStore(dbPath); err == nil {
+		t.Fatal("opened encrypted store without a key")
+	}
+
+	ncs, err = NewEncryptedNinjaStore(dbPath, key)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+
+	defer func(ncs *NinjaStore) {
+		_ = ncs.Close()
+	}(ncs)
+
+	if ncs.replayed != len(entries) {
+		t.Errorf("replayed %d journal entries, want %d", ncs.replayed, len(entries))
+	}
+
+	for _, i := range []int{0, checkpointWrites - 1, rules - 1} {
+		rule, err := ncs.GetRule(ctx, fmt.Sprintf("rule%d", i))
+		if err != nil || rule.Command != "cc $in" {
+			t.Errorf("rule%d not recovered: %v", i, err)
+		}
+	}
+}
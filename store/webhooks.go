@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// NinjaWebhook is a URL receiving signed event notifications
+type NinjaWebhook struct {
+	ID        quad.IRI `json:"-" quad:"@id"`
+	Type      quad.IRI `json:"-" quad:"@type"`
+	WebhookID string   `json:"id" quad:"webhook_id"`
+	URL       string   `json:"url" quad:"url"`
+	Secret    string   `json:"-" quad:"secret"`
+	Events    string   `json:"-" quad:"events"`
+	CreatedAt string   `json:"created_at" quad:"created_at"`
+}
+
+// GetEvents returns the event types the webhook subscribes to, empty means all events
+func (w *NinjaWebhook) GetEvents() ([]string, error) {
+	var events []string
+	if w.Events == "" {
+		return events, nil
+	}
+
+	err := json.Unmarshal([]byte(w.Events), &events)
+
+	return events, err
+}
+
+// SetEvents sets the event types the webhook subscribes to
+func (w *NinjaWebhook) SetEvents(events []string) error {
+	if events == nil {
+		events = []string{}
+	}
+
+	data, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	w.Events = string(data)
+
+	return nil
+}
+
+// AddWebhook stores a webhook under its WebhookID
+func (ncs *NinjaStore) AddWebhook(ctx context.Context, webhook *NinjaWebhook) error {
+	if webhook.WebhookID == "" || webhook.URL == "" || webhook.Secret == "" {
+		return fmt.Errorf("webhook id, url and secret are required")
+	}
+
+	if webhook.Events == "" {
+		webhook.Events = "[]"
+	}
+
+	webhook.ID = quad.IRI(fmt.Sprintf("webhook:%s", webhook.WebhookID))
+	webhook.Type = "NinjaWebhook"
+
+	tx := graph.NewTransaction()
+	if _, err := ncs.schema.WriteAsQuads(graph.NewTxWriter(tx, graph.Add), webhook); err != nil {
+		return fmt.Errorf("failed to write webhook: %w", err)
+	}
+
+	return ncs.store.ApplyTransaction(tx)
+}
+
+// GetWebhook returns the webhook with the given id
+func (ncs *NinjaStore) GetWebhook(ctx context.Context, id string) (*NinjaWebhook, error) {
+	var webhook NinjaWebhook
+
+	err := ncs.loadTo(ctx, &webhook, quad.IRI(fmt.Sprintf("webhook:%s", id)))
+	if err != nil {
+		return nil, fmt.Errorf("webhook %s: %w", id, ErrNotFound)
+	}
+
+	return &webhook, nil
+}
+
+// ListWebhooks returns all webhooks
+func (ncs *NinjaStore) ListWebhooks(ctx context.Context) ([]*NinjaWebhook, error) {
+	subjects, err := ncs.typeSubjects(ctx, "NinjaWebhook")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	var webhooks []*NinjaWebhook
+
+	for _, subject := range subjects {
+		var webhook NinjaWebhook
+		if err := ncs.loadTo(ctx, &webhook, subject); err != nil {
+			continue // Skip webhooks we can't load
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes the webhook with the given id
+func (ncs *NinjaStore) DeleteWebhook(ctx context.Context, id string) error {
+	old, err := ncs.subjectQuads(ctx, quad.IRI(fmt.Sprintf("webhook:%s", id)))
+	if err != nil {
+		return fmt.Errorf("failed to load webhook %s: %w", id, err)
+	}
+
+	if len(old) == 0 {
+		return fmt.Errorf("webhook %s: %w", id, ErrNotFound)
+	}
+
+	tx := graph.NewTransaction()
+	for _, q := range old {
+		tx.RemoveQuad(q)
+	}
+
+	return ncs.store.ApplyTransaction(tx)
+}
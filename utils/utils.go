@@ -2,6 +2,9 @@ package utils
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -36,3 +39,23 @@ func WriteTable(_ context.Context, data [][]string) error {
 
 	return nil
 }
+
+// NewLogger creates a structured logger, format is either "text" or "json". Records logged with a
+// context carrying a request ID include it.
+func NewLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %s: %w", level, err)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case "", "text":
+		return slog.New(requestIDHandler{Handler: slog.NewTextHandler(w, opts)}), nil
+	case "json":
+		return slog.New(requestIDHandler{Handler: slog.NewJSONHandler(w, opts)}), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %s", format)
+	}
+}